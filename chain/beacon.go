@@ -3,8 +3,12 @@ package chain
 import (
 	"bytes"
 	"crypto/sha256"
+	"encoding/binary"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"math"
+	"time"
 
 	json "github.com/nikkolasg/hexjson"
 
@@ -39,6 +43,55 @@ func (b *Beacon) Unmarshal(buff []byte) error {
 	return json.Unmarshal(buff, b)
 }
 
+// Encode returns the binary encoding of the beacon, used to send beacons in
+// bulk:
+//
+//	[round: 8 bytes LE][prev_sig_len: 2 bytes LE][prev_sig][sig_len: 2 bytes LE][sig]
+func (b *Beacon) Encode() ([]byte, error) {
+	if len(b.PreviousSig) > math.MaxUint16 || len(b.Signature) > math.MaxUint16 {
+		return nil, errors.New("signature too long to encode the beacon")
+	}
+	buff := make([]byte, 12+len(b.PreviousSig)+len(b.Signature))
+	binary.LittleEndian.PutUint64(buff, b.Round)
+	n := 8
+	for _, sig := range [][]byte{b.PreviousSig, b.Signature} {
+		binary.LittleEndian.PutUint16(buff[n:], uint16(len(sig)))
+		n += 2
+		n += copy(buff[n:], sig)
+	}
+	return buff, nil
+}
+
+// DecodeBeacon decodes a beacon encoded with Encode. An empty signature is
+// decoded as nil.
+func DecodeBeacon(buff []byte) (*Beacon, error) {
+	if len(buff) < 8 {
+		return nil, errors.New("encoded beacon too short")
+	}
+	b := &Beacon{Round: binary.LittleEndian.Uint64(buff)}
+	buff = buff[8:]
+	var sigs [2][]byte
+	for i := range sigs {
+		if len(buff) < 2 {
+			return nil, errors.New("encoded beacon too short")
+		}
+		l := int(binary.LittleEndian.Uint16(buff))
+		buff = buff[2:]
+		if len(buff) < l {
+			return nil, errors.New("encoded beacon too short")
+		}
+		if l > 0 {
+			sigs[i] = append([]byte(nil), buff[:l]...)
+		}
+		buff = buff[l:]
+	}
+	if len(buff) != 0 {
+		return nil, fmt.Errorf("encoded beacon has %d trailing bytes", len(buff))
+	}
+	b.PreviousSig, b.Signature = sigs[0], sigs[1]
+	return b, nil
+}
+
 // Randomness returns the hashed signature. It is an example that uses sha256,
 // but it could use blake2b for example.
 func (b *Beacon) Randomness() []byte {
@@ -50,6 +103,13 @@ func (b *Beacon) GetRound() uint64 {
 	return b.Round
 }
 
+// Time returns the time at which the round of the beacon is expected to be
+// generated, given the period and genesis time of the chain. See TimeOfRound
+// for the behavior on invalid rounds.
+func (b *Beacon) Time(period time.Duration, genesis int64) time.Time {
+	return time.Unix(TimeOfRound(period, genesis, b.Round), 0)
+}
+
 // RandomnessFromSignature derives the round randomness from its signature
 func RandomnessFromSignature(sig []byte) []byte {
 	out := sha256.Sum256(sig)
@@ -81,6 +141,24 @@ func Verify(pubkey kyber.Point, prevSig, signature []byte, round uint64) error {
 	})
 }
 
+// ErrGroupVerificationRequired is returned by VerifySingleNode for a chain
+// whose threshold is above 1: its beacons must be verified against the
+// distributed public key of the group.
+var ErrGroupVerificationRequired = errors.New("chain: threshold above 1, the beacon must be verified against the group key")
+
+// VerifySingleNode verifies the beacon of a chain of threshold 1, e.g. a
+// single node test setup, against the public key of the share of a node,
+// without the group. With a threshold of 1, the partial signature of any
+// node is the signature of the beacon, and the public key of its share is
+// the distributed public key. It returns ErrGroupVerificationRequired if the
+// threshold is above 1.
+func VerifySingleNode(pubKey kyber.Point, threshold int, b *Beacon) error {
+	if threshold > 1 {
+		return ErrGroupVerificationRequired
+	}
+	return VerifyBeacon(pubKey, b)
+}
+
 // Message returns a slice of bytes as the message to sign or to verify
 // alongside a beacon signature.
 // H ( prevSig || currRound)
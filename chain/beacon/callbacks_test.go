@@ -1,6 +1,7 @@
 package beacon
 
 import (
+	"context"
 	"io/ioutil"
 	"os"
 	"testing"
@@ -38,6 +39,39 @@ func TestStoreCallback(t *testing.T) {
 	require.False(t, checkOne(doneCh))
 }
 
+func TestStoreDrainCallbacks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "*")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	bbstore, err := boltdb.NewBoltStore(dir, nil)
+	require.NoError(t, err)
+	cb := NewCallbackStore(bbstore)
+	defer cb.Close()
+
+	// nothing to drain yet
+	require.NoError(t, cb.DrainCallbacks(context.Background()))
+
+	release := make(chan bool)
+	var called bool
+	cb.AddCallback("slow", func(b *chain.Beacon) {
+		<-release
+		called = true
+	})
+	require.NoError(t, cb.Put(&chain.Beacon{Round: 1}))
+
+	// the callback is still blocked so draining times out
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	require.Equal(t, context.DeadlineExceeded, cb.DrainCallbacks(ctx))
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+	}()
+	require.NoError(t, cb.DrainCallbacks(context.Background()))
+	require.True(t, called)
+}
+
 func checkOne(ch chan bool) bool {
 	select {
 	case <-ch:
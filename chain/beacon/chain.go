@@ -3,6 +3,7 @@ package beacon
 import (
 	"context"
 	"fmt"
+	"runtime/debug"
 
 	"github.com/drand/drand/chain"
 	"github.com/drand/drand/key"
@@ -27,6 +28,7 @@ type chainStore struct {
 	sync        Syncer
 	crypto      *cryptoStore
 	ticker      *ticker
+	peers       *excludedPeers
 	done        chan bool
 	newPartials chan partialInfo
 	// catchupBeacons is used to notify the Handler when a node has aggregated a
@@ -37,7 +39,7 @@ type chainStore struct {
 	beaconStoredAgg chan *chain.Beacon
 }
 
-func newChainStore(l log.Logger, cf *Config, cl net.ProtocolClient, c *cryptoStore, store chain.Store, t *ticker) *chainStore {
+func newChainStore(l log.Logger, cf *Config, cl net.ProtocolClient, c *cryptoStore, store chain.Store, t *ticker, p *excludedPeers) *chainStore {
 	// we make sure the chain is increasing monotically
 	as := newAppendStore(store)
 	// we write some stats about the timing when new beacon is saved
@@ -54,6 +56,7 @@ func newChainStore(l log.Logger, cf *Config, cl net.ProtocolClient, c *cryptoSto
 		sync:            syncer,
 		crypto:          c,
 		ticker:          t,
+		peers:           p,
 		done:            make(chan bool, 1),
 		newPartials:     make(chan partialInfo, defaultPartialChanBuffer),
 		catchupBeacons:  make(chan *chain.Beacon, 1),
@@ -103,72 +106,95 @@ func (c *chainStore) runAggregator() {
 			cache.FlushRounds(lastBeacon.Round)
 			break
 		case partial := <-c.newPartials:
-			// look if we have info for this round first
-			pRound := partial.p.GetRound()
-			// look if we want to store ths partial anyway
-			isNotInPast := pRound > lastBeacon.Round
-			isNotTooFar := pRound <= lastBeacon.Round+uint64(partialCacheStoreLimit+1)
-			shouldStore := isNotInPast && isNotTooFar
-			// check if we can reconstruct
-			if !shouldStore {
-				c.l.Debug("ignoring_partial", partial.p.GetRound(), "last_beacon_stored", lastBeacon.Round)
-				break
-			}
-			// NOTE: This line means we can only verify partial signatures of
-			// the current group we are in as only current members should
-			// participate in the randomness generation. Previous beacons can be
-			// verified using the single distributed public key point from the
-			// crypto store.
-			thr := c.crypto.GetGroup().Threshold
-			n := c.crypto.GetGroup().Len()
-			cache.Append(partial.p)
-			roundCache := cache.GetRoundCache(partial.p.GetRound(), partial.p.GetPreviousSig())
-			if roundCache == nil {
-				c.l.Error("store_partial", partial.addr, "no_round_cache", partial.p.GetRound())
-				break
-			}
+			lastBeacon = c.processPartial(cache, lastBeacon, partial)
+		}
+	}
+}
 
-			c.l.Debug("store_partial", partial.addr, "round", roundCache.round, "len_partials", fmt.Sprintf("%d/%d", roundCache.Len(), thr))
-			if roundCache.Len() < thr {
-				break
-			}
+// processPartial adds the partial to the cache and aggregates the beacon of
+// its round if there are enough partials. It returns the last beacon, i.e. the
+// new one if it could be appended to the chain. A panic, for example due to a
+// malformed partial, is logged and leaves the aggregation loop running.
+func (c *chainStore) processPartial(cache *partialCache, last *chain.Beacon, partial partialInfo) (lastBeacon *chain.Beacon) {
+	lastBeacon = last
+	defer recoverLoop(c.l, "chain_aggregator")
+	// look if we have info for this round first
+	pRound := partial.p.GetRound()
+	// look if we want to store ths partial anyway
+	isNotInPast := pRound > lastBeacon.Round
+	isNotTooFar := pRound <= lastBeacon.Round+uint64(partialCacheStoreLimit+1)
+	shouldStore := isNotInPast && isNotTooFar
+	// check if we can reconstruct
+	if !shouldStore {
+		c.l.Debug("ignoring_partial", partial.p.GetRound(), "last_beacon_stored", lastBeacon.Round)
+		return lastBeacon
+	}
+	// NOTE: This line means we can only verify partial signatures of
+	// the current group we are in as only current members should
+	// participate in the randomness generation. Previous beacons can be
+	// verified using the single distributed public key point from the
+	// crypto store.
+	group := c.crypto.GetGroup()
+	thr := group.Threshold
+	n := group.Len()
+	idx, _ := key.Scheme.IndexOf(partial.p.GetPartialSig())
+	if c.peers.indexExcluded(group, idx) {
+		c.l.Debug("ignoring_partial", partial.p.GetRound(), "skipped_peer", idx)
+		return lastBeacon
+	}
+	cache.Append(partial.p)
+	roundCache := cache.GetRoundCache(partial.p.GetRound(), partial.p.GetPreviousSig())
+	if roundCache == nil {
+		c.l.Error("store_partial", partial.addr, "no_round_cache", partial.p.GetRound())
+		return lastBeacon
+	}
 
-			msg := roundCache.Msg()
-			finalSig, err := key.Scheme.Recover(c.crypto.GetPub(), msg, roundCache.Partials(), thr, n)
-			if err != nil {
-				c.l.Debug("invalid_recovery", err, "round", pRound, "got", fmt.Sprintf("%d/%d", roundCache.Len(), n))
-				break
-			}
-			if err := key.Scheme.VerifyRecovered(c.crypto.GetPub().Commit(), msg, finalSig); err != nil {
-				c.l.Error("invalid_sig", err, "round", pRound)
-				break
-			}
-			cache.FlushRounds(partial.p.GetRound())
-			newBeacon := &chain.Beacon{
-				Round:       roundCache.round,
-				PreviousSig: roundCache.prev,
-				Signature:   finalSig,
-			}
-			c.l.Info("aggregated_beacon", newBeacon.Round)
-			if c.tryAppend(lastBeacon, newBeacon) {
-				lastBeacon = newBeacon
-				break
-			}
-			// XXX store them for lfutur usage if it's a later round than what
-			// we have
-			c.l.Debug("new_aggregated", "not_appendable", "last", lastBeacon.String(), "new", newBeacon.String())
-			if c.shouldSync(lastBeacon, newBeacon) {
-				peers := toPeers(c.crypto.GetGroup().Nodes)
-				go func() {
-					// XXX Could do something smarter with context and cancellation
-					// if we got to the right round
-					if err := c.sync.Follow(context.Background(), newBeacon.Round, peers); err != nil {
-						c.l.Debug("chain_store", "unable to follow", "err", err)
-					}
-				}()
+	c.l.Debug("store_partial", partial.addr, "round", roundCache.round, "len_partials", fmt.Sprintf("%d/%d", roundCache.Len(), thr))
+	if roundCache.Len() < thr {
+		return lastBeacon
+	}
+
+	msg := roundCache.Msg()
+	finalSig, err := key.Scheme.Recover(c.crypto.GetPub(), msg, roundCache.Partials(), thr, n)
+	if err != nil {
+		c.l.Debug("invalid_recovery", err, "round", pRound, "got", fmt.Sprintf("%d/%d", roundCache.Len(), n))
+		return lastBeacon
+	}
+	if err := key.Scheme.VerifyRecovered(c.crypto.GetPub().Commit(), msg, finalSig); err != nil {
+		c.l.Error("invalid_sig", err, "round", pRound)
+		return lastBeacon
+	}
+	cache.FlushRounds(partial.p.GetRound())
+	newBeacon := &chain.Beacon{
+		Round:       roundCache.round,
+		PreviousSig: roundCache.prev,
+		Signature:   finalSig,
+	}
+	c.l.Info("aggregated_beacon", newBeacon.Round)
+	if c.tryAppend(lastBeacon, newBeacon) {
+		return newBeacon
+	}
+	// XXX store them for lfutur usage if it's a later round than what
+	// we have
+	c.l.Debug("new_aggregated", "not_appendable", "last", lastBeacon.String(), "new", newBeacon.String())
+	if c.shouldSync(lastBeacon, newBeacon) {
+		peers := toPeers(c.crypto.GetGroup().Nodes)
+		go func() {
+			// XXX Could do something smarter with context and cancellation
+			// if we got to the right round
+			if err := c.sync.Follow(context.Background(), newBeacon.Round, peers); err != nil {
+				c.l.Debug("chain_store", "unable to follow", "err", err)
 			}
-			break
-		}
+		}()
+	}
+	return lastBeacon
+}
+
+// recoverLoop recovers from a panic in an iteration of a beacon loop and logs
+// it, so the loop keeps running.
+func recoverLoop(l log.Logger, loop string) {
+	if err := recover(); err != nil {
+		l.Error(loop, "panic", "err", err, "stack", string(debug.Stack()))
 	}
 }
 
@@ -6,6 +6,10 @@ import "time"
 // from one peer
 var MaxSyncWaitTime = 2 * time.Second
 
+// CatchupProgressPeriod is the interval at which the handler reports the
+// progress of a catchup.
+var CatchupProgressPeriod = 1 * time.Second
+
 // MaxPartialsPerNode is the maximum number of partials the cache stores about
 // any node at any given time. This constant could be much lower, 3 for example
 // but when the network is catching up, it may happen that some nodes goes much
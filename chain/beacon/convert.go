@@ -1,7 +1,11 @@
 package beacon
 
 import (
+	"context"
+
 	"github.com/drand/drand/chain"
+	"github.com/drand/drand/log"
+	"github.com/drand/drand/net"
 	proto "github.com/drand/drand/protobuf/drand"
 )
 
@@ -20,3 +24,28 @@ func protoToBeacon(p *proto.BeaconPacket) *chain.Beacon {
 		PreviousSig: p.GetPreviousSig(),
 	}
 }
+
+// unpackBatches decodes the beacons of the batches received from a PushSync
+// and sends them one by one on the returned channel. It stops at the first
+// beacon that can not be decoded.
+func unpackBatches(ctx context.Context, batches chan *proto.BeaconBatch, l log.Logger) chan *proto.BeaconPacket {
+	beacons := make(chan *proto.BeaconPacket, net.MaxSyncBuffer)
+	go func() {
+		defer close(beacons)
+		for batch := range batches {
+			for _, buff := range batch.GetBeacons() {
+				b, err := chain.DecodeBeacon(buff)
+				if err != nil {
+					l.Debug("syncer", "invalid_encoded_beacon", "err", err)
+					return
+				}
+				select {
+				case beacons <- beaconToProto(b):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return beacons
+}
@@ -1,12 +1,14 @@
 package beacon
 
 import (
+	"bytes"
 	"context"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/drand/drand/chain"
@@ -29,6 +31,9 @@ type Config struct {
 	Group *key.Group
 	// Clock to use - useful to testing
 	Clock clock.Clock
+	// PeersFile is the file where the peers excluded with ExcludePeer are
+	// kept. They are not persisted if empty.
+	PeersFile string
 }
 
 // Handler holds the logic to initiate, and react to the TBLS protocol. Each time
@@ -43,12 +48,20 @@ type Handler struct {
 	// main logic that treats incoming packet / new beacons created
 	chain  *chainStore
 	ticker *ticker
+	// progress of the catchup, if any
+	catchupCh chan CatchupStatus
+	// catchupLock serializes the updates of catchupCh with its closing, which
+	// happens once even when several catchups run
+	catchupLock   sync.Mutex
+	catchupClosed bool
 
 	close   chan bool
 	addr    string
 	started bool
 	stopped bool
-	l       log.Logger
+	// paused is set while the generation is halted by Pause
+	paused bool
+	l      log.Logger
 }
 
 // NewHandler returns a fresh handler ready to serve and create randomness
@@ -70,21 +83,54 @@ func NewHandler(c net.ProtocolClient, s chain.Store, conf *Config, l log.Logger)
 		return nil, err
 	}
 
+	peers, err := loadExcludedPeers(conf.PeersFile)
+	if err != nil {
+		return nil, fmt.Errorf("beacon: can't load the excluded peers: %w", err)
+	}
+
 	ticker := newTicker(conf.Clock, conf.Group.Period, conf.Group.GenesisTime)
-	store := newChainStore(logger, conf, c, crypto, s, ticker)
+	store := newChainStore(logger, conf, c, crypto, s, ticker, peers)
 	handler := &Handler{
-		conf:   conf,
-		client: c,
-		crypto: crypto,
-		chain:  store,
-		ticker: ticker,
-		addr:   addr,
-		close:  make(chan bool),
-		l:      logger,
+		conf:      conf,
+		client:    c,
+		crypto:    crypto,
+		chain:     store,
+		ticker:    ticker,
+		catchupCh: make(chan CatchupStatus, 1),
+		addr:      addr,
+		close:     make(chan bool),
+		l:         logger,
 	}
 	return handler, nil
 }
 
+// RoundAt returns the round of the chain active at time t, or 0 if t is before
+// the genesis time.
+func (h *Handler) RoundAt(t time.Time) uint64 {
+	return chain.RoundAt(t, h.conf.Group.Period, h.conf.Group.GenesisTime)
+}
+
+// VerifyBeacon returns an error if the given beacon is not a valid beacon of
+// the chain: its round must be positive, its signature must verify against the
+// distributed public key and its previous signature must be the signature of
+// the previous round in the store.
+func (h *Handler) VerifyBeacon(b *chain.Beacon) error {
+	if b.Round == 0 {
+		return errors.New("beacon: invalid round 0")
+	}
+	if err := chain.VerifyBeacon(h.crypto.chain.PublicKey, b); err != nil {
+		return fmt.Errorf("beacon: invalid signature for round %d: %w", b.Round, err)
+	}
+	prev, err := h.chain.Get(b.Round - 1)
+	if err != nil {
+		return fmt.Errorf("beacon: can't load previous round %d: %w", b.Round-1, err)
+	}
+	if !bytes.Equal(prev.Signature, b.PreviousSig) {
+		return fmt.Errorf("beacon: previous signature of round %d does not match the chain", b.Round)
+	}
+	return nil
+}
+
 var errOutOfRound = "out-of-round beacon request"
 
 // ProcessPartialBeacon receives a request for a beacon partial signature. It
@@ -136,11 +182,62 @@ func (h *Handler) ProcessPartialBeacon(c context.Context, p *proto.PartialBeacon
 	return new(proto.Empty), nil
 }
 
+// ExcludePeer excludes the peer at addr, a node of the group, from the
+// aggregation of the partial beacons, or includes it back: the partials of an
+// excluded peer are skipped, without changing the group. The excluded peers
+// are saved to the PeersFile of the config.
+func (h *Handler) ExcludePeer(addr string, excluded bool) error {
+	if addr == h.addr {
+		return errors.New("beacon: can't exclude the node itself")
+	}
+	found := false
+	for _, n := range h.crypto.GetGroup().Nodes {
+		found = found || n.Address() == addr
+	}
+	if !found {
+		return fmt.Errorf("beacon: no peer %s in the group", addr)
+	}
+	if err := h.chain.peers.Set(addr, excluded); err != nil {
+		return fmt.Errorf("beacon: can't exclude %s: %w", addr, err)
+	}
+	h.l.Info("exclude_peer", addr, "excluded", excluded)
+	return nil
+}
+
+// PeerExcluded returns true if the peer at addr is excluded with ExcludePeer.
+func (h *Handler) PeerExcluded(addr string) bool {
+	return h.chain.peers.Excluded(addr)
+}
+
 // Store returns the store associated with this beacon handler
 func (h *Handler) Store() chain.Store {
 	return h.chain
 }
 
+// HistoricalRand returns the beacon of the given round from the store. Round 0
+// is the genesis beacon. Like a slice index in Python, a negative round counts
+// backward from the last beacon: -1 is the last beacon, -2 the one before, and
+// so on. Since the round is unsigned, a negative round n is passed as
+// uint64(int64(n)), e.g. uint64(math.MaxUint64) for -1.
+func (h *Handler) HistoricalRand(round uint64) (*chain.Beacon, error) {
+	if offset := int64(round); offset < 0 {
+		last, err := h.chain.Last()
+		if err != nil {
+			return nil, fmt.Errorf("beacon: can't load last beacon: %w", err)
+		}
+		// -1 is the last beacon, -(last.Round+1) the genesis one
+		if uint64(-offset) > last.Round+1 {
+			return nil, fmt.Errorf("beacon: offset %d is before the genesis beacon, last round is %d", offset, last.Round)
+		}
+		round = last.Round + 1 - uint64(-offset)
+	}
+	b, err := h.chain.Get(round)
+	if err != nil {
+		return nil, fmt.Errorf("beacon: can't get round %d: %w", round, err)
+	}
+	return b, nil
+}
+
 // Start runs the beacon protocol (threshold BLS signature). The first round
 // will sign the message returned by the config.FirstRound() function. If the
 // genesis time specified in the group is already passed, Start returns an
@@ -167,7 +264,87 @@ func (h *Handler) Start() error {
 func (h *Handler) Catchup() {
 	nRound, tTime := chain.NextRound(h.conf.Clock.Now().Unix(), h.conf.Group.Period, h.conf.Group.GenesisTime)
 	go h.run(tTime)
+	done := make(chan bool)
+	go h.reportCatchup(nRound, done)
 	h.chain.RunSync(context.Background(), nRound, nil)
+	close(done)
+}
+
+// CatchupStatus describes the progress of a node catching up with the chain.
+type CatchupStatus struct {
+	// StoredRound is the last round stored locally
+	StoredRound uint64
+	// TargetRound is the round the node is catching up to
+	TargetRound uint64
+	// BytesDownloaded is the size of the beacons stored since the start of the
+	// catchup
+	BytesDownloaded uint64
+}
+
+// CatchupProgress returns a channel that receives updates about the progress
+// of the catchup every CatchupProgressPeriod. The channel is closed once the
+// catchup is done. Only the latest update is kept if nobody reads from the
+// channel.
+func (h *Handler) CatchupProgress() <-chan CatchupStatus {
+	return h.catchupCh
+}
+
+// sendCatchup pushes the status to catchupCh, replacing the update nobody read
+// yet. It does nothing once the channel is closed.
+func (h *Handler) sendCatchup(status CatchupStatus) {
+	h.catchupLock.Lock()
+	defer h.catchupLock.Unlock()
+	if h.catchupClosed {
+		return
+	}
+	select {
+	case <-h.catchupCh:
+	default:
+	}
+	h.catchupCh <- status
+}
+
+func (h *Handler) closeCatchup() {
+	h.catchupLock.Lock()
+	defer h.catchupLock.Unlock()
+	if h.catchupClosed {
+		return
+	}
+	h.catchupClosed = true
+	close(h.catchupCh)
+}
+
+func (h *Handler) reportCatchup(target uint64, done chan bool) {
+	defer h.closeCatchup()
+	var downloaded uint64
+	id := fmt.Sprintf("catchup_progress_%p", done)
+	h.chain.AddCallback(id, func(b *chain.Beacon) {
+		size := len(b.PreviousSig) + len(b.Signature) + 8
+		atomic.AddUint64(&downloaded, uint64(size))
+	})
+	defer h.chain.RemoveCallback(id)
+
+	send := func() {
+		status := CatchupStatus{
+			TargetRound:     target,
+			BytesDownloaded: atomic.LoadUint64(&downloaded),
+		}
+		if last, err := h.chain.Last(); err == nil {
+			status.StoredRound = last.Round
+		}
+		h.sendCatchup(status)
+	}
+	ticker := time.NewTicker(CatchupProgressPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			send()
+		case <-done:
+			send()
+			return
+		}
+	}
 }
 
 // Transition makes this beacon continuously sync until the time written in the
@@ -219,29 +396,9 @@ func (h *Handler) run(startTime int64) {
 	for {
 		select {
 		case current = <-chanTick:
-			lastBeacon, err := h.chain.Last()
-			if err != nil {
-				h.l.Error("beacon_loop", "loading_last", "err", err)
-				break
-			}
-			h.l.Debug("beacon_loop", "new_round", "round", current.round, "lastbeacon", lastBeacon.Round)
-			h.broadcastNextPartial(current, lastBeacon)
-			// if the next round of the last beacon we generated is not the round we
-			// are now, that means there is a gap between the two rounds. In other
-			// words, the chain has halted for that amount of rounds or our
-			// network is not functioning properly.
-			if lastBeacon.Round+1 < current.round {
-				// We also launch a sync with the other nodes. If there is one node
-				// that has a higher beacon, we'll build on it next epoch. If
-				// nobody has a higher beacon, then this one will be next if the
-				// network conditions allow for it.
-				// XXX find a way to start the catchup as soon as the runsync is
-				// done. Not critical but leads to faster network recovery.
-				h.l.Debug("beacon_loop", "run_sync_catchup", "last_is", lastBeacon, "should_be", current.round)
-				go h.chain.RunSync(context.Background(), current.round, nil)
-			}
+			h.runRound(current)
 		case b := <-h.chain.AppendedBeaconNoSync():
-			if b.Round < current.round {
+			if b.Round < current.round && !h.isPaused() {
 				// When network is down, all alive nodes will broadcast their
 				// signatures periodically with the same period. As soon as one
 				// new beacon is created,i.e. network is up again, this channel
@@ -252,6 +409,7 @@ func (h *Handler) run(startTime int64) {
 				// channel will trigger again etc until we arrive at the correct
 				// round.
 				go func(c roundInfo, latest *chain.Beacon) {
+					defer recoverLoop(h.l, "beacon_loop")
 					h.conf.Clock.Sleep(h.conf.Group.CatchupPeriod)
 					h.broadcastNextPartial(c, latest)
 				}(current, b)
@@ -263,6 +421,38 @@ func (h *Handler) run(startTime int64) {
 	}
 }
 
+// runRound broadcasts the partial signature of the given round upon the last
+// beacon, and syncs with the other nodes if the chain is behind. A panic is
+// logged and leaves the beacon loop running.
+func (h *Handler) runRound(current roundInfo) {
+	defer recoverLoop(h.l, "beacon_loop")
+	if h.isPaused() {
+		h.l.Debug("beacon_loop", "paused", "round", current.round)
+		return
+	}
+	lastBeacon, err := h.chain.Last()
+	if err != nil {
+		h.l.Error("beacon_loop", "loading_last", "err", err)
+		return
+	}
+	h.l.Debug("beacon_loop", "new_round", "round", current.round, "lastbeacon", lastBeacon.Round)
+	h.broadcastNextPartial(current, lastBeacon)
+	// if the next round of the last beacon we generated is not the round we
+	// are now, that means there is a gap between the two rounds. In other
+	// words, the chain has halted for that amount of rounds or our
+	// network is not functioning properly.
+	if lastBeacon.Round+1 < current.round {
+		// We also launch a sync with the other nodes. If there is one node
+		// that has a higher beacon, we'll build on it next epoch. If
+		// nobody has a higher beacon, then this one will be next if the
+		// network conditions allow for it.
+		// XXX find a way to start the catchup as soon as the runsync is
+		// done. Not critical but leads to faster network recovery.
+		h.l.Debug("beacon_loop", "run_sync_catchup", "last_is", lastBeacon, "should_be", current.round)
+		go h.chain.RunSync(context.Background(), current.round, nil)
+	}
+}
+
 func (h *Handler) broadcastNextPartial(current roundInfo, upon *chain.Beacon) {
 	ctx := context.Background()
 	previousSig := upon.Signature
@@ -322,6 +512,32 @@ func (h *Handler) Stop() {
 	h.l.Info("beacon", "stop")
 }
 
+// Pause halts the generation of new beacons without stopping the handler: the
+// loop does not broadcast partial signatures nor sync with the other nodes
+// until Resume is called, while the store can still be read.
+func (h *Handler) Pause() {
+	h.Lock()
+	defer h.Unlock()
+	h.paused = true
+	h.l.Info("beacon", "pause")
+}
+
+// Resume restarts the generation halted by Pause. At the next round, the loop
+// builds on the last stored beacon and syncs with the other nodes to fetch the
+// beacons created in the meantime.
+func (h *Handler) Resume() {
+	h.Lock()
+	defer h.Unlock()
+	h.paused = false
+	h.l.Info("beacon", "resume")
+}
+
+func (h *Handler) isPaused() bool {
+	h.Lock()
+	defer h.Unlock()
+	return h.paused
+}
+
 // StopAt will stop the handler at the given time. It is useful when
 // transitionining for a resharing.
 func (h *Handler) StopAt(stopTime int64) error {
@@ -337,6 +553,43 @@ func (h *Handler) StopAt(stopTime int64) error {
 	return nil
 }
 
+// WaitForRound blocks until the beacon of the current round is stored, i.e.
+// until the aggregation in progress is complete, or until the context is
+// done. It returns right away when the node is behind the chain or paused
+// since there is no aggregation in progress. It is meant to be called before
+// Stop so the node does not leave a round half aggregated.
+func (h *Handler) WaitForRound(ctx context.Context) error {
+	h.Lock()
+	stopped, paused := h.stopped, h.paused
+	h.Unlock()
+	round := h.RoundAt(h.conf.Clock.Now())
+	if stopped || paused || round == 0 {
+		return nil
+	}
+	if last, err := h.chain.Last(); err != nil || last.Round+1 < round {
+		return nil
+	}
+	done := make(chan struct{})
+	var once sync.Once
+	id := fmt.Sprintf("wait_round_%p", done)
+	h.chain.AddCallback(id, func(b *chain.Beacon) {
+		if b.Round >= round {
+			once.Do(func() { close(done) })
+		}
+	})
+	defer h.chain.RemoveCallback(id)
+	// the beacon may have been stored before the callback was registered
+	if last, err := h.chain.Last(); err == nil && last.Round >= round {
+		return nil
+	}
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("beacon: round %d not complete: %w", round, ctx.Err())
+	}
+}
+
 // AddCallback is a proxy method to register a callback on the backend store
 func (h *Handler) AddCallback(id string, fn func(*chain.Beacon)) {
 	h.chain.AddCallback(id, fn)
@@ -347,11 +600,23 @@ func (h *Handler) RemoveCallback(id string) {
 	h.chain.RemoveCallback(id)
 }
 
+// DrainCallbacks waits until all the registered callbacks have been called
+// with the latest beacon, or until the context is done. It is meant to be
+// called before Stop so the streams get the last beacon.
+func (h *Handler) DrainCallbacks(ctx context.Context) error {
+	return h.chain.DrainCallbacks(ctx)
+}
+
 // SyncChain is a proxy method to sync a chain
 func (h *Handler) SyncChain(req *proto.SyncRequest, stream proto.Protocol_SyncChainServer) error {
 	return h.chain.sync.SyncChain(req, stream)
 }
 
+// PushSync is a proxy method to push the chain to a syncing node
+func (h *Handler) PushSync(req *proto.SyncRequest, stream proto.Protocol_PushSyncServer) error {
+	return h.chain.sync.PushSync(req, stream)
+}
+
 func shortSigStr(sig []byte) string {
 	max := 3
 	if len(sig) < max {
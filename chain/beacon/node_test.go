@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"os"
 	"path"
 	"sync"
@@ -49,6 +50,13 @@ func (t *testBeaconServer) SyncChain(req *drand.SyncRequest, p drand.Protocol_Sy
 	return t.h.chain.sync.SyncChain(req, p)
 }
 
+func (t *testBeaconServer) PushSync(req *drand.SyncRequest, p drand.Protocol_PushSyncServer) error {
+	if t.disable {
+		return errors.New("disabled server")
+	}
+	return t.h.chain.sync.PushSync(req, p)
+}
+
 func dkgShares(n, t int) ([]*key.Share, []kyber.Point) {
 	var priPoly *share.PriPoly
 	var pubPoly *share.PubPoly
@@ -482,6 +490,267 @@ func TestBeaconSimple(t *testing.T) {
 	checkWait(counter)
 }
 
+func TestBeaconVerify(t *testing.T) {
+	n := 3
+	thr := n/2 + 1
+	period := 2 * time.Second
+
+	var genesisTime int64 = clock.NewFakeClock().Now().Unix() + 2
+
+	bt := NewBeaconTest(n, thr, period, genesisTime)
+	defer bt.CleanUp()
+
+	var counter = &sync.WaitGroup{}
+	counter.Add(n)
+	for i := 0; i < n; i++ {
+		bt.CallbackFor(i, func(*chain.Beacon) { counter.Done() })
+		bt.ServeBeacon(i)
+	}
+	bt.StartBeacons(n)
+	bt.MoveTime(2 * time.Second)
+	checkWait(counter)
+
+	handler := bt.nodes[0].handler
+	b, err := handler.Store().Get(1)
+	require.NoError(t, err)
+	require.NoError(t, handler.VerifyBeacon(b))
+
+	// round 0 is the genesis beacon and can not be verified
+	require.Error(t, handler.VerifyBeacon(&chain.Beacon{Signature: b.PreviousSig}))
+
+	invalidSig := &chain.Beacon{
+		Round:       b.Round,
+		PreviousSig: b.PreviousSig,
+		Signature:   append([]byte{}, b.Signature...),
+	}
+	invalidSig.Signature[0] ^= 0xff
+	require.Error(t, handler.VerifyBeacon(invalidSig))
+
+	// a correctly signed beacon that does not build on the chain
+	broken := &chain.Beacon{
+		Round:       b.Round,
+		PreviousSig: []byte("not the previous signature"),
+	}
+	msg := chain.Message(broken.Round, broken.PreviousSig)
+	partials := make([][]byte, 0, thr)
+	for _, s := range bt.shares[:thr] {
+		partial, err := key.Scheme.Sign(s.PrivateShare(), msg)
+		require.NoError(t, err)
+		partials = append(partials, partial)
+	}
+	broken.Signature, err = key.Scheme.Recover(bt.group.PublicKey.PubPoly(), msg, partials, thr, n)
+	require.NoError(t, err)
+	require.NoError(t, chain.VerifyBeacon(bt.dpublic, broken))
+	require.Error(t, handler.VerifyBeacon(broken))
+}
+
+func TestBeaconCatchupClose(t *testing.T) {
+	h := &Handler{catchupCh: make(chan CatchupStatus, 1)}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			h.sendCatchup(CatchupStatus{StoredRound: uint64(i)})
+		}(i)
+		go func() {
+			defer wg.Done()
+			h.closeCatchup()
+		}()
+	}
+	wg.Wait()
+	// the channel is closed once, after which the updates are dropped
+	h.sendCatchup(CatchupStatus{})
+	h.closeCatchup()
+	for range h.CatchupProgress() {
+	}
+}
+
+func TestBeaconHistoricalRand(t *testing.T) {
+	n := 3
+	thr := n/2 + 1
+	period := 2 * time.Second
+
+	var genesisTime int64 = clock.NewFakeClock().Now().Unix() + 2
+
+	bt := NewBeaconTest(n, thr, period, genesisTime)
+	defer bt.CleanUp()
+
+	var counter = &sync.WaitGroup{}
+	counter.Add(n)
+	for i := 0; i < n; i++ {
+		bt.CallbackFor(i, func(*chain.Beacon) { counter.Done() })
+		bt.ServeBeacon(i)
+	}
+	bt.StartBeacons(n)
+	bt.MoveTime(2 * time.Second)
+	checkWait(counter)
+	counter.Add(n)
+	bt.MoveTime(period)
+	checkWait(counter)
+
+	handler := bt.nodes[0].handler
+	negative := func(n int64) uint64 { return uint64(n) }
+	for _, tc := range []struct {
+		round    uint64
+		expected uint64
+	}{
+		{0, 0},
+		{1, 1},
+		{2, 2},
+		{negative(-1), 2},
+		{negative(-2), 1},
+		{negative(-3), 0},
+	} {
+		b, err := handler.HistoricalRand(tc.round)
+		require.NoError(t, err, int64(tc.round))
+		require.Equal(t, tc.expected, b.Round, int64(tc.round))
+	}
+	genesis, err := handler.HistoricalRand(0)
+	require.NoError(t, err)
+	require.Equal(t, bt.group.GetGenesisSeed(), genesis.Signature)
+
+	_, err = handler.HistoricalRand(3)
+	require.Error(t, err)
+	_, err = handler.HistoricalRand(negative(-4))
+	require.Error(t, err)
+	_, err = handler.HistoricalRand(math.MaxUint64 / 2)
+	require.Error(t, err)
+}
+
+func TestBeaconWaitForRound(t *testing.T) {
+	n := 3
+	thr := n/2 + 1
+	period := 2 * time.Second
+
+	var genesisTime int64 = clock.NewFakeClock().Now().Unix() + 2
+
+	bt := NewBeaconTest(n, thr, period, genesisTime)
+	defer bt.CleanUp()
+
+	var first = &sync.WaitGroup{}
+	var second = &sync.WaitGroup{}
+	first.Add(n)
+	second.Add(n - 1)
+	for i := 0; i < n; i++ {
+		idx := i
+		bt.CallbackFor(i, func(b *chain.Beacon) {
+			if b.Round == 1 {
+				first.Done()
+			} else if b.Round == 2 && idx != 0 {
+				second.Done()
+			}
+		})
+		bt.ServeBeacon(i)
+	}
+	bt.StartBeacons(n)
+	bt.MoveTime(2 * time.Second)
+	checkWait(first)
+
+	handler := bt.nodes[0].handler
+	// the current round is complete
+	require.NoError(t, handler.WaitForRound(context.Background()))
+
+	// the first node only has its own partial for the next round
+	bt.DisableReception(1)
+	bt.MoveTime(period)
+	checkWait(second)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	require.Error(t, handler.WaitForRound(ctx))
+
+	waitErr := make(chan error, 1)
+	go func() { waitErr <- handler.WaitForRound(context.Background()) }()
+	select {
+	case err := <-waitErr:
+		t.Fatalf("round completed without the partials: %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// the missing partial completes the round before the node stops
+	prev, err := handler.Store().Get(1)
+	require.NoError(t, err)
+	partial, err := key.Scheme.Sign(bt.shares[1].PrivateShare(), chain.Message(2, prev.Signature))
+	require.NoError(t, err)
+	_, err = handler.ProcessPartialBeacon(context.Background(), &drand.PartialBeaconPacket{
+		Round:       2,
+		PreviousSig: prev.Signature,
+		PartialSig:  partial,
+	})
+	require.NoError(t, err)
+	select {
+	case err := <-waitErr:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("round not completed")
+	}
+	last, err := handler.Store().Last()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), last.Round)
+	bt.StopBeacon(0)
+}
+
+func TestBeaconPushSync(t *testing.T) {
+	n := 3
+	thr := n/2 + 1
+	period := 2 * time.Second
+
+	var genesisTime int64 = clock.NewFakeClock().Now().Unix() + 2
+
+	bt := NewBeaconTest(n, thr, period, genesisTime)
+	defer bt.CleanUp()
+
+	var counter = &sync.WaitGroup{}
+	counter.Add(n)
+	for i := 0; i < n; i++ {
+		bt.CallbackFor(i, func(*chain.Beacon) { counter.Done() })
+		bt.ServeBeacon(i)
+	}
+	bt.StartBeacons(n)
+	bt.MoveTime(2 * time.Second)
+	checkWait(counter)
+	counter.Add(n)
+	bt.MoveTime(period)
+	checkWait(counter)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	peer := net.CreatePeer(bt.nodes[0].private.Public.Address(), false)
+	// two streams from the same client, hence from the same address
+	client := net.NewGrpcClient()
+	batches, err := client.PushSync(ctx, peer, &drand.SyncRequest{FromRound: 1})
+	require.NoError(t, err)
+	beacons := unpackBatches(ctx, batches, log.DefaultLogger())
+	batches, err = client.PushSync(ctx, peer, &drand.SyncRequest{FromRound: 2})
+	require.NoError(t, err)
+	others := unpackBatches(ctx, batches, log.DefaultLogger())
+
+	next := func(beacons chan *drand.BeaconPacket) *drand.BeaconPacket {
+		select {
+		case b := <-beacons:
+			return b
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "no beacon pushed")
+		}
+		return nil
+	}
+	// the stored beacons are pushed first
+	require.Equal(t, uint64(1), next(beacons).GetRound())
+	require.Equal(t, uint64(2), next(beacons).GetRound())
+	require.Equal(t, uint64(2), next(others).GetRound())
+
+	// then the new ones as they are created, to each stream
+	counter.Add(n)
+	bt.MoveTime(period)
+	checkWait(counter)
+	b := next(beacons)
+	require.Equal(t, uint64(3), b.GetRound())
+	stored, err := bt.nodes[0].handler.Store().Get(3)
+	require.NoError(t, err)
+	require.Equal(t, stored.Signature, b.GetSignature())
+	require.Equal(t, uint64(3), next(others).GetRound())
+}
+
 func TestBeaconThreshold(t *testing.T) {
 	n := 3
 	thr := n/2 + 1
@@ -544,6 +813,12 @@ func TestBeaconThreshold(t *testing.T) {
 	fmt.Printf("\nLAST NODE LAUNCHED ! \n\n")
 	// 2s because of gRPC default timeouts backoff
 	time.Sleep(2 * time.Second)
+	select {
+	case status := <-bt.nodes[bt.searchNode(n-1)].handler.CatchupProgress():
+		require.NotZero(t, status.TargetRound)
+	case <-time.After(2 * CatchupProgressPeriod):
+		t.Fatal("no catchup progress reported")
+	}
 	bt.CallbackFor(n-1, myCallBack(n-1))
 	fmt.Printf("\n | MAKE NEW ROUNDS |\n\n")
 	// and then run a few rounds
@@ -570,3 +845,11 @@ func (b *BeaconTest) CallbackFor(i int, fn func(*chain.Beacon)) {
 	j := b.searchNode(i)
 	b.nodes[j].handler.AddCallback(b.nodes[j].private.Public.Address(), fn)
 }
+
+// A panic in an iteration of a beacon loop is logged and does not propagate
+func TestBeaconRecoverLoop(t *testing.T) {
+	require.NotPanics(t, func() {
+		defer recoverLoop(log.DefaultLogger(), "beacon_loop")
+		panic("malformed partial")
+	})
+}
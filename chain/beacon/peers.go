@@ -0,0 +1,104 @@
+package beacon
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/drand/drand/fs"
+	"github.com/drand/drand/key"
+)
+
+// PeersFileName is the name of the file, in the base folder of the node, where
+// the peers excluded with ExcludePeer are kept.
+const PeersFileName = "peers.json"
+
+// excludedPeers holds the addresses of the peers whose partials are skipped
+// when aggregating a beacon. The other partials are not ordered: the threshold
+// signature is the same for any threshold of valid partials. They are
+// persisted as a JSON list in the file at path, if any.
+type excludedPeers struct {
+	sync.Mutex
+	path  string
+	addrs map[string]bool
+}
+
+// loadExcludedPeers reads the excluded peers from the file at path. A missing
+// file means no peer is excluded.
+func loadExcludedPeers(path string) (*excludedPeers, error) {
+	p := &excludedPeers{path: path, addrs: make(map[string]bool)}
+	if path == "" {
+		return p, nil
+	}
+	buff, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return p, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var addrs []string
+	if err := json.Unmarshal(buff, &addrs); err != nil {
+		return nil, err
+	}
+	for _, addr := range addrs {
+		p.addrs[addr] = true
+	}
+	return p, nil
+}
+
+// Excluded returns true if the peer at addr is excluded.
+func (p *excludedPeers) Excluded(addr string) bool {
+	p.Lock()
+	defer p.Unlock()
+	return p.addrs[addr]
+}
+
+// Set excludes or includes back the peer at addr and saves the excluded peers.
+func (p *excludedPeers) Set(addr string, excluded bool) error {
+	p.Lock()
+	defer p.Unlock()
+	prev := p.addrs[addr]
+	if excluded {
+		p.addrs[addr] = true
+	} else {
+		delete(p.addrs, addr)
+	}
+	if err := p.save(); err != nil {
+		if prev {
+			p.addrs[addr] = true
+		} else {
+			delete(p.addrs, addr)
+		}
+		return err
+	}
+	return nil
+}
+
+func (p *excludedPeers) save() error {
+	if p.path == "" {
+		return nil
+	}
+	addrs := make([]string, 0, len(p.addrs))
+	for addr := range p.addrs {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	buff, err := json.MarshalIndent(addrs, "", "    ")
+	if err != nil {
+		return err
+	}
+	// written to a temporary file first so a crash never leaves it truncated
+	return fs.WriteFileAtomic(p.path, buff, 0600)
+}
+
+// indexExcluded returns true if the node of the group at the given index is
+// excluded.
+func (p *excludedPeers) indexExcluded(group *key.Group, idx int) bool {
+	node := group.Node(key.Index(idx))
+	if node == nil {
+		return false
+	}
+	return p.Excluded(node.Address())
+}
@@ -0,0 +1,44 @@
+package beacon
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/drand/drand/key"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExcludedPeers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "peers")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := path.Join(dir, PeersFileName)
+
+	p, err := loadExcludedPeers(file)
+	require.NoError(t, err)
+	require.False(t, p.Excluded("127.0.0.1:1"))
+	require.NoError(t, p.Set("127.0.0.1:1", true))
+	require.NoError(t, p.Set("127.0.0.1:2", true))
+	require.NoError(t, p.Set("127.0.0.1:2", false))
+	require.NoError(t, p.Set("127.0.0.1:3", false))
+
+	p, err = loadExcludedPeers(file)
+	require.NoError(t, err)
+	require.True(t, p.Excluded("127.0.0.1:1"))
+	require.False(t, p.Excluded("127.0.0.1:2"))
+	require.False(t, p.Excluded("127.0.0.1:3"))
+	require.Len(t, p.addrs, 1)
+
+	group := &key.Group{}
+	for i, addr := range []string{"127.0.0.1:1", "127.0.0.1:2", "127.0.0.1:3"} {
+		group.Nodes = append(group.Nodes, &key.Node{
+			Identity: key.NewKeyPair(addr).Public,
+			Index:    key.Index(i),
+		})
+	}
+	require.True(t, p.indexExcluded(group, 0))
+	require.False(t, p.indexExcluded(group, 1))
+	require.False(t, p.indexExcluded(group, 4))
+}
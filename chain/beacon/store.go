@@ -2,6 +2,7 @@ package beacon
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"runtime"
 	"sync"
@@ -19,6 +20,9 @@ type CallbackStore interface {
 	chain.Store
 	AddCallback(id string, fn func(*chain.Beacon))
 	RemoveCallback(id string)
+	// DrainCallbacks waits until the callbacks have been called with all the
+	// beacons stored so far, or until the context is done.
+	DrainCallbacks(ctx context.Context) error
 }
 
 // appendStore is a store that only appends new block with a round +1 from the
@@ -73,7 +77,7 @@ func (d *discrepancyStore) Put(b *chain.Beacon) error {
 		return err
 	}
 	actual := time.Now().UnixNano()
-	expected := chain.TimeOfRound(d.group.Period, d.group.GenesisTime, b.Round) * 1e9
+	expected := b.Time(d.group.Period, d.group.GenesisTime).UnixNano()
 	discrepancy := float64(actual-expected) / float64(time.Millisecond)
 	metrics.BeaconDiscrepancyLatency.Set(float64(actual-expected) / float64(time.Millisecond))
 	metrics.LastBeaconRound.Set(float64(b.GetRound()))
@@ -90,6 +94,11 @@ type callbackStore struct {
 	done      chan bool
 	callbacks map[string]func(*chain.Beacon)
 	newJob    chan cbPair
+	// pending is the number of jobs not processed yet by the workers, and idle
+	// is closed each time it drops to zero
+	pendingLock sync.Mutex
+	pending     int
+	idle        chan struct{}
 }
 
 type cbPair struct {
@@ -106,7 +115,9 @@ func NewCallbackStore(s chain.Store) CallbackStore {
 		callbacks: make(map[string]func(*chain.Beacon)),
 		newJob:    make(chan cbPair, CallbackWorkerQueue),
 		done:      make(chan bool, 1),
+		idle:      make(chan struct{}),
 	}
+	close(cbs.idle)
 	cbs.runWorkers(runtime.NumCPU())
 	return cbs
 }
@@ -120,6 +131,7 @@ func (c *callbackStore) Put(b *chain.Beacon) error {
 		c.Lock()
 		defer c.Unlock()
 		for _, cb := range c.callbacks {
+			c.addPending()
 			c.newJob <- cbPair{
 				cb: cb,
 				b:  b,
@@ -142,6 +154,39 @@ func (c *callbackStore) RemoveCallback(id string) {
 	delete(c.callbacks, id)
 }
 
+// DrainCallbacks waits until the workers have processed all the jobs
+// dispatched so far, i.e. until the registered callbacks have been called with
+// the latest beacon.
+func (c *callbackStore) DrainCallbacks(ctx context.Context) error {
+	c.pendingLock.Lock()
+	idle := c.idle
+	c.pendingLock.Unlock()
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *callbackStore) addPending() {
+	c.pendingLock.Lock()
+	defer c.pendingLock.Unlock()
+	if c.pending == 0 {
+		c.idle = make(chan struct{})
+	}
+	c.pending++
+}
+
+func (c *callbackStore) donePending() {
+	c.pendingLock.Lock()
+	defer c.pendingLock.Unlock()
+	c.pending--
+	if c.pending == 0 {
+		close(c.idle)
+	}
+}
+
 func (c *callbackStore) Close() {
 	c.Store.Close()
 	close(c.done)
@@ -158,6 +203,7 @@ func (c *callbackStore) runWorker() {
 		select {
 		case newJob := <-c.newJob:
 			newJob.cb(newJob.b)
+			c.donePending()
 		case <-c.done:
 			return
 		}
@@ -12,6 +12,8 @@ import (
 	"github.com/drand/drand/log"
 	"github.com/drand/drand/net"
 	proto "github.com/drand/drand/protobuf/drand"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // Syncer allows to follow a chain from other nodes and replies to syncing
@@ -26,8 +28,14 @@ type Syncer interface {
 	Syncing() bool
 	// SyncChain imeplements the server side of the syncing process
 	SyncChain(req *proto.SyncRequest, p proto.Protocol_SyncChainServer) error
+	// PushSync implements the server side of the push syncing process
+	PushSync(req *proto.SyncRequest, p proto.Protocol_PushSyncServer) error
 }
 
+// pushSyncBatchSize is the maximum number of beacons sent in one batch when
+// push syncing
+const pushSyncBatchSize = 100
+
 // syncer implements the Syncer interface
 type syncer struct {
 	l         log.Logger
@@ -87,9 +95,17 @@ func (s *syncer) tryNode(global context.Context, upTo uint64, n net.Peer) bool {
 	if err != nil {
 		return false
 	}
-	beaconCh, err := s.client.SyncChain(cnode, n, &proto.SyncRequest{
+	req := &proto.SyncRequest{
 		FromRound: last.Round + 1,
-	})
+	}
+	var beaconCh chan *proto.BeaconPacket
+	batches, err := s.client.PushSync(cnode, n, req)
+	if err == nil {
+		beaconCh = unpackBatches(cnode, batches, s.l)
+	} else if status.Code(err) == codes.Unimplemented {
+		s.l.Debug("syncer", "push_sync_unsupported", "with_peer", n.Address())
+		beaconCh, err = s.client.SyncChain(cnode, n, req)
+	}
 	if err != nil {
 		s.l.Debug("syncer", "unable_to_sync", "with_peer", n.Address(), "err", err)
 		return false
@@ -145,16 +161,11 @@ func (s *syncer) SyncChain(req *proto.SyncRequest, stream proto.Protocol_SyncCha
 
 	if fromRound <= last.Round {
 		// first sync up from the store itself
-		var err error
-		s.store.Cursor(func(c chain.Cursor) {
-			for bb := c.Seek(fromRound); bb != nil; bb = c.Next() {
-				if err = stream.Send(beaconToProto(bb)); err != nil {
-					s.l.Debug("syncer", "streaming_send", "err", err)
-					return
-				}
-			}
+		err := s.store.IterateFrom(fromRound, func(bb *chain.Beacon) error {
+			return stream.Send(beaconToProto(bb))
 		})
 		if err != nil {
+			s.l.Debug("syncer", "streaming_send", "err", err)
 			return err
 		}
 	}
@@ -178,6 +189,88 @@ func (s *syncer) SyncChain(req *proto.SyncRequest, stream proto.Protocol_SyncCha
 	}
 }
 
+func (s *syncer) PushSync(req *proto.SyncRequest, stream proto.Protocol_PushSyncServer) error {
+	addr := net.RemoteAddress(stream.Context())
+	s.l.Debug("syncer", "push_sync_request", "from", addr, "from_round", req.GetFromRound())
+
+	last, err := s.store.Last()
+	if err != nil {
+		return err
+	}
+	if last.Round < req.GetFromRound() {
+		return fmt.Errorf("no beacon stored above requested round %d < %d", last.Round, req.GetFromRound())
+	}
+
+	// register the callback first so the beacons stored while sending the
+	// history are not missed
+	newBeacons := make(chan *chain.Beacon, pushSyncBatchSize)
+	// several streams can come from the same address
+	id := fmt.Sprintf("push_sync_%s_%p", addr, newBeacons)
+	s.store.AddCallback(id, func(b *chain.Beacon) {
+		select {
+		case newBeacons <- b:
+		default:
+			// the beacon is read from the store with the next one
+		}
+	})
+	defer s.store.RemoveCallback(id)
+
+	next, err := s.pushBatches(stream, req.GetFromRound())
+	if err != nil {
+		s.l.Debug("syncer", "push_sync_send", "err", err)
+		return err
+	}
+	for {
+		select {
+		case b := <-newBeacons:
+			if b.Round < next {
+				continue
+			}
+			if next, err = s.pushBatches(stream, next); err != nil {
+				s.l.Debug("syncer", "push_sync_send", "err", err)
+				return err
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+// pushBatches sends the stored beacons from the given round up to the head of
+// the chain by batches and returns the next round to send.
+func (s *syncer) pushBatches(stream proto.Protocol_PushSyncServer, from uint64) (uint64, error) {
+	last, err := s.store.Last()
+	if err != nil {
+		return from, err
+	}
+	for from <= last.Round {
+		to := from + pushSyncBatchSize - 1
+		if to > last.Round {
+			to = last.Round
+		}
+		beacons, err := s.store.GetRange(from, to)
+		if err != nil {
+			return from, err
+		}
+		if len(beacons) == 0 {
+			break
+		}
+		batch := &proto.BeaconBatch{Beacons: make([][]byte, 0, len(beacons))}
+		for _, b := range beacons {
+			buff, err := b.Encode()
+			if err != nil {
+				return from, err
+			}
+			batch.Beacons = append(batch.Beacons, buff)
+		}
+		if err := stream.Send(batch); err != nil {
+			return from, err
+		}
+		from = beacons[len(beacons)-1].Round + 1
+	}
+	return from, nil
+}
+
 func peersToString(peers []net.Peer) string {
 	var adds []string
 	for _, p := range peers {
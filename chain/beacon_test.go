@@ -1,10 +1,16 @@
 package chain
 
 import (
+	"errors"
+	"math"
+	mrand "math/rand"
 	"testing"
+	"time"
 
 	"github.com/drand/drand/key"
+	"github.com/drand/kyber/share"
 	"github.com/drand/kyber/util/random"
+	"github.com/stretchr/testify/require"
 )
 
 func BenchmarkVerifyBeacon(b *testing.B) {
@@ -26,3 +32,100 @@ func BenchmarkVerifyBeacon(b *testing.B) {
 		}
 	}
 }
+
+func TestBeaconTime(t *testing.T) {
+	genesis := time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC).Unix()
+	period := 30 * time.Second
+
+	// round 0 is the genesis beacon and round 1 happens at genesis time
+	require.Equal(t, genesis, (&Beacon{Round: 0}).Time(period, genesis).Unix())
+	require.Equal(t, genesis, (&Beacon{Round: 1}).Time(period, genesis).Unix())
+	require.Equal(t, genesis+60, (&Beacon{Round: 3}).Time(period, genesis).Unix())
+
+	// very large rounds don't overflow but return the error value
+	large := (&Beacon{Round: math.MaxUint64}).Time(period, genesis)
+	require.Equal(t, int64(TimeOfRoundErrorValue), large.Unix())
+	require.True(t, large.After((&Beacon{Round: math.MaxInt32}).Time(period, genesis)))
+}
+
+func TestBeaconEncode(t *testing.T) {
+	b := &Beacon{
+		PreviousSig: []byte("a magnificent signature"),
+		Round:       145,
+		Signature:   []byte("one signature to"),
+	}
+	buff, err := b.Encode()
+	require.NoError(t, err)
+	require.Len(t, buff, 12+len(b.PreviousSig)+len(b.Signature))
+	require.Equal(t, []byte{145, 0, 0, 0, 0, 0, 0, 0, byte(len(b.PreviousSig)), 0}, buff[:10])
+	decoded, err := DecodeBeacon(buff)
+	require.NoError(t, err)
+	require.Equal(t, b, decoded)
+
+	// truncated and trailing bytes
+	for i := 0; i < len(buff); i++ {
+		_, err := DecodeBeacon(buff[:i])
+		require.Error(t, err, "length %d", i)
+	}
+	_, err = DecodeBeacon(append(buff, 0))
+	require.Error(t, err)
+
+	_, err = (&Beacon{Signature: make([]byte, math.MaxUint16+1)}).Encode()
+	require.Error(t, err)
+}
+
+// TestBeaconEncodeFuzz checks random beacons survive an encoding round trip
+// and random inputs never make DecodeBeacon panic.
+func TestBeaconEncodeFuzz(t *testing.T) {
+	// a fixed seed so a failure can be reproduced
+	r := mrand.New(mrand.NewSource(42))
+	randomBytes := func(max int) []byte {
+		buff := make([]byte, r.Intn(max))
+		r.Read(buff)
+		return buff
+	}
+	for i := 0; i < 1000; i++ {
+		b := &Beacon{
+			PreviousSig: randomBytes(200),
+			Round:       r.Uint64(),
+			Signature:   randomBytes(200),
+		}
+		buff, err := b.Encode()
+		require.NoError(t, err)
+		decoded, err := DecodeBeacon(buff)
+		require.NoError(t, err)
+		require.True(t, b.Equal(decoded), "beacon %s decoded as %s", b, decoded)
+
+		// a random input is either rejected or decodes into a beacon that
+		// encodes back to the same bytes
+		garbage := randomBytes(64)
+		if decoded, err := DecodeBeacon(garbage); err == nil {
+			reencoded, err := decoded.Encode()
+			require.NoError(t, err)
+			require.Equal(t, garbage, reencoded)
+		}
+	}
+}
+
+func TestVerifySingleNode(t *testing.T) {
+	// the share of the only node of a chain of threshold 1
+	poly := share.NewPriPoly(key.KeyGroup, 1, nil, random.New())
+	priShare := poly.Shares(1)[0]
+	pubKey := key.KeyGroup.Point().Mul(priShare.V, nil)
+
+	prevSig := []byte("My Sweet Previous Signature")
+	msg := Message(16, prevSig)
+	partial, err := key.Scheme.Sign(priShare, msg)
+	require.NoError(t, err)
+	sig, err := key.Scheme.Recover(poly.Commit(key.KeyGroup.Point().Base()), msg, [][]byte{partial}, 1, 1)
+	require.NoError(t, err)
+	b := &Beacon{Round: 16, PreviousSig: prevSig, Signature: sig}
+
+	require.NoError(t, VerifySingleNode(pubKey, 1, b))
+	require.Error(t, VerifySingleNode(pubKey, 1, &Beacon{Round: 17, PreviousSig: prevSig, Signature: sig}))
+	other := key.KeyGroup.Point().Pick(random.New())
+	require.Error(t, VerifySingleNode(other, 1, b))
+
+	err = VerifySingleNode(pubKey, 2, b)
+	require.True(t, errors.Is(err, ErrGroupVerificationRequired))
+}
@@ -1,8 +1,12 @@
 package boltdb
 
 import (
+	"bytes"
+	"encoding/binary"
 	"errors"
+	"fmt"
 	"io"
+	"os"
 	"path"
 	"sync"
 
@@ -16,11 +20,19 @@ import (
 // db file.
 type boltStore struct {
 	sync.Mutex
-	db *bolt.DB
+	db          *bolt.DB
+	opts        *bolt.Options
+	checkpoints *chain.CheckpointCache
 }
 
 var beaconBucket = []byte("beacons")
 
+// randomnessBucket indexes the rounds by the randomness of their beacon. It is
+// created and populated lazily by GetByRandomness. Under indexHeadKey, it
+// holds the highest round indexed so far.
+var randomnessBucket = []byte("randomness")
+var indexHeadKey = []byte("head")
+
 // BoltFileName is the name of the file boltdb writes to
 const BoltFileName = "drand.db"
 
@@ -41,7 +53,9 @@ func NewBoltStore(folder string, opts *bolt.Options) (chain.Store, error) {
 	})
 
 	return &boltStore{
-		db: db,
+		db:          db,
+		opts:        opts,
+		checkpoints: chain.NewCheckpointCache(),
 	}, err
 }
 
@@ -74,11 +88,24 @@ func (b *boltStore) Put(beacon *chain.Beacon) error {
 		if err != nil {
 			return err
 		}
-		return bucket.Put(key, buff)
+		if err := bucket.Put(key, buff); err != nil {
+			return err
+		}
+		// the next lookup only scans the rounds above the head of the index,
+		// so a round below has to be indexed now
+		index := tx.Bucket(randomnessBucket)
+		if index == nil {
+			return nil
+		}
+		if head := index.Get(indexHeadKey); head != nil && bytes.Compare(key, head) <= 0 {
+			return index.Put(beacon.Randomness(), key)
+		}
+		return nil
 	})
 	if err != nil {
 		return err
 	}
+	b.checkpoints.Invalidate(beacon.Round)
 	return nil
 }
 
@@ -106,6 +133,26 @@ func (b *boltStore) Last() (*chain.Beacon, error) {
 	return beacon, err
 }
 
+// First returns the beacon with the lowest round saved into the db
+func (b *boltStore) First() (*chain.Beacon, error) {
+	var beacon *chain.Beacon
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(beaconBucket)
+		cursor := bucket.Cursor()
+		_, v := cursor.First()
+		if v == nil {
+			return ErrNoBeaconSaved
+		}
+		b := &chain.Beacon{}
+		if err := b.Unmarshal(v); err != nil {
+			return err
+		}
+		beacon = b
+		return nil
+	})
+	return beacon, err
+}
+
 // Get returns the beacon saved at this round
 func (b *boltStore) Get(round uint64) (*chain.Beacon, error) {
 	var beacon *chain.Beacon
@@ -129,10 +176,12 @@ func (b *boltStore) Get(round uint64) (*chain.Beacon, error) {
 }
 
 func (b *boltStore) Del(round uint64) error {
-	return b.db.Update(func(tx *bolt.Tx) error {
+	err := b.db.Update(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket(beaconBucket)
 		return bucket.Delete(chain.RoundToBytes(round))
 	})
+	b.checkpoints.Invalidate(round)
+	return err
 }
 
 func (b *boltStore) Cursor(fn func(chain.Cursor)) {
@@ -147,6 +196,168 @@ func (b *boltStore) Cursor(fn func(chain.Cursor)) {
 	}
 }
 
+// IterateFrom implements the chain.Store interface
+func (b *boltStore) IterateFrom(from uint64, fn func(*chain.Beacon) error) error {
+	var err error
+	b.Cursor(func(c chain.Cursor) {
+		for bb := c.Seek(from); bb != nil; bb = c.Next() {
+			if err = fn(bb); err != nil {
+				return
+			}
+		}
+	})
+	return err
+}
+
+// GetRange implements the chain.Store interface
+func (b *boltStore) GetRange(from, to uint64) ([]*chain.Beacon, error) {
+	if from > to {
+		return nil, fmt.Errorf("boltdb: invalid range [%d, %d]", from, to)
+	}
+	var beacons []*chain.Beacon
+	err := b.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(beaconBucket).Cursor()
+		last, _ := cursor.Last()
+		if last == nil {
+			return nil
+		}
+		if head := binary.BigEndian.Uint64(last); head < to {
+			to = head
+		}
+		if from > to {
+			return nil
+		}
+		beacons = make([]*chain.Beacon, 0, to-from+1)
+		max := chain.RoundToBytes(to)
+		for k, v := cursor.Seek(chain.RoundToBytes(from)); k != nil && bytes.Compare(k, max) <= 0; k, v = cursor.Next() {
+			b := new(chain.Beacon)
+			if err := b.Unmarshal(v); err != nil {
+				return err
+			}
+			beacons = append(beacons, b)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return beacons, nil
+}
+
+// LastN implements the chain.Store interface by walking the chain backwards
+// from its head.
+func (b *boltStore) LastN(n int) ([]*chain.Beacon, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("boltdb: invalid number of beacons %d", n)
+	}
+	var beacons []*chain.Beacon
+	err := b.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(beaconBucket).Cursor()
+		for k, v := cursor.Last(); k != nil && len(beacons) < n; k, v = cursor.Prev() {
+			b := new(chain.Beacon)
+			if err := b.Unmarshal(v); err != nil {
+				return err
+			}
+			beacons = append(beacons, b)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(beacons)-1; i < j; i, j = i+1, j-1 {
+		beacons[i], beacons[j] = beacons[j], beacons[i]
+	}
+	return beacons, nil
+}
+
+// Checkpoint implements the chain.Store interface. It keeps the Merkle
+// frontiers of the chain in memory, see chain.CheckpointCache.
+func (b *boltStore) Checkpoint(round uint64) (*chain.Checkpoint, error) {
+	return b.checkpoints.Checkpoint(b, round)
+}
+
+// GetByRandomness implements the chain.Store interface. It looks the round up
+// in a secondary index. When the randomness is not indexed, it scans the
+// beacons stored since the last lookup and adds them to the index, so the
+// index is built on the first call.
+func (b *boltStore) GetByRandomness(randomness []byte) (*chain.Beacon, error) {
+	var beacon *chain.Beacon
+	err := b.db.View(func(tx *bolt.Tx) error {
+		index := tx.Bucket(randomnessBucket)
+		if index == nil {
+			return nil
+		}
+		var err error
+		beacon, err = indexedBeacon(tx.Bucket(beaconBucket), index, randomness)
+		return err
+	})
+	if err != nil || beacon != nil {
+		return beacon, err
+	}
+	err = b.db.Update(func(tx *bolt.Tx) error {
+		index, err := tx.CreateBucketIfNotExists(randomnessBucket)
+		if err != nil {
+			return err
+		}
+		cursor := tx.Bucket(beaconBucket).Cursor()
+		head := index.Get(indexHeadKey)
+		var k, v []byte
+		if head == nil {
+			k, v = cursor.First()
+		} else if k, v = cursor.Seek(head); bytes.Equal(k, head) {
+			k, v = cursor.Next()
+		}
+		var last *chain.Beacon
+		for ; k != nil; k, v = cursor.Next() {
+			b := new(chain.Beacon)
+			if err := b.Unmarshal(v); err != nil {
+				return err
+			}
+			r := b.Randomness()
+			if err := index.Put(r, chain.RoundToBytes(b.Round)); err != nil {
+				return err
+			}
+			if beacon == nil && bytes.Equal(r, randomness) {
+				beacon = b
+			}
+			last = b
+		}
+		if last == nil {
+			return nil
+		}
+		return index.Put(indexHeadKey, chain.RoundToBytes(last.Round))
+	})
+	if err != nil {
+		return nil, err
+	}
+	if beacon == nil {
+		return nil, ErrNoBeaconSaved
+	}
+	return beacon, nil
+}
+
+// indexedBeacon returns the beacon the index maps the randomness to, or nil if
+// there is none. An entry left by a deleted or overwritten beacon is ignored.
+func indexedBeacon(bucket, index *bolt.Bucket, randomness []byte) (*chain.Beacon, error) {
+	round := index.Get(randomness)
+	if round == nil {
+		return nil, nil
+	}
+	v := bucket.Get(round)
+	if v == nil {
+		return nil, nil
+	}
+	b := new(chain.Beacon)
+	if err := b.Unmarshal(v); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(b.Randomness(), randomness) {
+		return nil, nil
+	}
+	return b, nil
+}
+
 // SaveTo saves the bolt database to an alternate file.
 func (b *boltStore) SaveTo(w io.Writer) error {
 	return b.db.View(func(tx *bolt.Tx) error {
@@ -155,6 +366,84 @@ func (b *boltStore) SaveTo(w io.Writer) error {
 	})
 }
 
+// vacuumBatchSize is the number of keys copied per transaction by Vacuum
+const vacuumBatchSize = 10000
+
+// Vacuum copies the database to a new file, which only holds the pages in
+// use, and replaces the current file with it. Bolt keeps the pages freed by
+// deleted beacons for later use, so the file never shrinks otherwise. It must
+// not be called while other calls to the store are in progress.
+func (b *boltStore) Vacuum() error {
+	b.Lock()
+	defer b.Unlock()
+	dbPath := b.db.Path()
+	tmpPath := dbPath + ".vacuum"
+	dst, err := bolt.Open(tmpPath, 0660, b.opts)
+	if err != nil {
+		return fmt.Errorf("boltdb: vacuum: %w", err)
+	}
+	if err := copyDB(dst, b.db); err != nil {
+		dst.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("boltdb: vacuum: %w", err)
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("boltdb: vacuum: %w", err)
+	}
+	if err := b.db.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("boltdb: vacuum: %w", err)
+	}
+	renameErr := os.Rename(tmpPath, dbPath)
+	// the store is reopened even if the new file could not replace it
+	db, err := bolt.Open(dbPath, 0660, b.opts)
+	if err != nil {
+		return fmt.Errorf("boltdb: vacuum: reopening the database: %w", err)
+	}
+	b.db = db
+	if renameErr != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("boltdb: vacuum: %w", renameErr)
+	}
+	return nil
+}
+
+// copyDB copies all the buckets of src to dst, committing every
+// vacuumBatchSize keys so the copy does not hold the whole database in memory.
+func copyDB(dst, src *bolt.DB) error {
+	return src.View(func(stx *bolt.Tx) error {
+		dtx, err := dst.Begin(true)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = dtx.Rollback() }()
+		count := 0
+		err = stx.ForEach(func(name []byte, sb *bolt.Bucket) error {
+			if _, err := dtx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+			return sb.ForEach(func(k, v []byte) error {
+				if err := dtx.Bucket(name).Put(k, v); err != nil {
+					return err
+				}
+				if count++; count%vacuumBatchSize != 0 {
+					return nil
+				}
+				if err := dtx.Commit(); err != nil {
+					return err
+				}
+				dtx, err = dst.Begin(true)
+				return err
+			})
+		})
+		if err != nil {
+			return err
+		}
+		return dtx.Commit()
+	})
+}
+
 type boltCursor struct {
 	*bolt.Cursor
 }
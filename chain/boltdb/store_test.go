@@ -1,13 +1,17 @@
 package boltdb
 
 import (
+	"bytes"
+	"errors"
 	"io/ioutil"
 	"os"
+	"path"
 	"testing"
 	"time"
 
 	"github.com/drand/drand/chain"
 	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
 )
 
 func TestStoreBoltOrder(t *testing.T) {
@@ -122,3 +126,299 @@ func TestStoreBolt(t *testing.T) {
 	require.Nil(t, unknown)
 	require.Equal(t, ErrNoBeaconSaved, err)
 }
+
+func TestStoreBoltIterateFrom(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "bolttest*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+	store, err := NewBoltStore(tmp, nil)
+	require.NoError(t, err)
+	defer store.Close()
+
+	for r := uint64(1); r <= 5; r++ {
+		require.NoError(t, store.Put(&chain.Beacon{Round: r, Signature: []byte{byte(r)}}))
+	}
+
+	var rounds []uint64
+	require.NoError(t, store.IterateFrom(3, func(b *chain.Beacon) error {
+		rounds = append(rounds, b.Round)
+		return nil
+	}))
+	require.Equal(t, []uint64{3, 4, 5}, rounds)
+
+	// iteration stops at the first error returned
+	errStop := errors.New("stop")
+	rounds = nil
+	err = store.IterateFrom(2, func(b *chain.Beacon) error {
+		rounds = append(rounds, b.Round)
+		if b.Round == 3 {
+			return errStop
+		}
+		return nil
+	})
+	require.Equal(t, errStop, err)
+	require.Equal(t, []uint64{2, 3}, rounds)
+
+	// nothing to iterate over past the head
+	require.NoError(t, store.IterateFrom(10, func(b *chain.Beacon) error {
+		t.Fatal("unexpected beacon", b.Round)
+		return nil
+	}))
+}
+
+func TestStoreBoltGetRange(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "bolttest*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+	store, err := NewBoltStore(tmp, nil)
+	require.NoError(t, err)
+	defer store.Close()
+
+	// empty store
+	beacons, err := store.GetRange(1, 3)
+	require.NoError(t, err)
+	require.Empty(t, beacons)
+
+	for r := uint64(1); r <= 5; r++ {
+		require.NoError(t, store.Put(&chain.Beacon{Round: r, Signature: []byte{byte(r)}}))
+	}
+
+	rounds := func(beacons []*chain.Beacon) []uint64 {
+		var rs []uint64
+		for _, b := range beacons {
+			rs = append(rs, b.Round)
+		}
+		return rs
+	}
+
+	beacons, err = store.GetRange(2, 4)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{2, 3, 4}, rounds(beacons))
+	require.Equal(t, 3, cap(beacons))
+
+	// single element
+	beacons, err = store.GetRange(3, 3)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{3}, rounds(beacons))
+	require.Equal(t, []byte{3}, beacons[0].Signature)
+
+	// range extending beyond the tip
+	beacons, err = store.GetRange(4, 100)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{4, 5}, rounds(beacons))
+	require.Equal(t, 2, cap(beacons))
+
+	// range past the tip
+	beacons, err = store.GetRange(10, 20)
+	require.NoError(t, err)
+	require.Empty(t, beacons)
+
+	_, err = store.GetRange(4, 2)
+	require.Error(t, err)
+}
+
+func TestStoreBoltLastN(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "bolttest*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+	store, err := NewBoltStore(tmp, nil)
+	require.NoError(t, err)
+	defer store.Close()
+
+	// empty store
+	beacons, err := store.LastN(3)
+	require.NoError(t, err)
+	require.Empty(t, beacons)
+
+	for r := uint64(1); r <= 5; r++ {
+		require.NoError(t, store.Put(&chain.Beacon{Round: r, Signature: []byte{byte(r)}}))
+	}
+	rounds := func(beacons []*chain.Beacon) []uint64 {
+		var rs []uint64
+		for _, b := range beacons {
+			rs = append(rs, b.Round)
+		}
+		return rs
+	}
+
+	beacons, err = store.LastN(3)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{3, 4, 5}, rounds(beacons))
+	require.Equal(t, []byte{5}, beacons[2].Signature)
+
+	// more than stored
+	beacons, err = store.LastN(store.Len() + 10)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1, 2, 3, 4, 5}, rounds(beacons))
+
+	_, err = store.LastN(0)
+	require.Error(t, err)
+}
+
+func TestStoreBoltFirst(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "bolttest*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+	store, err := NewBoltStore(tmp, nil)
+	require.NoError(t, err)
+	defer store.Close()
+
+	_, err = store.First()
+	require.Equal(t, ErrNoBeaconSaved, err)
+
+	// stored out of order
+	for _, r := range []uint64{3, 1, 2} {
+		require.NoError(t, store.Put(&chain.Beacon{Round: r, Signature: []byte{byte(r)}}))
+	}
+	first, err := store.First()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), first.Round)
+	require.Equal(t, []byte{1}, first.Signature)
+
+	require.NoError(t, store.Put(&chain.Beacon{Round: 0, Signature: []byte{0}}))
+	first, err = store.First()
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), first.Round)
+}
+
+func TestStoreBoltGetByRandomness(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "bolttest*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+	store, err := NewBoltStore(tmp, nil)
+	require.NoError(t, err)
+	defer store.Close()
+
+	newBeacon := func(r uint64, sig byte) *chain.Beacon {
+		return &chain.Beacon{Round: r, Signature: []byte{byte(r), sig}}
+	}
+	indexed := func(b *chain.Beacon) bool {
+		var found bool
+		require.NoError(t, store.(*boltStore).db.View(func(tx *bolt.Tx) error {
+			index := tx.Bucket(randomnessBucket)
+			found = index != nil && index.Get(b.Randomness()) != nil
+			return nil
+		}))
+		return found
+	}
+
+	// empty store
+	_, err = store.GetByRandomness(newBeacon(1, 0).Randomness())
+	require.True(t, errors.Is(err, ErrNoBeaconSaved))
+
+	for r := uint64(1); r <= 5; r++ {
+		require.NoError(t, store.Put(newBeacon(r, 0)))
+	}
+	require.False(t, indexed(newBeacon(3, 0)))
+	b, err := store.GetByRandomness(newBeacon(3, 0).Randomness())
+	require.NoError(t, err)
+	require.Equal(t, newBeacon(3, 0), b)
+	// the first lookup indexes the whole chain
+	require.True(t, indexed(newBeacon(5, 0)))
+
+	_, err = store.GetByRandomness([]byte("unknown"))
+	require.True(t, errors.Is(err, ErrNoBeaconSaved))
+
+	// new beacons are not indexed and found by scanning
+	require.NoError(t, store.Put(newBeacon(6, 0)))
+	require.NoError(t, store.Put(newBeacon(7, 0)))
+	require.False(t, indexed(newBeacon(7, 0)))
+	b, err = store.GetByRandomness(newBeacon(7, 0).Randomness())
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), b.Round)
+	require.True(t, indexed(newBeacon(6, 0)))
+
+	// an overwritten beacon below the head of the index is indexed right away
+	require.NoError(t, store.Put(newBeacon(2, 1)))
+	require.True(t, indexed(newBeacon(2, 1)))
+	b, err = store.GetByRandomness(newBeacon(2, 1).Randomness())
+	require.NoError(t, err)
+	require.Equal(t, newBeacon(2, 1), b)
+	_, err = store.GetByRandomness(newBeacon(2, 0).Randomness())
+	require.True(t, errors.Is(err, ErrNoBeaconSaved))
+
+	// a deleted beacon is not returned by a stale index entry
+	require.NoError(t, store.Del(4))
+	_, err = store.GetByRandomness(newBeacon(4, 0).Randomness())
+	require.True(t, errors.Is(err, ErrNoBeaconSaved))
+}
+
+func TestStoreBoltVacuum(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "drandtest*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+	store, err := NewBoltStore(tmp, nil)
+	require.NoError(t, err)
+	defer func() { store.Close() }()
+	dbPath := path.Join(tmp, BoltFileName)
+	size := func() int64 {
+		info, err := os.Stat(dbPath)
+		require.NoError(t, err)
+		return info.Size()
+	}
+
+	n := 5000
+	sig := bytes.Repeat([]byte{0x42}, 96)
+	for i := 1; i <= n; i++ {
+		require.NoError(t, store.Put(&chain.Beacon{Round: uint64(i), Signature: sig, PreviousSig: sig}))
+	}
+	// deleting the beacons does not shrink the file
+	for i := 1; i < n; i++ {
+		require.NoError(t, store.Del(uint64(i)))
+	}
+	before := size()
+	require.NoError(t, store.Vacuum())
+	after := size()
+	require.True(t, after < before, "size before %d, after %d", before, after)
+
+	// the store is usable after the vacuum
+	require.Equal(t, 1, store.Len())
+	b, err := store.Last()
+	require.NoError(t, err)
+	require.Equal(t, uint64(n), b.Round)
+	require.NoError(t, store.Put(&chain.Beacon{Round: uint64(n + 1), Signature: sig}))
+	_, err = store.Get(uint64(n + 1))
+	require.NoError(t, err)
+	_, err = os.Stat(dbPath + ".vacuum")
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestStoreBoltCheckpoint(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "bolttest*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+	store, err := NewBoltStore(tmp, nil)
+	require.NoError(t, err)
+	defer store.Close()
+
+	for i := 0; i <= 10; i++ {
+		require.NoError(t, store.Put(&chain.Beacon{
+			Round:       uint64(i),
+			Signature:   []byte{byte(i)},
+			PreviousSig: []byte{byte(i - 1)},
+		}))
+	}
+	roots := make(map[string]bool)
+	for round := uint64(1); round <= 10; round++ {
+		cp, err := store.Checkpoint(round)
+		require.NoError(t, err)
+		require.Equal(t, round, cp.Round)
+		require.Equal(t, []byte{byte(round)}, cp.Signature)
+		require.Equal(t, []byte{byte(round - 1)}, cp.PreviousSignature)
+		require.NoError(t, cp.VerifyInclusion())
+		roots[string(cp.Root)] = true
+	}
+	require.Len(t, roots, 10)
+
+	_, err = store.Checkpoint(0)
+	require.Error(t, err)
+	_, err = store.Checkpoint(11)
+	require.Error(t, err)
+
+	// a gap in the chain
+	require.NoError(t, store.Del(4))
+	_, err = store.Checkpoint(3)
+	require.NoError(t, err)
+	_, err = store.Checkpoint(5)
+	require.Error(t, err)
+}
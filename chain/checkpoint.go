@@ -0,0 +1,239 @@
+package chain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+)
+
+// Checkpoint holds the beacon of Round with Root, the root of a SHA256 Merkle
+// tree whose leaves are the signatures of the beacons of the rounds 1 to
+// Round, in order, and Proof, the list of the sibling hashes on the path from
+// the beacon of Round, the last leaf, to the root. Neither the root nor the
+// other leaves are signed by the group, and the proof only binds the last
+// leaf: the only thing a checkpoint verifies is the signature of its own
+// beacon. Its root can be compared against the root of the same round from
+// another source.
+type Checkpoint struct {
+	Round             uint64   `json:"round"`
+	Signature         []byte   `json:"signature"`
+	PreviousSignature []byte   `json:"previous_signature,omitempty"`
+	Root              []byte   `json:"root"`
+	Proof             [][]byte `json:"proof"`
+}
+
+// Beacon returns the beacon proved by the checkpoint.
+func (c *Checkpoint) Beacon() *Beacon {
+	return &Beacon{
+		Round:       c.Round,
+		Signature:   c.Signature,
+		PreviousSig: c.PreviousSignature,
+	}
+}
+
+// VerifyInclusion checks that the proof links the signature of the beacon to
+// the root. It does not verify the signature itself.
+func (c *Checkpoint) VerifyInclusion() error {
+	if c.Round == 0 {
+		return errors.New("checkpoint: no checkpoint for the genesis round")
+	}
+	h := merkleLeaf(c.Signature)
+	proof := c.Proof
+	// the beacon of Round is the last leaf of a tree of Round leaves
+	for idx, n := c.Round-1, c.Round; n > 1; idx, n = idx>>1, (n+1)>>1 {
+		if idx^1 >= n {
+			// last node of a level with an odd number of nodes, moved up as is
+			continue
+		}
+		if len(proof) == 0 {
+			return errors.New("checkpoint: proof too short")
+		}
+		if idx&1 == 0 {
+			h = merkleNode(h, proof[0])
+		} else {
+			h = merkleNode(proof[0], h)
+		}
+		proof = proof[1:]
+	}
+	if len(proof) != 0 {
+		return errors.New("checkpoint: proof too long")
+	}
+	if !bytes.Equal(h, c.Root) {
+		return errors.New("checkpoint: proof does not match the root")
+	}
+	return nil
+}
+
+// NewCheckpoint returns the checkpoint of the given round, computed from the
+// beacons of the store. All the rounds from 1 to round must be stored. The
+// signatures of these rounds are read at each call, so it costs a pass over
+// the chain: stores use a CheckpointCache instead.
+func NewCheckpoint(s Store, round uint64) (*Checkpoint, error) {
+	return NewCheckpointCache().Checkpoint(s, round)
+}
+
+// CheckpointInterval is the number of rounds between two frontiers kept by a
+// CheckpointCache.
+var CheckpointInterval uint64 = 1024
+
+// CheckpointCache computes the checkpoints of a store without a pass over the
+// chain at each call. It extends the Merkle frontier of the rounds of the
+// store as checkpoints of new rounds are asked for, and keeps a copy of it
+// every CheckpointInterval rounds, so a checkpoint of a round it already
+// covers reads at most CheckpointInterval beacons. The checkpoints are
+// computed one at a time. The store must call Invalidate when it changes a
+// stored beacon.
+type CheckpointCache struct {
+	computing sync.Mutex
+	sync.Mutex
+	interval uint64
+	// frontiers[i] covers the rounds 1 to i*interval
+	frontiers []*frontier
+	// changed is the lowest round invalidated while a checkpoint is computed:
+	// the frontiers covering it computed meanwhile are dropped
+	changed uint64
+}
+
+// NewCheckpointCache returns an empty cache.
+func NewCheckpointCache() *CheckpointCache {
+	return &CheckpointCache{
+		interval:  CheckpointInterval,
+		frontiers: []*frontier{new(frontier)},
+	}
+}
+
+// Invalidate drops the frontiers covering the given round, after the beacon
+// of that round changed.
+func (c *CheckpointCache) Invalidate(round uint64) {
+	if round == 0 {
+		// the genesis beacon is not in the tree
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	if round < c.changed {
+		c.changed = round
+	}
+	if keep := (round-1)/c.interval + 1; keep < uint64(len(c.frontiers)) {
+		c.frontiers = c.frontiers[:keep]
+	}
+}
+
+// Checkpoint returns the checkpoint of the given round, computed from the
+// beacons of the store. All the rounds from 1 to round must be stored.
+func (c *CheckpointCache) Checkpoint(s Store, round uint64) (*Checkpoint, error) {
+	if round == 0 {
+		return nil, errors.New("checkpoint: no checkpoint for the genesis round")
+	}
+	c.computing.Lock()
+	defer c.computing.Unlock()
+	// start from the last frontier before the round. The store is read without
+	// the lock so Invalidate doesn't wait for it.
+	c.Lock()
+	i := (round - 1) / c.interval
+	if last := uint64(len(c.frontiers) - 1); i > last {
+		i = last
+	}
+	f := c.frontiers[i].clone()
+	c.changed = math.MaxUint64
+	c.Unlock()
+
+	var beacon *Beacon
+	var added []*frontier
+	errDone := errors.New("done")
+	err := s.IterateFrom(f.n+1, func(b *Beacon) error {
+		if b.Round != f.n+1 {
+			return fmt.Errorf("checkpoint: round %d missing from the store", f.n+1)
+		}
+		if b.Round == round {
+			beacon = b
+			return errDone
+		}
+		f.add(merkleLeaf(b.Signature))
+		if f.n%c.interval == 0 {
+			added = append(added, f.clone())
+		}
+		return nil
+	})
+	c.Lock()
+	for _, a := range added {
+		if a.n < c.changed && a.n/c.interval == uint64(len(c.frontiers)) {
+			c.frontiers = append(c.frontiers, a)
+		}
+	}
+	c.Unlock()
+	if err != nil && err != errDone {
+		return nil, err
+	}
+	if beacon == nil {
+		return nil, fmt.Errorf("checkpoint: round %d not stored", round)
+	}
+	return f.checkpoint(beacon), nil
+}
+
+// frontier holds the roots of the perfect subtrees of the Merkle tree of the
+// first n leaves, from the largest to the smallest: one per bit set in n. The
+// tree of n leaves is the right fold of these roots, and the proof of the
+// leaf n+1 in the tree of n+1 leaves is the list of these roots, from the
+// smallest to the largest.
+type frontier struct {
+	n     uint64
+	peaks [][]byte
+}
+
+func (f *frontier) clone() *frontier {
+	return &frontier{n: f.n, peaks: append([][]byte(nil), f.peaks...)}
+}
+
+// add appends a leaf, merging the subtrees of the same size.
+func (f *frontier) add(leaf []byte) {
+	h := leaf
+	for n := f.n; n&1 == 1; n >>= 1 {
+		h = merkleNode(f.peaks[len(f.peaks)-1], h)
+		f.peaks = f.peaks[:len(f.peaks)-1]
+	}
+	f.peaks = append(f.peaks, h)
+	f.n++
+}
+
+// checkpoint returns the checkpoint of the beacon following the rounds of the
+// frontier.
+func (f *frontier) checkpoint(b *Beacon) *Checkpoint {
+	var proof [][]byte
+	for i := len(f.peaks) - 1; i >= 0; i-- {
+		proof = append(proof, f.peaks[i])
+	}
+	next := f.clone()
+	next.add(merkleLeaf(b.Signature))
+	root := next.peaks[len(next.peaks)-1]
+	for i := len(next.peaks) - 2; i >= 0; i-- {
+		root = merkleNode(next.peaks[i], root)
+	}
+	return &Checkpoint{
+		Round:             b.Round,
+		Signature:         b.Signature,
+		PreviousSignature: b.PreviousSig,
+		Root:              root,
+		Proof:             proof,
+	}
+}
+
+// merkleLeaf and merkleNode use a different prefix, so an inner node can not
+// be passed for a leaf.
+func merkleLeaf(signature []byte) []byte {
+	h := sha256.New()
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write(signature)
+	return h.Sum(nil)
+}
+
+func merkleNode(left, right []byte) []byte {
+	h := sha256.New()
+	_, _ = h.Write([]byte{1})
+	_, _ = h.Write(left)
+	_, _ = h.Write(right)
+	return h.Sum(nil)
+}
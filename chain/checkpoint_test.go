@@ -0,0 +1,178 @@
+package chain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// naiveRoot computes the root of the tree recursively, splitting the leaves
+// at the largest power of two lower than their number.
+func naiveRoot(leaves [][]byte) []byte {
+	if len(leaves) == 1 {
+		return leaves[0]
+	}
+	split := 1
+	for split*2 < len(leaves) {
+		split *= 2
+	}
+	return merkleNode(naiveRoot(leaves[:split]), naiveRoot(leaves[split:]))
+}
+
+// merkleProof returns the root of the tree of the given leaves, and the
+// sibling hashes on the path from the leaf at idx to the root, computed level
+// by level.
+func merkleProof(leaves [][]byte, idx int) ([]byte, [][]byte) {
+	var proof [][]byte
+	level := leaves
+	for len(level) > 1 {
+		if sibling := idx ^ 1; sibling < len(level) {
+			proof = append(proof, level[sibling])
+		}
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+			} else {
+				next = append(next, merkleNode(level[i], level[i+1]))
+			}
+		}
+		level = next
+		idx >>= 1
+	}
+	return level[0], proof
+}
+
+func TestCheckpointInclusion(t *testing.T) {
+	var sigs, leaves [][]byte
+	for n := 1; n <= 33; n++ {
+		sig := sha256.Sum256([]byte{byte(n)})
+		sigs = append(sigs, sig[:])
+		leaves = append(leaves, merkleLeaf(sig[:]))
+
+		root, proof := merkleProof(leaves, n-1)
+		require.Equal(t, naiveRoot(leaves), root, "round %d", n)
+		// the frontier of the previous rounds gives the same root and proof
+		f := new(frontier)
+		for _, l := range leaves[:n-1] {
+			f.add(l)
+		}
+		fromFrontier := f.checkpoint(&Beacon{Round: uint64(n), Signature: sigs[n-1]})
+		require.Equal(t, root, fromFrontier.Root, "round %d", n)
+		require.Equal(t, proof, fromFrontier.Proof, "round %d", n)
+		cp := &Checkpoint{Round: uint64(n), Signature: sigs[n-1], Root: root, Proof: proof}
+		require.NoError(t, cp.VerifyInclusion(), "round %d", n)
+
+		tampered := *cp
+		tampered.Signature = bytes.Repeat([]byte{1}, 32)
+		require.Error(t, tampered.VerifyInclusion())
+		tampered = *cp
+		tampered.Root = sigs[0]
+		require.Error(t, tampered.VerifyInclusion())
+		tampered = *cp
+		tampered.Proof = append(proof, root)
+		require.Error(t, tampered.VerifyInclusion())
+		if len(proof) > 0 {
+			tampered = *cp
+			tampered.Proof = proof[1:]
+			require.Error(t, tampered.VerifyInclusion())
+		}
+	}
+	require.Error(t, (&Checkpoint{Round: 0}).VerifyInclusion())
+}
+
+// countingStore serves the beacons of a slice and counts the beacons read
+type countingStore struct {
+	Store
+	beacons []*Beacon
+	read    int
+	// onRead is called with the round of each beacon read
+	onRead func(round uint64)
+}
+
+func (s *countingStore) IterateFrom(from uint64, fn func(*Beacon) error) error {
+	for _, b := range s.beacons {
+		if b.Round < from {
+			continue
+		}
+		s.read++
+		if s.onRead != nil {
+			s.onRead(b.Round)
+		}
+		if err := fn(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestCheckpointCache(t *testing.T) {
+	CheckpointInterval = 4
+	defer func() { CheckpointInterval = 1024 }()
+	s := new(countingStore)
+	for i := 0; i <= 20; i++ {
+		sig := sha256.Sum256([]byte{byte(i)})
+		s.beacons = append(s.beacons, &Beacon{Round: uint64(i), Signature: sig[:]})
+	}
+	cache := NewCheckpointCache()
+	check := func(round uint64) {
+		cp, err := cache.Checkpoint(s, round)
+		require.NoError(t, err)
+		expected, err := NewCheckpoint(&countingStore{beacons: s.beacons}, round)
+		require.NoError(t, err)
+		require.Equal(t, expected, cp)
+		require.NoError(t, cp.VerifyInclusion())
+	}
+	// the first checkpoint reads the chain up to its round
+	check(18)
+	require.Equal(t, 18, s.read)
+	// the next ones start from the closest frontier
+	for _, round := range []uint64{3, 9, 17, 20} {
+		s.read = 0
+		check(round)
+		require.LessOrEqual(t, s.read, 4, "round %d", round)
+	}
+
+	// a changed beacon drops the frontiers covering it
+	sig := sha256.Sum256([]byte("changed"))
+	s.beacons[6] = &Beacon{Round: 6, Signature: sig[:]}
+	cache.Invalidate(6)
+	s.read = 0
+	check(18)
+	require.Equal(t, 14, s.read)
+
+	// a beacon stored after the rounds of a checkpoint while it is computed
+	// keeps its frontiers, one of the rounds drops the frontiers covering it
+	cache = NewCheckpointCache()
+	s.onRead = func(round uint64) {
+		if round == 2 {
+			cache.Invalidate(21)
+		}
+	}
+	check(18)
+	s.read = 0
+	check(17)
+	require.LessOrEqual(t, s.read, 4)
+	cache = NewCheckpointCache()
+	s.onRead = func(round uint64) {
+		if round == 10 {
+			cache.Invalidate(6)
+		}
+	}
+	check(18)
+	s.onRead = nil
+	s.read = 0
+	check(17)
+	require.Equal(t, 13, s.read)
+
+	_, err := cache.Checkpoint(s, 0)
+	require.Error(t, err)
+	_, err = cache.Checkpoint(s, 21)
+	require.Error(t, err)
+	s.beacons = append(s.beacons[:10], s.beacons[11:]...)
+	cache.Invalidate(10)
+	_, err = cache.Checkpoint(s, 12)
+	require.Error(t, err)
+}
@@ -1,6 +1,8 @@
 package chain
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"time"
@@ -11,19 +13,31 @@ import (
 	"github.com/drand/drand/protobuf/drand"
 )
 
-// InfoFromProto returns a Info from the protocol description
+// InfoFromProto returns a Info from the protocol description. It checks the
+// description is usable to verify beacons and, when it carries the hash of the
+// chain, that this hash matches the decoded information.
 func InfoFromProto(p *drand.ChainInfoPacket) (*Info, error) {
+	if p == nil {
+		return nil, errors.New("empty chain info")
+	}
+	if p.Period == 0 {
+		return nil, errors.New("chain info with a zero period")
+	}
 	public := key.KeyGroup.Point()
 	if err := public.UnmarshalBinary(p.PublicKey); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("invalid chain public key: %w", err)
 	}
 
-	return &Info{
+	info := &Info{
 		PublicKey:   public,
 		GenesisTime: p.GenesisTime,
 		Period:      time.Duration(p.Period) * time.Second,
 		GroupHash:   p.GroupHash,
-	}, nil
+	}
+	if len(p.Hash) > 0 && !bytes.Equal(p.Hash, info.Hash()) {
+		return nil, fmt.Errorf("chain info hash %x does not match its content", p.Hash)
+	}
+	return info, nil
 }
 
 // ToProto returns the protobuf description of the chain info
@@ -54,3 +54,36 @@ func TestChainInfo(t *testing.T) {
 	require.NotNil(t, c13)
 	require.Equal(t, c1, c13)
 }
+
+func TestChainInfoFromProto(t *testing.T) {
+	_, g := test.BatchIdentities(5)
+	c := NewChainInfo(g)
+
+	c2, err := InfoFromProto(c.ToProto())
+	require.NoError(t, err)
+	require.True(t, c.Equal(c2))
+
+	// the hash is optional
+	p := c.ToProto()
+	p.Hash = nil
+	_, err = InfoFromProto(p)
+	require.NoError(t, err)
+
+	p = c.ToProto()
+	p.Hash[0] ^= 0xff
+	_, err = InfoFromProto(p)
+	require.Error(t, err)
+
+	p = c.ToProto()
+	p.Period = 0
+	_, err = InfoFromProto(p)
+	require.Error(t, err)
+
+	p = c.ToProto()
+	p.PublicKey = []byte{0x01, 0x02}
+	_, err = InfoFromProto(p)
+	require.Error(t, err)
+
+	_, err = InfoFromProto(nil)
+	require.Error(t, err)
+}
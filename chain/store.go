@@ -16,11 +16,32 @@ type Store interface {
 	Len() int
 	Put(*Beacon) error
 	Last() (*Beacon, error)
+	// First returns the beacon with the lowest round stored, usually the
+	// genesis beacon.
+	First() (*Beacon, error)
 	Get(round uint64) (*Beacon, error)
 	Cursor(func(Cursor))
+	// IterateFrom calls fn on each stored beacon starting from the given round,
+	// in increasing order. It stops at the first error returned by fn and
+	// returns it.
+	IterateFrom(from uint64, fn func(*Beacon) error) error
+	// GetRange returns the stored beacons with a round in [from, to], in
+	// increasing order. Rounds past the head of the chain are ignored.
+	GetRange(from, to uint64) ([]*Beacon, error)
+	// LastN returns the n most recent beacons, in increasing order. It returns
+	// all the stored beacons if there are less than n.
+	LastN(n int) ([]*Beacon, error)
+	// GetByRandomness returns the stored beacon whose randomness, i.e. the
+	// hash of its signature, is the given one.
+	GetByRandomness(randomness []byte) (*Beacon, error)
+	// Checkpoint returns the checkpoint of the given round, with the root of
+	// the Merkle tree of the signatures up to that round.
+	Checkpoint(round uint64) (*Checkpoint, error)
 	Close()
 	Del(round uint64) error
 	SaveTo(w io.Writer) error
+	// Vacuum reclaims the disk space left unused by the deleted beacons.
+	Vacuum() error
 }
 
 // Cursor iterates over items in sorted key order. This starts from the
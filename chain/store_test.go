@@ -3,6 +3,8 @@ package chain
 import (
 	"testing"
 
+	"github.com/drand/drand/key"
+	"github.com/drand/drand/test"
 	"github.com/stretchr/testify/require"
 )
 
@@ -12,3 +14,27 @@ func TestRoundToBytes(t *testing.T) {
 	require.Equal(t, []byte{0x00, 0x00, 0x00, 0x2a, 0xec, 0x04, 0x83, 0xff}, RoundToBytes(184348345343))
 	require.Equal(t, []byte{0xA1, 0xB2, 0xC3, 0xD4, 0xE5, 0xF6, 0xA7, 0xB8}, RoundToBytes(0xA1B2C3D4E5F6A7B8))
 }
+
+// The genesis beacon only depends on the group, so every node derives the same
+// one, whether it reads the group from a file or builds it itself.
+func TestGenesisBeacon(t *testing.T) {
+	_, group := test.BatchIdentities(5)
+	genesis := GenesisBeacon(NewChainInfo(group))
+	require.Equal(t, uint64(0), genesis.Round)
+	require.Nil(t, genesis.PreviousSig)
+	require.Equal(t, group.Hash(), genesis.Signature)
+
+	gtoml := group.TOML().(*key.GroupTOML)
+	loaded := new(key.Group)
+	require.NoError(t, loaded.FromTOML(gtoml))
+	require.Equal(t, genesis, GenesisBeacon(NewChainInfo(loaded)))
+
+	// without the stored seed, it is derived again from the group
+	gtoml.GenesisSeed = ""
+	rebuilt := new(key.Group)
+	require.NoError(t, rebuilt.FromTOML(gtoml))
+	require.Equal(t, genesis, GenesisBeacon(NewChainInfo(rebuilt)))
+
+	_, other := test.BatchIdentities(5)
+	require.NotEqual(t, genesis, GenesisBeacon(NewChainInfo(other)))
+}
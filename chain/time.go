@@ -37,6 +37,15 @@ func TimeOfRound(period time.Duration, genesis int64, round uint64) int64 {
 	return val
 }
 
+// RoundAt returns the round active at time t, i.e. the last round whose time
+// is not after t. It returns 0 if t is before the genesis time.
+func RoundAt(t time.Time, period time.Duration, genesis int64) uint64 {
+	if period <= 0 || t.Unix() < genesis {
+		return 0
+	}
+	return CurrentRound(t.Unix(), period, genesis)
+}
+
 // CurrentRound calculates the active round at `now`
 func CurrentRound(now int64, period time.Duration, genesis int64) uint64 {
 	nextRound, _ := NextRound(now, period, genesis)
@@ -3,6 +3,7 @@ package chain
 import (
 	"math"
 	"testing"
+	"testing/quick"
 	"time"
 
 	clock "github.com/jonboulle/clockwork"
@@ -68,3 +69,34 @@ func TestChainNextRound(t *testing.T) {
 	time2 := TimeOfRound(period, genesis, 3)
 	require.Equal(t, expTime2, time2)
 }
+
+func TestRoundAt(t *testing.T) {
+	genesis := time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC).Unix()
+	period := 30 * time.Second
+
+	require.Equal(t, uint64(0), RoundAt(time.Unix(genesis-1, 0), period, genesis))
+	require.Equal(t, uint64(1), RoundAt(time.Unix(genesis, 0), period, genesis))
+	require.Equal(t, uint64(1), RoundAt(time.Unix(genesis+29, 0), period, genesis))
+	require.Equal(t, uint64(2), RoundAt(time.Unix(genesis+30, 0), period, genesis))
+
+	// offsets are seconds after genesis, kept in a range that cannot overflow
+	toTime := func(offset uint32) time.Time {
+		return time.Unix(genesis+int64(offset), 0)
+	}
+	monotonic := func(a, b uint32) bool {
+		if a > b {
+			a, b = b, a
+		}
+		return RoundAt(toTime(a), period, genesis) <= RoundAt(toTime(b), period, genesis)
+	}
+	require.NoError(t, quick.Check(monotonic, nil))
+
+	inverse := func(offset uint32) bool {
+		ts := toTime(offset)
+		round := RoundAt(ts, period, genesis)
+		// the round is active at ts, and the next one is not yet
+		return TimeOfRound(period, genesis, round) <= ts.Unix() &&
+			TimeOfRound(period, genesis, round+1) > ts.Unix()
+	}
+	require.NoError(t, quick.Check(inverse, nil))
+}
@@ -28,6 +28,16 @@ type grpcClient struct {
 
 // New creates a drand client backed by a GRPC connection.
 func New(address, certPath string, insecure bool) (client.Client, error) {
+	conn, err := dial(address, certPath, insecure)
+	if err != nil {
+		return nil, err
+	}
+	return &grpcClient{address, drand.NewPublicClient(conn), conn, log.DefaultLogger()}, nil
+}
+
+// dial connects to the node at address with the certificate at certPath if
+// given, without TLS if insecure is set, or with the system certificates.
+func dial(address, certPath string, insecure bool) (*grpc.ClientConn, error) {
 	opts := []grpc.DialOption{}
 	if certPath != "" {
 		creds, err := credentials.NewClientTLSFromFile(certPath, "")
@@ -44,11 +54,7 @@ func New(address, certPath string, insecure bool) (client.Client, error) {
 		grpc.WithUnaryInterceptor(grpc_prometheus.UnaryClientInterceptor),
 		grpc.WithStreamInterceptor(grpc_prometheus.StreamClientInterceptor),
 	)
-	conn, err := grpc.Dial(address, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return &grpcClient{address, drand.NewPublicClient(conn), conn, log.DefaultLogger()}, nil
+	return grpc.Dial(address, opts...)
 }
 
 func asRD(r *drand.PublicRandResponse) *client.RandomData {
@@ -0,0 +1,79 @@
+package grpc
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/log"
+	"github.com/drand/drand/protobuf/drand"
+)
+
+// back-off bounds between two reconnections of WatchRandomness
+var (
+	watchMinBackoff = 1 * time.Second
+	watchMaxBackoff = 60 * time.Second
+)
+
+// WatchRandomness streams the beacons of the node at address from the given
+// round, or the new ones if from is 0, with the TLS settings of New. When the
+// stream fails, it reconnects with an exponential back-off and resumes after
+// the last beacon received, so no beacon is sent twice. The channel is closed
+// when ctx is done.
+func WatchRandomness(ctx context.Context, address, certPath string, insecure bool, from uint64) (<-chan *chain.Beacon, error) {
+	conn, err := dial(address, certPath, insecure)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *chain.Beacon)
+	go func() {
+		defer conn.Close()
+		defer close(out)
+		watchStream(ctx, drand.NewPublicClient(conn), from, out, log.DefaultLogger())
+	}()
+	return out, nil
+}
+
+func watchStream(ctx context.Context, client drand.PublicClient, next uint64, out chan<- *chain.Beacon, l log.Logger) {
+	backoff := watchMinBackoff
+	for {
+		stream, err := client.PublicRandStream(ctx, &drand.PublicRandRequest{Round: next})
+		for err == nil {
+			var resp *drand.PublicRandResponse
+			if resp, err = stream.Recv(); err != nil {
+				break
+			}
+			// a node may resend the rounds we already have after a reconnection
+			if next != 0 && resp.GetRound() < next {
+				continue
+			}
+			select {
+			case out <- &chain.Beacon{
+				Round:       resp.GetRound(),
+				Signature:   resp.GetSignature(),
+				PreviousSig: resp.GetPreviousSignature(),
+			}:
+			case <-ctx.Done():
+				return
+			}
+			next = resp.GetRound() + 1
+			backoff = watchMinBackoff
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		// wait between half and all of the back-off so clients disconnected
+		// together don't reconnect together
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		l.Warn("grpc_client", "public rand stream", "err", err, "next_round", next, "retry_in", wait)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return
+		}
+		if backoff *= 2; backoff > watchMaxBackoff {
+			backoff = watchMaxBackoff
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/protobuf/drand"
+	"google.golang.org/grpc"
+)
+
+// droppingServer sends 3 beacons from the requested round, or from round 1,
+// then drops the stream. It records the round of each request.
+type droppingServer struct {
+	drand.UnimplementedPublicServer
+	sync.Mutex
+	requests []uint64
+}
+
+func (d *droppingServer) PublicRandStream(req *drand.PublicRandRequest, stream drand.Public_PublicRandStreamServer) error {
+	d.Lock()
+	d.requests = append(d.requests, req.GetRound())
+	d.Unlock()
+	from := req.GetRound()
+	if from == 0 {
+		from = 1
+	}
+	// resend the round before the requested one, as a node could
+	if from > 1 {
+		from--
+	}
+	for r := from; r < from+3; r++ {
+		if err := stream.Send(&drand.PublicRandResponse{Round: r, Signature: []byte{byte(r)}}); err != nil {
+			return err
+		}
+	}
+	return errors.New("stream dropped")
+}
+
+func TestWatchRandomnessReconnect(t *testing.T) {
+	watchMinBackoff, watchMaxBackoff = 10*time.Millisecond, 20*time.Millisecond
+	defer func() { watchMinBackoff, watchMaxBackoff = time.Second, 60*time.Second }()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	dropping := new(droppingServer)
+	drand.RegisterPublicServer(server, dropping)
+	go func() { _ = server.Serve(lis) }()
+	defer server.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	beacons, err := WatchRandomness(ctx, lis.Addr().String(), "", true, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for round := uint64(1); round <= 7; round++ {
+		select {
+		case b := <-beacons:
+			if b.Round != round || b.Signature[0] != byte(round) {
+				t.Fatalf("expected round %d, got %d", round, b.Round)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("round %d not received", round)
+		}
+	}
+	cancel()
+	// the channel is closed once ctx is done
+	for range beacons {
+	}
+
+	dropping.Lock()
+	defer dropping.Unlock()
+	if len(dropping.requests) < 3 || dropping.requests[0] != 0 || dropping.requests[1] != 4 || dropping.requests[2] != 6 {
+		t.Fatalf("unexpected requests %v", dropping.requests)
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	nhttp "net/http"
 	"os"
 	"path"
@@ -13,6 +15,7 @@ import (
 
 	"github.com/drand/drand/chain"
 	"github.com/drand/drand/client"
+	"github.com/drand/drand/key"
 	"github.com/drand/drand/log"
 	"github.com/drand/drand/metrics"
 	"github.com/prometheus/client_golang/prometheus"
@@ -296,6 +299,70 @@ func (h *httpClient) Get(ctx context.Context, round uint64) (client.Result, erro
 	}
 }
 
+// Checkpoint fetches the checkpoint of the given round and verifies it against
+// the chain info of the client. The root of the checkpoint is the one of the
+// server, see client.VerifyCheckpoint.
+func (h *httpClient) Checkpoint(ctx context.Context, round uint64) (*chain.Checkpoint, error) {
+	url := fmt.Sprintf("%schain/%x/checkpoint/%d", h.root, h.chainInfo.Hash(), round)
+	req, err := nhttp.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", h.Agent)
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("doing request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != nhttp.StatusOK {
+		return nil, fmt.Errorf("checkpoint of round %d: %s", round, resp.Status)
+	}
+	cp := new(chain.Checkpoint)
+	if err := json.NewDecoder(resp.Body).Decode(cp); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+	if cp.Round != round {
+		return nil, fmt.Errorf("checkpoint of round %d instead of %d", cp.Round, round)
+	}
+	if err := client.VerifyCheckpoint(h.chainInfo, cp); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
+
+// Group fetches the group of the chain and checks it is the group of the chain
+// info of the client.
+func (h *httpClient) Group(ctx context.Context) (*key.Group, error) {
+	req, err := nhttp.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%sgroup", h.root), nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", h.Agent)
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("doing request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != nhttp.StatusOK {
+		return nil, fmt.Errorf("group: %s", resp.Status)
+	}
+	buff, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+	group, err := key.GroupFromJSON(buff)
+	if err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+	if group.PublicKey == nil {
+		return nil, errors.New("group without distributed key")
+	}
+	if !bytes.Equal(chain.NewChainInfo(group).Hash(), h.chainInfo.Hash()) {
+		return nil, fmt.Errorf("%s does not advertise the group of the chain", h.root)
+	}
+	return group, nil
+}
+
 // Watch returns new randomness as it becomes available.
 func (h *httpClient) Watch(ctx context.Context) <-chan client.Result {
 	out := make(chan client.Result)
@@ -2,13 +2,23 @@ package http
 
 import (
 	"context"
+	"fmt"
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"os"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/chain/boltdb"
 	"github.com/drand/drand/client"
 	"github.com/drand/drand/client/test/http/mock"
+	rmock "github.com/drand/drand/client/test/result/mock"
+	"github.com/drand/drand/key"
+	"github.com/drand/drand/test"
+	json "github.com/nikkolasg/hexjson"
 )
 
 func TestHTTPClient(t *testing.T) {
@@ -144,3 +154,108 @@ func TestHTTPClientClose(t *testing.T) {
 
 	wg.Wait() // wait for the watch to close
 }
+
+func TestHTTPCheckpoint(t *testing.T) {
+	info, results := rmock.VerifiableResults(5)
+	tmp, err := ioutil.TempDir("", "drand-checkpoint-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	store, err := boltdb.NewBoltStore(tmp, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	for _, r := range results {
+		if err := store.Put(&chain.Beacon{Round: r.Rnd, Signature: r.Sig, PreviousSig: r.PSig}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkpoint, err := store.Checkpoint(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	served := *checkpoint
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != fmt.Sprintf("/chain/%x/checkpoint/4", info.Hash()) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(&served)
+	}))
+	defer server.Close()
+
+	c, err := NewWithInfo(server.URL, info, http.DefaultTransport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	checkpointer := c.(interface {
+		Checkpoint(context.Context, uint64) (*chain.Checkpoint, error)
+	})
+	ctx := context.Background()
+	cp, err := checkpointer.Checkpoint(ctx, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp.Round != 4 || string(cp.Root) != string(checkpoint.Root) {
+		t.Fatal("unexpected checkpoint")
+	}
+	if _, err := checkpointer.Checkpoint(ctx, 3); err == nil {
+		t.Fatal("round not served")
+	}
+
+	// a root that does not match the proof
+	served.Root = results[2].Sig
+	if _, err := checkpointer.Checkpoint(ctx, 4); err == nil {
+		t.Fatal("checkpoint with an invalid proof should not verify")
+	}
+	// a beacon not signed by the chain
+	served = *checkpoint
+	served.Signature = results[3].PSig
+	if _, err := checkpointer.Checkpoint(ctx, 4); err == nil {
+		t.Fatal("checkpoint with an invalid signature should not verify")
+	}
+}
+
+func TestHTTPGroup(t *testing.T) {
+	_, group := test.BatchIdentities(3)
+	info := chain.NewChainInfo(group)
+	served := group
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/group" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		buff, err := served.ToJSON()
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write(buff)
+	}))
+	defer server.Close()
+
+	c, err := NewWithInfo(server.URL, info, http.DefaultTransport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	grouper := c.(interface {
+		Group(context.Context) (*key.Group, error)
+	})
+	received, err := grouper.Group(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !received.Equal(group) {
+		t.Fatal("unexpected group")
+	}
+
+	// the group of another chain
+	_, served = test.BatchIdentities(3)
+	if _, err := grouper.Group(context.Background()); err == nil {
+		t.Fatal("group of another chain should not be accepted")
+	}
+}
@@ -191,3 +191,15 @@ func (v *verifyingClient) verify(ctx context.Context, info *chain.Info, r *Rando
 func (v *verifyingClient) String() string {
 	return fmt.Sprintf("%s.(+verifier)", v.Client)
 }
+
+// VerifyCheckpoint checks that the beacon of the checkpoint is signed by the
+// chain, and that the proof of the checkpoint links it to the root. The root
+// is not signed: it is the one computed by the node that served the
+// checkpoint, so a verified checkpoint proves nothing beyond the signature of
+// its beacon.
+func VerifyCheckpoint(info *chain.Info, c *chain.Checkpoint) error {
+	if err := chain.VerifyBeacon(info.PublicKey, c.Beacon()); err != nil {
+		return fmt.Errorf("checkpoint: invalid beacon signature: %w", err)
+	}
+	return c.VerifyInclusion()
+}
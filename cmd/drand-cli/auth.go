@@ -0,0 +1,150 @@
+package drand
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/urfave/cli/v2"
+	"google.golang.org/grpc"
+
+	"github.com/drand/drand/key"
+	"github.com/drand/drand/net"
+)
+
+// controlKeysFileName is the file, in the config folder, listing the public
+// keys allowed to issue control commands to the daemon
+const controlKeysFileName = "control_keys.toml"
+
+// controlKeysTOML is the content of the control keys file, with the keys hex
+// encoded
+type controlKeysTOML struct {
+	Keys []string
+}
+
+func controlKeysPath(c *cli.Context) string {
+	return path.Join(contextToConfig(c).ConfigFolder(), controlKeysFileName)
+}
+
+// loadControlKeys returns the hex encoded keys of the control keys file, or
+// none if the file does not exist.
+func loadControlKeys(filePath string) ([]string, error) {
+	ck := new(controlKeysTOML)
+	if _, err := toml.DecodeFile(filePath, ck); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("can't read control keys: %w", err)
+	}
+	return ck.Keys, nil
+}
+
+func saveControlKeys(filePath string, keys []string) error {
+	fd, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("can't write control keys: %w", err)
+	}
+	defer fd.Close()
+	return toml.NewEncoder(fd).Encode(&controlKeysTOML{Keys: keys})
+}
+
+// controlIdentities returns the identities allowed to issue control commands
+// given their hex encoded keys
+func controlIdentities(keys []string) ([]*key.Identity, error) {
+	ids := make([]*key.Identity, 0, len(keys))
+	for _, k := range keys {
+		id, err := parseControlKey(k)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func parseControlKey(k string) (*key.Identity, error) {
+	buff, err := hex.DecodeString(k)
+	if err != nil {
+		return nil, fmt.Errorf("invalid control key %q: %w", k, err)
+	}
+	pub := key.KeyGroup.Point()
+	if err := pub.UnmarshalBinary(buff); err != nil {
+		return nil, fmt.Errorf("invalid control key %q: %w", k, err)
+	}
+	return &key.Identity{Key: pub}, nil
+}
+
+func addKeyCmd(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("add-key takes the hex encoded public key to allow")
+	}
+	k := strings.ToLower(c.Args().First())
+	if _, err := parseControlKey(k); err != nil {
+		return err
+	}
+	filePath := controlKeysPath(c)
+	keys, err := loadControlKeys(filePath)
+	if err != nil {
+		return err
+	}
+	for _, existing := range keys {
+		if existing == k {
+			fmt.Fprintf(output, "drand: key %s is already allowed\n", k)
+			return nil
+		}
+	}
+	if err := saveControlKeys(filePath, append(keys, k)); err != nil {
+		return err
+	}
+	fmt.Fprintf(output, "drand: key %s allowed, restart the daemon to apply it\n", k)
+	return nil
+}
+
+func removeKeyCmd(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("remove-key takes the hex encoded public key to remove")
+	}
+	k := strings.ToLower(c.Args().First())
+	filePath := controlKeysPath(c)
+	keys, err := loadControlKeys(filePath)
+	if err != nil {
+		return err
+	}
+	kept := make([]string, 0, len(keys))
+	for _, existing := range keys {
+		if existing != k {
+			kept = append(kept, existing)
+		}
+	}
+	if len(kept) == len(keys) {
+		return fmt.Errorf("key %s is not allowed", k)
+	}
+	if err := saveControlKeys(filePath, kept); err != nil {
+		return err
+	}
+	fmt.Fprintf(output, "drand: key %s removed, restart the daemon to apply it\n", k)
+	return nil
+}
+
+// newControlClient returns a client of the daemon at the given control port,
+// signing its requests with the key pair of the operator folder if it is set.
+func newControlClient(c *cli.Context, port string) (*net.ControlClient, error) {
+	var opts []grpc.DialOption
+	if c.IsSet(operatorFolderFlag.Name) {
+		fs, err := key.NewFileStore(c.String(operatorFolderFlag.Name))
+		if err != nil {
+			return nil, fmt.Errorf("can't open operator key store: %w", err)
+		}
+		defer fs.Close()
+		pair, err := fs.LoadKeyPair()
+		if err != nil {
+			return nil, fmt.Errorf("can't load operator key pair: %w", err)
+		}
+		opts = net.ControlAuthOptions(pair)
+	}
+	return net.NewControlClient(port, opts...)
+}
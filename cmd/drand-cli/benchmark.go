@@ -0,0 +1,183 @@
+package drand
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/chain/boltdb"
+	"github.com/urfave/cli/v2"
+)
+
+// storeBackends lists the beacon store backends that can be benchmarked. Each
+// one creates a store in the given folder.
+var storeBackends = map[string]func(folder string) (chain.Store, error){
+	"boltdb": func(folder string) (chain.Store, error) {
+		return boltdb.NewBoltStore(folder, nil)
+	},
+}
+
+// fakeSigSize is the size of the BLS signatures of the fake beacons
+const fakeSigSize = 96
+
+// storeBenchmark holds the latency of each operation of a store benchmark
+// phase and its total duration.
+type storeBenchmark struct {
+	latencies []time.Duration
+	total     time.Duration
+}
+
+func benchmarkStoreCmd(c *cli.Context) error {
+	name := c.String(storeBackendFlag.Name)
+	newStore, ok := storeBackends[name]
+	if !ok {
+		var names []string
+		for n := range storeBackends {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown backend %q, supported backends: %s", name, strings.Join(names, ", "))
+	}
+	count := c.Int(benchCountFlag.Name)
+	workers := c.Int(benchWorkersFlag.Name)
+	if count <= 0 || workers <= 0 {
+		return errors.New("count and workers must be positive")
+	}
+
+	if err := os.MkdirAll(c.String(benchDirFlag.Name), 0700); err != nil {
+		return err
+	}
+	folder, err := ioutil.TempDir(c.String(benchDirFlag.Name), "drand-benchmark")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(folder)
+	store, err := newStore(folder)
+	if err != nil {
+		return fmt.Errorf("can't create %s store: %s", name, err)
+	}
+	defer store.Close()
+
+	beacons, err := fakeBeacons(count)
+	if err != nil {
+		return err
+	}
+	writes, err := runStoreBenchmark(beacons, workers, func(b *chain.Beacon) error {
+		return store.Put(b)
+	})
+	if err != nil {
+		return fmt.Errorf("write failed: %s", err)
+	}
+	reads, err := runStoreBenchmark(beacons, workers, func(b *chain.Beacon) error {
+		_, err := store.Get(b.Round)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("read failed: %s", err)
+	}
+	size, err := folderSize(folder)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(output, "backend: %s, beacons: %d, workers: %d\n", name, count, workers)
+	fmt.Fprintf(output, "write: %s\n", writes)
+	fmt.Fprintf(output, "read: %s\n", reads)
+	fmt.Fprintf(output, "database size: %d bytes\n", size)
+	return nil
+}
+
+// fakeBeacons returns count beacons with random signatures, starting at
+// round 1.
+func fakeBeacons(count int) ([]*chain.Beacon, error) {
+	beacons := make([]*chain.Beacon, count)
+	prev := make([]byte, fakeSigSize)
+	if _, err := rand.Read(prev); err != nil {
+		return nil, err
+	}
+	for i := range beacons {
+		sig := make([]byte, fakeSigSize)
+		if _, err := rand.Read(sig); err != nil {
+			return nil, err
+		}
+		beacons[i] = &chain.Beacon{
+			Round:       uint64(i + 1),
+			PreviousSig: prev,
+			Signature:   sig,
+		}
+		prev = sig
+	}
+	return beacons, nil
+}
+
+// runStoreBenchmark calls op on each beacon from the given number of parallel
+// workers and measures the latency of each call.
+func runStoreBenchmark(beacons []*chain.Beacon, workers int, op func(*chain.Beacon) error) (*storeBenchmark, error) {
+	latencies := make([]time.Duration, len(beacons))
+	errCh := make(chan error, workers)
+	var wg sync.WaitGroup
+	start := time.Now()
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < len(beacons); i += workers {
+				opStart := time.Now()
+				if err := op(beacons[i]); err != nil {
+					errCh <- err
+					return
+				}
+				latencies[i] = time.Since(opStart)
+			}
+		}(w)
+	}
+	wg.Wait()
+	total := time.Since(start)
+	close(errCh)
+	if err := <-errCh; err != nil {
+		return nil, err
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	return &storeBenchmark{latencies: latencies, total: total}, nil
+}
+
+// percentile returns the latency under which the fraction p of the operations
+// completed.
+func (s *storeBenchmark) percentile(p float64) time.Duration {
+	idx := int(math.Ceil(p*float64(len(s.latencies)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return s.latencies[idx]
+}
+
+func (s *storeBenchmark) String() string {
+	throughput := float64(len(s.latencies)) / s.total.Seconds()
+	return fmt.Sprintf("p50 %s, p95 %s, p99 %s, throughput %.0f beacons/s",
+		s.percentile(0.50), s.percentile(0.95), s.percentile(0.99), throughput)
+}
+
+// folderSize returns the total size of the files in the folder, so it does not
+// depend on how a backend lays out its files.
+func folderSize(folder string) (int64, error) {
+	var size int64
+	err := filepath.Walk(folder, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
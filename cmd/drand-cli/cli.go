@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
@@ -16,10 +17,12 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 
 	gonet "net"
 
 	"github.com/BurntSushi/toml"
+	"github.com/drand/drand/chain"
 	"github.com/drand/drand/chain/boltdb"
 	"github.com/drand/drand/core"
 	"github.com/drand/drand/fs"
@@ -27,6 +30,7 @@ import (
 	"github.com/drand/drand/log"
 	"github.com/drand/drand/net"
 	"github.com/drand/drand/protobuf/drand"
+	json "github.com/nikkolasg/hexjson"
 	"github.com/urfave/cli/v2"
 )
 
@@ -55,6 +59,13 @@ var folderFlag = &cli.StringFlag{
 	Usage: "Folder to keep all drand cryptographic information, with absolute path.",
 }
 
+var operatorFolderFlag = &cli.StringFlag{
+	Name: "operator-folder",
+	Usage: "Folder of the key pair signing the control commands, as generated by generate-keypair. " +
+		"Needed when the daemon only accepts the keys given to util add-key.",
+	EnvVars: []string{"DRAND_OPERATOR_FOLDER"},
+}
+
 var verboseFlag = &cli.BoolFlag{
 	Name:  "verbose",
 	Usage: "If set, verbosity is at the debug level",
@@ -75,8 +86,9 @@ var tlsKeyFlag = &cli.StringFlag{
 }
 
 var insecureFlag = &cli.BoolFlag{
-	Name:  "tls-disable",
-	Usage: "Disable TLS for all communications (not recommended).",
+	Name:    "tls-disable",
+	Aliases: []string{"no-tls"},
+	Usage:   "Disable TLS for all communications (not recommended).",
 }
 
 var controlFlag = &cli.StringFlag{
@@ -84,11 +96,50 @@ var controlFlag = &cli.StringFlag{
 	Usage: "Set the port you want to listen to for control port commands. If not specified, we will use the default port 8888.",
 }
 
+var controlSocketFlag = &cli.StringFlag{
+	Name:  "control-socket",
+	Usage: "Use the unix domain socket at the given path for the control commands instead of the control port.",
+}
+
 var metricsFlag = &cli.StringFlag{
 	Name:  "metrics",
 	Usage: "Launch a metrics server at the specified (host:)port.",
 }
 
+var maxBeaconLagFlag = &cli.Uint64Flag{
+	Name: "max-beacon-lag",
+	Usage: "Freeze the beacon generation when the last stored round is more than this number of " +
+		"rounds behind the current round. Once caught up, resume it with 'drand util unfreeze'. " +
+		"0 disables the check.",
+}
+
+var auditLogFlag = &cli.StringFlag{
+	Name: "audit-log",
+	Usage: "Path of a file the node appends a JSON line to for each call it serves to the protocol " +
+		"and control APIs, e.g. DKG packets, partial beacons and chain syncs, with the time, the remote " +
+		"address, the hash of the request and the status of the response. The public reads are not logged.",
+}
+
+var wellKnownDirFlag = &cli.StringFlag{
+	Name: "well-known-dir",
+	Usage: "Directory whose files are served under /.well-known/ on the public listener, e.g. a " +
+		"drand-chain file with the chain info for the clients to discover the chain. The files " +
+		"can be updated without restarting the node.",
+}
+
+var thresholdWaitFlag = &cli.DurationFlag{
+	Name: "threshold-wait",
+	Usage: "Extra time the leader of a setup waits for the nodes, after the wait timeout, when less " +
+		"nodes than the threshold signaled themselves",
+	Value: core.DefaultThresholdWait,
+}
+
+var pidFileFlag = &cli.StringFlag{
+	Name: "pid-file",
+	Usage: "Write the PID of the daemon to the given file once it is started, and remove it on exit. " +
+		"The daemon refuses to start if the file refers to a running process.",
+}
+
 var privListenFlag = &cli.StringFlag{
 	Name:  "private-listen",
 	Usage: "Set the listening (binding) address of the private API. Useful if you have some kind of proxy.",
@@ -104,12 +155,125 @@ var nodeFlag = &cli.StringFlag{
 	Usage: "Contact the nodes at the given list of whitespace-separated addresses which have to be present in group.toml.",
 }
 
+var storeBackendFlag = &cli.StringFlag{
+	Name:  "backend",
+	Usage: "Beacon store backend to benchmark: boltdb",
+	Value: "boltdb",
+}
+
+var benchCountFlag = &cli.IntFlag{
+	Name:  "count",
+	Usage: "Number of fake beacons written and read back",
+	Value: 10000,
+}
+
+var benchWorkersFlag = &cli.IntFlag{
+	Name:  "workers",
+	Usage: "Number of parallel writers and readers",
+	Value: 1,
+}
+
+var benchDirFlag = &cli.StringFlag{
+	Name:  "dir",
+	Usage: "Folder in which the temporary benchmark database is created, to benchmark a given disk",
+	Value: os.TempDir(),
+}
+
+var watchFlag = &cli.BoolFlag{
+	Name:  "watch",
+	Usage: "Print each new public randomness as it is generated, until interrupted",
+}
+
+var verifyRandFlag = &cli.BoolFlag{
+	Name: "verify",
+	Usage: "Verify the signature of the randomness and its link to the previous round against the chain " +
+		"info of the node, or the one given with --chain-info, before printing it, and fail without " +
+		"printing it if it does not verify",
+}
+
+var randFormatFlag = &cli.StringFlag{
+	Name:  "format",
+	Usage: "Output format of the randomness: text ([round N] <hex randomness>), json (with hex-encoded bytes), " +
+		"hex, base64 (URL-safe) or raw (the randomness bytes, for piping). A single round is printed in JSON " +
+		"unless the format is given. With --verbose, the text formats also print the signature and previous signature.",
+	Value: randFormatText,
+}
+
+var fromRoundFlag = &cli.Uint64Flag{
+	Name: "from",
+	Usage: "Print the randomness of the rounds from the given one, up to --to if given, " +
+		"or else streaming the new rounds until interrupted",
+}
+
+var toRoundFlag = &cli.Uint64Flag{
+	Name:  "to",
+	Usage: "Last round to print with --from",
+}
+
+var parallelFlag = &cli.IntFlag{
+	Name:  "parallel",
+	Usage: "Maximum number of rounds requested at the same time with --from and --to",
+	Value: 4,
+}
+
+var grpcTimeoutFlag = &cli.DurationFlag{
+	Name: "grpc-timeout",
+	Usage: "Maximum duration of each request for a round to a node. The streams of --watch and of --from " +
+		"without --to are not timed out.",
+	Value: 10 * time.Second,
+}
+
+var chainFromFlag = &cli.Uint64Flag{
+	Name:     "from",
+	Usage:    "First round of the chain to verify",
+	Required: true,
+}
+
+var chainToFlag = &cli.Uint64Flag{
+	Name:  "to",
+	Usage: "Last round of the chain to verify, the last round of the node by default",
+}
+
+var chainInfoFileFlag = &cli.StringFlag{
+	Name: "chain-info",
+	Usage: "Path of the chain info, in the JSON format of get chain-info, to verify the beacons " +
+		"against instead of the chain info of the node",
+}
+
+var allChainsFlag = &cli.BoolFlag{
+	Name: "all",
+	Usage: "Query all the given addresses instead of the first reachable one, and print the distinct " +
+		"chains they run as a JSON array",
+}
+
+var singleNodeFlag = &cli.BoolFlag{
+	Name: "single-node",
+	Usage: "Verify the beacon against the share of a node, given with --share-file, instead of the " +
+		"chain info. Only for a chain of threshold 1, such as a single node test setup",
+}
+
+var shareFileFlag = &cli.StringFlag{
+	Name:  "share-file",
+	Usage: "Path of the private share file of the node (dist_key.private)",
+}
+
 var roundFlag = &cli.IntFlag{
 	Name: "round",
 	Usage: "Request the public randomness generated at round num. If the drand beacon does not have the requested value," +
 		" it returns an error. If not specified, the current randomness is returned.",
 }
 
+var roundTimeFlag = &cli.StringFlag{
+	Name:  "time",
+	Usage: "Time, in RFC3339 format, for which to compute the round. If not specified, the current time is used.",
+}
+
+var peerAddrFlag = &cli.StringFlag{
+	Name:     "addr",
+	Usage:    "Address of the peer, as written in the group file",
+	Required: true,
+}
+
 var certsDirFlag = &cli.StringFlag{
 	Name:  "certs-dir",
 	Usage: "directory containing trusted certificates (PEM format). Useful for testing and self signed certificates",
@@ -136,9 +300,21 @@ var thresholdFlag = &cli.IntFlag{
 	Usage: "threshold to use for the DKG",
 }
 
+var nodesDirFlag = &cli.StringFlag{
+	Name:  "nodes-dir",
+	Usage: "Directory containing the identity files of the nodes, in the TOML or JSON format, one per node",
+}
+
 var shareNodeFlag = &cli.IntFlag{
-	Name:  "nodes",
-	Usage: "number of nodes expected",
+	Name:    "nodes",
+	Aliases: []string{"node-count"},
+	Usage:   "number of nodes expected. The leader waits for exactly that many nodes before starting the DKG",
+}
+
+var waitTimeoutFlag = &cli.DurationFlag{
+	Name: "wait-timeout",
+	Usage: "Time the leader waits for the expected number of nodes to connect before aborting the setup. " +
+		"Default is " + core.MaxWaitPrepareDKG.String(),
 }
 
 var transitionFlag = &cli.BoolFlag{
@@ -148,8 +324,9 @@ var transitionFlag = &cli.BoolFlag{
 }
 
 var forceFlag = &cli.BoolFlag{
-	Name:  "force, f",
-	Usage: "When set, this flag forces the daemon to start a new reshare operation." + "By default, it does not allow to restart one",
+	Name: "force, f",
+	Usage: "When set, this flag forces the daemon to start a new setup or reshare operation even if one is already " +
+		"in progress, aborting the latter. By default, it does not allow to restart one",
 }
 
 // secret flag is the "manual" security when the "leader"/coordinator creates the
@@ -169,8 +346,24 @@ var connectFlag = &cli.StringFlag{
 }
 
 var leaderFlag = &cli.BoolFlag{
-	Name:  "leader",
-	Usage: "Specify if this node should act as the leader for setting up the group",
+	Name: "leader",
+	Usage: "Act as the leader of the setup: wait for --nodes participants to connect, " +
+		"create the group and start the DKG. Mutually exclusive with --follower.",
+}
+
+var followerFlag = &cli.BoolFlag{
+	Name: "follower",
+	Usage: "Act as a follower of the setup: send the key of this node to the leader " +
+		"given with --connect and run the DKG on the group it creates. Mutually exclusive with --leader.",
+}
+
+var observerFlag = &cli.BoolFlag{
+	Name: "observer",
+	Usage: "Observe the setup of the leader given with --connect without taking part in the DKG: " +
+		"the node is listed in the group as an observer, logs the DKG packets to " +
+		"dkg_transcript.log in its folder, saves the group to observed_group.toml and stores the beacons of " +
+		"the chain, but gets no share. The node follows the chain again when it restarts. " +
+		"Mutually exclusive with --leader and --follower.",
 }
 
 var beaconOffset = &cli.IntFlag{
@@ -179,6 +372,13 @@ var beaconOffset = &cli.IntFlag{
 		" group is ready to run the share protocol",
 }
 
+var genesisTimeFlag = &cli.StringFlag{
+	Name: "genesis-time",
+	Usage: "Leader uses this flag to set the genesis time of a new chain in the RFC3339 format, " +
+		"e.g. 2020-09-13T12:30:00Z, instead of deriving it from --beacon-delay. It must be at " +
+		"least one period in the future.",
+}
+
 var oldGroupFlag = &cli.StringFlag{
 	Name: "from",
 	Usage: "Old group.toml path to specify when a new node wishes to participate " +
@@ -186,6 +386,50 @@ var oldGroupFlag = &cli.StringFlag{
 		"included in the current DKG.",
 }
 
+var keyFileFlag = &cli.StringFlag{
+	Name: "key-file",
+	Usage: "Path of a file holding the private key of the node, in the format of the private key " +
+		"of the config folder, to use instead of the latter. The identity of the node keeps its " +
+		"address. The daemon remembers the key file given to the share command across restarts, " +
+		"the one given to start takes precedence.",
+}
+
+var abortFlag = &cli.BoolFlag{
+	Name: "abort",
+	Usage: "Abandons the DKG or resharing in progress on the node, keeping its key " +
+		"pair and the group of a previous DKG. A new one can be run afterwards.",
+}
+
+var joinExistingFlag = &cli.BoolFlag{
+	Name: "join-existing",
+	Usage: "Used by a new node to join an existing chain: the node announces itself " +
+		"to the leader given by --connect and waits to be included in the next " +
+		"resharing. Requires the current group file with --from or --from-group-hash.",
+}
+
+var fromGroupHashFlag = &cli.StringFlag{
+	Name: "from-group-hash",
+	Usage: "Hex-encoded hash of the chain a new node wishes to participate in, to " +
+		"specify instead of --from: the node fetches the chain info and the group " +
+		"from the leader given by --connect and checks both match the hash. The hash " +
+		"does not cover the list of nodes of the group, so the leader must be " +
+		"reached over TLS with a verified certificate.",
+}
+
+var groupSignatureFlag = &cli.StringFlag{
+	Name: "group-signature",
+	Usage: "Hex-encoded signature of the SHA256 of the group file given with --from, " +
+		"made by the leader with its long-term key, as printed by util sign-group. The " +
+		"group file is rejected if the signature does not verify with the key of the leader.",
+}
+
+var leaderKeyFlag = &cli.StringFlag{
+	Name: "leader-key",
+	Usage: "Public key file of the leader, such as its drand_id.public, used to verify " +
+		"--group-signature. By default, the key of the node given by --connect in the " +
+		"group of the running daemon is used.",
+}
+
 var skipValidationFlag = &cli.BoolFlag{
 	Name:  "skipValidation",
 	Usage: "skips bls verification of beacon rounds for faster catchup.",
@@ -196,6 +440,26 @@ var timeoutFlag = &cli.StringFlag{
 	Usage: fmt.Sprintf("Timeout to use during the DKG, in string format. Default is %s", core.DefaultDKGTimeout),
 }
 
+var timeoutPhaseFlag = &cli.DurationFlag{
+	Name: "timeout-phase",
+	Usage: fmt.Sprintf("Duration of each phase of the DKG (deal, response and justification). Each phase ends "+
+		"at its own deadline, and the phase that timed out is logged. Same as --%s. Default is %s",
+		timeoutFlag.Name, core.DefaultDKGTimeout),
+}
+
+var retryOnFailureFlag = &cli.IntFlag{
+	Name: "retry-on-failure",
+	Usage: "Number of times the node sends again a DKG packet that another node failed to receive, " +
+		"100ms apart, before giving up on that node, or on the end of its DKG. At most 50.",
+}
+
+var stopTimeoutFlag = &cli.DurationFlag{
+	Name: "timeout",
+	Usage: "Time the daemon waits for in-flight requests to finish when stopping. " +
+		"Connections still open after that time are closed, aborting their requests.",
+	Value: 30 * time.Second,
+}
+
 var pushFlag = &cli.BoolFlag{
 	Name: "push",
 	Usage: "Push mode forces the daemon to start making beacon requests to the other node, " +
@@ -228,6 +492,12 @@ var hashOnly = &cli.BoolFlag{
 	Usage: "Only print the hash of the group file",
 }
 
+var verifyFlag = &cli.BoolFlag{
+	Name: "verify",
+	Usage: "Fail unless the chain info carries the hash of the chain and this hash " +
+		"matches the rest of the chain info",
+}
+
 var hashInfoFlag = &cli.StringFlag{
 	Name:     "chain-hash",
 	Usage:    "The hash of the chain info",
@@ -242,6 +512,97 @@ var syncNodeFlag = &cli.StringFlag{
 	Required: true,
 }
 
+var beaconIDFlag = &cli.StringFlag{
+	Name:  "beacon-id",
+	Usage: "Id of the beacon, as shown by list-beacons. If set, the command fails if the daemon does not run that beacon",
+}
+
+var beaconIDPrefixFlag = &cli.StringFlag{
+	Name: "beacon-id-prefix",
+	Usage: "Alphanumeric prefix, of at most 32 characters, prepended with a dash to the ids of the " +
+		"beacons of the daemon, to keep apart the beacons of the organizations sharing a deployment",
+}
+
+var stripPrefixFlag = &cli.BoolFlag{
+	Name:  "strip-prefix",
+	Usage: "Show the ids of the beacons without the prefix set with --beacon-id-prefix",
+}
+
+var groupFormatFlag = &cli.StringFlag{
+	Name:  "format",
+	Usage: "Format of the exported group: json (with hex-encoded keys), toml or proto (raw protobuf bytes)",
+	Value: groupFormatJSON,
+}
+
+var prettyFlag = &cli.BoolFlag{
+	Name:  "pretty",
+	Usage: "Indent the JSON output",
+}
+
+var jsonFlag = &cli.BoolFlag{
+	Name:  "json",
+	Usage: "Print the output in JSON format",
+}
+
+var verifyIdentityFlag = &cli.StringFlag{
+	Name: "verify-identity",
+	Usage: "Path to the public identity file of the node (drand_id.public). " +
+		"Checks the node answering on that address proves it holds the corresponding private key.",
+}
+
+var certHostFlag = &cli.StringFlag{
+	Name:  "host",
+	Usage: "Host name or IP address the certificate is valid for",
+}
+
+var outCertFlag = &cli.StringFlag{
+	Name:  "out-cert",
+	Usage: "Path where to write the PEM encoded certificate",
+	Value: "server.crt",
+}
+
+var outKeyFlag = &cli.StringFlag{
+	Name:  "out-key",
+	Usage: "Path where to write the PEM encoded private key",
+	Value: "server.key",
+}
+
+var diffOldFlag = &cli.StringFlag{
+	Name:  "old",
+	Usage: "Path of the current group file",
+}
+
+var diffNewFlag = &cli.StringFlag{
+	Name:  "new",
+	Usage: "Path of the group file to compare to the current one, e.g. the group of a resharing",
+}
+
+var groupFileFlag = &cli.StringFlag{
+	Name:  "group-file",
+	Usage: "Path of a group file, in the TOML format or in the JSON format of export-group",
+}
+
+var newCertFlag = &cli.StringFlag{
+	Name:  "new-cert",
+	Usage: "Path of the new PEM encoded TLS certificate of the node",
+}
+
+var newKeyFlag = &cli.StringFlag{
+	Name:  "new-key",
+	Usage: "Path of the private key of the new TLS certificate",
+}
+
+var backupExistingFlag = &cli.BoolFlag{
+	Name:  "backup-existing",
+	Usage: "Copy the current certificate and key of the daemon next to them, with a .bak suffix, before replacing them",
+}
+
+var validForFlag = &cli.DurationFlag{
+	Name:  "valid-for",
+	Usage: "Duration for which the certificate is valid",
+	Value: 365 * 24 * time.Hour,
+}
+
 var upToFlag = &cli.IntFlag{
 	Name:  "up-to",
 	Usage: "Specify a round to which the drand daemon will stop following the chain",
@@ -250,11 +611,15 @@ var upToFlag = &cli.IntFlag{
 
 var appCommands = []*cli.Command{
 	{
-		Name:  "start",
-		Usage: "Start the drand daemon.",
+		Name: "start",
+		Usage: "Start the drand daemon. The settings of the optional file " + settingsFileName + " in the config folder, " +
+			"such as 'verbose = true' or 'control_stop_timeout = \"10s\"', override the flags. " +
+			"They are read again when the daemon receives SIGHUP.",
 		Flags: toArray(folderFlag, tlsCertFlag, tlsKeyFlag,
-			insecureFlag, controlFlag, privListenFlag, pubListenFlag, metricsFlag,
-			certsDirFlag, pushFlag, verboseFlag, enablePrivateRand, oldGroupFlag, skipValidationFlag),
+			insecureFlag, controlFlag, controlSocketFlag, privListenFlag, pubListenFlag, metricsFlag,
+			certsDirFlag, pushFlag, verboseFlag, enablePrivateRand, oldGroupFlag, skipValidationFlag,
+			pidFileFlag, maxBeaconLagFlag, thresholdWaitFlag, auditLogFlag, keyFileFlag,
+			wellKnownDirFlag, beaconIDPrefixFlag),
 		Action: func(c *cli.Context) error {
 			banner()
 			return startCmd(c)
@@ -263,19 +628,37 @@ var appCommands = []*cli.Command{
 	{
 		Name:  "stop",
 		Usage: "Stop the drand daemon.\n",
-		Flags: toArray(controlFlag),
+		Flags: toArray(controlFlag, controlSocketFlag, stopTimeoutFlag),
 		Action: func(c *cli.Context) error {
 			banner()
 			return stopDaemon(c)
 		},
 	},
 	{
-		Name:  "share",
-		Usage: "Launch a sharing protocol.",
-		Flags: toArray(insecureFlag, controlFlag, oldGroupFlag,
-			timeoutFlag, sourceFlag, userEntropyOnlyFlag, secretFlag,
-			periodFlag, shareNodeFlag, thresholdFlag, connectFlag, outFlag,
-			leaderFlag, beaconOffset, transitionFlag, forceFlag, catchupPeriodFlag),
+		Name:   "status",
+		Usage:  "Get the status of the beacon of the daemon, such as its catchup progress.\n",
+		Flags:  toArray(controlFlag, controlSocketFlag),
+		Action: statusCmd,
+	},
+	{
+		Name:   "list-beacons",
+		Usage:  "List the randomness beacons run by the daemon.\n",
+		Flags:  toArray(controlFlag, controlSocketFlag, jsonFlag, stripPrefixFlag),
+		Action: listBeaconsCmd,
+	},
+	{
+		Name: "share",
+		Usage: "Launch a sharing protocol. Each node takes one of two roles: " +
+			"one node runs it with --leader and all the others with --follower " +
+			"--connect <leader address>. Nodes auditing a fresh DKG run it with --observer " +
+			"--connect <leader address>, before the last follower. A setup in progress " +
+			"is abandoned with --abort.",
+		Flags: toArray(folderFlag, insecureFlag, controlFlag, controlSocketFlag, oldGroupFlag,
+			timeoutFlag, timeoutPhaseFlag, retryOnFailureFlag, sourceFlag, userEntropyOnlyFlag, secretFlag,
+			periodFlag, shareNodeFlag, waitTimeoutFlag, thresholdFlag, connectFlag, outFlag,
+			leaderFlag, followerFlag, observerFlag, beaconOffset, genesisTimeFlag, transitionFlag, forceFlag,
+			catchupPeriodFlag, joinExistingFlag, fromGroupHashFlag, abortFlag, keyFileFlag,
+			groupSignatureFlag, leaderKeyFlag),
 		Action: func(c *cli.Context) error {
 			banner()
 			return shareCmd(c)
@@ -284,7 +667,7 @@ var appCommands = []*cli.Command{
 	{
 		Name:  "follow",
 		Usage: "follow and store a randomness chain",
-		Flags: toArray(folderFlag, controlFlag, hashInfoFlag, syncNodeFlag,
+		Flags: toArray(folderFlag, controlFlag, controlSocketFlag, hashInfoFlag, syncNodeFlag,
 			tlsCertFlag, insecureFlag, upToFlag),
 		Action: followCmd,
 	},
@@ -328,18 +711,28 @@ var appCommands = []*cli.Command{
 					"beacon via TLS and falls back to plaintext communication " +
 					"if the contacted node has not activated TLS in which case " +
 					"it prints a warning.\n",
-				Flags:  toArray(tlsCertFlag, insecureFlag, roundFlag, nodeFlag),
+				Flags: toArray(tlsCertFlag, insecureFlag, roundFlag, nodeFlag, watchFlag, randFormatFlag, verboseFlag,
+					fromRoundFlag, toRoundFlag, parallelFlag, grpcTimeoutFlag, verifyRandFlag, chainInfoFileFlag),
 				Action: getPublicRandomness,
 			},
 			{
 				Name:      "chain-info",
 				Usage:     "Get the binding chain information that this nodes participates to",
 				ArgsUsage: "`ADDRESS1` `ADDRESS2` ... provides the addresses of the node to try to contact to.",
-				Flags:     toArray(tlsCertFlag, insecureFlag, hashOnly),
+				Flags:     toArray(tlsCertFlag, insecureFlag, hashOnly, verifyFlag, allChainsFlag),
 				Action:    getChainInfo,
 			},
 		},
 	},
+	{
+		Name: "verify-chain",
+		Usage: "Fetches the rounds --from to --to, or to its last round, from a drand node and checks they " +
+			"form a valid chain: each beacon is signed by the distributed key and builds on the signature " +
+			"of the previous round. Prints the broken links and fails if there is any.",
+		ArgsUsage: "`ADDRESS` of the node to fetch the beacons from.",
+		Flags:     toArray(tlsCertFlag, insecureFlag, chainFromFlag, chainToFlag, chainInfoFileFlag, grpcTimeoutFlag),
+		Action:    verifyChainCmd,
+	},
 	{
 		Name:  "util",
 		Usage: "Multiple commands of utility functions, such as reseting a state, checking the connection of a peer...",
@@ -356,13 +749,13 @@ var appCommands = []*cli.Command{
 			{
 				Name:   "ping",
 				Usage:  "pings the daemon checking its state\n",
-				Flags:  toArray(controlFlag),
+				Flags:  toArray(controlFlag, controlSocketFlag, verifyIdentityFlag, certsDirFlag),
 				Action: pingpongCmd,
 			},
 			{
 				Name:   "reset",
 				Usage:  "Resets the local distributed information (share, group file and random beacons). It KEEPS the private/public key pair.",
-				Flags:  toArray(folderFlag, controlFlag),
+				Flags:  toArray(folderFlag, controlFlag, controlSocketFlag),
 				Action: resetCmd,
 			},
 			{
@@ -372,18 +765,171 @@ var appCommands = []*cli.Command{
 				Flags:  toArray(folderFlag),
 				Action: deleteBeaconCmd,
 			},
+			{
+				Name: "vacuum",
+				Usage: "Rewrites the beacon database to reclaim the disk space left unused by deleted beacons, " +
+					"and prints its size before and after. The daemon MUST be stopped.",
+				Flags:  toArray(folderFlag),
+				Action: vacuumCmd,
+			},
+			{
+				Name: "restore-checkpoint",
+				Usage: "Restores the group, the share and the DKG state of the node from the given `CHECKPOINT`, " +
+					"a copy of its config folder, which is left untouched. The beacons already stored are kept. " +
+					"The daemon MUST be stopped.",
+				Flags:  toArray(folderFlag),
+				Action: restoreCheckpointCmd,
+			},
 			{
 				Name:   "self-sign",
 				Usage:  "Signs the public identity of this node. Needed for backward compatibility with previous versions.",
 				Flags:  toArray(folderFlag),
 				Action: selfSign,
 			},
+			{
+				Name: "sign-group",
+				Usage: "Prints the hex encoded signature of a group file by the key of the running " +
+					"daemon, for the followers to check with share --group-signature that the file " +
+					"they were given comes from the leader.",
+				ArgsUsage: "<group.toml>",
+				Flags:     toArray(controlFlag, controlSocketFlag),
+				Action:    signGroupCmd,
+			},
+			{
+				Name: "show",
+				Usage: "Shows the parameters of the beacon run by the daemon: chain information, " +
+					"threshold, index and key fingerprint of the node, current and last stored rounds.",
+				Flags:  toArray(controlFlag, controlSocketFlag, beaconIDFlag, jsonFlag),
+				Action: showBeaconCmd,
+			},
+			{
+				Name: "unfreeze",
+				Usage: "Resumes the beacon generation of a frozen daemon, e.g. one that froze because it " +
+					"lagged more than --max-beacon-lag rounds behind the chain.",
+				Flags:  toArray(controlFlag, controlSocketFlag),
+				Action: unfreezeCmd,
+			},
+			{
+				Name: "round-at",
+				Usage: "Prints the round of the chain run by the daemon that is active at the given time, " +
+					"or 0 if that time is before the genesis time.",
+				Flags:  toArray(controlFlag, controlSocketFlag, roundTimeFlag),
+				Action: roundAtCmd,
+			},
+			{
+				Name: "verify",
+				Usage: "Verifies the signature of a beacon, in the JSON format of get public --format json, " +
+					"against the chain info given with --chain-info, or with --single-node, against the share " +
+					"of the node given with --share-file.",
+				ArgsUsage: "<beacon.json>",
+				Flags:     toArray(chainInfoFileFlag, singleNodeFlag, shareFileFlag),
+				Action:    verifyBeaconCmd,
+			},
+			{
+				Name: "verify-group",
+				Usage: "Reconstructs the distributed public key from the given shares and checks " +
+					"it matches the one of the group.",
+				ArgsUsage: "<group.toml> <share1.toml> <share2.toml> ... at least a threshold of shares " +
+					"(dist_key.private files) from the group members is needed.",
+				Action: verifyGroupCmd,
+			},
+			{
+				Name: "diff-group",
+				Usage: "Prints the nodes added, removed and kept between two group files, and whether " +
+					"the threshold or the period changed.",
+				Flags:  toArray(diffOldFlag, diffNewFlag),
+				Action: diffGroupCmd,
+			},
+			{
+				Name: "list-nodes",
+				Usage: "Prints the nodes of a group file with their index, address, key fingerprint and " +
+					"whether they use TLS, followed by the threshold, period, genesis time and hashes of the group.",
+				Flags:  toArray(groupFileFlag),
+				Action: listNodesCmd,
+			},
+			{
+				Name: "pack-group",
+				Usage: "Builds a group file from the identity files (*.toml or *.json) of the nodes " +
+					"found in a directory, and prints the hash of the group.",
+				Flags:  toArray(nodesDirFlag, thresholdFlag, periodFlag, catchupPeriodFlag, outFlag),
+				Action: packGroupCmd,
+			},
+			{
+				Name: "add-key",
+				Usage: "Allows the given hex encoded public key to sign the control commands of the daemon, " +
+					"which then rejects the unsigned ones. The daemon MUST be restarted.",
+				ArgsUsage: "<public key>",
+				Flags:     toArray(folderFlag),
+				Action:    addKeyCmd,
+			},
+			{
+				Name: "remove-key",
+				Usage: "Removes a public key allowed by add-key. The daemon accepts unsigned control " +
+					"commands again once no key is left. The daemon MUST be restarted.",
+				ArgsUsage: "<public key>",
+				Flags:     toArray(folderFlag),
+				Action:    removeKeyCmd,
+			},
+			{
+				Name:   "export-group",
+				Usage:  "Exports the group of the beacon run by the daemon in the given format.",
+				Flags:  toArray(controlFlag, controlSocketFlag, beaconIDFlag, groupFormatFlag, prettyFlag, outFlag),
+				Action: exportGroupCmd,
+			},
+			{
+				Name: "benchmark-store",
+				Usage: "Writes fake beacons to a temporary beacon store, reads them back and prints " +
+					"the latency percentiles, the throughput and the size of the database.",
+				Flags:  toArray(storeBackendFlag, benchCountFlag, benchWorkersFlag, benchDirFlag),
+				Action: benchmarkStoreCmd,
+			},
+			{
+				Name: "rotate-tls",
+				Usage: "Validates the given TLS certificate and key and installs them in place of the ones " +
+					"of the running daemon, which serves them without restarting. If the daemon is not " +
+					"running, the files are only validated.",
+				Flags:  toArray(controlFlag, controlSocketFlag, newCertFlag, newKeyFlag, backupExistingFlag),
+				Action: rotateTLSCmd,
+			},
 			{
 				Name:   "backup",
 				Usage:  "backs up the primary drand database to a secondary location.",
-				Flags:  toArray(outFlag, controlFlag),
+				Flags:  toArray(outFlag, controlFlag, controlSocketFlag),
 				Action: backupDBCmd,
 			},
+			{
+				Name:  "peer",
+				Usage: "Commands to manage how the daemon treats the other nodes of its group.",
+				Subcommands: []*cli.Command{
+					{
+						Name: "exclude",
+						Usage: "Skips the partial beacons of a node of the group when the daemon aggregates " +
+							"them, without changing the group. The excluded nodes are kept in peers.json " +
+							"in the config folder.",
+						Flags:  toArray(controlFlag, controlSocketFlag, peerAddrFlag),
+						Action: excludePeerCmd,
+					},
+					{
+						Name:   "include",
+						Usage:  "Uses again the partial beacons of a node excluded with exclude.",
+						Flags:  toArray(controlFlag, controlSocketFlag, peerAddrFlag),
+						Action: includePeerCmd,
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:  "tls",
+		Usage: "Commands to help setting up TLS for a drand node.",
+		Subcommands: []*cli.Command{
+			{
+				Name: "generate-cert",
+				Usage: "Generates a self-signed certificate and its private key for the given host, " +
+					"and prints the SHA-256 fingerprint of the certificate.",
+				Flags:  toArray(certHostFlag, outCertFlag, outKeyFlag, validForFlag),
+				Action: generateCertCmd,
+			},
 		},
 	},
 	{
@@ -394,12 +940,12 @@ var appCommands = []*cli.Command{
 			"long-term private key (drand.private), the long-term public key " +
 			"(drand.public), or the private key share (drand.share), " +
 			"respectively.\n",
-		Flags: toArray(folderFlag, controlFlag),
+		Flags: toArray(folderFlag, controlFlag, controlSocketFlag),
 		Subcommands: []*cli.Command{
 			{
 				Name:   "share",
 				Usage:  "shows the private share\n",
-				Flags:  toArray(controlFlag),
+				Flags:  toArray(controlFlag, controlSocketFlag),
 				Action: showShareCmd,
 			},
 			{
@@ -407,25 +953,25 @@ var appCommands = []*cli.Command{
 				Usage: "shows the current group.toml used. The group.toml " +
 					"may contain the distributed public key if the DKG has been " +
 					"ran already.\n",
-				Flags:  toArray(outFlag, controlFlag, hashOnly),
+				Flags:  toArray(outFlag, controlFlag, controlSocketFlag, hashOnly),
 				Action: showGroupCmd,
 			},
 			{
 				Name:   "chain-info",
 				Usage:  "shows the chain information this node is participating to",
-				Flags:  toArray(controlFlag, hashOnly),
+				Flags:  toArray(controlFlag, controlSocketFlag, hashOnly),
 				Action: showChainInfo,
 			},
 			{
 				Name:   "private",
 				Usage:  "shows the long-term private key of a node.\n",
-				Flags:  toArray(controlFlag),
+				Flags:  toArray(controlFlag, controlSocketFlag),
 				Action: showPrivateCmd,
 			},
 			{
 				Name:   "public",
 				Usage:  "shows the long-term public key of a node.\n",
-				Flags:  toArray(controlFlag),
+				Flags:  toArray(controlFlag, controlSocketFlag),
 				Action: showPublicCmd,
 			},
 		},
@@ -448,7 +994,7 @@ func CLI() *cli.App {
 	app.Usage = "distributed randomness service"
 	// =====Commands=====
 	app.Commands = appCommands
-	app.Flags = toArray(verboseFlag, folderFlag)
+	app.Flags = toArray(verboseFlag, folderFlag, operatorFolderFlag)
 	app.Before = testWindows
 	return app
 }
@@ -467,7 +1013,12 @@ func resetCmd(c *cli.Context) error {
 		fmt.Fprintf(output, "drand: not reseting the state.")
 		return nil
 	}
-	store := key.NewFileStore(conf.ConfigFolder())
+	store, err := key.NewFileStore(conf.ConfigFolder())
+	if err != nil {
+		fmt.Fprintf(output, "drand: err opening key store: %v\n", err)
+		os.Exit(1)
+	}
+	defer store.Close()
 	if err := store.Reset(); err != nil {
 		fmt.Fprintf(output, "drand: err reseting key store: %v\n", err)
 		os.Exit(1)
@@ -527,7 +1078,11 @@ func keygenCmd(c *cli.Context) error {
 	}
 
 	config := contextToConfig(c)
-	fileStore := key.NewFileStore(config.ConfigFolder())
+	fileStore, err := key.NewFileStore(config.ConfigFolder())
+	if err != nil {
+		return fmt.Errorf("could not open key store: %w", err)
+	}
+	defer fileStore.Close()
 
 	if _, err := fileStore.LoadKeyPair(); err == nil {
 		fmt.Fprintf(output, "Keypair already present in `%s`.\nRemove them before generating new one\n", config.ConfigFolder())
@@ -652,6 +1207,31 @@ func checkIdentityAddress(conf *core.Config, addr string, tls bool) error {
 	return nil
 }
 
+// maxHomeSkew is the maximum difference tolerated between the local time and
+// the time at which a node signed its home response
+const maxHomeSkew = 1 * time.Minute
+
+// verifyHomeIdentity checks the node reachable at the address of the given
+// identity signs its home response with the identity's key.
+func verifyHomeIdentity(conf *core.Config, id *key.Identity) error {
+	client := net.NewGrpcClientFromCertManager(conf.Certs())
+	resp, err := client.Home(context.Background(), id, &drand.HomeRequest{})
+	if err != nil {
+		return err
+	}
+	if resp.GetAddress() != id.Address() {
+		return fmt.Errorf("mismatch of address: contact %s reply with %s", id.Address(), resp.GetAddress())
+	}
+	skew := time.Since(time.Unix(resp.GetTimestamp(), 0))
+	if skew > maxHomeSkew || skew < -maxHomeSkew {
+		return fmt.Errorf("home response timestamp is %s off the local time", skew)
+	}
+	if err := id.VerifyHome(resp.GetTimestamp(), resp.GetSignature()); err != nil {
+		return fmt.Errorf("invalid home signature: %w", err)
+	}
+	return nil
+}
+
 // deleteBeaconCmd deletes all beacon in the database from the given round until
 // the head of the chain
 func deleteBeaconCmd(c *cli.Context) error {
@@ -689,6 +1269,236 @@ func deleteBeaconCmd(c *cli.Context) error {
 	return nil
 }
 
+// vacuumCmd reclaims the disk space left unused in the beacon database
+func vacuumCmd(c *cli.Context) error {
+	conf := contextToConfig(c)
+	dbPath := path.Join(conf.DBFolder(), boltdb.BoltFileName)
+	before, err := os.Stat(dbPath)
+	if err != nil {
+		return fmt.Errorf("can't open the database: %s", err)
+	}
+	store, err := boltdb.NewBoltStore(conf.DBFolder(), conf.BoltOptions())
+	if err != nil {
+		return fmt.Errorf("invalid bolt store creation: %s", err)
+	}
+	defer store.Close()
+	if err := store.Vacuum(); err != nil {
+		return fmt.Errorf("can't vacuum the database: %s", err)
+	}
+	after, err := os.Stat(dbPath)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(output, "database size: %d bytes before, %d bytes after\n", before.Size(), after.Size())
+	return nil
+}
+
+func restoreCheckpointCmd(c *cli.Context) error {
+	if !c.Args().Present() {
+		return errors.New("restore-checkpoint requires the checkpoint folder")
+	}
+	dir := c.Args().First()
+	conf := contextToConfig(c)
+	fs, err := key.NewFileStore(conf.ConfigFolder())
+	if err != nil {
+		return fmt.Errorf("can't open key store: %w", err)
+	}
+	defer fs.Close()
+	if err := core.RestoreCheckpoint(fs, dir); err != nil {
+		return err
+	}
+	fmt.Fprintln(output, "drand: restored the group and share from", dir)
+	return nil
+}
+
+func verifyGroupCmd(c *cli.Context) error {
+	if c.NArg() < 2 {
+		return errors.New("verify-group requires a group file and at least one share file")
+	}
+	group, err := getGroup(c)
+	if err != nil {
+		return err
+	}
+	shares := make([]*key.Share, 0, c.NArg()-1)
+	for _, path := range c.Args().Tail() {
+		s := new(key.Share)
+		if err := key.Load(path, s); err != nil {
+			return fmt.Errorf("drand: error loading share file %s: %w", path, err)
+		}
+		shares = append(shares, s)
+	}
+	if err := group.VerifyPublicKey(shares); err != nil {
+		return err
+	}
+	fmt.Fprintf(output, "group public key verified: %s\n", key.PointToString(group.PublicKey.Key()))
+	return nil
+}
+
+func diffGroupCmd(c *cli.Context) error {
+	if !c.IsSet(diffOldFlag.Name) || !c.IsSet(diffNewFlag.Name) {
+		return fmt.Errorf("diff-group requires the --%s and --%s group files", diffOldFlag.Name, diffNewFlag.Name)
+	}
+	oldGroup, newGroup := new(key.Group), new(key.Group)
+	if err := key.Load(c.String(diffOldFlag.Name), oldGroup); err != nil {
+		return fmt.Errorf("drand: error loading group file: %w", err)
+	}
+	if err := key.Load(c.String(diffNewFlag.Name), newGroup); err != nil {
+		return fmt.Errorf("drand: error loading group file: %w", err)
+	}
+	diff := oldGroup.Diff(newGroup)
+	for _, n := range diff.Added {
+		fmt.Fprintf(output, "+ %s (index %d)\n", n.Address(), n.Index)
+	}
+	for _, n := range diff.Removed {
+		fmt.Fprintf(output, "- %s (index %d)\n", n.Address(), n.Index)
+	}
+	for _, n := range diff.Unchanged {
+		fmt.Fprintf(output, "= %s (index %d)\n", n.Address(), n.Index)
+	}
+	if diff.ThresholdChanged {
+		fmt.Fprintf(output, "threshold: %d -> %d\n", oldGroup.Threshold, newGroup.Threshold)
+	}
+	if diff.PeriodChanged {
+		fmt.Fprintf(output, "period: %s -> %s\n", oldGroup.Period, newGroup.Period)
+	}
+	return nil
+}
+
+func listNodesCmd(c *cli.Context) error {
+	if !c.IsSet(groupFileFlag.Name) {
+		return fmt.Errorf("list-nodes requires the --%s flag", groupFileFlag.Name)
+	}
+	group, err := loadGroupFile(c.String(groupFileFlag.Name))
+	if err != nil {
+		return fmt.Errorf("drand: error loading group file: %w", err)
+	}
+	fmt.Fprintln(output, "index\taddress\ttls\tfingerprint")
+	for _, n := range group.Nodes {
+		fmt.Fprintf(output, "%d\t%s\t%v\t%s\n", n.Index, n.Address(), n.TLS, n.Fingerprint())
+	}
+	fmt.Fprintf(output, "threshold: %d\n", group.Threshold)
+	fmt.Fprintf(output, "period: %s\n", group.Period)
+	fmt.Fprintf(output, "genesis time: %s (%d)\n", time.Unix(group.GenesisTime, 0).UTC().Format(time.RFC3339), group.GenesisTime)
+	fmt.Fprintf(output, "group hash: %x\n", group.Hash())
+	// the chain hash needs the distributed key, which a group only has after the DKG
+	if group.PublicKey != nil {
+		fmt.Fprintf(output, "chain hash: %x\n", chain.NewChainInfo(group).Hash())
+	}
+	return nil
+}
+
+func packGroupCmd(c *cli.Context) error {
+	for _, f := range []cli.Flag{nodesDirFlag, thresholdFlag, periodFlag} {
+		if !c.IsSet(f.Names()[0]) {
+			return fmt.Errorf("pack-group requires the --%s flag", f.Names()[0])
+		}
+	}
+	ids, err := loadIdentities(c.String(nodesDirFlag.Name))
+	if err != nil {
+		return err
+	}
+	threshold := c.Int(thresholdFlag.Name)
+	if threshold < key.MinimumT(len(ids)) || threshold > len(ids) {
+		return fmt.Errorf("drand: threshold %d out of range [%d, %d] for %d nodes",
+			threshold, key.MinimumT(len(ids)), len(ids), len(ids))
+	}
+	period, err := time.ParseDuration(c.String(periodFlag.Name))
+	if err != nil {
+		return fmt.Errorf("drand: invalid period: %w", err)
+	}
+	if err := key.ValidatePeriod(period); err != nil {
+		return err
+	}
+	catchupPeriod, err := time.ParseDuration(c.String(catchupPeriodFlag.Name))
+	if err != nil {
+		return fmt.Errorf("drand: invalid catchup period: %w", err)
+	}
+	// the genesis time is set by the leader of the DKG
+	group := key.NewGroup(ids, threshold, 0, period, catchupPeriod)
+	if c.IsSet(outFlag.Name) {
+		if err := key.Save(c.String(outFlag.Name), group, false); err != nil {
+			return fmt.Errorf("drand: can't write group file: %w", err)
+		}
+	} else if err := toml.NewEncoder(output).Encode(group.TOML()); err != nil {
+		return err
+	}
+	fmt.Fprintf(output, "group hash: %x\n", group.Hash())
+	return nil
+}
+
+// loadIdentities loads the identity files, in the TOML format or in the JSON
+// format of the Identity message, found in dir. The addresses must be valid
+// and unique, and the signatures valid when present.
+func loadIdentities(dir string) ([]*key.Identity, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("drand: can't read nodes directory: %w", err)
+	}
+	var ids []*key.Identity
+	addrs := make(map[string]string)
+	for _, f := range files {
+		ext := filepath.Ext(f.Name())
+		if f.IsDir() || (ext != ".toml" && ext != ".json") {
+			continue
+		}
+		filePath := path.Join(dir, f.Name())
+		id := new(key.Identity)
+		if ext == ".json" {
+			buff, err := ioutil.ReadFile(filePath)
+			if err != nil {
+				return nil, err
+			}
+			packet := new(drand.Identity)
+			if err := json.Unmarshal(buff, packet); err != nil {
+				return nil, fmt.Errorf("drand: can't decode %s: %w", f.Name(), err)
+			}
+			if id, err = key.IdentityFromProto(packet); err != nil {
+				return nil, fmt.Errorf("drand: invalid identity %s: %w", f.Name(), err)
+			}
+		} else if err := key.Load(filePath, id); err != nil {
+			return nil, fmt.Errorf("drand: invalid identity %s: %w", f.Name(), err)
+		}
+		if _, _, err := gonet.SplitHostPort(id.Address()); err != nil {
+			return nil, fmt.Errorf("drand: invalid address in %s: %w", f.Name(), err)
+		}
+		if other, ok := addrs[id.Address()]; ok {
+			return nil, fmt.Errorf("drand: address %s in both %s and %s", id.Address(), other, f.Name())
+		}
+		addrs[id.Address()] = f.Name()
+		if len(id.Signature) > 0 {
+			if err := id.ValidSignature(); err != nil {
+				return nil, fmt.Errorf("drand: invalid signature in %s: %w", f.Name(), err)
+			}
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("drand: no identity file in %s", dir)
+	}
+	return ids, nil
+}
+
+// loadGroupFile loads a group file in the TOML format, or in the JSON format
+// written by export-group.
+func loadGroupFile(groupPath string) (*key.Group, error) {
+	buff, err := ioutil.ReadFile(groupPath)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(buff), []byte("{")) {
+		packet := new(drand.GroupPacket)
+		if err := json.Unmarshal(buff, packet); err != nil {
+			return nil, err
+		}
+		return key.GroupFromProto(packet)
+	}
+	group := new(key.Group)
+	if err := key.Load(groupPath, group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
 func toArray(flags ...cli.Flag) []cli.Flag {
 	return flags
 }
@@ -721,9 +1531,8 @@ func contextToConfig(c *cli.Context) *core.Config {
 		opts = append(opts, core.WithPrivateListenAddress(c.String(privListenFlag.Name)))
 	}
 
-	port := c.String(controlFlag.Name)
-	if port != "" {
-		opts = append(opts, core.WithControlPort(port))
+	if c.IsSet(controlFlag.Name) || c.IsSet(controlSocketFlag.Name) {
+		opts = append(opts, core.WithControlPort(controlPort(c)))
 	}
 	if c.IsSet(folderFlag.Name) {
 		opts = append(opts, core.WithConfigFolder(c.String(folderFlag.Name)))
@@ -749,6 +1558,24 @@ func contextToConfig(c *cli.Context) *core.Config {
 	if c.Bool(enablePrivateRand.Name) {
 		opts = append(opts, core.WithPrivateRandomness())
 	}
+	if c.IsSet(maxBeaconLagFlag.Name) {
+		opts = append(opts, core.WithMaxBeaconLag(c.Uint64(maxBeaconLagFlag.Name)))
+	}
+	if c.IsSet(thresholdWaitFlag.Name) {
+		opts = append(opts, core.WithThresholdWait(c.Duration(thresholdWaitFlag.Name)))
+	}
+	if c.IsSet(auditLogFlag.Name) {
+		opts = append(opts, core.WithAuditLog(c.String(auditLogFlag.Name)))
+	}
+	if c.IsSet(keyFileFlag.Name) {
+		opts = append(opts, core.WithKeyFile(c.String(keyFileFlag.Name)))
+	}
+	if c.IsSet(wellKnownDirFlag.Name) {
+		opts = append(opts, core.WithWellKnownDir(c.String(wellKnownDirFlag.Name)))
+	}
+	if c.IsSet(beaconIDPrefixFlag.Name) {
+		opts = append(opts, core.WithBeaconIDPrefix(c.String(beaconIDPrefixFlag.Name)))
+	}
 	conf := core.NewConfig(opts...)
 	return conf
 }
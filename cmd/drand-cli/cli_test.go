@@ -3,14 +3,21 @@ package drand
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"math"
 	gnet "net"
 	"os"
 	"path"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"testing"
 	"time"
 
@@ -18,15 +25,23 @@ import (
 
 	"github.com/BurntSushi/toml"
 	"github.com/drand/drand/chain"
+	"github.com/drand/drand/chain/beacon"
 	"github.com/drand/drand/chain/boltdb"
+	"github.com/drand/drand/client"
+	"github.com/drand/drand/client/grpc"
 	"github.com/drand/drand/core"
 	"github.com/drand/drand/fs"
 	"github.com/drand/drand/key"
+	"github.com/drand/drand/net"
+	"github.com/drand/drand/protobuf/drand"
 	"github.com/drand/drand/test"
+	"github.com/drand/drand/test/mock"
 	"github.com/drand/kyber"
 	"github.com/drand/kyber/share"
 	"github.com/drand/kyber/util/random"
+	proto "github.com/golang/protobuf/proto"
 	"github.com/kabukky/httpscerts"
+	"github.com/urfave/cli/v2"
 
 	"github.com/stretchr/testify/require"
 )
@@ -83,6 +98,83 @@ func TestDeleteBeacon(t *testing.T) {
 	require.Nil(t, b)
 }
 
+func TestVacuum(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "drand-cli-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+	conf := core.NewConfig(core.WithConfigFolder(tmp))
+	require.NoError(t, os.MkdirAll(conf.DBFolder(), 0740))
+	store, err := boltdb.NewBoltStore(conf.DBFolder(), conf.BoltOptions())
+	require.NoError(t, err)
+	for i := 1; i <= 2000; i++ {
+		require.NoError(t, store.Put(&chain.Beacon{Round: uint64(i), Signature: bytes.Repeat([]byte{1}, 96)}))
+	}
+	for i := 1; i < 2000; i++ {
+		require.NoError(t, store.Del(uint64(i)))
+	}
+	store.Close()
+
+	var buff bytes.Buffer
+	output = &buff
+	defer func() { output = os.Stdout }()
+	require.NoError(t, CLI().Run([]string{"drand", "util", "vacuum", "--folder", tmp}))
+	var before, after int64
+	_, err = fmt.Sscanf(buff.String(), "database size: %d bytes before, %d bytes after", &before, &after)
+	require.NoError(t, err)
+	require.True(t, after < before, buff.String())
+}
+
+func TestRestoreCheckpointCmd(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "drand-restore")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+	folder := path.Join(tmp, "node")
+	cpDir := path.Join(tmp, "checkpoint")
+
+	// a checkpoint of a single node group
+	pair := key.NewKeyPair("127.0.0.1:8081")
+	cp, err := key.NewFileStore(cpDir)
+	require.NoError(t, err)
+	require.NoError(t, cp.SaveKeyPair(pair))
+	dist := &key.DistPublic{Coefficients: []kyber.Point{pair.Public.Key}}
+	group := key.LoadGroup([]*key.Node{{Identity: pair.Public, Index: 0}}, 1, dist, 30*time.Second, 0)
+	sh := &key.Share{Commits: dist.Coefficients, Share: &share.PriShare{I: 0, V: pair.Key}}
+	require.NoError(t, cp.SaveGroupAndShare(group, sh, false))
+	require.NoError(t, cp.Close())
+	before, err := ioutil.ReadDir(cpDir)
+	require.NoError(t, err)
+
+	restore := []string{"drand", "util", "restore-checkpoint", "--folder", folder}
+	require.Error(t, CLI().Run(restore))
+
+	// it can't run while the daemon holds the store
+	fs, err := key.NewFileStore(folder)
+	require.NoError(t, err)
+	require.Error(t, CLI().Run(append(restore, cpDir)))
+	require.NoError(t, fs.Close())
+
+	var buff bytes.Buffer
+	output = &buff
+	defer func() { output = os.Stdout }()
+	require.NoError(t, CLI().Run(append(restore, cpDir)))
+	require.Contains(t, buff.String(), "restored the group and share")
+	fs, err = key.NewFileStore(folder)
+	require.NoError(t, err)
+	defer fs.Close()
+	restored, err := fs.LoadGroup()
+	require.NoError(t, err)
+	require.Equal(t, group.Hash(), restored.Hash())
+
+	// the checkpoint is left untouched
+	after, err := ioutil.ReadDir(cpDir)
+	require.NoError(t, err)
+	require.Equal(t, len(before), len(after))
+	for i := range before {
+		require.Equal(t, before[i].Name(), after[i].Name())
+		require.Equal(t, before[i].ModTime(), after[i].ModTime())
+	}
+}
+
 func TestKeySelfSign(t *testing.T) {
 	tmp := path.Join(os.TempDir(), "drand")
 	defer os.RemoveAll(tmp)
@@ -95,16 +187,1051 @@ func TestKeySelfSign(t *testing.T) {
 	testCommand(t, selfSign, expectedOutput)
 
 	// load, remove signature and save
-	fileStore := key.NewFileStore(tmp)
+	fileStore, err := key.NewFileStore(tmp)
+	require.NoError(t, err)
 	pair, err := fileStore.LoadKeyPair()
 	require.NoError(t, err)
 	pair.Public.Signature = nil
 	require.NoError(t, fileStore.SaveKeyPair(pair))
+	require.NoError(t, fileStore.Close())
 
 	expectedOutput = "identity self signed"
 	testCommand(t, selfSign, expectedOutput)
 }
 
+func TestGenerateCert(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "drand-cli-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+	certPath := path.Join(tmp, "server.crt")
+	keyPath := path.Join(tmp, "server.key")
+
+	args := []string{"drand", "tls", "generate-cert", "--host", "127.0.0.1",
+		"--out-cert", certPath, "--out-key", keyPath, "--valid-for", "1h"}
+	testCommand(t, args, "SHA-256 fingerprint")
+	_, err = tls.LoadX509KeyPair(certPath, keyPath)
+	require.NoError(t, err)
+
+	require.Error(t, CLI().Run([]string{"drand", "tls", "generate-cert", "--out-cert", certPath}))
+}
+
+func TestRotateTLSWithoutDaemon(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "drand-cli-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+	certPath := path.Join(tmp, "new.crt")
+	keyPath := path.Join(tmp, "new.key")
+	testCommand(t, []string{"drand", "tls", "generate-cert", "--host", "127.0.0.1",
+		"--out-cert", certPath, "--out-key", keyPath}, "")
+
+	// without a running daemon, the files are only validated
+	args := []string{"drand", "util", "rotate-tls", "--control", test.FreePort(),
+		"--new-cert", certPath, "--new-key", keyPath}
+	testCommand(t, args, "has not been installed")
+	testCommand(t, args, "Expires on")
+
+	// a key not matching the certificate is refused
+	otherCert := path.Join(tmp, "other.crt")
+	otherKey := path.Join(tmp, "other.key")
+	testCommand(t, []string{"drand", "tls", "generate-cert", "--host", "127.0.0.1",
+		"--out-cert", otherCert, "--out-key", otherKey}, "")
+	require.Error(t, CLI().Run([]string{"drand", "util", "rotate-tls", "--control", test.FreePort(),
+		"--new-cert", certPath, "--new-key", otherKey}))
+}
+
+func TestVerifyGroup(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "drand-cli-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+
+	n, thr := 4, 3
+	_, group := test.BatchIdentities(n)
+	group.Threshold = thr
+	priPoly := share.NewPriPoly(key.KeyGroup, thr, nil, random.New())
+	_, commits := priPoly.Commit(key.KeyGroup.Point().Base()).Info()
+	group.PublicKey = &key.DistPublic{Coefficients: commits}
+	groupPath := path.Join(tmp, "group.toml")
+	require.NoError(t, key.Save(groupPath, group, false))
+
+	var sharePaths []string
+	for i, s := range priPoly.Shares(n) {
+		p := path.Join(tmp, fmt.Sprintf("share%d.toml", i))
+		require.NoError(t, key.Save(p, &key.Share{Commits: commits, Share: s}, true))
+		sharePaths = append(sharePaths, p)
+	}
+
+	args := append([]string{"drand", "util", "verify-group", groupPath}, sharePaths[1:]...)
+	testCommand(t, args, "group public key verified")
+
+	// not enough shares
+	args = append([]string{"drand", "util", "verify-group", groupPath}, sharePaths[:thr-1]...)
+	require.Error(t, CLI().Run(args))
+
+	// shares not matching the group key
+	group.PublicKey = &key.DistPublic{Coefficients: []kyber.Point{key.KeyGroup.Point().Pick(random.New())}}
+	require.NoError(t, key.Save(groupPath, group, false))
+	args = append([]string{"drand", "util", "verify-group", groupPath}, sharePaths...)
+	require.Error(t, CLI().Run(args))
+}
+
+func TestDiffGroup(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "drand-cli-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+
+	_, oldGroup := test.BatchIdentities(3)
+	_, other := test.BatchIdentities(1)
+	newGroup := key.LoadGroup(append(oldGroup.Nodes[1:], other.Nodes[0]), oldGroup.GenesisTime,
+		oldGroup.PublicKey, oldGroup.Period, 0)
+	newGroup.Threshold = oldGroup.Threshold + 1
+	oldPath := path.Join(tmp, "old.toml")
+	newPath := path.Join(tmp, "new.toml")
+	require.NoError(t, key.Save(oldPath, oldGroup, false))
+	require.NoError(t, key.Save(newPath, newGroup, false))
+
+	args := []string{"drand", "util", "diff-group", "--old", oldPath, "--new", newPath}
+	testCommand(t, args, "+ "+other.Nodes[0].Address())
+	testCommand(t, args, "- "+oldGroup.Nodes[0].Address())
+	testCommand(t, args, "= "+oldGroup.Nodes[1].Address())
+	testCommand(t, args, fmt.Sprintf("threshold: %d -> %d", oldGroup.Threshold, newGroup.Threshold))
+
+	require.Error(t, CLI().Run([]string{"drand", "util", "diff-group", "--old", oldPath}))
+}
+
+// exampleIdentities returns key pairs for the nodes drand-<name>.example.com
+func exampleIdentities(names ...string) []*key.Pair {
+	pairs := make([]*key.Pair, len(names))
+	for i, name := range names {
+		pairs[i] = key.NewTLSKeyPair("drand-" + name + ".example.com:4444")
+	}
+	return pairs
+}
+
+func TestListNodes(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "drand-cli-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+	pairs := exampleIdentities("a", "b", "c")
+	pairs[2].Public.TLS = false
+	pairs[2].SelfSign()
+	dpub := &key.DistPublic{Coefficients: []kyber.Point{
+		key.KeyGroup.Point().Pick(random.New()),
+		key.KeyGroup.Point().Pick(random.New()),
+	}}
+	group := key.LoadGroup(test.ListFromPrivates(pairs), 1600000000, dpub, 30*time.Second, 0)
+	groupPath := path.Join(tmp, "group.toml")
+	require.NoError(t, key.Save(groupPath, group, false))
+	groupHash := fmt.Sprintf("group hash: %x", group.Hash())
+
+	args := []string{"drand", "util", "list-nodes", "--group-file", groupPath}
+	testCommand(t, args, "0\tdrand-a.example.com:4444\ttrue\t"+pairs[0].Public.Fingerprint())
+	testCommand(t, args, "2\tdrand-c.example.com:4444\tfalse\t"+pairs[2].Public.Fingerprint())
+	testCommand(t, args, "threshold: 2")
+	testCommand(t, args, "period: 30s")
+	testCommand(t, args, "genesis time: 2020-09-13T12:26:40Z (1600000000)")
+	testCommand(t, args, groupHash)
+	testCommand(t, args, fmt.Sprintf("chain hash: %x", chain.NewChainInfo(group).Hash()))
+
+	// the same group exported in JSON
+	buff, err := encodeGroup(group, groupFormatJSON, true)
+	require.NoError(t, err)
+	jsonPath := path.Join(tmp, "group.json")
+	require.NoError(t, ioutil.WriteFile(jsonPath, buff, 0600))
+	args = []string{"drand", "util", "list-nodes", "--group-file", jsonPath}
+	testCommand(t, args, "2\tdrand-c.example.com:4444\tfalse\t"+pairs[2].Public.Fingerprint())
+	testCommand(t, args, groupHash)
+
+	require.Error(t, CLI().Run([]string{"drand", "util", "list-nodes"}))
+	require.Error(t, CLI().Run([]string{"drand", "util", "list-nodes", "--group-file", path.Join(tmp, "none.toml")}))
+}
+
+func TestPackGroup(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "drand-cli-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+	// identity files in the TOML format of the key store and in the JSON
+	// format of the Identity message
+	nodesDir := path.Join(tmp, "nodes")
+	require.NoError(t, os.Mkdir(nodesDir, 0700))
+	pairs := exampleIdentities("a", "b", "c", "d", "e")
+	ids := make([]*key.Identity, len(pairs))
+	for i, p := range pairs {
+		ids[i] = p.Public
+		name := path.Join(nodesDir, fmt.Sprintf("drand-%c", 'a'+i))
+		if i < 3 {
+			require.NoError(t, key.Save(name+".toml", p.Public, false))
+			continue
+		}
+		buff, err := json.Marshal(p.Public.ToProto())
+		require.NoError(t, err)
+		require.NoError(t, ioutil.WriteFile(name+".json", buff, 0600))
+	}
+	outPath := path.Join(tmp, "group.toml")
+	args := []string{"drand", "util", "pack-group", "--nodes-dir", nodesDir,
+		"--threshold", "3", "--period", "30s", "--out", outPath}
+	expected := key.NewGroup(ids, 3, 0, 30*time.Second, 0)
+	testCommand(t, args, fmt.Sprintf("group hash: %x", expected.Hash()))
+
+	group := new(key.Group)
+	require.NoError(t, key.Load(outPath, group))
+	require.Equal(t, expected.Hash(), group.Hash())
+	require.Equal(t, 5, group.Len())
+	require.Equal(t, 3, group.Threshold)
+	require.Equal(t, 30*time.Second, group.Period)
+	addrs := make(map[string]bool)
+	for i, n := range group.Nodes {
+		require.Equal(t, key.Index(i), n.Index)
+		addrs[n.Address()] = true
+	}
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		require.True(t, addrs["drand-"+name+".example.com:4444"])
+	}
+
+	// the threshold must be between a majority and the number of nodes
+	args[6] = "6"
+	require.Error(t, CLI().Run(args))
+	args[6] = "2"
+	require.Error(t, CLI().Run(args))
+
+	// duplicate address
+	dupDir := path.Join(tmp, "dup")
+	require.NoError(t, os.Mkdir(dupDir, 0700))
+	buff, err := ioutil.ReadFile(path.Join(nodesDir, "drand-a.toml"))
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(path.Join(dupDir, "drand-a.toml"), buff, 0600))
+	require.NoError(t, ioutil.WriteFile(path.Join(dupDir, "drand-a2.toml"), buff, 0600))
+	require.Error(t, CLI().Run([]string{"drand", "util", "pack-group", "--nodes-dir", dupDir,
+		"--threshold", "2", "--period", "30s"}))
+
+	// invalid address
+	badDir := path.Join(tmp, "bad")
+	require.NoError(t, os.Mkdir(badDir, 0700))
+	bad := bytes.Replace(buff, []byte("drand-a.example.com:4444"), []byte("drand-a.example.com"), 1)
+	require.NoError(t, ioutil.WriteFile(path.Join(badDir, "drand-a.toml"), bad, 0600))
+	require.Error(t, CLI().Run([]string{"drand", "util", "pack-group", "--nodes-dir", badDir,
+		"--threshold", "1", "--period", "30s"}))
+
+	require.Error(t, CLI().Run([]string{"drand", "util", "pack-group", "--nodes-dir", tmp,
+		"--threshold", "1", "--period", "30s"}))
+	require.Error(t, CLI().Run([]string{"drand", "util", "pack-group", "--nodes-dir", nodesDir,
+		"--threshold", "3", "--period", "500ms"}))
+}
+
+func TestControlKeys(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "drand-cli-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+	pair := key.NewKeyPair("127.0.0.1:8080")
+	pub, err := pair.Public.Key.MarshalBinary()
+	require.NoError(t, err)
+	hexKey := hex.EncodeToString(pub)
+	keysPath := path.Join(tmp, controlKeysFileName)
+
+	add := []string{"drand", "util", "add-key", "--folder", tmp, hexKey}
+	testCommand(t, add, "allowed")
+	testCommand(t, add, "already allowed")
+	keys, err := loadControlKeys(keysPath)
+	require.NoError(t, err)
+	require.Equal(t, []string{hexKey}, keys)
+	ids, err := controlIdentities(keys)
+	require.NoError(t, err)
+	require.Len(t, ids, 1)
+	require.True(t, ids[0].Key.Equal(pair.Public.Key))
+	require.Error(t, CLI().Run([]string{"drand", "util", "add-key", "--folder", tmp, "deadbeef"}))
+
+	testCommand(t, []string{"drand", "util", "remove-key", "--folder", tmp, hexKey}, "removed")
+	keys, err = loadControlKeys(keysPath)
+	require.NoError(t, err)
+	require.Empty(t, keys)
+	require.Error(t, CLI().Run([]string{"drand", "util", "remove-key", "--folder", tmp, hexKey}))
+}
+
+func TestExportGroupFormats(t *testing.T) {
+	_, group := test.BatchIdentities(3)
+
+	for _, pretty := range []bool{false, true} {
+		buff, err := encodeGroup(group, groupFormatJSON, pretty)
+		require.NoError(t, err)
+		require.Equal(t, pretty, bytes.Contains(buff, []byte("\n")))
+		packet := new(drand.GroupPacket)
+		require.NoError(t, json.Unmarshal(buff, packet))
+		g, err := key.GroupFromProto(packet)
+		require.NoError(t, err)
+		require.True(t, group.Equal(g))
+	}
+
+	buff, err := encodeGroup(group, groupFormatTOML, false)
+	require.NoError(t, err)
+	gtoml := group.TOMLValue()
+	_, err = toml.Decode(string(buff), gtoml)
+	require.NoError(t, err)
+	g := new(key.Group)
+	require.NoError(t, g.FromTOML(gtoml))
+	require.True(t, group.Equal(g))
+
+	buff, err = encodeGroup(group, groupFormatProto, false)
+	require.NoError(t, err)
+	packet := new(drand.GroupPacket)
+	require.NoError(t, proto.Unmarshal(buff, packet))
+	g, err = key.GroupFromProto(packet)
+	require.NoError(t, err)
+	require.True(t, group.Equal(g))
+
+	_, err = encodeGroup(group, "yaml", false)
+	require.Error(t, err)
+}
+
+// lockedBuffer is a bytes.Buffer safe to read while the watch routine writes
+type lockedBuffer struct {
+	sync.Mutex
+	b bytes.Buffer
+}
+
+func (l *lockedBuffer) Write(p []byte) (int, error) {
+	l.Lock()
+	defer l.Unlock()
+	return l.b.Write(p)
+}
+
+func (l *lockedBuffer) Lines() []string {
+	l.Lock()
+	defer l.Unlock()
+	return strings.Split(strings.TrimSpace(l.b.String()), "\n")
+}
+
+func TestWatchRandomness(t *testing.T) {
+	l, server := mock.NewMockGRPCPublicServer("localhost:0", false)
+	go l.Start()
+	defer l.Stop(context.Background())
+	emit := server.(mock.MockService).EmitRand
+
+	cl, err := grpc.New(l.Addr(), "", true)
+	require.NoError(t, err)
+	defer cl.Close()
+
+	var buff lockedBuffer
+	output = &buff
+	defer func() { output = os.Stdout }()
+	watchMinBackoff = 10 * time.Millisecond
+	defer func() { watchMinBackoff = 1 * time.Second }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errCh := make(chan error, 1)
+	go func() { errCh <- watchRandomness(ctx, cl, randFormatText, false) }()
+
+	// emit beacons until the given number of lines are printed
+	waitLines := func(n int) []string {
+		var lines []string
+		require.Eventually(t, func() bool {
+			emit(false)
+			time.Sleep(20 * time.Millisecond)
+			lines = buff.Lines()
+			return len(lines) >= n && lines[0] != ""
+		}, 5*time.Second, 50*time.Millisecond)
+		return lines
+	}
+	lines := waitLines(1)
+	require.Regexp(t, `^\[round 1969\] [0-9a-f]{64}$`, lines[0])
+
+	// the stream is re-established after a disconnection
+	emit(true)
+	lines = waitLines(len(lines) + 1)
+	require.Regexp(t, `^\[round \d+\] [0-9a-f]{64}$`, lines[len(lines)-1])
+
+	cancel()
+	select {
+	case err := <-errCh:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("watch did not stop")
+	}
+
+	var jsonBuff bytes.Buffer
+	output = &jsonBuff
+	require.NoError(t, printRandomness(&client.RandomData{Rnd: 12, Random: []byte{1}}, randFormatJSON, false))
+	require.Equal(t, `{"round":12,"randomness":"01"}`+"\n", jsonBuff.String())
+}
+
+func TestPrintRandomnessFormats(t *testing.T) {
+	sig := bytes.Repeat([]byte{0xfb}, 96)
+	prev := bytes.Repeat([]byte{0x01}, 96)
+	b := &chain.Beacon{Round: 3, Signature: sig, PreviousSig: prev}
+	r := &client.RandomData{Rnd: b.Round, Random: b.Randomness(), Sig: sig, PreviousSignature: prev}
+	defer func() { output = os.Stdout }()
+	printed := func(format string, verbose bool) []byte {
+		var buff bytes.Buffer
+		output = &buff
+		require.NoError(t, printRandomness(r, format, verbose))
+		return buff.Bytes()
+	}
+
+	// the raw output is the randomness itself, the hash of the signature
+	raw := printed(randFormatRaw, true)
+	require.Equal(t, r.Random, raw)
+	h := sha256.Sum256(sig)
+	require.Equal(t, h[:], raw)
+
+	require.Equal(t, hex.EncodeToString(r.Random)+"\n", string(printed(randFormatHex, false)))
+	require.Equal(t, base64.URLEncoding.EncodeToString(r.Random)+"\n", string(printed(randFormatBase64, false)))
+	require.Equal(t, "[round 3] "+hex.EncodeToString(r.Random)+"\n", string(printed(randFormatText, false)))
+	require.Equal(t, fmt.Sprintf("%s signature=%s previous_signature=%s\n",
+		base64.URLEncoding.EncodeToString(r.Random),
+		base64.URLEncoding.EncodeToString(sig),
+		base64.URLEncoding.EncodeToString(prev)), string(printed(randFormatBase64, true)))
+}
+
+// rangeClient answers the requests for the higher rounds faster, so they end
+// out of order, and records the maximum number of requests in flight
+type rangeClient struct {
+	client.Client
+	sync.Mutex
+	inFlight    int
+	maxInFlight int
+	failRound   uint64
+}
+
+func (r *rangeClient) Get(ctx context.Context, round uint64) (client.Result, error) {
+	r.Lock()
+	r.inFlight++
+	if r.inFlight > r.maxInFlight {
+		r.maxInFlight = r.inFlight
+	}
+	r.Unlock()
+	defer func() {
+		r.Lock()
+		r.inFlight--
+		r.Unlock()
+	}()
+	time.Sleep(time.Duration(20-round%10) * time.Millisecond)
+	if round == r.failRound {
+		return nil, errors.New("round not found")
+	}
+	return &client.RandomData{Rnd: round, Random: []byte{byte(round)}}, nil
+}
+
+func TestGetRandomnessRange(t *testing.T) {
+	var buff bytes.Buffer
+	output = &buff
+	defer func() { output = os.Stdout }()
+
+	cl := new(rangeClient)
+	var progress bytes.Buffer
+	from, to, parallel := uint64(3), uint64(3+rangeProgressStep), 4
+	require.NoError(t, getRandomnessRange(context.Background(), cl, from, to, parallel, time.Second, randFormatText, false, &progress))
+	lines := strings.Split(strings.TrimSpace(buff.String()), "\n")
+	require.Len(t, lines, int(to-from+1))
+	for i, line := range lines {
+		round := from + uint64(i)
+		require.Equal(t, fmt.Sprintf("[round %d] %02x", round, byte(round)), line)
+	}
+	require.True(t, cl.maxInFlight > 1)
+	require.True(t, cl.maxInFlight <= parallel)
+	require.Contains(t, progress.String(), fmt.Sprintf("round %d of %d", to-from+1, to-from+1))
+
+	// the rounds before the failing one are printed
+	buff.Reset()
+	cl = &rangeClient{failRound: 6}
+	err := getRandomnessRange(context.Background(), cl, 1, 10, parallel, time.Second, randFormatText, false, &progress)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "round 6")
+	require.Len(t, strings.Split(strings.TrimSpace(buff.String()), "\n"), 5)
+
+	// the whole range is not held in memory
+	buff.Reset()
+	cl = &rangeClient{failRound: 10}
+	err = getRandomnessRange(context.Background(), cl, 1, math.MaxUint64, parallel, time.Second, randFormatText, false, &progress)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "round 10")
+	require.Len(t, strings.Split(strings.TrimSpace(buff.String()), "\n"), 9)
+}
+
+// hangingClient never answers, until the request is canceled
+type hangingClient struct {
+	client.Client
+}
+
+func (h *hangingClient) Get(ctx context.Context, round uint64) (client.Result, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestGetRandomnessRangeTimeout(t *testing.T) {
+	var buff bytes.Buffer
+	output = &buff
+	defer func() { output = os.Stdout }()
+
+	err := getRandomnessRange(context.Background(), new(hangingClient), 1, 2, 1, 50*time.Millisecond,
+		randFormatText, false, ioutil.Discard)
+	var exit cli.ExitCoder
+	require.True(t, errors.As(err, &exit))
+	require.Equal(t, timeoutExitCode, exit.ExitCode())
+	require.Contains(t, err.Error(), "did not answer within 50ms")
+	require.Empty(t, buff.String())
+}
+
+// fakeReloader records the configurations it reloads and fails the first time
+type fakeReloader struct {
+	configs []*core.Config
+}
+
+func (f *fakeReloader) ReloadConfig(c *core.Config) error {
+	f.configs = append(f.configs, c)
+	if len(f.configs) == 1 {
+		return core.ErrNotReloadable
+	}
+	return nil
+}
+
+func TestReloadOnSignal(t *testing.T) {
+	var buff lockedBuffer
+	output = &buff
+	defer func() { output = os.Stdout }()
+
+	reloader := new(fakeReloader)
+	sigs := make(chan os.Signal, 4)
+	for i := 0; i < 4; i++ {
+		sigs <- syscall.SIGHUP
+	}
+	close(sigs)
+	calls := 0
+	reloadOnSignal(sigs, reloader, func() (*core.Config, error) {
+		calls++
+		switch calls {
+		case 3:
+			return nil, errors.New("invalid settings")
+		case 4:
+			panic("boom")
+		}
+		return core.NewConfig(), nil
+	})
+
+	require.Len(t, reloader.configs, 2)
+	lines := buff.Lines()
+	require.Len(t, lines, 4)
+	require.Contains(t, lines[0], "can't reload config")
+	require.Equal(t, "drand: config reloaded", lines[1])
+	require.Contains(t, lines[2], "invalid settings")
+	require.Contains(t, lines[3], "boom")
+}
+
+func TestLoadSettings(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "drand-settings")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+	settings := path.Join(tmp, settingsFileName)
+
+	// no file, no change
+	require.NoError(t, loadSettings(settings, core.NewConfig()))
+
+	require.NoError(t, ioutil.WriteFile(settings, []byte("verbose = true\ncontrol_stop_timeout = \"10s\"\n"), 0600))
+	require.NoError(t, loadSettings(settings, core.NewConfig()))
+
+	require.NoError(t, ioutil.WriteFile(settings, []byte("control_stop_timeout = \"soon\"\n"), 0600))
+	err = loadSettings(settings, core.NewConfig())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "control_stop_timeout")
+}
+
+func TestShareRole(t *testing.T) {
+	err := CLI().Run([]string{"drand", "share", "--connect", "127.0.0.1:8080"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "--leader")
+	require.Contains(t, err.Error(), "--follower")
+
+	err = CLI().Run([]string{"drand", "share", "--leader", "--follower"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "mutually exclusive")
+
+	err = CLI().Run([]string{"drand", "share", "--observer", "--follower"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "mutually exclusive")
+
+	err = CLI().Run([]string{"drand", "share", "--observer", "--transition", "--connect", "127.0.0.1:8080"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "fresh DKG")
+}
+
+func TestShareTimeoutPhase(t *testing.T) {
+	require.NoError(t, os.Setenv("DRAND_SHARE_SECRET", strings.Repeat("a", minimumShareSecretLength)))
+	defer os.Unsetenv("DRAND_SHARE_SECRET")
+	err := CLI().Run([]string{"drand", "share", "--leader", "--nodes", "3", "--threshold", "2", "--timeout", "5s", "--timeout-phase", "5s"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "can not be used together")
+}
+
+func TestShareNoTLS(t *testing.T) {
+	require.NoError(t, os.Setenv("DRAND_SHARE_SECRET", strings.Repeat("a", minimumShareSecretLength)))
+	defer os.Unsetenv("DRAND_SHARE_SECRET")
+	for _, tc := range []struct {
+		args  []string
+		isTLS bool
+	}{
+		{nil, true},
+		{[]string{"--no-tls"}, false},
+		{[]string{"--tls-disable"}, false},
+		{[]string{"--tls-disable=false"}, true},
+	} {
+		var isTLS bool
+		app := &cli.App{
+			Flags: toArray(insecureFlag, secretFlag, timeoutFlag, timeoutPhaseFlag, waitTimeoutFlag),
+			Action: func(c *cli.Context) error {
+				args, err := getShareArgs(c)
+				if err != nil {
+					return err
+				}
+				isTLS = args.isTLS
+				return nil
+			},
+		}
+		require.NoError(t, app.Run(append([]string{"drand"}, tc.args...)))
+		require.Equal(t, tc.isTLS, isTLS, tc.args)
+	}
+}
+
+func TestShareFromGroupHash(t *testing.T) {
+	err := CLI().Run([]string{"drand", "share", "--leader", "--from-group-hash", "abcd"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "can not be used with")
+
+	err = CLI().Run([]string{"drand", "share", "--follower", "--from", "group.toml", "--from-group-hash", "abcd"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "can not be used with")
+
+	err = CLI().Run([]string{"drand", "share", "--follower", "--join-existing", "--connect", "127.0.0.1:8080", "--from-group-hash", "zz"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid hash")
+
+	err = CLI().Run([]string{"drand", "share", "--follower", "--join-existing", "--connect", "127.0.0.1:8080", "--from-group-hash", "abcd", "--tls-disable"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "--tls-disable")
+}
+
+func TestShareGroupSignature(t *testing.T) {
+	require.NoError(t, os.Setenv("DRAND_SHARE_SECRET", strings.Repeat("a", minimumShareSecretLength)))
+	defer os.Unsetenv("DRAND_SHARE_SECRET")
+	tmp, err := ioutil.TempDir("", "drand-cli-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+
+	privs, group := test.BatchIdentities(3)
+	leader := privs[0]
+	leaderPath := path.Join(tmp, "leader.public")
+	require.NoError(t, key.Save(leaderPath, leader.Public, false))
+	groupPath := path.Join(tmp, "group.toml")
+	require.NoError(t, key.Save(groupPath, group, false))
+	content, err := ioutil.ReadFile(groupPath)
+	require.NoError(t, err)
+	hashed := sha256.Sum256(content)
+	signature, err := key.DKGAuthScheme.Sign(leader.Key, hashed[:])
+	require.NoError(t, err)
+	require.NoError(t, verifyGroupSignature(groupPath, signature, leader.Public))
+
+	require.Error(t, verifyGroupSignature(groupPath, signature, privs[1].Public))
+
+	// a tampered group file is rejected before contacting the leader
+	group.Period = time.Second
+	require.NoError(t, key.Save(groupPath, group, false))
+	require.Error(t, verifyGroupSignature(groupPath, signature, leader.Public))
+	err = CLI().Run([]string{"drand", "share", "--follower", "--connect", leader.Public.Address(),
+		"--folder", tmp, "--from", groupPath, "--group-signature", hex.EncodeToString(signature),
+		"--leader-key", leaderPath})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not signed by the leader")
+
+	// without --leader-key the key is searched in the group of the daemon
+	err = CLI().Run([]string{"drand", "share", "--follower", "--connect", leader.Public.Address(),
+		"--folder", tmp, "--control", test.FreePort(), "--from", groupPath,
+		"--group-signature", hex.EncodeToString(signature)})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no group from the daemon")
+
+	err = CLI().Run([]string{"drand", "share", "--leader", "--from", groupPath,
+		"--group-signature", hex.EncodeToString(signature)})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "verify the group file")
+}
+
+// The group signature is made and checked through the daemon, which holds the
+// lock of the key store
+func TestGroupSignatureWithDaemon(t *testing.T) {
+	require.NoError(t, os.Setenv("DRAND_SHARE_SECRET", strings.Repeat("a", minimumShareSecretLength)))
+	defer os.Unsetenv("DRAND_SHARE_SECRET")
+	tmp, err := ioutil.TempDir("", "drand-cli-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+
+	priv := key.NewKeyPair("127.0.0.1:" + test.FreePort())
+	priv.Public.TLS = false
+	_, group := test.BatchIdentities(3)
+	group.Nodes[0] = &key.Node{Identity: priv.Public, Index: 0}
+	group.Period = 5 * time.Second
+	group.GenesisTime = time.Now().Unix() + 3600
+	group.PublicKey = &key.DistPublic{Coefficients: []kyber.Point{
+		key.KeyGroup.Point().Pick(random.New()),
+		key.KeyGroup.Point().Pick(random.New()),
+	}}
+	fileStore, err := key.NewFileStore(tmp)
+	require.NoError(t, err)
+	require.NoError(t, fileStore.SaveKeyPair(priv))
+	require.NoError(t, fileStore.SaveGroup(group, false))
+	require.NoError(t, fileStore.SaveShare(&key.Share{Share: &share.PriShare{I: 0, V: key.KeyGroup.Scalar().One()}}))
+	require.NoError(t, fileStore.Close())
+	groupPath := path.Join(tmp, "group.toml")
+	require.NoError(t, key.Save(groupPath, group, false))
+
+	ctrlPort := test.FreePort()
+	go CLI().Run([]string{"drand", "start", "--tls-disable", "--folder", tmp, "--control", ctrlPort})
+	defer CLI().Run([]string{"drand", "stop", "--control", ctrlPort})
+	for i := 0; i < 20; i++ {
+		if err = CLI().Run([]string{"drand", "util", "ping", "--control", ctrlPort}); err == nil {
+			break
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	require.NoError(t, err)
+	require.True(t, errors.Is(key.CheckUnlocked(tmp), key.ErrStoreLocked))
+
+	var buff bytes.Buffer
+	output = &buff
+	defer func() { output = os.Stdout }()
+	require.NoError(t, CLI().Run([]string{"drand", "util", "sign-group", "--control", ctrlPort, groupPath}))
+	signature, err := hex.DecodeString(strings.TrimSpace(buff.String()))
+	require.NoError(t, err)
+	require.NoError(t, verifyGroupSignature(groupPath, signature, priv.Public))
+	require.Error(t, CLI().Run([]string{"drand", "util", "sign-group", "--control", ctrlPort}))
+
+	// the key of the leader is found in the group of the daemon, so the
+	// tampered group file is rejected before contacting the leader
+	group.Period = time.Second
+	require.NoError(t, key.Save(groupPath, group, false))
+	err = CLI().Run([]string{"drand", "share", "--follower", "--connect", priv.Public.Address(),
+		"--folder", tmp, "--control", ctrlPort, "--from", groupPath,
+		"--group-signature", hex.EncodeToString(signature)})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not signed by the leader")
+}
+
+func TestShareGenesisTime(t *testing.T) {
+	require.NoError(t, os.Setenv("DRAND_SHARE_SECRET", strings.Repeat("a", minimumShareSecretLength)))
+	defer os.Unsetenv("DRAND_SHARE_SECRET")
+	share := func(genesis string, extra ...string) error {
+		args := []string{"drand", "share", "--leader", "--nodes", "3", "--threshold", "2",
+			"--period", "30s", "--genesis-time", genesis}
+		return CLI().Run(append(args, extra...))
+	}
+
+	err := share("tomorrow")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "genesis time given is invalid")
+
+	err = share(time.Now().Add(10 * time.Second).Format(time.RFC3339))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "at least one period")
+
+	err = share(time.Now().Add(time.Hour).Format(time.RFC3339), "--beacon-delay", "60")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "mutually exclusive")
+}
+
+// chainInfoService answers the chain info requests with the given packet
+type chainInfoService struct {
+	net.Service
+	info *drand.ChainInfoPacket
+}
+
+func (c *chainInfoService) ChainInfo(context.Context, *drand.ChainInfoRequest) (*drand.ChainInfoPacket, error) {
+	return c.info, nil
+}
+
+func TestGetChainInfoVerify(t *testing.T) {
+	mockServer := mock.NewMockServer(false)
+	packet, err := mockServer.ChainInfo(context.Background(), new(drand.ChainInfoRequest))
+	require.NoError(t, err)
+	info, err := chain.InfoFromProto(packet)
+	require.NoError(t, err)
+
+	service := &chainInfoService{Service: mockServer}
+	l, err := net.NewGRPCListenerForPrivate(context.Background(), "127.0.0.1:0", "", "", service, true)
+	require.NoError(t, err)
+	go l.Start()
+	defer l.Stop(context.Background())
+	args := func(extra ...string) []string {
+		return append([]string{"drand", "get", "chain-info", "--tls-disable", "--hash"}, append(extra, l.Addr())...)
+	}
+
+	// the hash is only required with --verify
+	service.info = packet
+	testCommand(t, args(), hex.EncodeToString(info.Hash()))
+	require.Error(t, CLI().Run(args("--verify")))
+
+	service.info = info.ToProto()
+	testCommand(t, args("--verify"), hex.EncodeToString(info.Hash()))
+
+	// a tampered hash is always rejected
+	tampered := info.ToProto()
+	tampered.Hash[0] ^= 0xff
+	service.info = tampered
+	require.Error(t, CLI().Run(args("--verify")))
+	require.Error(t, CLI().Run(args()))
+}
+
+func TestGetChainInfoAll(t *testing.T) {
+	mockServer := mock.NewMockServer(false)
+	var addrs, hashes []string
+	var listeners []net.Listener
+	defer func() {
+		for _, l := range listeners {
+			l.Stop(context.Background())
+		}
+	}()
+	serve := func(info *chain.Info) {
+		service := &chainInfoService{Service: mockServer, info: info.ToProto()}
+		l, err := net.NewGRPCListenerForPrivate(context.Background(), "127.0.0.1:0", "", "", service, true)
+		require.NoError(t, err)
+		go l.Start()
+		listeners = append(listeners, l)
+		addrs = append(addrs, l.Addr())
+	}
+	var infos []*chain.Info
+	for i := 0; i < 3; i++ {
+		info := &chain.Info{
+			PublicKey:   key.NewKeyPair("127.0.0.1:8080").Public.Key,
+			Period:      time.Duration(i+1) * time.Second,
+			GenesisTime: 1,
+			GroupHash:   []byte("genesis seed"),
+		}
+		infos = append(infos, info)
+		hashes = append(hashes, hex.EncodeToString(info.Hash()))
+		serve(info)
+	}
+	// a second node of the first chain, and a node not reachable
+	serve(infos[0])
+	addrs = append(addrs, "127.0.0.1:"+test.FreePort())
+
+	var buff bytes.Buffer
+	output = &buff
+	defer func() { output = os.Stdout }()
+	args := append([]string{"drand", "get", "chain-info", "--tls-disable", "--all"}, addrs...)
+	require.NoError(t, CLI().Run(args))
+	var packets []*drand.ChainInfoPacket
+	require.NoError(t, json.Unmarshal(buff.Bytes(), &packets))
+	require.Len(t, packets, 3)
+	for i, p := range packets {
+		info, err := chain.InfoFromProto(p)
+		require.NoError(t, err)
+		require.True(t, infos[i].Equal(info))
+	}
+
+	buff.Reset()
+	require.NoError(t, CLI().Run(append([]string{"drand", "get", "chain-info", "--tls-disable", "--all", "--hash"}, addrs...)))
+	require.Equal(t, strings.Join(hashes, "\n")+"\n", buff.String())
+
+	require.Error(t, CLI().Run([]string{"drand", "get", "chain-info", "--tls-disable", "--all", addrs[4]}))
+}
+
+func TestVerifyChain(t *testing.T) {
+	pair := key.NewKeyPair("127.0.0.1:8080")
+	info := &chain.Info{
+		PublicKey:   pair.Public.Key,
+		Period:      time.Second,
+		GenesisTime: 1,
+		GroupHash:   []byte("genesis seed"),
+	}
+	beacons := []*chain.Beacon{chain.GenesisBeacon(info)}
+	for round := uint64(1); round <= 5; round++ {
+		prev := beacons[round-1].Signature
+		sig, err := key.AuthScheme.Sign(pair.Key, chain.Message(round, prev))
+		require.NoError(t, err)
+		beacons = append(beacons, &chain.Beacon{Round: round, Signature: sig, PreviousSig: prev})
+	}
+	var served []*chain.Beacon
+	fetch := func(ctx context.Context, round uint64) (*chain.Beacon, error) {
+		if round >= uint64(len(served)) {
+			return nil, errors.New("round not found")
+		}
+		return served[round], nil
+	}
+	verify := func(from, to uint64) (int, string, error) {
+		var buff bytes.Buffer
+		broken, err := verifyChain(context.Background(), info, from, to, fetch, time.Second, &buff)
+		return broken, buff.String(), err
+	}
+
+	served = beacons
+	broken, out, err := verify(1, 5)
+	require.NoError(t, err)
+	require.Equal(t, 0, broken)
+	require.Contains(t, out, "verified rounds 1 to 5")
+
+	// an invalid signature also breaks the link with the next round
+	tampered := *beacons[3]
+	tampered.Signature = beacons[2].Signature
+	served = append([]*chain.Beacon{}, beacons...)
+	served[3] = &tampered
+	broken, out, err = verify(2, 5)
+	require.NoError(t, err)
+	require.Equal(t, 2, broken)
+	require.Contains(t, out, "round 3: invalid signature")
+	require.Contains(t, out, "round 4: previous signature does not match the signature of round 3")
+
+	// the rounds must be contiguous
+	served = append([]*chain.Beacon{}, beacons...)
+	served[2] = beacons[3]
+	broken, out, err = verify(1, 3)
+	require.NoError(t, err)
+	require.Equal(t, 1, broken)
+	require.Contains(t, out, "round 2: the node returned round 3")
+
+	broken, _, err = verify(4, 6)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "round 6")
+	require.Equal(t, 0, broken)
+
+	// the last round of the node by default
+	last := fetch
+	fetch = func(ctx context.Context, round uint64) (*chain.Beacon, error) {
+		if round == 0 {
+			return served[len(served)-1], nil
+		}
+		return last(ctx, round)
+	}
+	served = beacons
+	broken, out, err = verify(2, 0)
+	require.NoError(t, err)
+	require.Equal(t, 0, broken)
+	require.Contains(t, out, "verified rounds 2 to 5")
+	_, _, err = verify(6, 0)
+	require.Error(t, err)
+
+	// the loop ends at the last uint64
+	fetch = func(ctx context.Context, round uint64) (*chain.Beacon, error) {
+		return &chain.Beacon{Round: round}, nil
+	}
+	broken, out, err = verify(math.MaxUint64-1, math.MaxUint64)
+	require.NoError(t, err)
+	require.Equal(t, 2, broken)
+	require.Contains(t, out, fmt.Sprintf("verified rounds %d to %d", uint64(math.MaxUint64-1), uint64(math.MaxUint64)))
+
+	require.Error(t, CLI().Run([]string{"drand", "verify-chain", "--from", "3", "--to", "2", "127.0.0.1:8080"}))
+}
+
+func TestVerifyRandomness(t *testing.T) {
+	pair := key.NewKeyPair("127.0.0.1:8080")
+	info := &chain.Info{
+		PublicKey:   pair.Public.Key,
+		Period:      time.Second,
+		GenesisTime: 1,
+		GroupHash:   []byte("genesis seed"),
+	}
+	beacons := []*chain.Beacon{chain.GenesisBeacon(info)}
+	for round := uint64(1); round <= 3; round++ {
+		prev := beacons[round-1].Signature
+		sig, err := key.AuthScheme.Sign(pair.Key, chain.Message(round, prev))
+		require.NoError(t, err)
+		beacons = append(beacons, &chain.Beacon{Round: round, Signature: sig, PreviousSig: prev})
+	}
+	served := beacons
+	fetch := func(ctx context.Context, round uint64) (*chain.Beacon, error) {
+		if round >= uint64(len(served)) {
+			return nil, errors.New("round not found")
+		}
+		return served[round], nil
+	}
+	ctx := context.Background()
+	for _, b := range beacons[1:] {
+		require.NoError(t, verifyRandomness(ctx, info, b, fetch))
+	}
+
+	tampered := *beacons[2]
+	tampered.Signature = beacons[1].Signature
+	err := verifyRandomness(ctx, info, &tampered, fetch)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid signature")
+
+	// a valid signature over a previous signature that is not the one of the
+	// previous round of the chain
+	forked := &chain.Beacon{Round: 3, PreviousSig: []byte("other chain")}
+	forked.Signature, err = key.AuthScheme.Sign(pair.Key, chain.Message(3, forked.PreviousSig))
+	require.NoError(t, err)
+	err = verifyRandomness(ctx, info, forked, fetch)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "previous signature does not match the signature of round 2")
+
+	served = beacons[:2]
+	err = verifyRandomness(ctx, info, beacons[3], fetch)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "round 2")
+}
+
+func TestVerifyBeaconCmd(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "drand-cli-verify")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+
+	// the only share of a chain of threshold 1, and one of a threshold 2
+	poly := share.NewPriPoly(key.KeyGroup, 1, nil, random.New())
+	single := &key.Share{Share: poly.Shares(1)[0], Commits: []kyber.Point{poly.Commit(nil).Commit()}}
+	singlePath := path.Join(tmp, "single.private")
+	require.NoError(t, key.Save(singlePath, single, true))
+	poly2 := share.NewPriPoly(key.KeyGroup, 2, nil, random.New())
+	_, commits2 := poly2.Commit(nil).Info()
+	multiPath := path.Join(tmp, "multi.private")
+	require.NoError(t, key.Save(multiPath, &key.Share{Share: poly2.Shares(3)[0], Commits: commits2}, true))
+
+	info := &chain.Info{PublicKey: single.Commits[0], Period: time.Second, GenesisTime: 1, GroupHash: []byte("seed")}
+	var infoBuff bytes.Buffer
+	require.NoError(t, info.ToJSON(&infoBuff))
+	infoPath := path.Join(tmp, "chain-info.json")
+	require.NoError(t, ioutil.WriteFile(infoPath, infoBuff.Bytes(), 0600))
+
+	writeBeacon := func(name string, round uint64) string {
+		prev := []byte("previous signature")
+		partial, err := key.Scheme.Sign(single.Share, chain.Message(round, prev))
+		require.NoError(t, err)
+		sig, err := key.Scheme.Recover(single.PubPoly(), chain.Message(round, prev), [][]byte{partial}, 1, 1)
+		require.NoError(t, err)
+		// in the format printed by get public --format json
+		var buff bytes.Buffer
+		output = &buff
+		defer func() { output = os.Stdout }()
+		require.NoError(t, printRandomness(&client.RandomData{Rnd: 3, Sig: sig, PreviousSignature: prev}, randFormatJSON, false))
+		p := path.Join(tmp, name)
+		require.NoError(t, ioutil.WriteFile(p, buff.Bytes(), 0600))
+		return p
+	}
+	valid := writeBeacon("valid.json", 3)
+	invalid := writeBeacon("invalid.json", 4)
+
+	testCommand(t, []string{"drand", "util", "verify", "--single-node", "--share-file", singlePath, valid},
+		"✓ round 3 verified")
+	testCommand(t, []string{"drand", "util", "verify", "--chain-info", infoPath, valid}, "✓ round 3 verified")
+	require.Error(t, CLI().Run([]string{"drand", "util", "verify", "--single-node", "--share-file", singlePath, invalid}))
+	require.Error(t, CLI().Run([]string{"drand", "util", "verify", "--chain-info", infoPath, invalid}))
+
+	err = CLI().Run([]string{"drand", "util", "verify", "--single-node", "--share-file", multiPath, valid})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "threshold of 2")
+	require.Error(t, CLI().Run([]string{"drand", "util", "verify", valid}))
+}
+
+func TestBenchmarkStore(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "drand-cli-bench")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+
+	args := []string{"drand", "util", "benchmark-store", "--count", "50", "--workers", "4", "--dir", tmp}
+	testCommand(t, args, "backend: boltdb, beacons: 50, workers: 4")
+	testCommand(t, args, "database size:")
+	// the temporary database is removed
+	files, err := ioutil.ReadDir(tmp)
+	require.NoError(t, err)
+	require.Empty(t, files)
+
+	args = []string{"drand", "util", "benchmark-store", "--backend", "badger", "--dir", tmp}
+	require.Error(t, CLI().Run(args))
+}
+
 func TestKeyGen(t *testing.T) {
 	tmp := path.Join(os.TempDir(), "drand")
 	defer os.RemoveAll(tmp)
@@ -112,10 +1239,12 @@ func TestKeyGen(t *testing.T) {
 	require.NoError(t, CLI().Run(args))
 
 	config := core.NewConfig(core.WithConfigFolder(tmp))
-	fileStore := key.NewFileStore(config.ConfigFolder())
+	fileStore, err := key.NewFileStore(config.ConfigFolder())
+	require.NoError(t, err)
 	priv, err := fileStore.LoadKeyPair()
 	require.NoError(t, err)
 	require.NotNil(t, priv.Public)
+	require.NoError(t, fileStore.Close())
 
 	tmp2 := path.Join(os.TempDir(), "drand2")
 	defer os.RemoveAll(tmp2)
@@ -123,7 +1252,9 @@ func TestKeyGen(t *testing.T) {
 	require.Error(t, CLI().Run(args))
 
 	config = core.NewConfig(core.WithConfigFolder(tmp2))
-	fileStore = key.NewFileStore(config.ConfigFolder())
+	fileStore, err = key.NewFileStore(config.ConfigFolder())
+	require.NoError(t, err)
+	defer fileStore.Close()
 	priv, err = fileStore.LoadKeyPair()
 	require.Error(t, err)
 	require.Nil(t, priv)
@@ -163,6 +1294,83 @@ func TestStartAndStop(t *testing.T) {
 	}
 }
 
+func TestStartPIDFile(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "drand-cli-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+	pidFile := path.Join(tmp, "drand.pid")
+	ctrlPort := test.FreePort()
+	generate := []string{"drand", "generate-keypair", "--tls-disable", "--folder", tmp, "127.0.0.1:" + test.FreePort()}
+	require.NoError(t, CLI().Run(generate))
+
+	startCh := make(chan error, 1)
+	go func() {
+		startArgs := []string{"drand", "start", "--tls-disable", "--folder", tmp,
+			"--control", ctrlPort, "--pid-file", pidFile}
+		startCh <- CLI().Run(startArgs)
+	}()
+	var pid []byte
+	for i := 0; i < 50 && len(pid) == 0; i++ {
+		time.Sleep(100 * time.Millisecond)
+		pid, _ = ioutil.ReadFile(pidFile)
+	}
+	require.Equal(t, fmt.Sprintf("%d\n", os.Getpid()), string(pid))
+
+	// the pid file refers to a running process
+	startArgs := []string{"drand", "start", "--tls-disable", "--folder", tmp,
+		"--control", test.FreePort(), "--pid-file", pidFile}
+	err = CLI().Run(startArgs)
+	require.True(t, errors.Is(err, ErrDaemonAlreadyRunning), "unexpected error: %v", err)
+
+	// the stop command itself reports an error, see TestStartAndStop
+	_ = CLI().Run([]string{"drand", "stop", "--control", ctrlPort})
+	select {
+	case err := <-startCh:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("drand daemon did not stop")
+	}
+	_, err = os.Stat(pidFile)
+	require.True(t, os.IsNotExist(err))
+
+	// a stale pid file does not prevent the daemon from starting
+	require.NoError(t, ioutil.WriteFile(pidFile, []byte("999999999\n"), 0644))
+	require.NoError(t, checkPIDFile(pidFile))
+}
+
+func TestStartControlSocket(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "drand-cli-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+	socket := path.Join(tmp, "control.sock")
+	generate := []string{"drand", "generate-keypair", "--tls-disable", "--folder", tmp, "127.0.0.1:" + test.FreePort()}
+	require.NoError(t, CLI().Run(generate))
+
+	startCh := make(chan error, 1)
+	go func() {
+		startArgs := []string{"drand", "start", "--tls-disable", "--folder", tmp, "--control-socket", socket}
+		startCh <- CLI().Run(startArgs)
+	}()
+	ping := []string{"drand", "util", "ping", "--control-socket", socket}
+	for i := 0; i < 50; i++ {
+		if err = CLI().Run(ping); err == nil {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	require.NoError(t, err)
+	require.NoError(t, CLI().Run([]string{"drand", "show", "public", "--control-socket", socket}))
+
+	// the stop command itself reports an error, see TestStartAndStop
+	_ = CLI().Run([]string{"drand", "stop", "--control-socket", socket})
+	select {
+	case err := <-startCh:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("drand daemon did not stop")
+	}
+}
+
 func TestUtilCheck(t *testing.T) {
 	tmp, err := ioutil.TempDir("", "drand-cli-*")
 	require.NoError(t, err)
@@ -175,10 +1383,9 @@ func TestUtilCheck(t *testing.T) {
 
 	listenPort := test.FreePort()
 	listenAddr := "127.0.0.1:" + listenPort
-	listen := []string{"drand", "start", "--tls-disable", "--private-listen", listenAddr, "--folder", tmp}
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-	go CLI().RunContext(ctx, listen)
+	ctrlPort := test.FreePort()
+	listen := []string{"drand", "start", "--tls-disable", "--private-listen", listenAddr, "--folder", tmp, "--control", ctrlPort}
+	go CLI().Run(listen)
 	// XXX can we maybe try to bind continuously to not having to wait
 	time.Sleep(200 * time.Millisecond)
 
@@ -187,12 +1394,13 @@ func TestUtilCheck(t *testing.T) {
 	check := []string{"drand", "util", "check", "--tls-disable", listenAddr}
 	require.Error(t, CLI().Run(check))
 
-	// cancel the daemon and make it listen on the right address
-	cancel()
-	ctx, cancel = context.WithCancel(context.Background())
-	defer cancel()
-	listen = []string{"drand", "start", "--tls-disable", "--folder", tmp, "--control", test.FreePort()}
-	go CLI().RunContext(ctx, listen)
+	// stop the daemon and make it listen on the right address
+	CLI().Run([]string{"drand", "stop", "--control", ctrlPort})
+	waitStoreUnlocked(t, tmp).Close()
+	ctrlPort = test.FreePort()
+	listen = []string{"drand", "start", "--tls-disable", "--folder", tmp, "--control", ctrlPort}
+	go CLI().Run(listen)
+	defer CLI().Run([]string{"drand", "stop", "--control", ctrlPort})
 	time.Sleep(200 * time.Millisecond)
 
 	check = []string{"drand", "util", "check", "--verbose", "--tls-disable", keyAddr}
@@ -219,8 +1427,10 @@ func TestStartWithoutGroup(t *testing.T) {
 	require.NoError(t, key.Save(pubPath, priv.Public, false))
 
 	config := core.NewConfig(core.WithConfigFolder(tmpPath))
-	fileStore := key.NewFileStore(config.ConfigFolder())
+	fileStore, err := key.NewFileStore(config.ConfigFolder())
+	require.NoError(t, err)
 	require.NoError(t, fileStore.SaveKeyPair(priv))
+	require.NoError(t, fileStore.Close())
 
 	startArgs := []string{
 		"drand",
@@ -240,6 +1450,7 @@ func TestStartWithoutGroup(t *testing.T) {
 	initDKGArgs := []string{"drand", "share", "--control", ctrlPort1}
 	require.Error(t, CLI().Run(initDKGArgs))
 	CLI().Run([]string{"drand", "stop", "--control", ctrlPort1})
+	fileStore = waitStoreUnlocked(t, config.ConfigFolder())
 
 	fmt.Println(" --- DRAND GROUP ---")
 	// fake group
@@ -262,13 +1473,14 @@ func TestStartWithoutGroup(t *testing.T) {
 	groupPath := path.Join(tmpPath, "drand_group.toml")
 	require.NoError(t, key.Save(groupPath, group, false))
 	// save it also to somewhere drand will find it
-	require.NoError(t, fileStore.SaveGroup(group))
+	require.NoError(t, fileStore.SaveGroup(group, false))
 
 	// fake share
 	scalarOne := key.KeyGroup.Scalar().One()
 	s := &share.PriShare{I: 2, V: scalarOne}
 	fakeShare := &key.Share{Share: s}
 	require.NoError(t, fileStore.SaveShare(fakeShare))
+	require.NoError(t, fileStore.Close())
 
 	fmt.Println(" --- DRAND START --- control ", ctrlPort2)
 
@@ -277,10 +1489,25 @@ func TestStartWithoutGroup(t *testing.T) {
 	defer CLI().Run([]string{"drand", "stop", "--control", ctrlPort2})
 	time.Sleep(500 * time.Millisecond)
 
-	testStartedDrandFunctional(t, ctrlPort2, tmpPath, priv.Public.Address(), group, fileStore)
+	testStartedDrandFunctional(t, ctrlPort2, tmpPath, priv.Public.Address(), group)
 }
 
-func testStartedDrandFunctional(t *testing.T, ctrlPort, rootPath, address string, group *key.Group, fileStore key.Store) {
+// waitStoreUnlocked opens the key store in the given folder once the daemon
+// using it has released it.
+func waitStoreUnlocked(t *testing.T, folder string) key.Store {
+	for i := 0; i < 50; i++ {
+		fileStore, err := key.NewFileStore(folder)
+		if err == nil {
+			return fileStore
+		}
+		require.True(t, errors.Is(err, key.ErrStoreLocked), err)
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Fatal("key store still locked by the daemon")
+	return nil
+}
+
+func testStartedDrandFunctional(t *testing.T, ctrlPort, rootPath, address string, group *key.Group) {
 	fmt.Println(" + running PING command with ", ctrlPort)
 	var err error
 	for i := 0; i < 3; i++ {
@@ -293,6 +1520,15 @@ func testStartedDrandFunctional(t *testing.T, ctrlPort, rootPath, address string
 	}
 	require.NoError(t, err)
 
+	// the node proves it holds the key of its identity, and only that one
+	pubPath := path.Join(rootPath, "pub.key")
+	verify := []string{"drand", "util", "ping", "--control", ctrlPort, "--verify-identity", pubPath}
+	testCommand(t, verify, "identity of "+address+" verified")
+	otherPath := path.Join(rootPath, "other.key")
+	require.NoError(t, key.Save(otherPath, key.NewKeyPair(address).Public, false))
+	verify = []string{"drand", "util", "ping", "--control", ctrlPort, "--verify-identity", otherPath}
+	require.Error(t, CLI().Run(verify))
+
 	require.NoError(t, toml.NewEncoder(os.Stdout).Encode(group))
 
 	groupPath := path.Join(rootPath, "drand_group.toml")
@@ -329,7 +1565,48 @@ func testStartedDrandFunctional(t *testing.T, ctrlPort, rootPath, address string
 	expectedOutput = fmt.Sprintf("%x", chain.NewChainInfo(group).Hash())
 	testCommand(t, showChainInfo, expectedOutput)
 
-	// reset state
+	exportPath := path.Join(rootPath, "exported_group.json")
+	exportGroup := []string{"drand", "util", "export-group", "--control", ctrlPort, "--out", exportPath,
+		"--beacon-id", hex.EncodeToString(group.Hash())}
+	require.NoError(t, CLI().Run(exportGroup))
+	exported, err := ioutil.ReadFile(exportPath)
+	require.NoError(t, err)
+	exportedPacket := new(drand.GroupPacket)
+	require.NoError(t, json.Unmarshal(exported, exportedPacket))
+	exportedGroup, err := key.GroupFromProto(exportedPacket)
+	require.NoError(t, err)
+	require.Equal(t, group.Hash(), exportedGroup.Hash())
+	exportGroup = []string{"drand", "util", "export-group", "--control", ctrlPort, "--beacon-id", "deadbeef"}
+	require.Error(t, CLI().Run(exportGroup))
+
+	roundTime := time.Unix(group.GenesisTime, 0).Add(2*group.Period + time.Second)
+	roundAt := []string{"drand", "util", "round-at", "--control", ctrlPort, "--time", roundTime.Format(time.RFC3339)}
+	testCommand(t, roundAt, "3")
+	roundAt = []string{"drand", "util", "round-at", "--control", ctrlPort, "--time", "yesterday"}
+	require.Error(t, CLI().Run(roundAt))
+
+	// the daemon is not frozen
+	err = CLI().Run([]string{"drand", "util", "unfreeze", "--control", ctrlPort})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not frozen")
+
+	peer := group.Nodes[2].Address()
+	exclude := []string{"drand", "util", "peer", "exclude", "--control", ctrlPort, "--addr", peer}
+	testCommand(t, exclude, peer+" excluded")
+	peers, err := ioutil.ReadFile(path.Join(rootPath, beacon.PeersFileName))
+	require.NoError(t, err)
+	require.Contains(t, string(peers), peer)
+	exclude = []string{"drand", "util", "peer", "exclude", "--control", ctrlPort, "--addr", "127.0.0.1:1"}
+	require.Error(t, CLI().Run(exclude))
+	include := []string{"drand", "util", "peer", "include", "--control", ctrlPort, "--addr", peer}
+	testCommand(t, include, peer+" included")
+	peers, err = ioutil.ReadFile(path.Join(rootPath, beacon.PeersFileName))
+	require.NoError(t, err)
+	require.NotContains(t, string(peers), peer)
+
+	// reset state, which requires the daemon to release the key store
+	CLI().Run([]string{"drand", "stop", "--control", ctrlPort})
+	waitStoreUnlocked(t, rootPath).Close()
 	resetCmd := []string{"drand", "util", "reset", "--folder", rootPath}
 	r, w, err := os.Pipe()
 	require.NoError(t, err)
@@ -337,6 +1614,9 @@ func testStartedDrandFunctional(t *testing.T, ctrlPort, rootPath, address string
 	require.NoError(t, err)
 	os.Stdin = r
 	require.NoError(t, CLI().Run(resetCmd))
+	fileStore, err := key.NewFileStore(rootPath)
+	require.NoError(t, err)
+	defer fileStore.Close()
 	_, err = fileStore.LoadShare()
 	require.Error(t, err)
 	_, err = fileStore.LoadGroup()
@@ -362,7 +1642,8 @@ func TestClientTLS(t *testing.T) {
 	require.NoError(t, key.Save(pubPath, priv.Public, false))
 
 	config := core.NewConfig(core.WithConfigFolder(tmpPath))
-	fileStore := key.NewFileStore(config.ConfigFolder())
+	fileStore, err := key.NewFileStore(config.ConfigFolder())
+	require.NoError(t, err)
 	fileStore.SaveKeyPair(priv)
 
 	if httpscerts.Check(certPath, keyPath) != nil {
@@ -388,7 +1669,7 @@ func TestClientTLS(t *testing.T) {
 	group.Period = 2 * time.Minute
 	group.GenesisTime = time.Now().Unix()
 	group.PublicKey = distKey
-	require.NoError(t, fileStore.SaveGroup(group))
+	require.NoError(t, fileStore.SaveGroup(group, false))
 	require.NoError(t, key.Save(groupPath, group, false))
 
 	// fake share
@@ -396,6 +1677,7 @@ func TestClientTLS(t *testing.T) {
 	s := &share.PriShare{I: 2, V: scalarOne}
 	fakeShare := &key.Share{Share: s}
 	fileStore.SaveShare(fakeShare)
+	require.NoError(t, fileStore.Close())
 
 	startArgs := []string{
 		"drand",
@@ -406,6 +1688,7 @@ func TestClientTLS(t *testing.T) {
 		"--folder", tmpPath,
 		"--metrics", metricsPort,
 		"--private-rand",
+		"--beacon-id-prefix", "acme",
 	}
 	go CLI().Run(startArgs)
 	defer CLI().Run([]string{"drand", "stop", "--control", ctrlPort})
@@ -455,6 +1738,41 @@ func testStartedTLSDrandFunctional(t *testing.T, ctrlPort, certPath, groupPath s
 	showHash := []string{"drand", "show", "group", "--control", ctrlPort, "--hash"}
 	groupHash := hex.EncodeToString(group.Hash())
 	testCommand(t, showHash, groupHash)
+
+	var buff bytes.Buffer
+	output = &buff
+	defer func() { output = os.Stdout }()
+	require.NoError(t, CLI().Run([]string{"drand", "util", "show", "--control", ctrlPort, "--json"}))
+	var summary beaconSummary
+	require.NoError(t, json.Unmarshal(buff.Bytes(), &summary))
+	beaconID := "acme-" + groupHash
+	require.Equal(t, beaconID, summary.ID)
+	require.Equal(t, hex.EncodeToString(chain.NewChainInfo(group).Hash()), summary.ChainHash)
+	require.Equal(t, group.Threshold, summary.Threshold)
+	require.Equal(t, group.Len(), summary.Nodes)
+	require.Equal(t, int64(group.Period.Seconds()), summary.Period)
+	require.Equal(t, priv.Public.Fingerprint(), summary.Fingerprint)
+	node := group.Find(priv.Public)
+	require.NotNil(t, node)
+	require.Equal(t, int(node.Index), summary.Index)
+
+	buff.Reset()
+	require.NoError(t, CLI().Run([]string{"drand", "util", "show", "--control", ctrlPort}))
+	require.Contains(t, buff.String(), "beacon id:        "+beaconID)
+	require.Contains(t, buff.String(), fmt.Sprintf("threshold:        %d of %d nodes", group.Threshold, group.Len()))
+
+	err = CLI().Run([]string{"drand", "util", "show", "--control", ctrlPort, "--beacon-id", "abcd"})
+	require.Error(t, err)
+	require.NoError(t, CLI().Run([]string{"drand", "util", "show", "--control", ctrlPort, "--beacon-id", beaconID}))
+	err = CLI().Run([]string{"drand", "util", "show", "--control", ctrlPort, "--beacon-id", groupHash})
+	require.Error(t, err)
+
+	buff.Reset()
+	require.NoError(t, CLI().Run([]string{"drand", "list-beacons", "--control", ctrlPort}))
+	require.True(t, strings.HasPrefix(buff.String(), beaconID+"\t"))
+	buff.Reset()
+	require.NoError(t, CLI().Run([]string{"drand", "list-beacons", "--control", ctrlPort, "--strip-prefix"}))
+	require.True(t, strings.HasPrefix(buff.String(), groupHash+"\t"))
 }
 
 func testCommand(t *testing.T, args []string, exp string) {
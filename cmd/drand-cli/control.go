@@ -1,20 +1,27 @@
 package drand
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync/atomic"
 	"time"
 
+	"github.com/BurntSushi/toml"
 	"github.com/briandowns/spinner"
 	"github.com/drand/drand/chain"
 	"github.com/drand/drand/core"
 	"github.com/drand/drand/key"
 	"github.com/drand/drand/net"
 	control "github.com/drand/drand/protobuf/drand"
+	proto "github.com/golang/protobuf/proto"
 
 	json "github.com/nikkolasg/hexjson"
 	"github.com/urfave/cli/v2"
@@ -23,13 +30,15 @@ import (
 const minimumShareSecretLength = 32
 
 type shareArgs struct {
-	secret    string
-	isTLS     bool
-	timeout   time.Duration
-	threshold int
-	entropy   *control.EntropyInfo
-	force     bool
-	conf      *core.Config
+	secret      string
+	isTLS       bool
+	timeout     time.Duration
+	waitTimeout time.Duration
+	threshold   int
+	entropy     *control.EntropyInfo
+	force       bool
+	retries     int
+	conf        *core.Config
 }
 
 func (s *shareArgs) loadSecret(c *cli.Context) error {
@@ -58,13 +67,18 @@ func getShareArgs(c *cli.Context) (*shareArgs, error) {
 		return nil, err
 	}
 
-	args.isTLS = !c.IsSet(insecureFlag.Name)
+	args.isTLS = !c.Bool(insecureFlag.Name)
 
 	args.timeout, err = getTimeout(c)
 	if err != nil {
 		return nil, err
 	}
 
+	args.waitTimeout = c.Duration(waitTimeoutFlag.Name)
+	if args.waitTimeout < 0 {
+		return nil, fmt.Errorf("--%s must be positive", waitTimeoutFlag.Name)
+	}
+
 	args.threshold, err = getThreshold(c)
 	if err != nil {
 		return nil, err
@@ -72,6 +86,11 @@ func getShareArgs(c *cli.Context) (*shareArgs, error) {
 
 	args.force = c.Bool(forceFlag.Name)
 
+	args.retries = c.Int(retryOnFailureFlag.Name)
+	if args.retries < 0 || args.retries > core.MaxDKGRetries {
+		return nil, fmt.Errorf("--%s must be between 0 and %d", retryOnFailureFlag.Name, core.MaxDKGRetries)
+	}
+
 	if c.IsSet(userEntropyOnlyFlag.Name) && !c.IsSet(sourceFlag.Name) {
 		fmt.Print("drand: userEntropyOnly needs to be used with the source flag, which is not specified here. userEntropyOnly flag is ignored.")
 	}
@@ -86,7 +105,30 @@ func getShareArgs(c *cli.Context) (*shareArgs, error) {
 }
 
 func shareCmd(c *cli.Context) error {
-	if c.IsSet(transitionFlag.Name) || c.IsSet(oldGroupFlag.Name) {
+	if c.Bool(abortFlag.Name) {
+		return abortShareCmd(c)
+	}
+	if err := checkShareRole(c); err != nil {
+		return err
+	}
+	if c.IsSet(groupSignatureFlag.Name) || c.IsSet(leaderKeyFlag.Name) {
+		if c.Bool(leaderFlag.Name) || !c.IsSet(oldGroupFlag.Name) || !c.IsSet(groupSignatureFlag.Name) {
+			return fmt.Errorf("--%s and --%s verify the group file a follower gives with --%s",
+				groupSignatureFlag.Name, leaderKeyFlag.Name, oldGroupFlag.Name)
+		}
+	}
+	if c.IsSet(keyFileFlag.Name) {
+		if err := useKeyFile(c); err != nil {
+			return err
+		}
+	}
+	if c.Bool(observerFlag.Name) {
+		return observeShareCmd(c)
+	}
+	if c.Bool(joinExistingFlag.Name) {
+		return joinExistingCmd(c)
+	}
+	if c.IsSet(transitionFlag.Name) || c.IsSet(oldGroupFlag.Name) || c.IsSet(fromGroupHashFlag.Name) {
 		return reshareCmd(c)
 	}
 
@@ -104,13 +146,13 @@ func shareCmd(c *cli.Context) error {
 	coordAddress := c.String(connectFlag.Name)
 	connectPeer := net.CreatePeer(coordAddress, args.isTLS)
 
-	ctrlClient, err := net.NewControlClient(args.conf.ControlPort())
+	ctrlClient, err := newControlClient(c, args.conf.ControlPort())
 	if err != nil {
 		return fmt.Errorf("could not create client: %v", err)
 	}
 
 	fmt.Fprintln(output, "Participating to the setup of the DKG")
-	groupP, shareErr := ctrlClient.InitDKG(connectPeer, args.entropy, args.secret)
+	groupP, shareErr := ctrlClient.InitDKG(connectPeer, args.entropy, args.secret, args.retries)
 
 	if shareErr != nil {
 		return fmt.Errorf("error setting up the network: %v", shareErr)
@@ -122,6 +164,94 @@ func shareCmd(c *cli.Context) error {
 	return groupOut(c, group)
 }
 
+// abortShareCmd abandons the DKG or resharing in progress on the node.
+func abortShareCmd(c *cli.Context) error {
+	for _, f := range []cli.Flag{leaderFlag, followerFlag, observerFlag} {
+		if c.IsSet(f.Names()[0]) {
+			return fmt.Errorf("--%s takes no role, got --%s", abortFlag.Name, f.Names()[0])
+		}
+	}
+	client, err := controlClient(c)
+	if err != nil {
+		return err
+	}
+	if err := client.AbortDKG(); err != nil {
+		return fmt.Errorf("drand: can't abort the dkg: %w", err)
+	}
+	fmt.Fprintln(output, "DKG aborted")
+	return nil
+}
+
+// useKeyFile makes the daemon read its private key from the file given with
+// --key-file for the DKG.
+func useKeyFile(c *cli.Context) error {
+	// the daemon may run from another directory
+	keyFile, err := filepath.Abs(c.String(keyFileFlag.Name))
+	if err != nil {
+		return err
+	}
+	client, err := controlClient(c)
+	if err != nil {
+		return err
+	}
+	resp, err := client.UseKeyFile(keyFile)
+	if err != nil {
+		return fmt.Errorf("drand: can't use the key file: %w", err)
+	}
+	fmt.Fprintf(output, "Using the key of %s: %x\n", keyFile, resp.GetIdentity().GetKey())
+	return nil
+}
+
+// checkShareRole makes sure exactly one of the leader, follower and observer
+// roles is given to the share command.
+func checkShareRole(c *cli.Context) error {
+	leader, follower, observer := c.Bool(leaderFlag.Name), c.Bool(followerFlag.Name), c.Bool(observerFlag.Name)
+	if leader && follower {
+		return fmt.Errorf("--%s and --%s are mutually exclusive", leaderFlag.Name, followerFlag.Name)
+	}
+	if observer && (leader || follower) {
+		return fmt.Errorf("--%s is mutually exclusive with --%s and --%s", observerFlag.Name, leaderFlag.Name, followerFlag.Name)
+	}
+	if !leader && !follower && !observer {
+		return fmt.Errorf("share needs the role of this node: --%s to create the group "+
+			"and start the DKG, --%s to join the setup of the leader given with --%s, "+
+			"or --%s to observe it",
+			leaderFlag.Name, followerFlag.Name, connectFlag.Name, observerFlag.Name)
+	}
+	return nil
+}
+
+// observeShareCmd joins the setup of the leader as an observer of the DKG.
+func observeShareCmd(c *cli.Context) error {
+	for _, f := range []cli.Flag{transitionFlag, oldGroupFlag, fromGroupHashFlag, joinExistingFlag} {
+		if c.IsSet(f.Names()[0]) {
+			return fmt.Errorf("--%s can only observe a fresh DKG, not --%s", observerFlag.Name, f.Names()[0])
+		}
+	}
+	if !c.IsSet(connectFlag.Name) {
+		return fmt.Errorf("need the address of the coordinator to observe the DKG")
+	}
+	args, err := getShareArgs(c)
+	if err != nil {
+		return err
+	}
+	connectPeer := net.CreatePeer(c.String(connectFlag.Name), args.isTLS)
+	ctrlClient, err := newControlClient(c, args.conf.ControlPort())
+	if err != nil {
+		return fmt.Errorf("could not create client: %v", err)
+	}
+	fmt.Fprintln(output, "Observing the setup of the DKG")
+	groupP, err := ctrlClient.ObserveDKG(connectPeer, args.secret)
+	if err != nil {
+		return fmt.Errorf("error observing the DKG: %v", err)
+	}
+	group, err := key.GroupFromProto(groupP)
+	if err != nil {
+		return fmt.Errorf("error interpreting the group from protobuf: %v", err)
+	}
+	return groupOut(c, group)
+}
+
 func leadShareCmd(c *cli.Context) error {
 	if !c.IsSet(thresholdFlag.Name) || !c.IsSet(shareNodeFlag.Name) {
 		return fmt.Errorf("leader needs to specify --nodes and --threshold for sharing")
@@ -137,7 +267,7 @@ func leadShareCmd(c *cli.Context) error {
 		fmt.Fprintln(output, "Warning: less than 2 nodes is an unsupported, degenerate mode.")
 	}
 
-	ctrlClient, err := net.NewControlClient(args.conf.ControlPort())
+	ctrlClient, err := newControlClient(c, args.conf.ControlPort())
 	if err != nil {
 		return fmt.Errorf("could not create client: %v", err)
 	}
@@ -150,6 +280,9 @@ func leadShareCmd(c *cli.Context) error {
 	if err != nil {
 		return fmt.Errorf("period given is invalid: %v", err)
 	}
+	if err := key.ValidatePeriod(period); err != nil {
+		return err
+	}
 	catchupPeriod := time.Duration(0)
 	catchupPeriodStr := c.String(catchupPeriodFlag.Name)
 	if catchupPeriod, err = time.ParseDuration(catchupPeriodStr); err != nil {
@@ -160,12 +293,26 @@ func leadShareCmd(c *cli.Context) error {
 	if c.IsSet(beaconOffset.Name) {
 		offset = c.Int(beaconOffset.Name)
 	}
+	var genesisTime int64
+	if c.IsSet(genesisTimeFlag.Name) {
+		if c.IsSet(beaconOffset.Name) {
+			return fmt.Errorf("--%s and --%s are mutually exclusive", genesisTimeFlag.Name, beaconOffset.Name)
+		}
+		genesis, err := time.Parse(time.RFC3339, c.String(genesisTimeFlag.Name))
+		if err != nil {
+			return fmt.Errorf("genesis time given is invalid: %v", err)
+		}
+		if time.Until(genesis) < period {
+			return fmt.Errorf("genesis time %s must be at least one period (%s) in the future", genesis.Format(time.RFC3339), period)
+		}
+		genesisTime = genesis.Unix()
+	}
 	fmt.Fprintln(output, "Initiating the DKG as a leader")
 	fmt.Fprintln(output, "You can stop the command at any point. If so, the group "+
 		"file will not be written out to the specified output. To get the "+
 		"group file once the setup phase is done, you can run the `drand show "+
 		"group` command")
-	groupP, shareErr := ctrlClient.InitDKGLeader(nodes, args.threshold, period, catchupPeriod, args.timeout, args.entropy, args.secret, offset)
+	groupP, shareErr := ctrlClient.InitDKGLeader(nodes, args.threshold, period, catchupPeriod, args.timeout, args.waitTimeout, args.entropy, args.secret, offset, genesisTime, args.force, args.retries)
 
 	if shareErr != nil {
 		return fmt.Errorf("error setting up the network: %v", shareErr)
@@ -178,6 +325,10 @@ func leadShareCmd(c *cli.Context) error {
 }
 
 func reshareCmd(c *cli.Context) error {
+	chainHash, err := getFromGroupHash(c)
+	if err != nil {
+		return err
+	}
 	if c.Bool(leaderFlag.Name) {
 		return leadReshareCmd(c)
 	}
@@ -190,10 +341,13 @@ func reshareCmd(c *cli.Context) error {
 	if !c.IsSet(connectFlag.Name) {
 		return fmt.Errorf("need to the address of the coordinator to create the group file")
 	}
+	if err := checkGroupSignature(c, args.conf); err != nil {
+		return err
+	}
 	coordAddress := c.String(connectFlag.Name)
 	connectPeer := net.CreatePeer(coordAddress, args.isTLS)
 
-	ctrlClient, err := net.NewControlClient(args.conf.ControlPort())
+	ctrlClient, err := newControlClient(c, args.conf.ControlPort())
 	if err != nil {
 		return fmt.Errorf("could not create client: %v", err)
 	}
@@ -212,7 +366,70 @@ func reshareCmd(c *cli.Context) error {
 	}
 
 	fmt.Fprintln(output, "Participating to the resharing")
-	groupP, shareErr := ctrlClient.InitReshare(connectPeer, args.secret, oldPath, args.force)
+	var groupP *control.GroupPacket
+	var shareErr error
+	if chainHash != nil {
+		groupP, shareErr = ctrlClient.ReshareFromChain(connectPeer, args.secret, chainHash, args.force, false, args.retries)
+	} else {
+		groupP, shareErr = ctrlClient.InitReshare(connectPeer, args.secret, oldPath, args.force, args.retries)
+	}
+	if shareErr != nil {
+		return fmt.Errorf("error setting up the network: %v", shareErr)
+	}
+	group, err := key.GroupFromProto(groupP)
+	if err != nil {
+		return fmt.Errorf("error interpreting the group from protobuf: %v", err)
+	}
+	return groupOut(c, group)
+}
+
+// joinExistingCmd lets a node that is not part of the current group join the
+// chain: it announces itself to the leader and then waits for the leader to
+// start a resharing that includes it, e.g. with
+// drand share --leader --transition --nodes <current nodes + 1> ...
+// The joining node itself runs drand share --follower --join-existing.
+func joinExistingCmd(c *cli.Context) error {
+	if c.Bool(leaderFlag.Name) || c.IsSet(transitionFlag.Name) {
+		return fmt.Errorf("--%s can not be used with --%s or --%s", joinExistingFlag.Name, leaderFlag.Name, transitionFlag.Name)
+	}
+	if !c.IsSet(connectFlag.Name) {
+		return fmt.Errorf("need the address of the leader of the existing group with --%s", connectFlag.Name)
+	}
+	chainHash, err := getFromGroupHash(c)
+	if err != nil {
+		return err
+	}
+	if chainHash == nil && !c.IsSet(oldGroupFlag.Name) {
+		return fmt.Errorf("need the current group file of the existing chain with --%s or its hash with --%s", oldGroupFlag.Name, fromGroupHashFlag.Name)
+	}
+	args, err := getShareArgs(c)
+	if err != nil {
+		return err
+	}
+	oldPath := c.String(oldGroupFlag.Name)
+	if chainHash == nil {
+		if err := key.Load(oldPath, new(key.Group)); err != nil {
+			return fmt.Errorf("could not load drand from path: %s", err)
+		}
+	}
+	if err := checkGroupSignature(c, args.conf); err != nil {
+		return err
+	}
+	connectPeer := net.CreatePeer(c.String(connectFlag.Name), args.isTLS)
+
+	ctrlClient, err := newControlClient(c, args.conf.ControlPort())
+	if err != nil {
+		return fmt.Errorf("could not create client: %v", err)
+	}
+
+	fmt.Fprintln(output, "Announcing the node to the leader and waiting to be included in the next resharing")
+	var groupP *control.GroupPacket
+	var shareErr error
+	if chainHash != nil {
+		groupP, shareErr = ctrlClient.ReshareFromChain(connectPeer, args.secret, chainHash, args.force, true, args.retries)
+	} else {
+		groupP, shareErr = ctrlClient.JoinReshare(connectPeer, args.secret, oldPath, args.force, args.retries)
+	}
 	if shareErr != nil {
 		return fmt.Errorf("error setting up the network: %v", shareErr)
 	}
@@ -223,6 +440,137 @@ func reshareCmd(c *cli.Context) error {
 	return groupOut(c, group)
 }
 
+// getFromGroupHash returns the chain hash given with --from-group-hash, or nil
+// if the flag is not set. The group of the chain is then fetched from the
+// leader, so the flag can not be used by the leader itself nor along with
+// another way of giving the old group.
+func getFromGroupHash(c *cli.Context) ([]byte, error) {
+	if !c.IsSet(fromGroupHashFlag.Name) {
+		return nil, nil
+	}
+	if c.Bool(leaderFlag.Name) || c.IsSet(oldGroupFlag.Name) || c.IsSet(transitionFlag.Name) {
+		return nil, fmt.Errorf("--%s can not be used with --%s, --%s or --%s",
+			fromGroupHashFlag.Name, leaderFlag.Name, oldGroupFlag.Name, transitionFlag.Name)
+	}
+	// the hash does not cover the nodes of the group, only TLS authenticates
+	// the leader sending them
+	if c.Bool(insecureFlag.Name) {
+		return nil, fmt.Errorf("--%s can not be used with --%s", fromGroupHashFlag.Name, insecureFlag.Name)
+	}
+	chainHash, err := hex.DecodeString(c.String(fromGroupHashFlag.Name))
+	if err != nil || len(chainHash) == 0 {
+		return nil, fmt.Errorf("invalid hash given with --%s: %q", fromGroupHashFlag.Name, c.String(fromGroupHashFlag.Name))
+	}
+	return chainHash, nil
+}
+
+// checkGroupSignature verifies the group file given with --from is signed by
+// the leader when --group-signature is set.
+func checkGroupSignature(c *cli.Context, conf *core.Config) error {
+	if !c.IsSet(groupSignatureFlag.Name) {
+		return nil
+	}
+	signature, err := hex.DecodeString(c.String(groupSignatureFlag.Name))
+	if err != nil || len(signature) == 0 {
+		return fmt.Errorf("invalid signature given with --%s", groupSignatureFlag.Name)
+	}
+	leader, err := getLeaderKey(c, conf)
+	if err != nil {
+		return err
+	}
+	return verifyGroupSignature(c.String(oldGroupFlag.Name), signature, leader)
+}
+
+// getLeaderKey returns the identity of the leader read from --leader-key, or
+// the node at the address given by --connect in the group of the running
+// daemon.
+func getLeaderKey(c *cli.Context, conf *core.Config) (*key.Identity, error) {
+	if c.IsSet(leaderKeyFlag.Name) {
+		leader := new(key.Identity)
+		if err := key.Load(c.String(leaderKeyFlag.Name), leader); err != nil {
+			return nil, fmt.Errorf("could not load the leader key: %w", err)
+		}
+		return leader, nil
+	}
+	// the daemon holds the lock of the key store, so the group is asked to it
+	client, err := newControlClient(c, conf.ControlPort())
+	if err != nil {
+		return nil, fmt.Errorf("could not create client: %w", err)
+	}
+	r, err := client.GroupFile()
+	if err != nil {
+		return nil, fmt.Errorf("no group from the daemon to find the leader key, use --%s: %w", leaderKeyFlag.Name, err)
+	}
+	group, err := key.GroupFromProto(r)
+	if err != nil {
+		return nil, err
+	}
+	addr := c.String(connectFlag.Name)
+	for _, n := range group.Nodes {
+		if n.Address() == addr {
+			return n.Identity, nil
+		}
+	}
+	return nil, fmt.Errorf("leader %s not in the group of the daemon, use --%s", addr, leaderKeyFlag.Name)
+}
+
+// verifyGroupSignature checks the signature is the one of the leader over the
+// SHA256 of the group file.
+func verifyGroupSignature(groupPath string, signature []byte, leader *key.Identity) error {
+	hashed, err := hashGroupFile(groupPath)
+	if err != nil {
+		return err
+	}
+	if err := key.DKGAuthScheme.Verify(leader.Key, hashed, signature); err != nil {
+		return fmt.Errorf("group file %s is not signed by the leader: %w", groupPath, err)
+	}
+	return nil
+}
+
+// hashGroupFile returns the SHA256 of the content of the group file, which is
+// what the leader signs for --group-signature.
+func hashGroupFile(groupPath string) ([]byte, error) {
+	content, err := ioutil.ReadFile(groupPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the group file: %w", err)
+	}
+	hashed := sha256.Sum256(content)
+	return hashed[:], nil
+}
+
+// signGroupCmd prints the hex encoded signature of the given group file by
+// the key of the running daemon, to give to the followers with
+// --group-signature.
+func signGroupCmd(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("sign-group takes the path of the group file to sign")
+	}
+	hashed, err := hashGroupFile(c.Args().First())
+	if err != nil {
+		return err
+	}
+	client, err := controlClient(c)
+	if err != nil {
+		return err
+	}
+	// the daemon holds the lock of the key store, and it knows the key file
+	// set with share --key-file, if any
+	resp, err := client.PrivateKey()
+	if err != nil {
+		return fmt.Errorf("could not request drand.private: %w", err)
+	}
+	priv := key.KeyGroup.Scalar()
+	if err := priv.UnmarshalBinary(resp.GetPriKey()); err != nil {
+		return fmt.Errorf("invalid private key from the daemon: %w", err)
+	}
+	signature, err := key.DKGAuthScheme.Sign(priv, hashed)
+	if err != nil {
+		return fmt.Errorf("signing the group file: %w", err)
+	}
+	fmt.Fprintln(output, hex.EncodeToString(signature))
+	return nil
+}
+
 func leadReshareCmd(c *cli.Context) error {
 	args, err := getShareArgs(c)
 	if err != nil {
@@ -235,7 +583,7 @@ func leadReshareCmd(c *cli.Context) error {
 
 	nodes := c.Int(shareNodeFlag.Name)
 
-	ctrlClient, err := net.NewControlClient(args.conf.ControlPort())
+	ctrlClient, err := newControlClient(c, args.conf.ControlPort())
 	if err != nil {
 		return fmt.Errorf("could not create client: %v", err)
 	}
@@ -265,7 +613,7 @@ func leadReshareCmd(c *cli.Context) error {
 		}
 	}
 	fmt.Fprintln(output, "Initiating the resharing as a leader")
-	groupP, shareErr := ctrlClient.InitReshareLeader(nodes, args.threshold, args.timeout, catchupPeriod, args.secret, oldPath, offset)
+	groupP, shareErr := ctrlClient.InitReshareLeader(nodes, args.threshold, args.timeout, catchupPeriod, args.waitTimeout, args.secret, oldPath, offset, args.force, args.retries)
 
 	if shareErr != nil {
 		return fmt.Errorf("error setting up the network: %v", shareErr)
@@ -277,7 +625,14 @@ func leadReshareCmd(c *cli.Context) error {
 	return groupOut(c, group)
 }
 
+// getTimeout returns the duration of each DKG phase
 func getTimeout(c *cli.Context) (timeout time.Duration, err error) {
+	if c.IsSet(timeoutPhaseFlag.Name) {
+		if c.IsSet(timeoutFlag.Name) {
+			return 0, fmt.Errorf("--%s and --%s can not be used together", timeoutFlag.Name, timeoutPhaseFlag.Name)
+		}
+		return c.Duration(timeoutPhaseFlag.Name), nil
+	}
 	if c.IsSet(timeoutFlag.Name) {
 		str := c.String(timeoutFlag.Name)
 		return time.ParseDuration(str)
@@ -294,6 +649,194 @@ func pingpongCmd(c *cli.Context) error {
 		return fmt.Errorf("drand: can't ping the daemon ... %s", err)
 	}
 	fmt.Fprintf(output, "drand daemon is alive on port %s", controlPort(c))
+	if !c.IsSet(verifyIdentityFlag.Name) {
+		return nil
+	}
+	id := new(key.Identity)
+	if err := key.Load(c.String(verifyIdentityFlag.Name), id); err != nil {
+		return fmt.Errorf("drand: can't load identity: %s", err)
+	}
+	if err := verifyHomeIdentity(contextToConfig(c), id); err != nil {
+		return fmt.Errorf("drand: can't verify identity of %s: %s", id.Address(), err)
+	}
+	fmt.Fprintf(output, "\nidentity of %s verified", id.Address())
+	return nil
+}
+
+func statusCmd(c *cli.Context) error {
+	client, err := controlClient(c)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Status()
+	if err != nil {
+		return fmt.Errorf("could not request status: %s", err)
+	}
+	if !resp.GetCatchingUp() {
+		fmt.Fprintf(output, "drand daemon last stored round: %d\n", resp.GetStoredRound())
+		return nil
+	}
+	var percent float64
+	if resp.GetTargetRound() > 0 {
+		percent = 100 * float64(resp.GetStoredRound()) / float64(resp.GetTargetRound())
+	}
+	fmt.Fprintf(output, "drand daemon catching up: round %d / %d (%.2f %%) - %d bytes downloaded\n",
+		resp.GetStoredRound(), resp.GetTargetRound(), percent, resp.GetBytesDownloaded())
+	return nil
+}
+
+func listBeaconsCmd(c *cli.Context) error {
+	client, err := controlClient(c)
+	if err != nil {
+		return err
+	}
+	resp, err := client.ListProtocols()
+	if err != nil {
+		return fmt.Errorf("could not list beacons: %s", err)
+	}
+	if c.Bool(stripPrefixFlag.Name) {
+		for _, p := range resp.GetProtocols() {
+			if p.GetIdPrefix() != "" {
+				p.Id = strings.TrimPrefix(p.GetId(), p.GetIdPrefix()+"-")
+			}
+		}
+	}
+	if c.Bool(jsonFlag.Name) {
+		return printJSON(resp.GetProtocols())
+	}
+	for _, p := range resp.GetProtocols() {
+		fmt.Fprintf(output, "%s\t%s\tround %d\tperiod %ds\t%s\n",
+			p.GetId(), p.GetStatus(), p.GetRound(), p.GetPeriod(), p.GetVersion())
+	}
+	return nil
+}
+
+// checkBeaconID returns the id of the beacon of the group run by the daemon,
+// with the prefix of the daemon if it has one, and fails if it is not the one
+// given with --beacon-id.
+func checkBeaconID(c *cli.Context, client *net.ControlClient, group *key.Group) (string, error) {
+	id := hex.EncodeToString(group.Hash())
+	resp, err := client.ListProtocols()
+	if err != nil {
+		return "", fmt.Errorf("could not list beacons: %s", err)
+	}
+	for _, p := range resp.GetProtocols() {
+		if strings.HasSuffix(p.GetId(), id) {
+			id = p.GetId()
+		}
+	}
+	if bid := c.String(beaconIDFlag.Name); bid != "" && bid != id {
+		return "", fmt.Errorf("no beacon with id %s", bid)
+	}
+	return id, nil
+}
+
+// beaconSummary gathers what the daemon knows about the beacon it runs
+type beaconSummary struct {
+	ID          string `json:"id"`
+	ChainHash   string `json:"chain_hash"`
+	PublicKey   string `json:"public_key"`
+	GenesisTime int64  `json:"genesis_time"`
+	Period      int64  `json:"period"`
+	Threshold   int    `json:"threshold"`
+	Nodes       int    `json:"nodes"`
+	// Index is the index of the node in the group, or -1 if it is not a member
+	Index          int    `json:"index"`
+	Address        string `json:"address,omitempty"`
+	Fingerprint    string `json:"fingerprint"`
+	CurrentRound   uint64 `json:"current_round"`
+	StoredRound    uint64 `json:"stored_round"`
+	LastBeaconTime int64  `json:"last_beacon_time"`
+	CatchingUp     bool   `json:"catching_up"`
+}
+
+func showBeaconCmd(c *cli.Context) error {
+	client, err := controlClient(c)
+	if err != nil {
+		return err
+	}
+	r, err := client.GroupFile()
+	if err != nil {
+		return fmt.Errorf("fetching group file error: %s", err)
+	}
+	group, err := key.GroupFromProto(r)
+	if err != nil {
+		return err
+	}
+	id, err := checkBeaconID(c, client, group)
+	if err != nil {
+		return err
+	}
+	resp, err := client.ChainInfo()
+	if err != nil {
+		return fmt.Errorf("could not request chain info: %s", err)
+	}
+	info, err := chain.InfoFromProto(resp)
+	if err != nil {
+		return fmt.Errorf("could not get correct chain info: %s", err)
+	}
+	status, err := client.Status()
+	if err != nil {
+		return fmt.Errorf("could not request status: %s", err)
+	}
+	pub, err := client.PublicKey()
+	if err != nil {
+		return fmt.Errorf("could not request public key: %s", err)
+	}
+	pubKey := key.KeyGroup.Point()
+	if err := pubKey.UnmarshalBinary(pub.GetPubKey()); err != nil {
+		return fmt.Errorf("invalid public key: %s", err)
+	}
+	distKey, err := info.PublicKey.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	s := &beaconSummary{
+		ID:           id,
+		ChainHash:    hex.EncodeToString(info.Hash()),
+		PublicKey:    hex.EncodeToString(distKey),
+		GenesisTime:  info.GenesisTime,
+		Period:       int64(info.Period.Seconds()),
+		Threshold:    group.Threshold,
+		Nodes:        group.Len(),
+		Index:        -1,
+		Fingerprint:  (&key.Identity{Key: pubKey}).Fingerprint(),
+		CurrentRound: chain.CurrentRound(time.Now().Unix(), info.Period, info.GenesisTime),
+		StoredRound:  status.GetStoredRound(),
+		CatchingUp:   status.GetCatchingUp(),
+	}
+	for _, node := range group.Nodes {
+		if node.Key.Equal(pubKey) {
+			s.Index = int(node.Index)
+			s.Address = node.Address()
+		}
+	}
+	if s.StoredRound > 0 {
+		s.LastBeaconTime = chain.TimeOfRound(info.Period, info.GenesisTime, s.StoredRound)
+	}
+	if c.Bool(jsonFlag.Name) {
+		return printJSON(s)
+	}
+	fmt.Fprintf(output, "beacon id:        %s\n", s.ID)
+	fmt.Fprintf(output, "chain hash:       %s\n", s.ChainHash)
+	fmt.Fprintf(output, "public key:       %s\n", s.PublicKey)
+	fmt.Fprintf(output, "genesis time:     %s\n", time.Unix(s.GenesisTime, 0).UTC().Format(time.RFC3339))
+	fmt.Fprintf(output, "period:           %s\n", info.Period)
+	fmt.Fprintf(output, "threshold:        %d of %d nodes\n", s.Threshold, s.Nodes)
+	if s.Index >= 0 {
+		fmt.Fprintf(output, "node:             index %d - %s - %s\n", s.Index, s.Address, s.Fingerprint)
+	} else {
+		fmt.Fprintf(output, "node:             not in the group - %s\n", s.Fingerprint)
+	}
+	fmt.Fprintf(output, "current round:    %d\n", s.CurrentRound)
+	fmt.Fprintf(output, "stored round:     %d\n", s.StoredRound)
+	if s.LastBeaconTime > 0 {
+		fmt.Fprintf(output, "last beacon time: %s\n", time.Unix(s.LastBeaconTime, 0).UTC().Format(time.RFC3339))
+	}
+	if s.CatchingUp {
+		fmt.Fprintf(output, "catching up to:   %d\n", status.GetTargetRound())
+	}
 	return nil
 }
 
@@ -313,6 +856,63 @@ func showGroupCmd(c *cli.Context) error {
 	return groupOut(c, group)
 }
 
+// formats in which a group can be exported
+const (
+	groupFormatJSON  = "json"
+	groupFormatTOML  = "toml"
+	groupFormatProto = "proto"
+)
+
+func exportGroupCmd(c *cli.Context) error {
+	client, err := controlClient(c)
+	if err != nil {
+		return err
+	}
+	r, err := client.GroupFile()
+	if err != nil {
+		return fmt.Errorf("fetching group file error: %s", err)
+	}
+	group, err := key.GroupFromProto(r)
+	if err != nil {
+		return err
+	}
+	if _, err := checkBeaconID(c, client, group); err != nil {
+		return err
+	}
+	buff, err := encodeGroup(group, c.String(groupFormatFlag.Name), c.Bool(prettyFlag.Name))
+	if err != nil {
+		return err
+	}
+	if c.IsSet(outFlag.Name) {
+		return ioutil.WriteFile(c.String(outFlag.Name), buff, 0644)
+	}
+	_, err = output.Write(buff)
+	return err
+}
+
+// encodeGroup serializes the group in the given format: JSON with hex-encoded
+// keys, indented if pretty is true, TOML as in group files, or the raw bytes
+// of its protobuf description.
+func encodeGroup(group *key.Group, format string, pretty bool) ([]byte, error) {
+	switch format {
+	case groupFormatJSON:
+		if pretty {
+			return json.MarshalIndent(group.ToProto(), "", "    ")
+		}
+		return json.Marshal(group.ToProto())
+	case groupFormatTOML:
+		var buff bytes.Buffer
+		if err := toml.NewEncoder(&buff).Encode(group.TOML()); err != nil {
+			return nil, fmt.Errorf("drand: can't encode group to TOML: %v", err)
+		}
+		return buff.Bytes(), nil
+	case groupFormatProto:
+		return proto.Marshal(group.ToProto())
+	default:
+		return nil, fmt.Errorf("unknown group format %q", format)
+	}
+}
+
 func showChainInfo(c *cli.Context) error {
 	client, err := controlClient(c)
 	if err != nil {
@@ -329,6 +929,69 @@ func showChainInfo(c *cli.Context) error {
 	return printChainInfo(c, ci)
 }
 
+func unfreezeCmd(c *cli.Context) error {
+	client, err := controlClient(c)
+	if err != nil {
+		return err
+	}
+	if err := client.UnfreezeBeacon(); err != nil {
+		return fmt.Errorf("drand: can't unfreeze the beacon: %w", err)
+	}
+	fmt.Fprintln(output, "beacon generation resumed")
+	return nil
+}
+
+func excludePeerCmd(c *cli.Context) error {
+	client, err := controlClient(c)
+	if err != nil {
+		return err
+	}
+	addr := c.String(peerAddrFlag.Name)
+	if err := client.ExcludePeer(addr, true); err != nil {
+		return fmt.Errorf("drand: can't exclude %s: %w", addr, err)
+	}
+	fmt.Fprintf(output, "%s excluded\n", addr)
+	return nil
+}
+
+func includePeerCmd(c *cli.Context) error {
+	client, err := controlClient(c)
+	if err != nil {
+		return err
+	}
+	addr := c.String(peerAddrFlag.Name)
+	if err := client.ExcludePeer(addr, false); err != nil {
+		return fmt.Errorf("drand: can't include %s: %w", addr, err)
+	}
+	fmt.Fprintf(output, "%s included\n", addr)
+	return nil
+}
+
+func roundAtCmd(c *cli.Context) error {
+	t := time.Now()
+	if c.IsSet(roundTimeFlag.Name) {
+		var err error
+		t, err = time.Parse(time.RFC3339, c.String(roundTimeFlag.Name))
+		if err != nil {
+			return fmt.Errorf("invalid time: %w", err)
+		}
+	}
+	client, err := controlClient(c)
+	if err != nil {
+		return err
+	}
+	resp, err := client.ChainInfo()
+	if err != nil {
+		return fmt.Errorf("could not request chain info: %s", err)
+	}
+	ci, err := chain.InfoFromProto(resp)
+	if err != nil {
+		return fmt.Errorf("could not get correct chain info: %s", err)
+	}
+	fmt.Fprintln(output, chain.RoundAt(t, ci.Period, ci.GenesisTime))
+	return nil
+}
+
 func showPrivateCmd(c *cli.Context) error {
 	client, err := controlClient(c)
 	if err != nil {
@@ -377,7 +1040,12 @@ func backupDBCmd(c *cli.Context) error {
 	return nil
 }
 
+// controlPort returns the address of the control port, or of the control
+// socket when one is given.
 func controlPort(c *cli.Context) string {
+	if c.IsSet(controlSocketFlag.Name) {
+		return "unix://" + c.String(controlSocketFlag.Name)
+	}
 	port := c.String(controlFlag.Name)
 	if port == "" {
 		port = core.DefaultControlPort
@@ -387,7 +1055,7 @@ func controlPort(c *cli.Context) string {
 
 func controlClient(c *cli.Context) (*net.ControlClient, error) {
 	port := controlPort(c)
-	client, err := net.NewControlClient(port)
+	client, err := newControlClient(c, port)
 	if err != nil {
 		return nil, fmt.Errorf("can't instantiate control client: %s", err)
 	}
@@ -420,7 +1088,11 @@ func entropyInfoFromReader(c *cli.Context) (*control.EntropyInfo, error) {
 }
 func selfSign(c *cli.Context) error {
 	conf := contextToConfig(c)
-	fs := key.NewFileStore(conf.ConfigFolder())
+	fs, err := key.NewFileStore(conf.ConfigFolder())
+	if err != nil {
+		return fmt.Errorf("opening key store: %w", err)
+	}
+	defer fs.Close()
 	pair, err := fs.LoadKeyPair()
 	if err != nil {
 		return fmt.Errorf("loading private/public: %s", err)
@@ -1,25 +1,63 @@
 package drand
 
 import (
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/BurntSushi/toml"
 	"github.com/drand/drand/core"
 	"github.com/drand/drand/key"
+	"github.com/drand/drand/log"
 	"github.com/drand/drand/metrics"
 	"github.com/drand/drand/metrics/pprof"
+	"github.com/drand/drand/net"
 	"github.com/urfave/cli/v2"
 )
 
 func startCmd(c *cli.Context) error {
-	conf := contextToConfig(c)
-	fs := key.NewFileStore(conf.ConfigFolder())
+	pidFile := c.String(pidFileFlag.Name)
+	if pidFile != "" {
+		if err := checkPIDFile(pidFile); err != nil {
+			return err
+		}
+	}
+	conf, err := daemonConfig(c)
+	if err != nil {
+		return err
+	}
+	if err := conf.Validate(); err != nil {
+		return err
+	}
+	controlKeys, err := loadControlKeys(controlKeysPath(c))
+	if err != nil {
+		return err
+	}
+	if len(controlKeys) > 0 {
+		ids, err := controlIdentities(controlKeys)
+		if err != nil {
+			return err
+		}
+		core.WithServiceMiddlewares(net.WithControlAuth(ids))(conf)
+	}
+	fs, err := key.NewFileStore(conf.ConfigFolder())
+	if err != nil {
+		return fmt.Errorf("can't open key store: %w", err)
+	}
+	defer fs.Close()
 	var drand *core.Drand
 	// determine if we already ran a DKG or not
 	_, errG := fs.LoadGroup()
 	_, errS := fs.LoadShare()
 	// XXX place that logic inside core/ directly with only one method
 	freshRun := errG != nil || errS != nil
-	var err error
 	if freshRun {
 		fmt.Println("drand: will run as fresh install -> expect to run DKG.")
 		drand, err = core.NewDrand(fs, conf)
@@ -38,21 +76,148 @@ func startCmd(c *cli.Context) error {
 		catchup := true
 		drand.StartBeacon(catchup)
 	}
+	if pidFile != "" {
+		if err := ioutil.WriteFile(pidFile, []byte(strconv.Itoa(os.Getpid())+"\n"), 0644); err != nil {
+			return fmt.Errorf("drand: can't write pid file: %w", err)
+		}
+		defer os.Remove(pidFile)
+	}
 	// Start metrics server
 	if c.IsSet(metricsFlag.Name) {
 		_ = metrics.Start(c.String(metricsFlag.Name), pprof.WithProfile(), drand.PeerMetrics)
 	}
+	// reload the settings that can change at runtime on SIGHUP
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	defer func() {
+		signal.Stop(reload)
+		close(reload)
+	}()
+	go reloadOnSignal(reload, drand, func() (*core.Config, error) { return daemonConfig(c) })
 	<-drand.WaitExit()
 
 	return nil
 }
 
+// ErrDaemonAlreadyRunning is returned by start when the PID file refers to a
+// process that is still running.
+var ErrDaemonAlreadyRunning = errors.New("drand: daemon already running")
+
+// checkPIDFile returns ErrDaemonAlreadyRunning if the PID file exists and the
+// process it refers to is alive. A stale PID file is overwritten on start.
+func checkPIDFile(path string) error {
+	buff, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("drand: can't read pid file: %w", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(buff)))
+	if err != nil {
+		return nil
+	}
+	// on unix, FindProcess always succeeds and the signal 0 only checks the
+	// process exists: EPERM means it runs as another user
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return nil
+	}
+	if err := p.Signal(syscall.Signal(0)); err != nil && !errors.Is(err, syscall.EPERM) {
+		return nil
+	}
+	return fmt.Errorf("%w with pid %d (pid file %s)", ErrDaemonAlreadyRunning, pid, path)
+}
+
+// configReloader is implemented by core.Drand
+type configReloader interface {
+	ReloadConfig(*core.Config) error
+}
+
+// reloadOnSignal reloads the configuration returned by conf each time a signal
+// is received, until the channel is closed.
+func reloadOnSignal(sigs <-chan os.Signal, r configReloader, conf func() (*core.Config, error)) {
+	for range sigs {
+		reloadConfig(r, conf)
+	}
+}
+
+// reloadConfig reloads the configuration returned by conf. A failure, even a
+// panic, is reported without stopping the daemon.
+func reloadConfig(r configReloader, conf func() (*core.Config, error)) {
+	defer func() {
+		if err := recover(); err != nil {
+			fmt.Fprintf(output, "drand: can't reload config: %v\n", err)
+		}
+	}()
+	c, err := conf()
+	if err == nil {
+		err = r.ReloadConfig(c)
+	}
+	if err != nil {
+		fmt.Fprintf(output, "drand: can't reload config: %s\n", err)
+		return
+	}
+	fmt.Fprintln(output, "drand: config reloaded")
+}
+
+// settingsFileName is the file, in the config folder, holding the settings of
+// the daemon that can change while it runs. It is read when the daemon starts
+// and again on SIGHUP.
+const settingsFileName = "daemon.toml"
+
+// settingsTOML is the content of the settings file. Each setting is optional
+// and overrides the corresponding flag.
+type settingsTOML struct {
+	// Verbose turns the debug logs on or off
+	Verbose *bool `toml:"verbose"`
+	// ControlStopTimeout is the grace period of the control listener on stop,
+	// e.g. "10s"
+	ControlStopTimeout string `toml:"control_stop_timeout"`
+}
+
+// daemonConfig returns the configuration given by the flags, updated with the
+// settings file of the config folder if there is one.
+func daemonConfig(c *cli.Context) (*core.Config, error) {
+	conf := contextToConfig(c)
+	if err := loadSettings(path.Join(conf.ConfigFolder(), settingsFileName), conf); err != nil {
+		return nil, err
+	}
+	return conf, nil
+}
+
+// loadSettings applies the settings of the given file to the configuration. A
+// missing file changes nothing.
+func loadSettings(filePath string, conf *core.Config) error {
+	st := new(settingsTOML)
+	if _, err := toml.DecodeFile(filePath, st); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("can't read settings: %w", err)
+	}
+	if st.Verbose != nil {
+		level := log.LogInfo
+		if *st.Verbose {
+			level = log.LogDebug
+		}
+		core.WithLogLevel(level)(conf)
+	}
+	if st.ControlStopTimeout != "" {
+		timeout, err := time.ParseDuration(st.ControlStopTimeout)
+		if err != nil {
+			return fmt.Errorf("can't read settings: invalid control_stop_timeout: %w", err)
+		}
+		core.WithControlStopTimeout(timeout)(conf)
+	}
+	return nil
+}
+
 func stopDaemon(c *cli.Context) error {
 	ctrlClient, err := controlClient(c)
 	if err != nil {
 		return err
 	}
-	if _, err := ctrlClient.Shutdown(); err != nil {
+	if _, err := ctrlClient.Shutdown(c.Duration(stopTimeoutFlag.Name)); err != nil {
 		return fmt.Errorf("error stopping drand daemon: %w", err)
 	}
 	fmt.Println("drand daemon stopped correctly. Bye.")
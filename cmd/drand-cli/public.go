@@ -1,18 +1,31 @@
 package drand
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	gonet "net"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/drand/drand/chain"
 	"github.com/drand/drand/client"
 	"github.com/drand/drand/client/grpc"
 	"github.com/drand/drand/core"
+	"github.com/drand/drand/key"
 	"github.com/drand/drand/net"
+	"github.com/drand/drand/protobuf/drand"
+	json "github.com/nikkolasg/hexjson"
 	"github.com/urfave/cli/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func getPrivateCmd(c *cli.Context) error {
@@ -72,42 +85,456 @@ func getPublicRandomness(c *cli.Context) error {
 		return errors.New("drand: group file must contain the distributed public key")
 	}
 
+	format, err := randFormat(c)
+	if err != nil {
+		return err
+	}
+	if c.Duration(grpcTimeoutFlag.Name) <= 0 {
+		return fmt.Errorf("drand: --%s must be positive", grpcTimeoutFlag.Name)
+	}
+	if c.Bool(verifyRandFlag.Name) && (c.IsSet(fromRoundFlag.Name) || c.Bool(watchFlag.Name)) {
+		return fmt.Errorf("drand: --%s checks a single round, not --%s or --%s", verifyRandFlag.Name, fromRoundFlag.Name, watchFlag.Name)
+	}
+	if c.IsSet(fromRoundFlag.Name) {
+		return getPublicRandomnessRange(c, ids, certPath)
+	}
+	if c.IsSet(toRoundFlag.Name) {
+		return fmt.Errorf("drand: --%s requires --%s", toRoundFlag.Name, fromRoundFlag.Name)
+	}
+	if c.Bool(watchFlag.Name) {
+		return watchPublicRandomness(c, ids, certPath)
+	}
+
+	timeout := c.Duration(grpcTimeoutFlag.Name)
 	var resp client.Result
-	var foundCorrect bool
+	var grpcClient client.Client
+	var foundCorrect, timedOut bool
 	for _, id := range ids {
-		grpcClient, err := grpc.New(id.Addr, certPath, !id.TLS)
+		grpcClient, err = grpc.New(id.Addr, certPath, !id.TLS)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "drand: could not connect to %s: %s", id.Addr, err)
 			break
 		}
 
-		resp, err = grpcClient.Get(c.Context, uint64(c.Int(roundFlag.Name)))
+		ctx, cancel := context.WithTimeout(c.Context, timeout)
+		resp, err = grpcClient.Get(ctx, uint64(c.Int(roundFlag.Name)))
+		cancel()
 
 		if err == nil {
 			foundCorrect = true
 			if c.Bool(verboseFlag.Name) {
-				fmt.Fprintf(output, "drand: public randomness retrieved from %s", id.Addr)
+				fmt.Fprintf(os.Stderr, "drand: public randomness retrieved from %s\n", id.Addr)
 			}
 			break
 		}
+		timedOut = timedOut || isTimeout(err)
 		fmt.Fprintf(os.Stderr, "drand: could not get public randomness from %s: %s", id.Addr, err)
 	}
+	if !foundCorrect && timedOut {
+		return timeoutError(timeout)
+	}
 	if !foundCorrect {
 		return errors.New("drand: could not verify randomness")
 	}
+	// the randomness is only printed once verified
+	if c.Bool(verifyRandFlag.Name) {
+		info, err := publicChainInfo(c, grpcClient, group)
+		if err != nil {
+			return err
+		}
+		fetch := func(ctx context.Context, round uint64) (*chain.Beacon, error) {
+			r, err := grpcClient.Get(ctx, round)
+			if err != nil {
+				return nil, err
+			}
+			return &chain.Beacon{Round: r.Round(), Signature: r.Signature(), PreviousSig: previousSignature(r)}, nil
+		}
+		b := &chain.Beacon{Round: resp.Round(), Signature: resp.Signature(), PreviousSig: previousSignature(resp)}
+		ctx, cancel := context.WithTimeout(c.Context, timeout)
+		defer cancel()
+		if err := verifyRandomness(ctx, info, b, fetch); err != nil {
+			fmt.Fprintf(output, "✗ round %d: %s\n", b.Round, err)
+			return fmt.Errorf("drand: round %d does not verify", b.Round)
+		}
+	}
+	if c.IsSet(randFormatFlag.Name) {
+		err = printRandomness(resp, format, c.Bool(verboseFlag.Name))
+	} else {
+		err = printJSON(resp)
+	}
+	if err != nil || !c.Bool(verifyRandFlag.Name) {
+		return err
+	}
+	fmt.Fprintf(output, "✓ round %d verified\n", resp.Round())
+	return nil
+}
+
+// publicChainInfo returns the chain info given with --chain-info, or else the
+// one of the node cl is connected to. Its key must be the one of the group.
+func publicChainInfo(c *cli.Context, cl client.Client, group *key.Group) (*chain.Info, error) {
+	var info *chain.Info
+	var err error
+	if c.IsSet(chainInfoFileFlag.Name) {
+		info, err = readChainInfo(c.String(chainInfoFileFlag.Name))
+	} else {
+		ctx, cancel := context.WithTimeout(c.Context, c.Duration(grpcTimeoutFlag.Name))
+		info, err = cl.Info(ctx)
+		cancel()
+		if err != nil {
+			err = fmt.Errorf("drand: can't get the chain info: %w", err)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !info.PublicKey.Equal(group.PublicKey.Key()) {
+		return nil, errors.New("drand: the chain info is not the one of the group")
+	}
+	return info, nil
+}
+
+// readChainInfo reads the chain info, in the JSON format of get chain-info,
+// from the file at path
+func readChainInfo(path string) (*chain.Info, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("drand: can't open chain info: %w", err)
+	}
+	defer f.Close()
+	info, err := chain.InfoFromJSON(f)
+	if err != nil {
+		return nil, fmt.Errorf("drand: %w", err)
+	}
+	return info, nil
+}
+
+// verifyRandomness checks the beacon b is signed by the distributed key of
+// info and builds on the signature of the previous round returned by fetch, or
+// on the genesis seed for the first round.
+func verifyRandomness(ctx context.Context, info *chain.Info, b *chain.Beacon,
+	fetch func(ctx context.Context, round uint64) (*chain.Beacon, error)) error {
+	if err := chain.VerifyBeacon(info.PublicKey, b); err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+	prev := chain.GenesisBeacon(info)
+	if b.Round > 1 {
+		var err error
+		if prev, err = fetch(ctx, b.Round-1); err != nil {
+			return fmt.Errorf("could not get round %d: %w", b.Round-1, err)
+		}
+	}
+	if !bytes.Equal(prev.Signature, b.PreviousSig) {
+		return fmt.Errorf("previous signature does not match the signature of round %d", b.Round-1)
+	}
+	return nil
+}
+
+// timeoutExitCode is the exit code of get public when a node does not answer
+// within --grpc-timeout
+const timeoutExitCode = 2
+
+// timeoutError returns the error of a request not answered within timeout
+func timeoutError(timeout time.Duration) error {
+	return cli.Exit(fmt.Sprintf("drand: the node did not answer within %s, see --%s", timeout, grpcTimeoutFlag.Name),
+		timeoutExitCode)
+}
+
+// isTimeout returns true if err is due to the deadline of the request
+func isTimeout(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || status.Code(err) == codes.DeadlineExceeded
+}
+
+// output formats of get public
+const (
+	randFormatText   = "text"
+	randFormatJSON   = "json"
+	randFormatHex    = "hex"
+	randFormatBase64 = "base64"
+	randFormatRaw    = "raw"
+)
+
+// randFormat returns the output format given with --format
+func randFormat(c *cli.Context) (string, error) {
+	format := c.String(randFormatFlag.Name)
+	switch format {
+	case randFormatText, randFormatJSON, randFormatHex, randFormatBase64, randFormatRaw:
+		return format, nil
+	}
+	return "", fmt.Errorf("drand: unknown format %q", format)
+}
+
+// watch back-off bounds when the randomness stream gets disconnected
+var (
+	watchMinBackoff = 1 * time.Second
+	watchMaxBackoff = 1 * time.Minute
+)
+
+// watchPublicRandomness streams new randomness from the first node reachable
+// and prints it until interrupted.
+func watchPublicRandomness(c *cli.Context, ids []*key.Node, certPath string) error {
+	format, err := randFormat(c)
+	if err != nil {
+		return err
+	}
+	cl, err := connectClient(ids, certPath)
+	if err != nil {
+		return err
+	}
+	defer cl.Close()
+
+	ctx, cancel := interruptContext(c.Context)
+	defer cancel()
+	return watchRandomness(ctx, cl, format, c.Bool(verboseFlag.Name))
+}
+
+// connectClient returns a client to the first node reachable
+func connectClient(ids []*key.Node, certPath string) (client.Client, error) {
+	for _, id := range ids {
+		cl, err := grpc.New(id.Addr, certPath, !id.TLS)
+		if err == nil {
+			return cl, nil
+		}
+		fmt.Fprintf(os.Stderr, "drand: could not connect to %s: %s", id.Addr, err)
+	}
+	return nil, errors.New("drand: could not connect to any node")
+}
+
+// interruptContext returns a context canceled when the process is interrupted
+func interruptContext(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		defer signal.Stop(sigs)
+		select {
+		case <-sigs:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// getPublicRandomnessRange prints the randomness of the rounds given with
+// --from and --to. Without --to, it streams the rounds from --from until
+// interrupted.
+func getPublicRandomnessRange(c *cli.Context, ids []*key.Node, certPath string) error {
+	format, err := randFormat(c)
+	if err != nil {
+		return err
+	}
+	if c.IsSet(roundFlag.Name) || c.Bool(watchFlag.Name) {
+		return fmt.Errorf("drand: --%s can not be used with --%s or --%s", fromRoundFlag.Name, roundFlag.Name, watchFlag.Name)
+	}
+	from := c.Uint64(fromRoundFlag.Name)
+	if from == 0 {
+		return errors.New("drand: the first round of the chain is 1")
+	}
+	ctx, cancel := interruptContext(c.Context)
+	defer cancel()
+	if !c.IsSet(toRoundFlag.Name) {
+		return streamRandomnessFrom(ctx, ids, certPath, from, format, c.Bool(verboseFlag.Name))
+	}
+	to := c.Uint64(toRoundFlag.Name)
+	if to < from {
+		return fmt.Errorf("drand: --%s %d is before --%s %d", toRoundFlag.Name, to, fromRoundFlag.Name, from)
+	}
+	parallel := c.Int(parallelFlag.Name)
+	if parallel < 1 {
+		return fmt.Errorf("drand: --%s must be at least 1", parallelFlag.Name)
+	}
+	cl, err := connectClient(ids, certPath)
+	if err != nil {
+		return err
+	}
+	defer cl.Close()
+	return getRandomnessRange(ctx, cl, from, to, parallel, c.Duration(grpcTimeoutFlag.Name), format,
+		c.Bool(verboseFlag.Name), os.Stderr)
+}
+
+// rangeProgressStep is the number of rounds between two progress lines when
+// fetching a range of rounds
+const rangeProgressStep = 100
 
-	return printJSON(resp)
+// getRandomnessRange fetches the rounds from..to by windows of parallel
+// rounds, each request bounded by timeout, and prints them in ascending order
+// as soon as they are available. Only a window is held in memory so the range
+// can be arbitrarily large. The progress of ranges larger than
+// rangeProgressStep is written to progress.
+func getRandomnessRange(ctx context.Context, cl client.Client, from, to uint64, parallel int, timeout time.Duration,
+	format string, verbose bool, progress io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	total := to - from + 1
+	results := make([]client.Result, parallel)
+	errs := make([]error, parallel)
+	done := make([]chan struct{}, parallel)
+	for start := from; ; start += uint64(parallel) {
+		size := parallel
+		if left := to - start + 1; left < uint64(size) {
+			size = int(left)
+		}
+		for i := 0; i < size; i++ {
+			done[i] = make(chan struct{})
+			go func(i int, round uint64) {
+				getCtx, getCancel := context.WithTimeout(ctx, timeout)
+				results[i], errs[i] = cl.Get(getCtx, round)
+				getCancel()
+				close(done[i])
+			}(i, start+uint64(i))
+		}
+		for i := 0; i < size; i++ {
+			select {
+			case <-done[i]:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			if isTimeout(errs[i]) {
+				return timeoutError(timeout)
+			}
+			if errs[i] != nil {
+				return fmt.Errorf("drand: could not get round %d: %w", start+uint64(i), errs[i])
+			}
+			if err := printRandomness(results[i], format, verbose); err != nil {
+				return err
+			}
+			count := start - from + uint64(i) + 1
+			if total > rangeProgressStep && (count%rangeProgressStep == 0 || count == total) {
+				fmt.Fprintf(progress, "drand: round %d of %d\n", count, total)
+			}
+		}
+		if to-start < uint64(parallel) {
+			return nil
+		}
+	}
+}
+
+// streamRandomnessFrom prints the rounds from the given one, as streamed by
+// the first node reachable, until ctx is done or the stream ends.
+func streamRandomnessFrom(ctx context.Context, ids []*key.Node, certPath string, from uint64, format string, verbose bool) error {
+	grpcClient := net.NewGrpcClient()
+	if certPath != "" {
+		manager := net.NewCertManager()
+		if err := manager.Add(certPath); err != nil {
+			return err
+		}
+		grpcClient = net.NewGrpcClientFromCertManager(manager)
+	}
+	var stream chan *drand.PublicRandResponse
+	for _, id := range ids {
+		var err error
+		stream, err = grpcClient.PublicRandStream(ctx, id, &drand.PublicRandRequest{Round: from})
+		if err == nil {
+			break
+		}
+		fmt.Fprintf(os.Stderr, "drand: could not stream from %s: %s\n", id.Addr, err)
+	}
+	if stream == nil {
+		return errors.New("drand: could not stream from any node")
+	}
+	for {
+		select {
+		case r, ok := <-stream:
+			if !ok {
+				return nil
+			}
+			if err := printRandomness(&client.RandomData{
+				Rnd:               r.GetRound(),
+				Random:            r.GetRandomness(),
+				Sig:               r.GetSignature(),
+				PreviousSignature: r.GetPreviousSignature(),
+			}, format, verbose); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// watchRandomness prints each new beacon of the client's stream, one per line.
+// When the stream gets disconnected, it reconnects with an exponential
+// back-off until ctx is done.
+func watchRandomness(ctx context.Context, cl client.Client, format string, verbose bool) error {
+	backoff := watchMinBackoff
+	for {
+		for r := range cl.Watch(ctx) {
+			backoff = watchMinBackoff
+			if err := printRandomness(r, format, verbose); err != nil {
+				return err
+			}
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		fmt.Fprintf(os.Stderr, "drand: randomness stream disconnected, reconnecting in %s\n", backoff)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil
+		}
+		if backoff *= 2; backoff > watchMaxBackoff {
+			backoff = watchMaxBackoff
+		}
+	}
+}
+
+// printRandomness prints the randomness of r in the given format. When
+// verbose is set, the text formats also print the signature and previous
+// signature of the beacon. The raw format writes the randomness bytes only,
+// with no separator, so it can be piped to another program.
+func printRandomness(r client.Result, format string, verbose bool) error {
+	var encode func([]byte) string
+	switch format {
+	case randFormatJSON:
+		buff, err := json.Marshal(r)
+		if err != nil {
+			return fmt.Errorf("could not JSON marshal: %s", err)
+		}
+		fmt.Fprintln(output, string(buff))
+		return nil
+	case randFormatRaw:
+		_, err := output.Write(r.Randomness())
+		return err
+	case randFormatBase64:
+		encode = base64.URLEncoding.EncodeToString
+	default:
+		encode = hex.EncodeToString
+	}
+	line := encode(r.Randomness())
+	if format == randFormatText {
+		line = fmt.Sprintf("[round %d] %s", r.Round(), line)
+	}
+	if verbose {
+		line += fmt.Sprintf(" signature=%s previous_signature=%s", encode(r.Signature()), encode(previousSignature(r)))
+	}
+	fmt.Fprintln(output, line)
+	return nil
+}
+
+// previousSignature returns the previous signature of r if it is known
+func previousSignature(r client.Result) []byte {
+	switch rp := r.(type) {
+	case *client.RandomData:
+		return rp.PreviousSignature
+	case interface{ PreviousSignature() []byte }:
+		return rp.PreviousSignature()
+	}
+	return nil
 }
 
 func getChainInfo(c *cli.Context) error {
-	var grpcClient = core.NewGrpcClient()
+	var grpcClient = net.NewGrpcClient()
 	if c.IsSet(tlsCertFlag.Name) {
 		defaultManager := net.NewCertManager()
 		certPath := c.String(tlsCertFlag.Name)
 		if err := defaultManager.Add(certPath); err != nil {
 			return err
 		}
-		grpcClient = core.NewGrpcClientFromCert(defaultManager)
+		grpcClient = net.NewGrpcClientFromCertManager(defaultManager)
+	}
+	if c.Bool(allChainsFlag.Name) {
+		return getAllChainInfos(c, grpcClient)
 	}
 	var ci *chain.Info
 	for _, addr := range c.Args().Slice() {
@@ -115,7 +542,7 @@ func getChainInfo(c *cli.Context) error {
 		if err != nil {
 			return fmt.Errorf("invalid address given: %s", err)
 		}
-		ci, err = grpcClient.ChainInfo(net.CreatePeer(addr, !c.Bool("tls-disable")))
+		ci, err = fetchChainInfo(grpcClient, net.CreatePeer(addr, !c.Bool("tls-disable")), c.Bool(verifyFlag.Name))
 		if err == nil {
 			break
 		}
@@ -128,6 +555,56 @@ func getChainInfo(c *cli.Context) error {
 	return printChainInfo(c, ci)
 }
 
+// getAllChainInfos fetches the chain info of every address given, and prints
+// the distinct chains found as a JSON array, or their hashes with --hash. A
+// node runs a single chain, so the chains are enumerated across the nodes.
+func getAllChainInfos(c *cli.Context, grpcClient net.PublicClient) error {
+	var infos []*chain.Info
+	seen := make(map[string]bool)
+	for _, addr := range c.Args().Slice() {
+		if _, _, err := gonet.SplitHostPort(addr); err != nil {
+			return fmt.Errorf("invalid address given: %s", err)
+		}
+		ci, err := fetchChainInfo(grpcClient, net.CreatePeer(addr, !c.Bool("tls-disable")), c.Bool(verifyFlag.Name))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "drand: error fetching chain info from %s: %s\n", addr, err)
+			continue
+		}
+		if h := hex.EncodeToString(ci.Hash()); !seen[h] {
+			seen[h] = true
+			infos = append(infos, ci)
+		}
+	}
+	if len(infos) == 0 {
+		return errors.New("drand: can't retrieve chain info from any node")
+	}
+	if c.Bool(hashOnly.Name) {
+		for _, ci := range infos {
+			fmt.Fprintf(output, "%s\n", hex.EncodeToString(ci.Hash()))
+		}
+		return nil
+	}
+	packets := make([]*drand.ChainInfoPacket, len(infos))
+	for i, ci := range infos {
+		packets[i] = ci.ToProto()
+	}
+	return printJSON(packets)
+}
+
+// fetchChainInfo gets the chain information from the peer. The hash of the
+// chain in the response is checked against its content when present. With
+// verify, the response must also carry this hash.
+func fetchChainInfo(client net.PublicClient, p net.Peer, verify bool) (*chain.Info, error) {
+	resp, err := client.ChainInfo(context.Background(), p, &drand.ChainInfoRequest{})
+	if err != nil {
+		return nil, err
+	}
+	if verify && len(resp.GetHash()) == 0 {
+		return nil, errors.New("chain info without hash can not be verified")
+	}
+	return chain.InfoFromProto(resp)
+}
+
 func printChainInfo(c *cli.Context, ci *chain.Info) error {
 	if c.Bool(hashOnly.Name) {
 		fmt.Fprintf(output, "%s\n", hex.EncodeToString(ci.Hash()))
@@ -135,3 +612,177 @@ func printChainInfo(c *cli.Context, ci *chain.Info) error {
 	}
 	return printJSON(ci.ToProto())
 }
+
+// verifyChainCmd fetches the rounds --from..--to from the node given as
+// argument, up to its last round if --to is not set, and checks they form a
+// valid chain. It fails if any link is broken.
+func verifyChainCmd(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("drand: verify-chain takes the address of a node as argument")
+	}
+	addr := c.Args().First()
+	if _, _, err := gonet.SplitHostPort(addr); err != nil {
+		return fmt.Errorf("invalid address given: %s", err)
+	}
+	from, to := c.Uint64(chainFromFlag.Name), c.Uint64(chainToFlag.Name)
+	if from == 0 {
+		return errors.New("drand: the first round of the chain is 1")
+	}
+	if c.IsSet(chainToFlag.Name) && to < from {
+		return fmt.Errorf("drand: --%s %d is before --%s %d", chainToFlag.Name, to, chainFromFlag.Name, from)
+	}
+	timeout := c.Duration(grpcTimeoutFlag.Name)
+	if timeout <= 0 {
+		return fmt.Errorf("drand: --%s must be positive", grpcTimeoutFlag.Name)
+	}
+	grpcClient := net.NewGrpcClient()
+	if c.IsSet(tlsCertFlag.Name) {
+		manager := net.NewCertManager()
+		if err := manager.Add(c.String(tlsCertFlag.Name)); err != nil {
+			return err
+		}
+		grpcClient = net.NewGrpcClientFromCertManager(manager)
+	}
+	peer := net.CreatePeer(addr, !c.Bool(insecureFlag.Name))
+
+	var info *chain.Info
+	if c.IsSet(chainInfoFileFlag.Name) {
+		var err error
+		if info, err = readChainInfo(c.String(chainInfoFileFlag.Name)); err != nil {
+			return err
+		}
+	} else {
+		var err error
+		if info, err = fetchChainInfo(grpcClient, peer, false); err != nil {
+			return fmt.Errorf("drand: can't get the chain info from %s: %w", addr, err)
+		}
+	}
+
+	fetch := func(ctx context.Context, round uint64) (*chain.Beacon, error) {
+		resp, err := grpcClient.PublicRand(ctx, peer, &drand.PublicRandRequest{Round: round})
+		if err != nil {
+			return nil, err
+		}
+		return &chain.Beacon{
+			Round:       resp.GetRound(),
+			Signature:   resp.GetSignature(),
+			PreviousSig: resp.GetPreviousSignature(),
+		}, nil
+	}
+	broken, err := verifyChain(c.Context, info, from, to, fetch, timeout, output)
+	if err != nil {
+		return err
+	}
+	if broken > 0 {
+		return fmt.Errorf("drand: the chain has %d broken links", broken)
+	}
+	return nil
+}
+
+// verifyChain checks the rounds from..to returned by fetch form a valid chain
+// of info: each beacon must be the requested round, be signed by the
+// distributed key and build on the signature of the previous round, or on the
+// genesis seed for the first round. A to of 0 stands for the last round, which
+// fetch returns for round 0. Each broken link is written to out, followed by a
+// summary. Each request is bounded by timeout. It returns the number of broken
+// links, or an error if a round can not be fetched.
+func verifyChain(ctx context.Context, info *chain.Info, from, to uint64,
+	fetch func(ctx context.Context, round uint64) (*chain.Beacon, error), timeout time.Duration,
+	out io.Writer) (int, error) {
+	var broken int
+	report := func(round uint64, format string, args ...interface{}) {
+		broken++
+		fmt.Fprintf(out, "round %d: %s\n", round, fmt.Sprintf(format, args...))
+	}
+	if to == 0 {
+		fetchCtx, cancel := context.WithTimeout(ctx, timeout)
+		b, err := fetch(fetchCtx, 0)
+		cancel()
+		if isTimeout(err) {
+			return 0, timeoutError(timeout)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("drand: could not get the last round: %w", err)
+		}
+		to = b.Round
+	}
+	if to < from {
+		return 0, fmt.Errorf("drand: the last round %d is before round %d", to, from)
+	}
+	var prev []byte
+	if from == 1 {
+		prev = chain.GenesisBeacon(info).Signature
+	}
+	// round wraps to 0 after the last uint64
+	for round := from; round >= from && round <= to; round++ {
+		fetchCtx, cancel := context.WithTimeout(ctx, timeout)
+		b, err := fetch(fetchCtx, round)
+		cancel()
+		if isTimeout(err) {
+			return broken, timeoutError(timeout)
+		}
+		if err != nil {
+			return broken, fmt.Errorf("drand: could not get round %d: %w", round, err)
+		}
+		if b.Round != round {
+			report(round, "the node returned round %d", b.Round)
+			prev = nil
+			continue
+		}
+		if err := chain.VerifyBeacon(info.PublicKey, b); err != nil {
+			report(round, "invalid signature: %s", err)
+		}
+		if prev != nil && !bytes.Equal(prev, b.PreviousSig) {
+			report(round, "previous signature does not match the signature of round %d", round-1)
+		}
+		prev = b.Signature
+	}
+	fmt.Fprintf(out, "verified rounds %d to %d of chain %x: %d broken links\n", from, to, info.Hash(), broken)
+	return broken, nil
+}
+
+func verifyBeaconCmd(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("drand: verify takes a beacon file as argument")
+	}
+	buff, err := ioutil.ReadFile(c.Args().First())
+	if err != nil {
+		return fmt.Errorf("drand: can't read beacon: %w", err)
+	}
+	r := new(client.RandomData)
+	if err := json.Unmarshal(buff, r); err != nil {
+		return fmt.Errorf("drand: invalid beacon: %w", err)
+	}
+	b := &chain.Beacon{Round: r.Round(), Signature: r.Signature(), PreviousSig: r.PreviousSignature}
+
+	if c.Bool(singleNodeFlag.Name) {
+		if !c.IsSet(shareFileFlag.Name) {
+			return fmt.Errorf("drand: --%s requires --%s", singleNodeFlag.Name, shareFileFlag.Name)
+		}
+		s := new(key.Share)
+		if err := key.Load(c.String(shareFileFlag.Name), s); err != nil {
+			return fmt.Errorf("drand: error loading share file: %w", err)
+		}
+		pubKey := s.PubPoly().Eval(s.Share.I).V
+		err = chain.VerifySingleNode(pubKey, len(s.Commits), b)
+		if errors.Is(err, chain.ErrGroupVerificationRequired) {
+			return fmt.Errorf("drand: the chain has a threshold of %d, verify the beacon with --%s",
+				len(s.Commits), chainInfoFileFlag.Name)
+		}
+	} else {
+		if !c.IsSet(chainInfoFileFlag.Name) {
+			return fmt.Errorf("drand: verify requires --%s or --%s", chainInfoFileFlag.Name, singleNodeFlag.Name)
+		}
+		var info *chain.Info
+		if info, err = readChainInfo(c.String(chainInfoFileFlag.Name)); err != nil {
+			return err
+		}
+		err = chain.VerifyBeacon(info.PublicKey, b)
+	}
+	if err != nil {
+		fmt.Fprintf(output, "✗ round %d: %s\n", b.Round, err)
+		return fmt.Errorf("drand: round %d does not verify", b.Round)
+	}
+	fmt.Fprintf(output, "✓ round %d verified\n", b.Round)
+	return nil
+}
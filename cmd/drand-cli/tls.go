@@ -0,0 +1,90 @@
+package drand
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/drand/drand/fs"
+	"github.com/drand/drand/net"
+	"github.com/urfave/cli/v2"
+)
+
+func generateCertCmd(c *cli.Context) error {
+	host := c.String(certHostFlag.Name)
+	if host == "" {
+		return errors.New("generate-cert: missing host, use the --host flag")
+	}
+	certPEM, keyPEM, err := net.SelfSignedCert(host, c.Duration(validForFlag.Name))
+	if err != nil {
+		return err
+	}
+	certPath := c.String(outCertFlag.Name)
+	if err := ioutil.WriteFile(certPath, certPEM, 0644); err != nil {
+		return fmt.Errorf("generate-cert: can't write certificate: %w", err)
+	}
+	keyPath := c.String(outKeyFlag.Name)
+	keyFile, err := fs.CreateSecureFile(keyPath)
+	if err != nil {
+		return fmt.Errorf("generate-cert: can't create key file: %w", err)
+	}
+	defer keyFile.Close()
+	if _, err := keyFile.Write(keyPEM); err != nil {
+		return fmt.Errorf("generate-cert: can't write key: %w", err)
+	}
+	block, _ := pem.Decode(certPEM)
+	fmt.Fprintf(output, "Generated self-signed certificate for %s at %s (key at %s)\n", host, certPath, keyPath)
+	fmt.Fprintf(output, "SHA-256 fingerprint: %s\n", certFingerprint(block.Bytes))
+	return nil
+}
+
+func rotateTLSCmd(c *cli.Context) error {
+	if !c.IsSet(newCertFlag.Name) || !c.IsSet(newKeyFlag.Name) {
+		return fmt.Errorf("rotate-tls: missing certificate or key, use the --%s and --%s flags", newCertFlag.Name, newKeyFlag.Name)
+	}
+	// the daemon may not run from the same directory
+	certPath, err := filepath.Abs(c.String(newCertFlag.Name))
+	if err != nil {
+		return fmt.Errorf("rotate-tls: %w", err)
+	}
+	keyPath, err := filepath.Abs(c.String(newKeyFlag.Name))
+	if err != nil {
+		return fmt.Errorf("rotate-tls: %w", err)
+	}
+	cert, err := net.LoadCertificate(certPath, keyPath, time.Now())
+	if err != nil {
+		return fmt.Errorf("rotate-tls: %w", err)
+	}
+
+	client, err := controlClient(c)
+	if err != nil {
+		return err
+	}
+	if err := client.Ping(); err != nil {
+		fmt.Fprintf(output, "The daemon is not running, the certificate is valid but has not been installed\n")
+	} else {
+		resp, err := client.ReloadCert(certPath, keyPath, c.Bool(backupExistingFlag.Name))
+		if err != nil {
+			return fmt.Errorf("rotate-tls: %w", err)
+		}
+		if resp.GetBackupCertPath() != "" {
+			fmt.Fprintf(output, "Previous certificate backed up at %s\n", resp.GetBackupCertPath())
+		}
+		fmt.Fprintf(output, "The daemon now serves the new certificate\n")
+	}
+	fmt.Fprintf(output, "SHA-256 fingerprint: %s\n", certFingerprint(cert.Leaf.Raw))
+	fmt.Fprintf(output, "Expires on: %s\n", cert.Leaf.NotAfter.UTC().Format(time.RFC3339))
+	return nil
+}
+
+// certFingerprint returns the hex encoded SHA-256 hash of the DER encoded
+// certificate.
+func certFingerprint(der []byte) string {
+	fingerprint := sha256.Sum256(der)
+	return hex.EncodeToString(fingerprint[:])
+}
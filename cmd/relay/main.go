@@ -62,6 +62,7 @@ func Relay(c *cli.Context) error {
 	if err != nil {
 		return fmt.Errorf("failed to create rest handler: %w", err)
 	}
+	handler = dhttp.NewCORSHandler(handler, []string{"*"})
 
 	if c.IsSet(accessLogFlag.Name) {
 		logFile, err := os.OpenFile(c.String(accessLogFlag.Name), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0666)
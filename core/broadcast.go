@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"math/rand"
 	"sync"
+	"time"
 
 	"github.com/drand/drand/key"
 	"github.com/drand/drand/log"
@@ -55,6 +56,11 @@ type echoBroadcast struct {
 	respCh chan dkg.ResponseBundle
 	justCh chan dkg.JustificationBundle
 	verif  verifier
+	// number of valid bundles of each kind received from the other nodes
+	deals, responses, justifs int
+	// phase of the last bundle pushed by the node
+	phase   dkg.Phase
+	stopped bool
 }
 
 type packet = dkg.Packet
@@ -65,10 +71,18 @@ var _ Broadcast = (*echoBroadcast)(nil)
 // Packet, namely that the signature is correct.
 type verifier func(packet) error
 
-func newEchoBroadcast(l log.Logger, c net.ProtocolClient, own string, to []*key.Node, v verifier) *echoBroadcast {
+// newEchoBroadcast returns a broadcast to the given nodes. A packet that a
+// node fails to receive is sent again up to retries times, at most
+// MaxDKGRetries, DKGRetryBackoff apart, until ctx, the context of the DKG, is
+// done: the receivers ignore the packets they already have, by hash, so
+// sending a packet twice is harmless.
+func newEchoBroadcast(ctx context.Context, l log.Logger, c net.ProtocolClient, own string, to []*key.Node, retries uint32, v verifier) *echoBroadcast {
+	if retries > MaxDKGRetries {
+		retries = MaxDKGRetries
+	}
 	return &echoBroadcast{
 		l:          l,
-		dispatcher: newDispatcher(l, c, to, own),
+		dispatcher: newDispatcher(ctx, l, c, to, own, retries),
 		dealCh:     make(chan dkg.DealBundle, len(to)),
 		respCh:     make(chan dkg.ResponseBundle, len(to)),
 		justCh:     make(chan dkg.JustificationBundle, len(to)),
@@ -83,6 +97,8 @@ func (b *echoBroadcast) PushDeals(bundle *dkg.DealBundle) {
 	defer b.Unlock()
 	h := hash(bundle.Hash())
 	b.l.Debug("echoBroadcast", "push", "deal")
+	b.phase = dkg.DealPhase
+	b.logPhase("deal")
 	b.sendout(h, bundle, true)
 }
 
@@ -92,6 +108,8 @@ func (b *echoBroadcast) PushResponses(bundle *dkg.ResponseBundle) {
 	defer b.Unlock()
 	h := hash(bundle.Hash())
 	b.l.Debug("echoBroadcast", "push", "response", bundle.String())
+	b.phase = dkg.ResponsePhase
+	b.logPhase("response")
 	b.sendout(h, bundle, true)
 }
 
@@ -101,6 +119,8 @@ func (b *echoBroadcast) PushJustifications(bundle *dkg.JustificationBundle) {
 	defer b.Unlock()
 	h := hash(bundle.Hash())
 	b.l.Debug("echoBroadcast", "push", "justification")
+	b.phase = dkg.JustifPhase
+	b.logPhase("justification")
 	b.sendout(h, bundle, true)
 }
 
@@ -132,13 +152,35 @@ func (b *echoBroadcast) BroadcastDKG(c context.Context, p *drand.DKGPacket) (*dr
 	return new(drand.Empty), nil
 }
 
+// logPhase logs the phase the DKG enters, which is when it pushes its own
+// bundle of that phase, with the number of valid bundles received so far. It
+// requires the echoBroadcast lock.
+func (b *echoBroadcast) logPhase(phase string) {
+	b.l.Info("dkg_phase", phase, "deals", b.deals, "responses", b.responses, "justifications", b.justifs)
+}
+
+// logTimeout is called when the phaser moves the DKG to the next phase. It
+// logs the phase that ended at its deadline if the node was still in it,
+// i.e. it did not receive all the bundles it needed to move on by itself.
+func (b *echoBroadcast) logTimeout(next dkg.Phase) {
+	b.Lock()
+	defer b.Unlock()
+	if b.stopped || next <= dkg.DealPhase || b.phase >= next {
+		return
+	}
+	b.l.Info("dkg_phase_timeout", (next - 1).String(), "deals", b.deals, "responses", b.responses, "justifications", b.justifs)
+}
+
 func (b *echoBroadcast) passToApplication(p packet) {
 	switch pp := p.(type) {
 	case *dkg.DealBundle:
+		b.deals++
 		b.dealCh <- *pp
 	case *dkg.ResponseBundle:
+		b.responses++
 		b.respCh <- *pp
 	case *dkg.JustificationBundle:
+		b.justifs++
 		b.justCh <- *pp
 	default:
 		b.l.Error("echoBroadcast", "application channel full")
@@ -183,6 +225,9 @@ func (b *echoBroadcast) IncomingJustification() <-chan dkg.JustificationBundle {
 }
 
 func (b *echoBroadcast) Stop() {
+	b.Lock()
+	b.stopped = true
+	b.Unlock()
 	b.dispatcher.stop()
 }
 
@@ -240,14 +285,14 @@ type dispatcher struct {
 	senders []*sender
 }
 
-func newDispatcher(l log.Logger, client net.ProtocolClient, to []*key.Node, us string) *dispatcher {
+func newDispatcher(ctx context.Context, l log.Logger, client net.ProtocolClient, to []*key.Node, us string, retries uint32) *dispatcher {
 	var senders = make([]*sender, 0, len(to)-1)
 	queue := senderQueueSize(len(to))
 	for _, node := range to {
 		if node.Address() == us {
 			continue
 		}
-		sender := newSender(l, client, node, queue)
+		sender := newSender(ctx, l, client, node, queue, retries)
 		go sender.run()
 		senders = append(senders, sender)
 	}
@@ -279,18 +324,22 @@ func (d *dispatcher) stop() {
 }
 
 type sender struct {
-	l      log.Logger
-	client net.ProtocolClient
-	to     net.Peer
-	newCh  chan broadcastPacket
+	ctx     context.Context
+	l       log.Logger
+	client  net.ProtocolClient
+	to      net.Peer
+	newCh   chan broadcastPacket
+	retries uint32
 }
 
-func newSender(l log.Logger, client net.ProtocolClient, to net.Peer, queueSize int) *sender {
+func newSender(ctx context.Context, l log.Logger, client net.ProtocolClient, to net.Peer, queueSize int, retries uint32) *sender {
 	return &sender{
-		l:      l,
-		client: client,
-		to:     to,
-		newCh:  make(chan broadcastPacket, queueSize),
+		ctx:     ctx,
+		l:       l,
+		client:  client,
+		to:      to,
+		newCh:   make(chan broadcastPacket, queueSize),
+		retries: retries,
 	}
 }
 
@@ -308,13 +357,40 @@ func (s *sender) run() {
 	}
 }
 
+// sendDirect sends the packet, retrying on failure. The retries run in the
+// background so they don't hold up the next packets to the same node, and they
+// go on after the sender is stopped, since the DKG of this node may be over
+// while the destination still waits for the packet, until the context of the
+// sender is done.
 func (s *sender) sendDirect(newPacket broadcastPacket) {
-	err := s.client.BroadcastDKG(context.Background(), s.to, newPacket)
+	if s.send(context.Background(), newPacket, 0) || s.retries == 0 {
+		return
+	}
+	backoff := DKGRetryBackoff
+	go func() {
+		for attempt := uint32(1); attempt <= s.retries; attempt++ {
+			select {
+			case <-time.After(backoff):
+			case <-s.ctx.Done():
+				return
+			}
+			if s.send(s.ctx, newPacket, attempt) {
+				return
+			}
+		}
+	}()
+}
+
+// send makes one attempt at sending the packet and returns whether it
+// succeeded.
+func (s *sender) send(ctx context.Context, newPacket broadcastPacket, attempt uint32) bool {
+	err := s.client.BroadcastDKG(ctx, s.to, newPacket)
 	if err != nil {
-		s.l.Debug("echoBroadcast", "sending out", "error to", s.to.Address(), "err:", err)
-	} else {
-		s.l.Debug("echoBroadcast", "sending out", "to", s.to.Address())
+		s.l.Debug("echoBroadcast", "sending out", "error to", s.to.Address(), "err:", err, "attempt", attempt)
+		return false
 	}
+	s.l.Debug("echoBroadcast", "sending out", "to", s.to.Address())
+	return true
 }
 
 func (s *sender) stop() {
@@ -2,15 +2,23 @@ package core
 
 import (
 	"context"
+	"errors"
 	"os"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/drand/drand/key"
+	"github.com/drand/drand/log"
+	"github.com/drand/drand/net"
+	pdkg "github.com/drand/drand/protobuf/crypto/dkg"
 	"github.com/drand/drand/protobuf/drand"
 	"github.com/drand/kyber"
 	"github.com/drand/kyber/share/dkg"
 	"github.com/drand/kyber/util/random"
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/golang/protobuf/proto"
 	"github.com/stretchr/testify/require"
 )
 
@@ -76,7 +84,7 @@ func TestBroadcast(t *testing.T) {
 	ids := make([]string, 0, n)
 	for _, d := range drands {
 		id := d.priv.Public.Address()
-		b := newEchoBroadcast(d.log, d.privGateway.ProtocolClient, id, group.Nodes, func(dkg.Packet) error { return nil })
+		b := newEchoBroadcast(context.Background(), d.log, d.privGateway.ProtocolClient, id, group.Nodes, 0, func(dkg.Packet) error { return nil })
 
 		d.dkgInfo = &dkgInfo{
 			board:   withCallback(id, b, callback),
@@ -183,3 +191,128 @@ func fakeDeal() *dkg.DealBundle {
 		}},
 	}
 }
+
+func TestEchoBroadcastLogTimeout(t *testing.T) {
+	logs := new(lockedBuffer)
+	self := []*key.Node{{Identity: &key.Identity{Addr: "127.0.0.1:8000"}}}
+	b := newEchoBroadcast(context.Background(), log.NewLogger(kitlog.NewLogfmtLogger(logs), log.LogInfo), nil, "127.0.0.1:8000", self, 0, nil)
+	timeouts := func() []string {
+		var phases []string
+		for _, field := range strings.Fields(logs.String()) {
+			if strings.HasPrefix(field, "dkg_phase_timeout=") {
+				phases = append(phases, strings.TrimPrefix(field, "dkg_phase_timeout="))
+			}
+		}
+		return phases
+	}
+
+	// the deal phase starts the DKG, no phase ended before
+	b.logTimeout(dkg.DealPhase)
+	// the node moved to the response phase before the deal deadline
+	b.phase = dkg.ResponsePhase
+	b.logTimeout(dkg.ResponsePhase)
+	require.Empty(t, timeouts())
+	// the node was still waiting for responses at the deadline
+	b.logTimeout(dkg.JustifPhase)
+	require.Equal(t, []string{"response"}, timeouts())
+	// the DKG ended before the last deadline
+	b.Stop()
+	b.logTimeout(dkg.FinishPhase)
+	require.Equal(t, []string{"response"}, timeouts())
+}
+
+// flakyClient fails the first failures attempts to send each DKG packet to
+// each node
+type flakyClient struct {
+	net.ProtocolClient
+	sync.Mutex
+	failures int
+	attempts map[string]int
+	// number of attempts that failed
+	failed int
+}
+
+func (f *flakyClient) BroadcastDKG(c context.Context, p net.Peer, in *drand.DKGPacket, opts ...net.CallOption) error {
+	buff, err := proto.Marshal(in)
+	if err != nil {
+		return err
+	}
+	f.Lock()
+	if f.attempts == nil {
+		f.attempts = make(map[string]int)
+	}
+	id := p.Address() + string(buff)
+	f.attempts[id]++
+	fail := f.attempts[id] <= f.failures
+	if fail {
+		f.failed++
+	}
+	f.Unlock()
+	if fail {
+		return errors.New("transient failure")
+	}
+	return f.ProtocolClient.BroadcastDKG(c, p, in, opts...)
+}
+
+// deliveredClient records the DKG packets delivered
+type deliveredClient struct {
+	net.ProtocolClient
+	delivered chan *drand.DKGPacket
+}
+
+func (d *deliveredClient) BroadcastDKG(c context.Context, p net.Peer, in *drand.DKGPacket, opts ...net.CallOption) error {
+	d.delivered <- in
+	return nil
+}
+
+func TestSenderRetries(t *testing.T) {
+	DKGRetryBackoff = 10 * time.Millisecond
+	defer func() { DKGRetryBackoff = 100 * time.Millisecond }()
+	l := log.DefaultLogger()
+	to := &key.Node{Identity: &key.Identity{Addr: "127.0.0.1:8000"}}
+	packet := &drand.DKGPacket{Dkg: &pdkg.Packet{}}
+	failed := func(f *flakyClient) int {
+		f.Lock()
+		defer f.Unlock()
+		return f.failed
+	}
+
+	delivered := &deliveredClient{delivered: make(chan *drand.DKGPacket, 1)}
+	flaky := &flakyClient{ProtocolClient: delivered, failures: 2}
+	newSender(context.Background(), l, flaky, to, 1, 2).sendDirect(packet)
+	select {
+	case <-delivered.delivered:
+	case <-time.After(time.Second):
+		t.Fatal("packet not delivered")
+	}
+	require.Equal(t, 2, failed(flaky))
+
+	// the sender gives up after the retries
+	flaky = &flakyClient{ProtocolClient: delivered, failures: 2}
+	newSender(context.Background(), l, flaky, to, 1, 1).sendDirect(packet)
+	time.Sleep(10 * DKGRetryBackoff)
+	require.Len(t, delivered.delivered, 0)
+	require.Equal(t, 2, failed(flaky))
+
+	// the retries don't hold up the next packets: each packet fails once, the
+	// second attempt at the next packet is delivered while the first packet
+	// waits for its retry
+	DKGRetryBackoff = time.Hour
+	flaky = &flakyClient{ProtocolClient: delivered, failures: 1}
+	s := newSender(context.Background(), l, flaky, to, 1, 1)
+	s.sendDirect(packet)
+	next := &drand.DKGPacket{Dkg: &pdkg.Packet{Bundle: &pdkg.Packet_Deal{Deal: &pdkg.DealBundle{DealerIndex: 1}}}}
+	s.sendDirect(next)
+	s.sendDirect(next)
+	require.Equal(t, next, <-delivered.delivered)
+
+	// the retries stop with the context of the sender
+	DKGRetryBackoff = 10 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	flaky = &flakyClient{ProtocolClient: delivered, failures: 1}
+	newSender(ctx, l, flaky, to, 1, 2).sendDirect(packet)
+	cancel()
+	time.Sleep(10 * DKGRetryBackoff)
+	require.Len(t, delivered.delivered, 0)
+	require.Equal(t, 1, failed(flaky))
+}
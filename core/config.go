@@ -1,7 +1,13 @@
 package core
 
 import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
 	"path"
+	"strings"
 	"time"
 
 	"github.com/drand/drand/chain"
@@ -18,25 +24,42 @@ type ConfigOption func(*Config)
 
 // Config holds all relevant information for a drand node to run.
 type Config struct {
-	configFolder      string
-	dbFolder          string
-	version           string
-	privateListenAddr string
-	publicListenAddr  string
-	controlPort       string
-	grpcOpts          []grpc.DialOption
-	callOpts          []grpc.CallOption
-	dkgTimeout        time.Duration
-	boltOpts          *bolt.Options
-	beaconCbs         []func(*chain.Beacon)
-	dkgCallback       func(*key.Share)
-	insecure          bool
-	certPath          string
-	keyPath           string
-	certmanager       *net.CertManager
-	logger            log.Logger
-	clock             clock.Clock
-	enablePrivate     bool
+	configFolder       string
+	dbFolder           string
+	version            string
+	privateListenAddr  string
+	publicListenAddr   string
+	controlPort        string
+	controlStopTimeout time.Duration
+	grpcOpts           []grpc.DialOption
+	callOpts           []grpc.CallOption
+	dkgTimeout         time.Duration
+	boltOpts           *bolt.Options
+	beaconCbs          []func(*chain.Beacon)
+	dkgCallback        func(*key.Share)
+	insecure           bool
+	certPath           string
+	keyPath            string
+	certmanager        *net.CertManager
+	logger             log.Logger
+	logLevel           *log.LevelVar
+	clock              clock.Clock
+	enablePrivate      bool
+	corsOrigins        []string
+	compression        string
+	hsts               bool
+	hstsMaxAge         time.Duration
+	hstsSubdomains     bool
+	maxConnections     int
+	maxRecvMsgSize     int
+	maxSendMsgSize     int
+	maxBeaconLag       uint64
+	thresholdWait      time.Duration
+	auditLogPath       string
+	keyFile            string
+	wellKnownDir       string
+	beaconIDPrefix     string
+	middlewares        []func(net.Service) net.Service
 }
 
 // NewConfig returns the config to pass to drand with the default options set
@@ -46,9 +69,15 @@ func NewConfig(opts ...ConfigOption) *Config {
 		configFolder: DefaultConfigFolder(),
 		dkgTimeout:   DefaultDKGTimeout,
 		//certmanager: net.NewCertManager(),
-		controlPort: DefaultControlPort,
-		logger:      log.DefaultLogger(),
-		clock:       clock.NewRealClock(),
+		controlPort:        DefaultControlPort,
+		controlStopTimeout: DefaultControlStopTimeout,
+		logger:             log.DefaultLogger(),
+		clock:              clock.NewRealClock(),
+		corsOrigins:        DefaultCORSOrigins,
+		maxConnections:     DefaultMaxConnections,
+		maxRecvMsgSize:     DefaultMaxRecvMsgSize,
+		maxSendMsgSize:     DefaultMaxSendMsgSize,
+		thresholdWait:      DefaultThresholdWait,
 	}
 	d.dbFolder = path.Join(d.configFolder, DefaultDBFolder)
 	for i := range opts {
@@ -63,6 +92,61 @@ func (d *Config) ConfigFolder() string {
 	return d.configFolder
 }
 
+// ConfigError is returned by Config.Validate with all the problems found
+type ConfigError struct {
+	Problems []error
+}
+
+func (e *ConfigError) Error() string {
+	msgs := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		msgs[i] = p.Error()
+	}
+	return "config: " + strings.Join(msgs, "; ")
+}
+
+// Is returns true if any of the problems is target
+func (e *ConfigError) Is(target error) bool {
+	for _, p := range e.Problems {
+		if errors.Is(p, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the config folder is a writable directory that no key
+// store holds, i.e. that a key store can be opened on it, and that the beacon
+// id prefix is valid. It must be called before the store is opened.
+func (d *Config) Validate() error {
+	var problems []error
+	if err := ValidateBeaconIDPrefix(d.beaconIDPrefix); err != nil {
+		problems = append(problems, err)
+	}
+	folder := d.ConfigFolder()
+	info, err := os.Stat(folder)
+	switch {
+	case err != nil:
+		problems = append(problems, fmt.Errorf("config folder: %w", err))
+	case !info.IsDir():
+		problems = append(problems, fmt.Errorf("config folder %s is not a directory", folder))
+	default:
+		if f, err := ioutil.TempFile(folder, ".write-check-*"); err != nil {
+			problems = append(problems, fmt.Errorf("config folder %s is not writable: %w", folder, err))
+		} else {
+			f.Close()
+			os.Remove(f.Name())
+		}
+		if err := key.CheckUnlocked(folder); err != nil {
+			problems = append(problems, err)
+		}
+	}
+	if len(problems) > 0 {
+		return &ConfigError{Problems: problems}
+	}
+	return nil
+}
+
 // DBFolder returns the folder under which drand stores all generated beacons.
 func (d *Config) DBFolder() string {
 	return d.dbFolder
@@ -107,6 +191,42 @@ func (d *Config) Logger() log.Logger {
 	return d.logger
 }
 
+// checkReloadable returns ErrNotReloadable if the given config changes a
+// setting that is only read when drand starts.
+func (d *Config) checkReloadable(c *Config) error {
+	fixed := []struct {
+		name    string
+		changed bool
+	}{
+		{"config folder", d.configFolder != c.configFolder},
+		{"db folder", d.dbFolder != c.dbFolder},
+		{"private listen address", d.privateListenAddr != c.privateListenAddr},
+		{"public listen address", d.publicListenAddr != c.publicListenAddr},
+		{"control port", d.controlPort != c.controlPort},
+		{"insecure", d.insecure != c.insecure},
+		{"tls certificate", d.certPath != c.certPath},
+		{"tls key", d.keyPath != c.keyPath},
+		{"cors origins", strings.Join(d.corsOrigins, ",") != strings.Join(c.corsOrigins, ",")},
+		{"response compression", d.compression != c.compression},
+		{"hsts", d.hsts != c.hsts || d.hstsMaxAge != c.hstsMaxAge || d.hstsSubdomains != c.hstsSubdomains},
+		{"max connections", d.maxConnections != c.maxConnections},
+		{"max message size", d.maxRecvMsgSize != c.maxRecvMsgSize || d.maxSendMsgSize != c.maxSendMsgSize},
+		{"max beacon lag", d.maxBeaconLag != c.maxBeaconLag},
+		{"audit log", d.auditLogPath != c.auditLogPath},
+		{"key file", d.keyFile != c.keyFile},
+		{"well-known dir", d.wellKnownDir != c.wellKnownDir},
+		{"beacon id prefix", d.beaconIDPrefix != c.beaconIDPrefix},
+		{"private randomness", d.enablePrivate != c.enablePrivate},
+		{"log level", d.logLevel == nil && c.logLevel != nil},
+	}
+	for _, f := range fixed {
+		if f.changed {
+			return fmt.Errorf("%w: %s", ErrNotReloadable, f.name)
+		}
+	}
+	return nil
+}
+
 func (d *Config) callbacks(b *chain.Beacon) {
 	for _, fn := range d.beaconCbs {
 		fn(b)
@@ -127,6 +247,81 @@ func WithGrpcOptions(opts ...grpc.DialOption) ConfigOption {
 	}
 }
 
+// WithServiceMiddlewares adds middlewares wrapping the gRPC services of drand,
+// i.e. the private and control APIs. They are chained in the given order, the
+// first one seeing the requests first.
+func WithServiceMiddlewares(middlewares ...func(net.Service) net.Service) ConfigOption {
+	return func(d *Config) {
+		d.middlewares = append(d.middlewares, middlewares...)
+	}
+}
+
+// WithAuditLog appends a JSON line to the file at path for each call to the
+// Protocol and Control services, with the time, the method, the remote
+// address, the hash of the request and the status of the response. The public
+// reads are not logged. The file is created if needed and written in the
+// background, see net.AuditLog and net.AuditEntry.
+func WithAuditLog(path string) ConfigOption {
+	return func(d *Config) {
+		d.auditLogPath = path
+	}
+}
+
+// WithKeyFile makes drand read its private key from the file at path instead
+// of from its config folder, as done by UseKeyFile. See key.NewKeyFileStore.
+func WithKeyFile(path string) ConfigOption {
+	return func(d *Config) {
+		d.keyFile = path
+	}
+}
+
+// WithWellKnownDir serves the files of dir under /.well-known/ on the public
+// listener, e.g. a drand-chain file with the chain info for the clients to
+// discover the chain. The files are listed at startup; their content is read
+// again at most every 10 seconds. See net.PublicGateway.ServeFile.
+func WithWellKnownDir(dir string) ConfigOption {
+	return func(d *Config) {
+		d.wellKnownDir = dir
+	}
+}
+
+// WithBeaconIDPrefix prepends prefix, followed by a dash, to the ids of the
+// beacons run by drand, to keep apart the beacons of the organizations sharing
+// a deployment. It must pass ValidateBeaconIDPrefix.
+func WithBeaconIDPrefix(prefix string) ConfigOption {
+	return func(d *Config) {
+		d.beaconIDPrefix = prefix
+	}
+}
+
+// MaxBeaconIDPrefixLen is the maximum length of a beacon id prefix.
+const MaxBeaconIDPrefixLen = 32
+
+// ValidateBeaconIDPrefix returns an error if prefix is longer than
+// MaxBeaconIDPrefixLen or has other characters than ASCII letters and digits.
+// The empty prefix is valid.
+func ValidateBeaconIDPrefix(prefix string) error {
+	if len(prefix) > MaxBeaconIDPrefixLen {
+		return fmt.Errorf("beacon id prefix longer than %d characters", MaxBeaconIDPrefixLen)
+	}
+	for _, r := range prefix {
+		if !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9') {
+			return fmt.Errorf("beacon id prefix %q is not alphanumeric", prefix)
+		}
+	}
+	return nil
+}
+
+// BeaconID returns the id of the beacon run by the given group: the hex
+// encoded hash of the group, after the beacon id prefix if any.
+func (d *Config) BeaconID(group *key.Group) string {
+	id := hex.EncodeToString(group.Hash())
+	if d.beaconIDPrefix == "" {
+		return id
+	}
+	return d.beaconIDPrefix + "-" + id
+}
+
 // WithCallOption applies grpc options when drand calls a gRPC method.
 func WithCallOption(opts ...grpc.CallOption) ConfigOption {
 	return func(d *Config) {
@@ -229,6 +424,80 @@ func WithPublicListenAddress(addr string) ConfigOption {
 	}
 }
 
+// WithCORSOrigins specifies the origins allowed to make cross-origin requests
+// to the public HTTP endpoint. Passing "*" allows any origin.
+func WithCORSOrigins(origins []string) ConfigOption {
+	return func(d *Config) {
+		d.corsOrigins = origins
+	}
+}
+
+// WithResponseCompression compresses the responses of the public HTTP endpoint
+// with the given algorithm, for the clients that accept it. Only "gzip" is
+// supported.
+func WithResponseCompression(algo string) ConfigOption {
+	return func(d *Config) {
+		d.compression = algo
+	}
+}
+
+// WithHSTS makes the public HTTP endpoint send a Strict-Transport-Security
+// header with the given max age on its responses, so browsers only reach it
+// over HTTPS. It can't be used with WithInsecure.
+func WithHSTS(maxAge time.Duration, includeSubdomains bool) ConfigOption {
+	return func(d *Config) {
+		d.hsts = true
+		d.hstsMaxAge = maxAge
+		d.hstsSubdomains = includeSubdomains
+	}
+}
+
+// WithMaxConnections sets the maximum number of simultaneous connections the
+// private gateway accepts. Connections above the limit are refused right away.
+// A value <= 0 removes the limit.
+func WithMaxConnections(n int) ConfigOption {
+	return func(d *Config) {
+		d.maxConnections = n
+	}
+}
+
+// WithMaxRecvMsgSize sets the maximum size, in bytes, of a request the private
+// gateway accepts. Larger requests fail with a ResourceExhausted status. A
+// value <= 0 keeps the gRPC default of 4 MiB.
+func WithMaxRecvMsgSize(bytes int) ConfigOption {
+	return func(d *Config) {
+		d.maxRecvMsgSize = bytes
+	}
+}
+
+// WithMaxSendMsgSize sets the maximum size, in bytes, of a response of the
+// private gateway. A value <= 0 keeps the gRPC default, i.e. no limit.
+func WithMaxSendMsgSize(bytes int) ConfigOption {
+	return func(d *Config) {
+		d.maxSendMsgSize = bytes
+	}
+}
+
+// WithMaxBeaconLag makes the node freeze its beacon generation when its last
+// stored round falls more than rounds behind the current round of the chain,
+// so it stops taking part in the rounds until the operator unfreezes it. Zero
+// disables the check.
+func WithMaxBeaconLag(rounds uint64) ConfigOption {
+	return func(d *Config) {
+		d.maxBeaconLag = rounds
+	}
+}
+
+// WithThresholdWait sets the extra time the leader of a setup waits for the
+// nodes, after the wait timeout, when less nodes than the threshold signaled
+// themselves. If the threshold is still not met, the setup fails with
+// ErrInsufficientKeys.
+func WithThresholdWait(t time.Duration) ConfigOption {
+	return func(d *Config) {
+		d.thresholdWait = t
+	}
+}
+
 // WithPrivateListenAddress specifies the address the drand instance should bind to. It
 // is useful if you want to advertise a public proxy address and the drand
 // instance runs behind your network.
@@ -246,10 +515,19 @@ func WithControlPort(port string) ConfigOption {
 	}
 }
 
+// WithControlStopTimeout sets the maximum time the control listener waits for
+// in-flight requests to finish when drand stops.
+func WithControlStopTimeout(t time.Duration) ConfigOption {
+	return func(d *Config) {
+		d.controlStopTimeout = t
+	}
+}
+
 // WithLogLevel sets the logging verbosity to the given level.
 func WithLogLevel(level int) ConfigOption {
 	return func(d *Config) {
-		d.logger = log.NewLogger(nil, level)
+		d.logLevel = log.NewLevelVar(level)
+		d.logger = log.NewLoggerWithLevel(nil, d.logLevel)
 	}
 }
 
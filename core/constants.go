@@ -29,6 +29,48 @@ const DefaultBeaconPeriod time.Duration = 1 * time.Minute
 // DefaultControlPort is the default port the functionnality control port communicate on.
 const DefaultControlPort = "8888"
 
+// DefaultControlStopTimeout is the maximum time the control listener waits for
+// in-flight requests to finish when the daemon stops, before closing the
+// remaining connections.
+const DefaultControlStopTimeout = 5 * time.Second
+
+// DrainCallbacksTimeout is the maximum time the beacon waits, when it stops,
+// for the registered callbacks such as the public streams to process the
+// latest beacon.
+var DrainCallbacksTimeout = 5 * time.Second
+
+// DrainRoundTimeout is the maximum time the beacon waits, when it stops, for
+// the round being aggregated to complete before it is stopped anyway.
+var DrainRoundTimeout = 5 * time.Second
+
+// MinPanicRecoverInterval is the minimum time between two restarts of the
+// beacon after a panic of a method feeding it.
+var MinPanicRecoverInterval = 1 * time.Minute
+
+// StreamBatchSize is the maximum number of beacons loaded at once from the
+// store when a public stream starts at a past round.
+var StreamBatchSize uint64 = 1000
+
+// DefaultCORSOrigins is the list of origins allowed to query the public HTTP
+// endpoint from a browser. By default, any origin is allowed.
+var DefaultCORSOrigins = []string{"*"}
+
+// DefaultMaxConnections is the maximum number of simultaneous connections the
+// private gateway accepts from other nodes and clients.
+const DefaultMaxConnections = 1000
+
+// DefaultMaxRecvMsgSize is the maximum size, in bytes, of a request the private
+// gateway accepts from other nodes and clients. The largest messages are the
+// DKG deal bundles, which hold one encrypted share per node and the public
+// polynomial: under 200 KiB for a group of 1000 nodes, so 4 MiB leaves ample
+// room for any realistic group while bounding the memory a peer can take.
+const DefaultMaxRecvMsgSize = 4 << 20
+
+// DefaultMaxSendMsgSize is the maximum size, in bytes, of a response of the
+// private gateway. The largest responses, the group file and the synced
+// beacons, are well under it.
+const DefaultMaxSendMsgSize = 4 << 20
+
 // DefaultDKGTimeout is the default time of each DKG period by default. Note
 // that by default, DKG uses the "fast sync" mode that shorten the first phase
 // and the second phase, "as fast as possible" when the protocol runs smoothly
@@ -44,6 +86,24 @@ var EciesHash = sha256.New
 // canceled.
 var MaxWaitPrepareDKG = 24 * 7 * 2 * time.Hour
 
+// DefaultThresholdWait is the extra time the leader of a setup waits, after the
+// wait timeout, when less nodes than the threshold signaled themselves.
+const DefaultThresholdWait = 1 * time.Minute
+
+// JoinRetryPeriod is the time a node joining an existing chain waits between
+// two attempts to signal its key to the leader, until the leader starts a
+// resharing that includes it.
+var JoinRetryPeriod = 5 * time.Second
+
+// DKGRetryBackoff is the time a node waits before sending again a DKG packet
+// that another node failed to receive, when the setup asks for retries.
+var DKGRetryBackoff = 100 * time.Millisecond
+
+// MaxDKGRetries is the highest number of times a node sends again a DKG packet
+// that another node failed to receive. The setups asking for more retries get
+// MaxDKGRetries.
+const MaxDKGRetries = 50
+
 // DefaultGenesisOffset is the time the leader adds after the maximum DKG time
 // (the full three phases) to compute the genesis time of the randomness chain.
 const DefaultGenesisOffset = 1 * time.Second
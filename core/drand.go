@@ -2,8 +2,13 @@ package core
 
 import (
 	"context"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
+	nethttp "net/http"
+	"os"
+	"path"
 	"strings"
 	"sync"
 	"time"
@@ -27,35 +32,71 @@ type Drand struct {
 	priv *key.Pair
 	// current group this drand node is using
 	group *key.Group
+	// index of this node in the current group. It is undefined until
+	// resolveIndex has been called.
 	index int
 
 	store       key.Store
 	privGateway *net.PrivateGateway
 	pubGateway  *net.PublicGateway
 	control     net.ControlListener
+	// auditLog is the file of the audit log, if enabled
+	auditLog io.Closer
 
 	beacon *beacon.Handler
+	// last progress reported by the beacon while catching up
+	catchup    beacon.CatchupStatus
+	catchingUp bool
+	// frozen is set while the beacon generation is halted by Freeze.
+	// startOnUnfreeze records a StartBeacon call made in the meantime.
+	frozen          bool
+	startOnUnfreeze bool
+	// recovering is set while RecoverBeacon restarts the beacon
+	recovering bool
+	// lastPanicRecover is the time of the last recovery of the beacon after a
+	// panic of the service
+	lastPanicRecover time.Time
+	// lagCancel stops the check of the beacon lag. lagTripped is set once
+	// the node froze because of the lag, until the lag is back within the
+	// maximum.
+	lagCancel  context.CancelFunc
+	lagTripped bool
+	// rejoin is set by LoadDrand when the node was down at the transition
+	// to its current group. The beacon then catches up before running.
+	rejoin bool
 	// dkg private share. can be nil if dkg not finished yet.
 	share   *key.Share
 	dkgDone bool
 	// manager is created and destroyed during a setup phase
 	manager  *setupManager
 	receiver *setupReceiver
+	// observer is set when the node joined the DKG as an observer
+	observer *ObserverProtocol
 
 	// dkgInfo contains all the information related to an upcoming or in
 	// progress dkg protocol. It is nil for the rest of the time.
 	dkgInfo *dkgInfo
 	// general logger
 	log log.Logger
+	// watchers of the lifecycle events of the node
+	events eventWatchers
 
 	// global state lock
-	state  sync.Mutex
-	exitCh chan bool
+	state sync.Mutex
+	// stopping is set at the start of stop, so no beacon is started meanwhile
+	stopping bool
+	exitCh   chan bool
+	// exitOnce makes sure drand stops and closes exitCh only once
+	exitOnce sync.Once
 
 	// that cancel function is set when the drand process is following a chain
 	// but not participating. Drand calls the cancel func when the node
-	// participates to a resharing.
+	// participates to a resharing. It is only set through syncerContext, which
+	// cancels any previous sync operation first, and cleared by the release
+	// function of the sync operation that set it or by stopSyncer.
 	syncerCancel context.CancelFunc
+	// syncerID identifies the sync operation that set syncerCancel
+	syncerID uint64
 
 	// only used for testing currently
 	// XXX need boundaries between gRPC and control plane such that we can give
@@ -70,6 +111,12 @@ func NewDrand(s key.Store, c *Config) (*Drand, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := d.abortDKG(); err != nil {
+		return nil, err
+	}
+	if err := d.resumeObserver(); err != nil {
+		return nil, err
+	}
 	return d, nil
 }
 
@@ -80,6 +127,16 @@ func initDrand(s key.Store, c *Config) (*Drand, error) {
 	if !c.insecure && (c.certPath == "" || c.keyPath == "") {
 		return nil, errors.New("config: need to set WithInsecure if no certificate and private key path given")
 	}
+	if c.hsts && c.insecure {
+		return nil, errors.New("config: HSTS can't be enabled without TLS")
+	}
+	if c.hsts && c.hstsMaxAge < 0 {
+		return nil, errors.New("config: the HSTS max age can't be negative")
+	}
+	s, err := withKeyFile(s, c)
+	if err != nil {
+		return nil, err
+	}
 	priv, err := s.LoadKeyPair()
 	if err != nil {
 		return nil, err
@@ -96,7 +153,7 @@ func initDrand(s key.Store, c *Config) (*Drand, error) {
 		priv:   priv,
 		opts:   c,
 		log:    logger,
-		exitCh: make(chan bool, 1),
+		exitCh: make(chan bool),
 	}
 	if err := setupDrand(d, c); err != nil {
 		return nil, err
@@ -115,21 +172,58 @@ func setupDrand(d *Drand, c *Config) error {
 	ctx := context.Background()
 	var err error
 	d.log.Info("network", "init", "insecure", c.insecure)
+	middlewares := c.middlewares
+	if c.auditLogPath != "" {
+		f, err := os.OpenFile(c.auditLogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return fmt.Errorf("drand: can't open audit log: %w", err)
+		}
+		auditLog := net.NewAuditLog(f, d.log)
+		d.auditLog = auditLog
+		// the audit log sees the calls first, so it also records the ones
+		// refused by the other middlewares
+		middlewares = append([]func(net.Service) net.Service{net.WithAuditLog(auditLog, d.log)}, middlewares...)
+	}
+	// the HTTP API goes through the same middlewares as the gRPC one
+	service := net.WithPanicRecovery(net.Chain(middlewares...)(d), d.log, d.onServicePanic)
 	if pubAddr != "" {
-		handler, err := http.New(ctx, &drandProxy{d}, c.Version(), d.log.With("server", "http"))
+		handler, err := http.New(ctx, &nodeProxy{drandProxy{service}, d}, c.Version(), d.log.With("server", "http"))
 		if err != nil {
+			d.closeAuditLog()
 			return err
 		}
-		if d.pubGateway, err = net.NewRESTPublicGateway(ctx, pubAddr, c.certPath, c.keyPath, c.certmanager, handler, c.insecure); err != nil {
-			return err
+		// the well-known files are served through the same chain as the API
+		if d.pubGateway, err = net.NewRESTPublicGateway(ctx, pubAddr, c.certPath, c.keyPath, c.certmanager, handler, c.wrapPublicHandler, c.insecure); err != nil {
+			d.closeAuditLog()
+			return fmt.Errorf("drand: can't create public gateway: %w", err)
+		}
+		if c.wellKnownDir != "" {
+			if err := mountWellKnown(d.pubGateway, c.wellKnownDir); err != nil {
+				d.pubGateway.StopAll(ctx)
+				d.closeAuditLog()
+				return err
+			}
 		}
 	}
-	d.privGateway, err = net.NewGRPCPrivateGateway(ctx, privAddr, c.certPath, c.keyPath, c.certmanager, d, c.insecure, d.opts.grpcOpts...)
+	d.privGateway, err = net.NewGRPCPrivateGateway(ctx, privAddr, c.certPath, c.keyPath, c.certmanager, service, c.insecure, c.maxConnections, c.maxRecvMsgSize, c.maxSendMsgSize, d.opts.grpcOpts...)
 	if err != nil {
-		return err
+		// release the addresses bound so far
+		if d.pubGateway != nil {
+			d.pubGateway.StopAll(ctx)
+		}
+		d.closeAuditLog()
+		return fmt.Errorf("drand: can't create private gateway: %w", err)
 	}
 	p := c.ControlPort()
-	d.control = net.NewTCPGrpcControlListener(d, p)
+	d.control, err = net.NewTCPGrpcControlListener(service, p)
+	if err != nil {
+		if d.pubGateway != nil {
+			d.pubGateway.StopAll(ctx)
+		}
+		d.privGateway.StopAll(ctx)
+		d.closeAuditLog()
+		return fmt.Errorf("drand: can't create control listener: %w", err)
+	}
 	go d.control.Start()
 	d.log.Info("private_listen", privAddr, "control_port", c.ControlPort(), "public_listen", pubAddr, "folder", d.opts.ConfigFolder())
 	d.privGateway.StartAll()
@@ -139,6 +233,22 @@ func setupDrand(d *Drand, c *Config) error {
 	return nil
 }
 
+// wrapPublicHandler applies the compression, CORS and HSTS options to the
+// handler of the public HTTP endpoint.
+func (c *Config) wrapPublicHandler(h nethttp.Handler) (nethttp.Handler, error) {
+	if c.compression != "" {
+		var err error
+		if h, err = http.NewCompressionHandler(h, c.compression); err != nil {
+			return nil, err
+		}
+	}
+	h = http.NewCORSHandler(h, c.corsOrigins)
+	if c.hsts {
+		h = http.NewHSTSHandler(h, c.hstsMaxAge, c.hstsSubdomains)
+	}
+	return h, nil
+}
+
 // LoadDrand restores a drand instance that is ready to serve randomness, with a
 // pre-existing distributed share.
 func LoadDrand(s key.Store, c *Config) (*Drand, error) {
@@ -151,15 +261,81 @@ func LoadDrand(s key.Store, c *Config) (*Drand, error) {
 		return nil, err
 	}
 	checkGroup(d.log, d.group)
+	if err := d.resolveIndex(); err != nil {
+		return nil, err
+	}
 	d.share, err = s.LoadShare()
 	if err != nil {
 		return nil, err
 	}
 	d.log.Debug("serving", d.priv.Public.Address())
 	d.dkgDone = true
+	store, err := d.createBoltStore()
+	if err != nil {
+		return nil, err
+	}
+	d.rejoin, err = d.missedTransition(store)
+	store.Close()
+	if err != nil {
+		return nil, err
+	}
+	if d.rejoin {
+		d.log.Info("transition", "missed transition window, attempting re-join", "transition_time", d.group.TransitionTime)
+	}
+	if err := d.abortDKG(); err != nil {
+		return nil, err
+	}
+	if err := d.resumeObserver(); err != nil {
+		return nil, err
+	}
 	return d, nil
 }
 
+// missedTransition returns true if the transition to the current group is
+// over but the node did not store the round of the transition, i.e. it was
+// down when its group took over the chain.
+func (d *Drand) missedTransition(store chain.Store) (bool, error) {
+	g := d.group
+	if g.TransitionTime == 0 || d.opts.clock.Now().Unix() < g.TransitionTime {
+		return false, nil
+	}
+	last, err := store.Last()
+	if errors.Is(err, boltdb.ErrNoBeaconSaved) {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	return last.Round < chain.CurrentRound(g.TransitionTime, g.Period, g.GenesisTime), nil
+}
+
+// abortDKG drops the DKG or resharing that was in progress when the node
+// stopped, if any. It can not be resumed: the dealer state is not persisted and
+// dealing a fresh secret for the same session would conflict with the deals
+// the other nodes already received, so the operator has to start a new one.
+func (d *Drand) abortDKG() error {
+	info, err := loadDKGInfo(d.store)
+	if err != nil || info == nil {
+		return err
+	}
+	d.log.Warn("dkg_abort", hex.EncodeToString(info.target.Hash()), "phase", info.phase, "started", info.started, "reshare", info.old != nil, "reason", "interrupted by restart")
+	return d.store.DeleteDKGState()
+}
+
+// ErrNotInGroup is returned when the keypair of this node is not part of the
+// group loaded.
+var ErrNotInGroup = errors.New("drand: keypair not included in the group")
+
+// resolveIndex sets the index of this node by looking up its public key in the
+// current group.
+func (d *Drand) resolveIndex() error {
+	node := d.group.Find(d.priv.Public)
+	if node == nil {
+		return ErrNotInGroup
+	}
+	d.index = int(node.Index)
+	return nil
+}
+
 // WaitDKG waits on the running dkg protocol. In case of an error, it returns
 // it. In case of a finished DKG protocol, it saves the dist. public  key and
 // private share. These should be loadable by the store.
@@ -169,17 +345,26 @@ func (d *Drand) WaitDKG() (*key.Group, error) {
 		d.state.Unlock()
 		return nil, errors.New("no dkg info set")
 	}
-	waitCh := d.dkgInfo.proto.WaitEnd()
+	info := d.dkgInfo
+	waitCh := info.proto.WaitEnd()
 	d.state.Unlock()
 
 	d.log.Debug("waiting_dkg_end", time.Now())
-	res := <-waitCh
+	var res dkg.OptionResult
+	select {
+	case res = <-waitCh:
+	case <-info.phaser.Aborted():
+		return nil, ErrDKGAborted
+	}
 	if res.Error != nil {
 		return nil, fmt.Errorf("drand: error from dkg: %v", res.Error)
 	}
 
 	d.state.Lock()
-	defer d.state.Unlock()
+	if d.dkgInfo != info {
+		d.state.Unlock()
+		return nil, ErrDKGAborted
+	}
 	// filter the nodes that are not present in the target group
 	var qualNodes []*key.Node
 	for _, node := range d.dkgInfo.target.Nodes {
@@ -190,46 +375,103 @@ func (d *Drand) WaitDKG() (*key.Group, error) {
 		}
 	}
 
+	disqualified := len(d.dkgInfo.target.Nodes) - len(qualNodes)
 	s := key.Share(*res.Result.Key)
-	d.share = &s
-	if err := d.store.SaveShare(d.share); err != nil {
-		return nil, err
-	}
 	targetGroup := d.dkgInfo.target
 	// only keep the qualified ones
 	targetGroup.Nodes = qualNodes
 	// setup the dist. public key
-	targetGroup.PublicKey = d.share.Public()
-	d.group = targetGroup
+	targetGroup.PublicKey = s.Public()
 	var output []string
 	for _, node := range qualNodes {
-		output = append(output, fmt.Sprintf("{addr: %s, idx: %d, pub: %s}", node.Address(), node.Index, node.Key))
+		output = append(output, fmt.Sprintf("{addr: %s, idx: %d, pub: %s}", node.Address(), node.Index, node.Fingerprint()))
 	}
-	d.log.Debug("dkg_end", time.Now(), "certified", d.group.Len(), "list", "["+strings.Join(output, ",")+"]")
-	if err := d.store.SaveGroup(d.group); err != nil {
+	d.log.Debug("dkg_end", time.Now(), "certified", targetGroup.Len(), "list", "["+strings.Join(output, ",")+"]")
+	d.log.Info("dkg_phase", "finish", "qualified", len(qualNodes), "disqualified", disqualified)
+	// a fresh DKG can not replace the group and share of an existing chain;
+	// a resharing replaces them.
+	if err := d.store.SaveGroupAndShare(targetGroup, &s, d.dkgInfo.old != nil); err != nil {
+		d.log.Error("dkg_end", "save", "err", err)
+		d.state.Unlock()
 		return nil, err
 	}
-	d.opts.applyDkgCallback(d.share)
+	d.group = targetGroup
+	d.share = &s
 	d.dkgInfo.board.Stop()
 	d.dkgInfo = nil
-	return d.group, nil
+	if err := d.store.DeleteDKGState(); err != nil {
+		d.log.Error("dkg_end", "delete_state", "err", err)
+	}
+	share, group := d.share, d.group
+	d.state.Unlock()
+	d.sendEvent(EventDKGFinished, fmt.Sprintf("qualified: %d, disqualified: %d", len(qualNodes), disqualified))
+	// the callback may call back into the node so it runs without the lock
+	d.opts.applyDkgCallback(share)
+	return group, nil
 }
 
 // StartBeacon initializes the beacon if needed and launch a go
 // routine that runs the generation loop.
+// It does nothing while the node is frozen: the beacon is then started by
+// Unfreeze.
 func (d *Drand) StartBeacon(catchup bool) {
+	d.state.Lock()
+	if d.stopping {
+		d.state.Unlock()
+		d.log.Info("beacon_start", "drand stopped")
+		return
+	}
+	if d.frozen {
+		d.startOnUnfreeze = true
+		d.state.Unlock()
+		d.log.Info("beacon_start", "frozen")
+		return
+	}
+	if d.rejoin && !catchup {
+		// the chain since the transition has to be synced from the group
+		// before running the beacon
+		d.log.Info("beacon_start", "rejoin", "catchup", true)
+		catchup = true
+	}
+	d.rejoin = false
+	d.state.Unlock()
 	b, err := d.newBeacon()
-	if err != nil {
+	if err == errStopping {
+		d.log.Info("beacon_start", "drand stopped")
+		return
+	} else if err != nil {
 		d.log.Error("init_beacon", err)
 		return
 	}
 
 	d.log.Info("beacon_start", time.Now(), "catchup", catchup)
 	if catchup {
+		d.state.Lock()
+		d.catchingUp = true
+		d.state.Unlock()
+		go d.watchCatchup(b.CatchupProgress())
 		go b.Catchup()
 	} else if err := b.Start(); err != nil {
 		d.log.Error("beacon_start", err)
+		return
 	}
+	d.sendEvent(EventBeaconStarted, fmt.Sprintf("catchup: %t", catchup))
+}
+
+// watchCatchup records and logs the progress of the beacon catching up until
+// it is done.
+func (d *Drand) watchCatchup(progress <-chan beacon.CatchupStatus) {
+	for status := range progress {
+		d.log.Info("catchup", "progress", "stored_round", status.StoredRound,
+			"target_round", status.TargetRound, "bytes", status.BytesDownloaded)
+		d.state.Lock()
+		d.catchup = status
+		d.state.Unlock()
+	}
+	d.log.Info("catchup", "done")
+	d.state.Lock()
+	d.catchingUp = false
+	d.state.Unlock()
 }
 
 // transition between an "old" group and a new group. This method is called
@@ -252,6 +494,7 @@ func (d *Drand) transition(oldGroup *key.Group, oldPresent, newPresent bool) {
 			d.log.Error("leaving_group", err)
 		} else {
 			d.log.Info("leaving_group", "done", "time", d.opts.clock.Now())
+			d.sendEvent(EventBeaconStopped, fmt.Sprintf("leaving the group at %d", timeToStop))
 		}
 		return
 	}
@@ -271,48 +514,252 @@ func (d *Drand) transition(oldGroup *key.Group, oldPresent, newPresent bool) {
 		}
 		if err := b.Transition(oldGroup); err != nil {
 			d.log.Error("sync_before", err)
+			return
 		}
 		d.log.Info("transition_new", "done")
+		d.sendEvent(EventBeaconStarted, fmt.Sprintf("joining the group at %d", d.group.TransitionTime))
 	}
 }
 
 // StopBeacon stops the beacon generation process and resets it.
 func (d *Drand) StopBeacon() {
+	d.state.Lock()
+	b := d.beacon
+	d.state.Unlock()
+	if b == nil {
+		return
+	}
+	// the state must not be locked while waiting since the partials of the
+	// round are processed under the lock
+	rctx, rcancel := context.WithTimeout(context.Background(), DrainRoundTimeout)
+	defer rcancel()
+	if err := b.WaitForRound(rctx); err != nil {
+		d.log.Warn("stop_beacon", "round not complete, force stop", "err", err)
+	}
+	// same for the callbacks, which can take the lock, e.g. a public stream
+	ctx, cancel := context.WithTimeout(context.Background(), DrainCallbacksTimeout)
+	defer cancel()
+	if err := b.DrainCallbacks(ctx); err != nil {
+		d.log.Error("stop_beacon", "drain_callbacks", "err", err)
+	}
+
 	d.state.Lock()
 	defer d.state.Unlock()
-	if d.beacon == nil {
+	if d.beacon != b {
+		// stopped or replaced in the meantime
 		return
 	}
 	d.beacon.Stop()
 	d.beacon = nil
+	d.sendEvent(EventBeaconStopped, "stopped")
+}
+
+// RecoverBeacon restarts the beacon from the group and share saved in the
+// store, for example after it panicked and may be left in a broken state. The
+// new beacon catches up the rounds missed in the meantime before running.
+func (d *Drand) RecoverBeacon() error {
+	d.state.Lock()
+	if !d.dkgDone {
+		d.state.Unlock()
+		return errors.New("drand: no beacon to recover, the dkg is not done")
+	}
+	if d.recovering {
+		d.state.Unlock()
+		return errors.New("drand: beacon recovery already in progress")
+	}
+	d.recovering = true
+	d.state.Unlock()
+	defer func() {
+		d.state.Lock()
+		d.recovering = false
+		d.state.Unlock()
+	}()
+
+	group, err := d.store.LoadGroup()
+	if err != nil {
+		return fmt.Errorf("drand: loading group: %w", err)
+	}
+	share, err := d.store.LoadShare()
+	if err != nil {
+		return fmt.Errorf("drand: loading share: %w", err)
+	}
+	d.log.Info("beacon_recover", "restarting")
+	d.StopBeacon()
+	d.state.Lock()
+	d.group = group
+	d.share = share
+	err = d.resolveIndex()
+	d.state.Unlock()
+	if err != nil {
+		return err
+	}
+	d.StartBeacon(true)
+	return nil
+}
+
+// onServicePanic restarts the beacon when a method feeding it panicked, as
+// the beacon may then be left in a broken state. The beacon is restarted at
+// most once per MinPanicRecoverInterval so the requests of a peer can not keep
+// it restarting.
+func (d *Drand) onServicePanic(method string) {
+	switch method {
+	case "PartialBeacon", "SyncChain":
+	default:
+		return
+	}
+	d.state.Lock()
+	now := d.opts.clock.Now()
+	if last := d.lastPanicRecover; !last.IsZero() && now.Sub(last) < MinPanicRecoverInterval {
+		d.state.Unlock()
+		d.log.Warn("beacon_recover", "skipped", "method", method, "last_recover", last)
+		return
+	}
+	d.lastPanicRecover = now
+	d.state.Unlock()
+	go func() {
+		if err := d.RecoverBeacon(); err != nil {
+			d.log.Error("beacon_recover", "failed", "method", method, "err", err)
+		}
+	}()
+}
+
+// Freeze temporarily halts the beacon generation, for example during a
+// maintenance, without stopping the node: the partial beacons of the other
+// nodes are refused and no new beacon is created or streamed, while the stored
+// beacons are still served. Unfreeze resumes the generation.
+func (d *Drand) Freeze() error {
+	d.state.Lock()
+	defer d.state.Unlock()
+	if d.frozen {
+		return errors.New("drand: beacon generation already frozen")
+	}
+	d.frozen = true
+	if d.beacon != nil {
+		d.beacon.Pause()
+	}
+	d.log.Info("freeze", "beacon generation halted")
+	return nil
+}
+
+// Unfreeze resumes the beacon generation halted by Freeze. The beacon builds
+// on the last stored round and catches up with the rounds created by the
+// other nodes in the meantime.
+func (d *Drand) Unfreeze() error {
+	d.state.Lock()
+	if !d.frozen {
+		d.state.Unlock()
+		return errors.New("drand: beacon generation not frozen")
+	}
+	d.frozen = false
+	b := d.beacon
+	start := d.startOnUnfreeze
+	d.startOnUnfreeze = false
+	d.state.Unlock()
+	d.log.Info("unfreeze", "beacon generation resumed")
+	if start {
+		d.StartBeacon(true)
+	} else if b != nil {
+		b.Resume()
+	}
+	return nil
 }
 
-// Stop simply stops all drand operations.
+// Stop simply stops all drand operations. In-flight requests have until the
+// deadline of ctx to finish, after which their connections are closed. It can
+// be called several times, concurrently or not: only the first call stops
+// drand, the others wait for it to finish.
 func (d *Drand) Stop(ctx context.Context) {
+	d.exitOnce.Do(func() {
+		d.stop(ctx)
+		close(d.exitCh)
+	})
+}
+
+func (d *Drand) stop(ctx context.Context) {
+	d.state.Lock()
+	d.stopping = true
+	d.state.Unlock()
 	d.StopBeacon()
+	// take what needs to be stopped under the lock but stop it without, since
+	// the gateways wait for the in-flight requests which may need the lock
+	d.stopObserver()
 	d.state.Lock()
-	if d.pubGateway != nil {
-		d.pubGateway.StopAll(ctx)
+	if d.lagCancel != nil {
+		d.lagCancel()
+		d.lagCancel = nil
+	}
+	pubGateway, privGateway, control := d.pubGateway, d.privGateway, d.control
+	controlTimeout := d.opts.controlStopTimeout
+	d.state.Unlock()
+
+	if pubGateway != nil {
+		pubGateway.StopAll(ctx)
+	}
+	privGateway.StopAll(ctx)
+	if deadline, ok := ctx.Deadline(); ok {
+		controlTimeout = time.Until(deadline)
 	}
-	d.privGateway.StopAll(ctx)
-	d.control.Stop()
+	if err := control.GracefulStop(controlTimeout); err != nil {
+		d.log.Warn("control", "stop", "err", err)
+	}
+	d.state.Lock()
+	d.closeAuditLog()
 	d.state.Unlock()
-	d.exitCh <- true
 }
 
-// WaitExit returns a channel that signals when drand stops its operations
+// closeAuditLog writes the pending entries of the audit log and closes its
+// file, if enabled
+func (d *Drand) closeAuditLog() {
+	if d.auditLog == nil {
+		return
+	}
+	if err := d.auditLog.Close(); err != nil {
+		d.log.Warn("audit", "close", "err", err)
+	}
+	d.auditLog = nil
+}
+
+// WaitExit returns a channel that is closed when drand stops its operations
 func (d *Drand) WaitExit() chan bool {
 	return d.exitCh
 }
 
+// ErrNotReloadable is returned by ReloadConfig when the new configuration
+// changes a setting that requires to restart drand.
+var ErrNotReloadable = errors.New("drand: setting can not change without a restart")
+
+// ReloadConfig applies the settings of the given configuration that can change
+// while drand runs: the log level and the control stop timeout. If any other
+// setting, such as a listen address or the TLS certificate, differs from the
+// running configuration, it changes nothing and returns ErrNotReloadable.
+func (d *Drand) ReloadConfig(c *Config) error {
+	d.state.Lock()
+	defer d.state.Unlock()
+	if err := d.opts.checkReloadable(c); err != nil {
+		return err
+	}
+	if c.logLevel != nil {
+		d.opts.logLevel.Set(c.logLevel.Level())
+	}
+	d.opts.controlStopTimeout = c.controlStopTimeout
+	d.log.Info("config", "reloaded", "control_stop_timeout", c.controlStopTimeout)
+	return nil
+}
+
 func (d *Drand) createBoltStore() (chain.Store, error) {
 	fs.CreateSecureFolder(d.opts.DBFolder())
 	return boltdb.NewBoltStore(d.opts.dbFolder, d.opts.boltOpts)
 }
 
+// errStopping is returned by newBeacon once drand is stopping.
+var errStopping = errors.New("drand: stopping")
+
 func (d *Drand) newBeacon() (*beacon.Handler, error) {
 	d.state.Lock()
 	defer d.state.Unlock()
+	if d.stopping {
+		return nil, errStopping
+	}
 	store, err := d.createBoltStore()
 	if err != nil {
 		return nil, err
@@ -323,23 +770,65 @@ func (d *Drand) newBeacon() (*beacon.Handler, error) {
 		return nil, fmt.Errorf("public key %s not found in group", pub)
 	}
 	conf := &beacon.Config{
-		Public: node,
-		Group:  d.group,
-		Share:  d.share,
-		Clock:  d.opts.clock,
+		Public:    node,
+		Group:     d.group,
+		Share:     d.share,
+		Clock:     d.opts.clock,
+		PeersFile: path.Join(d.opts.ConfigFolder(), beacon.PeersFileName),
 	}
 	b, err := beacon.NewHandler(d.privGateway.ProtocolClient, store, conf, d.log)
 	if err != nil {
 		return nil, err
 	}
 	d.beacon = b
+	if d.frozen {
+		d.beacon.Pause()
+	}
 	d.beacon.AddCallback("opts", d.opts.callbacks)
+	if d.lagCancel == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		d.lagCancel = cancel
+		go d.watchBeaconLag(ctx, d.group.Period)
+	}
 	// cancel any sync operations
+	d.stopSyncer()
+	return d.beacon, nil
+}
+
+// syncerContext returns the context of a new sync operation derived from
+// parent and registers its cancel function as the current syncerCancel. A
+// previous sync operation still registered is cancelled first so its context
+// does not leak. The returned function cancels the context and clears
+// syncerCancel if it still belongs to this operation. The state lock must be
+// held when calling syncerContext, but not when calling the returned function.
+func (d *Drand) syncerContext(parent context.Context) (context.Context, func()) {
+	d.stopSyncer()
+	ctx, cancel := context.WithCancel(parent)
+	d.syncerID++
+	id := d.syncerID
+	d.syncerCancel = cancel
+	return ctx, func() {
+		d.state.Lock()
+		defer d.state.Unlock()
+		cancel()
+		if d.syncerID == id {
+			d.syncerCancel = nil
+		}
+	}
+}
+
+// stopSyncer cancels the current sync operation, if any. The state lock must
+// be held.
+func (d *Drand) stopSyncer() {
 	if d.syncerCancel != nil {
 		d.syncerCancel()
 		d.syncerCancel = nil
 	}
-	return d.beacon, nil
+}
+
+// String returns the address of the node and the fingerprint of its key.
+func (d *Drand) String() string {
+	return d.priv.Public.String()
 }
 
 func checkGroup(l log.Logger, group *key.Group) {
@@ -349,7 +838,7 @@ func checkGroup(l log.Logger, group *key.Group) {
 	}
 	var info []string
 	for _, n := range unsigned {
-		info = append(info, fmt.Sprintf("{%s - %s}", n.Address(), key.PointToString(n.Key)[0:10]))
+		info = append(info, fmt.Sprintf("{%s - %s}", n.Address(), n.Fingerprint()))
 	}
 	l.Info("UNSIGNED_GROUP", "["+strings.Join(info, ",")+"]", "FIX", "upgrade")
 }
@@ -359,8 +848,105 @@ func checkGroup(l log.Logger, group *key.Group) {
 type dkgInfo struct {
 	target  *key.Group
 	board   Broadcast
-	phaser  *dkg.TimePhaser
+	phaser  *dkgPhaser
 	conf    *dkg.Config
 	proto   *dkg.Protocol
 	started bool
+	// old is the group being reshared, nil in case of a fresh DKG
+	old     *key.Group
+	timeout uint32
+	retries uint32
+	phase   dkg.Phase
+	// cancel stops the retries of the packets of the board
+	cancel context.CancelFunc
+}
+
+// Persist saves the state of the DKG to the store so the node can detect and
+// clean up an interrupted protocol when it restarts.
+func (i *dkgInfo) Persist(store key.Store) error {
+	return store.SaveDKGState(&key.DKGState{
+		Target:  i.target,
+		Old:     i.old,
+		Timeout: i.timeout,
+		Retries: i.retries,
+		Started: i.started,
+		Phase:   int(i.phase),
+	})
+}
+
+// loadDKGInfo returns the information of the DKG saved in the store, or nil if
+// there was no DKG in progress. Only the persisted fields are set.
+func loadDKGInfo(store key.Store) (*dkgInfo, error) {
+	state, err := store.LoadDKGState()
+	if err != nil || state == nil {
+		return nil, err
+	}
+	return &dkgInfo{
+		target:  state.Target,
+		old:     state.Old,
+		timeout: state.Timeout,
+		retries: state.Retries,
+		started: state.Started,
+		phase:   dkg.Phase(state.Phase),
+	}, nil
+}
+
+// dkgPhaser wraps a time phaser to be notified of each phase transition
+type dkgPhaser struct {
+	*dkg.TimePhaser
+	out       chan dkg.Phase
+	onPhase   func(dkg.Phase)
+	aborted   chan struct{}
+	abortOnce sync.Once
+}
+
+func newDKGPhaser(p *dkg.TimePhaser, onPhase func(dkg.Phase)) *dkgPhaser {
+	return &dkgPhaser{
+		TimePhaser: p,
+		out:        make(chan dkg.Phase, 4),
+		onPhase:    onPhase,
+		aborted:    make(chan struct{}),
+	}
+}
+
+// Abort stops forwarding the phases and moves the protocol to the finish
+// phase so it returns, whatever phase it was in.
+func (p *dkgPhaser) Abort() {
+	p.abortOnce.Do(func() {
+		close(p.aborted)
+		select {
+		case p.out <- dkg.FinishPhase:
+		default:
+		}
+	})
+}
+
+// Aborted returns a channel closed once the phaser is aborted.
+func (p *dkgPhaser) Aborted() <-chan struct{} {
+	return p.aborted
+}
+
+// NextPhase implements the dkg.Phaser interface
+func (p *dkgPhaser) NextPhase() chan dkg.Phase {
+	return p.out
+}
+
+// Start runs the time phaser and forwards each phase to the protocol once
+// onPhase returned.
+func (p *dkgPhaser) Start() {
+	go func() {
+		for phase := range p.TimePhaser.NextPhase() {
+			select {
+			case <-p.aborted:
+				return
+			default:
+			}
+			p.onPhase(phase)
+			p.out <- phase
+			if phase == dkg.FinishPhase {
+				return
+			}
+		}
+	}()
+	p.TimePhaser.Start()
 }
@@ -9,13 +9,16 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"path"
 	"strings"
 	"time"
 
 	"github.com/drand/drand/chain"
 	"github.com/drand/drand/chain/beacon"
 	"github.com/drand/drand/entropy"
+	"github.com/drand/drand/fs"
 	"github.com/drand/drand/key"
 	"github.com/drand/drand/log"
 	"github.com/drand/drand/net"
@@ -28,6 +31,23 @@ import (
 // errPreempted is returned on reshares when a subsequent reshare is started concurrently
 var errPreempted = errors.New("time out: pre-empted")
 
+// ErrInsufficientParticipants is returned to the leader of a setup when less
+// nodes than expected signaled themselves before the wait timeout.
+var ErrInsufficientParticipants = errors.New("drand: insufficient participants")
+
+// ErrInsufficientKeys is returned to the leader of a setup when less nodes than
+// the threshold signaled themselves, even after the extra threshold wait.
+var ErrInsufficientKeys = errors.New("drand: insufficient keys to meet the threshold")
+
+// ErrDKGAborted is returned by a DKG or a resharing aborted with
+// GracefulDKGAbort.
+var ErrDKGAborted = errors.New("drand: dkg aborted")
+
+// ErrSetupInProgress is returned when this node is asked to lead a new DKG or
+// resharing setup while it is already leading one, unless the new setup is
+// forced.
+var ErrSetupInProgress = errors.New("drand: setup already in progress; use --force")
+
 // InitDKG take a InitDKGPacket, extracts the informations needed and wait for
 // the DKG protocol to finish. If the request specifies this node is a leader,
 // it starts the DKG protocol.
@@ -39,6 +59,10 @@ func (d *Drand) InitDKG(c context.Context, in *drand.InitDKGPacket) (*drand.Grou
 		return nil, errors.New("dkg phase already done - call reshare")
 	}
 	d.state.Unlock()
+	if !in.GetInfo().GetObserver() {
+		// the node takes part in this DKG, it stops observing a previous one
+		d.stopObserver()
+	}
 	if !isLeader {
 		// different logic for leader than the rest
 		out, err := d.setupAutomaticDKG(c, in)
@@ -48,12 +72,14 @@ func (d *Drand) InitDKG(c context.Context, in *drand.InitDKGPacket) (*drand.Grou
 
 	// setup the manager
 	newSetup := func(d *Drand) (*setupManager, error) {
-		return newDKGSetup(d.log, d.opts.clock, d.priv.Public, in.GetBeaconPeriod(), in.GetCatchupPeriod(), in.GetInfo())
+		return newDKGSetup(d.log, d.opts.clock, d.priv.Public, in.GetBeaconPeriod(), in.GetCatchupPeriod(), in.GetGenesisTime(), in.GetInfo())
 	}
 
 	// expect the group
-	group, err := d.leaderRunSetup(newSetup)
-	if err != nil {
+	group, err := d.leaderRunSetup(newSetup, in.GetInfo().GetForce())
+	if err == ErrSetupInProgress || errors.Is(err, ErrInsufficientParticipants) || errors.Is(err, ErrInsufficientKeys) {
+		return nil, err
+	} else if err != nil {
 		d.log.Error("init_dkg", "leader setup", "err", err)
 		return nil, fmt.Errorf("drand: invalid setup configuration: %s", err)
 	}
@@ -63,17 +89,26 @@ func (d *Drand) InitDKG(c context.Context, in *drand.InitDKGPacket) (*drand.Grou
 	if err := d.pushDKGInfo([]*key.Node{}, nodes, 0, group, in.GetInfo().GetSecret(), in.GetInfo().GetTimeout()); err != nil {
 		return nil, err
 	}
-	finalGroup, err := d.runDKG(true, group, in.GetInfo().GetTimeout(), in.GetEntropy())
+	finalGroup, err := d.runDKG(true, group, in.GetInfo().GetTimeout(), in.GetInfo().GetRetries(), in.GetEntropy())
 	if err != nil {
 		return nil, err
 	}
 	return finalGroup.ToProto(), nil
 }
 
-func (d *Drand) leaderRunSetup(newSetup func(d *Drand) (*setupManager, error)) (group *key.Group, err error) {
+// leaderRunSetup runs a new setup manager and waits for the group it builds. Only
+// one setup can run at a time: if another one is in progress, it returns
+// ErrSetupInProgress unless force is true, in which case the other setup is
+// pre-empted. The manager is cleared once the setup is over.
+func (d *Drand) leaderRunSetup(newSetup func(d *Drand) (*setupManager, error), force bool) (group *key.Group, err error) {
 	// setup the manager
 	d.state.Lock()
 	if d.manager != nil {
+		if !force {
+			d.log.Info("reshare", "already_in_progress", "restart", "NOT AUTHORIZED")
+			d.state.Unlock()
+			return nil, ErrSetupInProgress
+		}
 		d.log.Info("reshare", "already_in_progress", "restart", "reshare", "old")
 		d.manager.StopPreemptively()
 	}
@@ -99,29 +134,43 @@ func (d *Drand) leaderRunSetup(newSetup func(d *Drand) (*setupManager, error)) (
 
 	// wait to receive the keys & send them to the other nodes
 	var ok bool
-	select {
-	case group, ok = <-manager.WaitGroup():
-		if ok {
+	timeout := time.After(manager.waitTimeout)
+	extended := false
+	for group == nil {
+		select {
+		case group, ok = <-manager.WaitGroup():
+			if !ok {
+				d.log.Debug("init_dkg", "pre-empted")
+				return nil, errPreempted
+			}
 			var addr []string
 			for _, k := range group.Nodes {
 				addr = append(addr, k.Address())
 			}
 			d.log.Debug("init_dkg", "setup_phase", "keys_received", "["+strings.Join(addr, "-")+"]")
-		} else {
-			d.log.Debug("init_dkg", "pre-empted")
-			return nil, errPreempted
+		case <-timeout:
+			arrived := manager.Arrived()
+			if arrived < manager.thr && !extended {
+				// too few nodes to even run a DKG: they may still be starting
+				d.log.Warn("init_dkg", "below_threshold", "arrived", arrived, "threshold", manager.thr, "wait", d.opts.thresholdWait)
+				timeout = time.After(d.opts.thresholdWait)
+				extended = true
+				continue
+			}
+			d.log.Info("init_dkg", "time_out", "arrived", arrived, "expected", manager.expected)
+			manager.StopPreemptively()
+			if arrived < manager.thr {
+				return nil, fmt.Errorf("%w: %d/%d nodes arrived, threshold %d", ErrInsufficientKeys, arrived, manager.expected, manager.thr)
+			}
+			return nil, fmt.Errorf("%w: %d/%d nodes arrived", ErrInsufficientParticipants, arrived, manager.expected)
 		}
-	case <-time.After(MaxWaitPrepareDKG):
-		d.log.Debug("init_dkg", "time_out")
-		manager.StopPreemptively()
-		return nil, errors.New("time outs: no key received")
 	}
 	return group, nil
 }
 
 // runDKG setups the proper structures and protocol to run the DKG and waits
 // until it finishes. If leader is true, this node sends the first packet.
-func (d *Drand) runDKG(leader bool, group *key.Group, timeout uint32, randomness *drand.EntropyInfo) (*key.Group, error) {
+func (d *Drand) runDKG(leader bool, group *key.Group, timeout, retries uint32, randomness *drand.EntropyInfo) (*key.Group, error) {
 	reader, user := extractEntropy(randomness)
 	config := &dkg.Config{
 		Suite:          key.KeyGroup.(dkg.Suite),
@@ -134,29 +183,46 @@ func (d *Drand) runDKG(leader bool, group *key.Group, timeout uint32, randomness
 		Nonce:          getNonce(group),
 		Auth:           key.DKGAuthScheme,
 	}
-	phaser := d.getPhaser(timeout)
-	board := newEchoBroadcast(d.log, d.privGateway.ProtocolClient, d.priv.Public.Address(), group.Nodes, func(p dkg.Packet) error {
+	// the observers receive the packets too so they can log them
+	to := nodeUnion(group.Nodes, group.Observers)
+	// the retries go on until the last phase is over, or the DKG is aborted
+	ctx, cancel := context.WithCancel(context.Background())
+	board := newEchoBroadcast(ctx, d.log, d.privGateway.ProtocolClient, d.priv.Public.Address(), to, retries, func(p dkg.Packet) error {
 		return dkg.VerifyPacketSignature(config, p)
 	})
+	var info *dkgInfo
+	phaser := newDKGPhaser(d.getPhaser(timeout), func(p dkg.Phase) {
+		board.logTimeout(p)
+		d.persistDKGPhase(info, p)
+		if p == dkg.FinishPhase {
+			cancel()
+		}
+	})
 	dkgProto, err := dkg.NewProtocol(config, board, phaser, true)
 	if err != nil {
+		cancel()
 		return nil, err
 	}
 
 	d.state.Lock()
-	dkgInfo := &dkgInfo{
-		target: group,
-		board:  board,
-		phaser: phaser,
-		conf:   config,
-		proto:  dkgProto,
-	}
-	d.dkgInfo = dkgInfo
+	info = &dkgInfo{
+		target:  group,
+		board:   board,
+		phaser:  phaser,
+		conf:    config,
+		proto:   dkgProto,
+		timeout: timeout,
+		retries: retries,
+		cancel:  cancel,
+	}
+	d.dkgInfo = info
 	if leader {
 		d.dkgInfo.started = true
 	}
+	d.persistDKG(info)
 	d.state.Unlock()
 
+	d.sendEvent(EventDKGStarted, fmt.Sprintf("leader: %t, nodes: %d", leader, group.Len()))
 	if leader {
 		// phaser will kick off the first phase for every other nodes so
 		// nodes will send their deals
@@ -168,7 +234,7 @@ func (d *Drand) runDKG(leader bool, group *key.Group, timeout uint32, randomness
 	if err != nil {
 		d.log.Error("init_dkg", err)
 		d.state.Lock()
-		if d.dkgInfo == dkgInfo {
+		if d.dkgInfo == info {
 			d.cleanupDKG()
 		}
 		d.state.Unlock()
@@ -187,14 +253,41 @@ func (d *Drand) runDKG(leader bool, group *key.Group, timeout uint32, randomness
 func (d *Drand) cleanupDKG() {
 	if d.dkgInfo != nil {
 		d.dkgInfo.board.Stop()
+		if d.dkgInfo.cancel != nil {
+			d.dkgInfo.cancel()
+		}
 	}
 	d.dkgInfo = nil
+	if err := d.store.DeleteDKGState(); err != nil {
+		d.log.Error("dkg", "delete_state", "err", err)
+	}
+}
+
+// persistDKG saves the state of the given DKG so an interrupted one is
+// detected after a restart. It must be called with the state lock held.
+func (d *Drand) persistDKG(info *dkgInfo) {
+	if err := info.Persist(d.store); err != nil {
+		d.log.Error("dkg", "persist_state", "err", err)
+	}
+}
+
+// persistDKGPhase records the phase the given DKG reached, if it is still the
+// one running.
+func (d *Drand) persistDKGPhase(info *dkgInfo, phase dkg.Phase) {
+	d.state.Lock()
+	defer d.state.Unlock()
+	if d.dkgInfo != info {
+		return
+	}
+	d.log.Debug("dkg", "phase", "phase", phase)
+	info.phase = phase
+	d.persistDKG(info)
 }
 
 // runResharing setups all necessary structures to run the resharing protocol
 // and waits until it finishes (or timeouts). If leader is true, it sends the
 // first packet so other nodes will start as soon as they receive it.
-func (d *Drand) runResharing(leader bool, oldGroup, newGroup *key.Group, timeout uint32) (*key.Group, error) {
+func (d *Drand) runResharing(leader bool, oldGroup, newGroup *key.Group, timeout, retries uint32) (*key.Group, error) {
 	oldNode := oldGroup.Find(d.priv.Public)
 	oldPresent := oldNode != nil
 	if leader && !oldPresent {
@@ -239,21 +332,35 @@ func (d *Drand) runResharing(leader bool, oldGroup, newGroup *key.Group, timeout
 	}
 
 	allNodes := nodeUnion(oldGroup.Nodes, newGroup.Nodes)
-	board := newEchoBroadcast(d.log, d.privGateway.ProtocolClient, d.priv.Public.Address(), allNodes, func(p dkg.Packet) error {
+	// the retries go on until the last phase is over, or the DKG is aborted
+	ctx, cancel := context.WithCancel(context.Background())
+	board := newEchoBroadcast(ctx, d.log, d.privGateway.ProtocolClient, d.priv.Public.Address(), allNodes, retries, func(p dkg.Packet) error {
 		return dkg.VerifyPacketSignature(config, p)
 	})
-	phaser := d.getPhaser(timeout)
+	var info *dkgInfo
+	phaser := newDKGPhaser(d.getPhaser(timeout), func(p dkg.Phase) {
+		board.logTimeout(p)
+		d.persistDKGPhase(info, p)
+		if p == dkg.FinishPhase {
+			cancel()
+		}
+	})
 
 	dkgProto, err := dkg.NewProtocol(config, board, phaser, true)
 	if err != nil {
+		cancel()
 		return nil, err
 	}
-	info := &dkgInfo{
-		target: newGroup,
-		board:  board,
-		phaser: phaser,
-		conf:   config,
-		proto:  dkgProto,
+	info = &dkgInfo{
+		target:  newGroup,
+		board:   board,
+		phaser:  phaser,
+		conf:    config,
+		proto:   dkgProto,
+		old:     oldGroup,
+		timeout: timeout,
+		retries: retries,
+		cancel:  cancel,
 	}
 	d.state.Lock()
 	d.dkgInfo = info
@@ -261,8 +368,10 @@ func (d *Drand) runResharing(leader bool, oldGroup, newGroup *key.Group, timeout
 		d.log.Info("dkg_reshare", "leader_start", "target_group", hex.EncodeToString(newGroup.Hash()), "index", newNode.Index)
 		d.dkgInfo.started = true
 	}
+	d.persistDKG(info)
 	d.state.Unlock()
 
+	d.sendEvent(EventReshareStarted, fmt.Sprintf("leader: %t, nodes: %d", leader, newGroup.Len()))
 	if leader {
 		// start the protocol so everyone else follows
 		// it sends to all previous and new nodes. old nodes will start their
@@ -319,9 +428,11 @@ func (d *Drand) setupAutomaticDKG(_ context.Context, in *drand.InitDKGPacket) (*
 	}(receiver)
 	// send public key to leader
 	id := d.priv.Public.ToProto()
+	observer := in.GetInfo().GetObserver()
 	prep := &drand.SignalDKGPacket{
 		Node:        id,
 		SecretProof: in.GetInfo().GetSecret(),
+		Observer:    observer,
 	}
 
 	d.log.Debug("init_dkg", "send_key", "leader", lpeer.Address())
@@ -350,6 +461,18 @@ func (d *Drand) setupAutomaticDKG(_ context.Context, in *drand.InitDKGPacket) (*
 		return nil, errors.New("control: group with genesis time in the past")
 	}
 
+	if observer {
+		if group.FindObserver(d.priv.Public) == nil {
+			d.log.Error("init_dkg", "absent_observer_key_in_received_group")
+			return nil, errors.New("drand: public key not found in group observers")
+		}
+		finalGroup, err := d.observeDKG(group, dkgTimeout)
+		if err != nil {
+			return nil, err
+		}
+		return finalGroup.ToProto(), nil
+	}
+
 	node := group.Find(d.priv.Public)
 	if node == nil {
 		d.log.Error("init_dkg", "absent_public_key_in_received_group")
@@ -360,13 +483,46 @@ func (d *Drand) setupAutomaticDKG(_ context.Context, in *drand.InitDKGPacket) (*
 	d.state.Unlock()
 
 	// run the dkg
-	finalGroup, err := d.runDKG(false, group, dkgTimeout, in.GetEntropy())
+	finalGroup, err := d.runDKG(false, group, dkgTimeout, in.GetInfo().GetRetries(), in.GetEntropy())
 	if err != nil {
 		return nil, err
 	}
 	return finalGroup.ToProto(), nil
 }
 
+// announceJoin is used by a node that is not part of the old group to join the
+// chain run by the leader. The workflow is the following:
+// * The new node runs drand start <...> with the old group file at hand
+// * The new node runs drand share --join-existing --connect <leader> --from <old group>
+// - It announces its identity to the leader, which checks the node builds on
+// its current group
+// - It then keeps signaling its key to the leader until the leader accepts it
+// * Leader runs drand share --leader --transition --nodes <n+1> <...>, with the
+// nodes count including the announced node
+// * The leader includes the new node in the resharing proposal and the new
+// node proceeds as a normal resharing participant
+func (d *Drand) announceJoin(ctx context.Context, lpeer net.Peer, oldGroup *key.Group, prep *drand.SignalDKGPacket) error {
+	if oldGroup.Find(d.priv.Public) != nil {
+		return errors.New("drand: node is already part of the group, use --transition")
+	}
+	d.log.Info("setup_reshare", "announcing_join_to_leader")
+	if err := d.privGateway.ProtocolClient.AnnounceJoin(ctx, lpeer, prep); err != nil {
+		return fmt.Errorf("drand: leader refused join announcement: %w", err)
+	}
+	for {
+		err := d.privGateway.ProtocolClient.SignalDKGParticipant(ctx, lpeer, prep)
+		if err == nil {
+			return nil
+		}
+		d.log.Info("setup_reshare", "waiting_resharing_from_leader", "err", err)
+		select {
+		case <-d.opts.clock.After(JoinRetryPeriod):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
 // similar to setupAutomaticDKG but with additional verification and information
 // w.r.t. to the previous group
 func (d *Drand) setupAutomaticResharing(_ context.Context, oldGroup *key.Group, in *drand.InitResharePacket) (*drand.GroupPacket, error) {
@@ -417,8 +573,12 @@ func (d *Drand) setupAutomaticResharing(_ context.Context, oldGroup *key.Group,
 	nc, cancel := context.WithTimeout(context.Background(), MaxWaitPrepareDKG)
 	defer cancel()
 
-	d.log.Info("setup_reshare", "signaling_key_to_leader")
-	err = d.privGateway.ProtocolClient.SignalDKGParticipant(nc, lpeer, prep)
+	if in.GetJoinExisting() {
+		err = d.announceJoin(nc, lpeer, oldGroup, prep)
+	} else {
+		d.log.Info("setup_reshare", "signaling_key_to_leader")
+		err = d.privGateway.ProtocolClient.SignalDKGParticipant(nc, lpeer, prep)
+	}
 	if err != nil {
 		d.log.Error("setup_reshare", "failed to signal key to leader", "err", err)
 		return nil, fmt.Errorf("drand: err when signaling key to leader: %s", err)
@@ -449,7 +609,7 @@ func (d *Drand) setupAutomaticResharing(_ context.Context, oldGroup *key.Group,
 	}
 
 	// run the dkg !
-	finalGroup, err := d.runResharing(false, oldGroup, newGroup, dkgTimeout)
+	finalGroup, err := d.runResharing(false, oldGroup, newGroup, dkgTimeout, in.GetInfo().GetRetries())
 	if err != nil {
 		d.log.Error("setup_reshare", "failed to run resharing", "err", err)
 		return nil, err
@@ -496,10 +656,53 @@ func (d *Drand) extractGroup(old *drand.GroupInfo) (oldGroup *key.Group, err err
 	return
 }
 
+// fetchChainGroup fetches the group of the chain with the given hash from the
+// node p. The chain hash covers the public key, the period, the genesis time
+// and the genesis seed but not the nodes of the group, so the connection to p
+// must be trusted: it fails if p does not use TLS.
+func (d *Drand) fetchChainGroup(c context.Context, p net.Peer, chainHash []byte) (*key.Group, error) {
+	if !p.IsTLS() {
+		return nil, fmt.Errorf("drand: can't fetch the group of the chain from %s without TLS", p.Address())
+	}
+	packet, err := d.privGateway.ChainInfo(c, p, new(drand.ChainInfoRequest))
+	if err != nil {
+		return nil, fmt.Errorf("drand: can't fetch chain info from %s: %w", p.Address(), err)
+	}
+	info, err := chain.InfoFromProto(packet)
+	if err != nil {
+		return nil, fmt.Errorf("drand: invalid chain info from %s: %w", p.Address(), err)
+	}
+	if !bytes.Equal(info.Hash(), chainHash) {
+		return nil, fmt.Errorf("drand: chain info from %s does not match the chain hash", p.Address())
+	}
+	groupPacket, err := d.privGateway.GroupFile(c, p, new(drand.GroupRequest))
+	if err != nil {
+		return nil, fmt.Errorf("drand: can't fetch group from %s: %w", p.Address(), err)
+	}
+	group, err := key.GroupFromProto(groupPacket)
+	if err != nil {
+		return nil, fmt.Errorf("drand: invalid group from %s: %w", p.Address(), err)
+	}
+	if group.PublicKey == nil || !bytes.Equal(chain.NewChainInfo(group).Hash(), chainHash) {
+		return nil, fmt.Errorf("drand: group from %s does not match the chain hash", p.Address())
+	}
+	if group.Threshold < vss.MinimumT(group.Len()) {
+		return nil, errors.New("drand: threshold of fetched group too low")
+	}
+	return group, nil
+}
+
 // InitReshare receives information about the old and new group from which to
 // operate the resharing protocol.
 func (d *Drand) InitReshare(c context.Context, in *drand.InitResharePacket) (*drand.GroupPacket, error) {
-	oldGroup, err := d.extractGroup(in.Old)
+	var oldGroup *key.Group
+	var err error
+	if chainHash := in.GetOld().GetChainHash(); len(chainHash) > 0 && !in.GetInfo().GetLeader() {
+		leader := net.CreatePeer(in.GetInfo().GetLeaderAddress(), in.GetInfo().GetLeaderTls())
+		oldGroup, err = d.fetchChainGroup(c, leader, chainHash)
+	} else {
+		oldGroup, err = d.extractGroup(in.Old)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -515,8 +718,10 @@ func (d *Drand) InitReshare(c context.Context, in *drand.InitResharePacket) (*dr
 		return newReshareSetup(d.log, d.opts.clock, d.priv.Public, oldGroup, in)
 	}
 
-	newGroup, err := d.leaderRunSetup(newSetup)
-	if err != nil {
+	newGroup, err := d.leaderRunSetup(newSetup, in.GetInfo().GetForce())
+	if err == ErrSetupInProgress || errors.Is(err, ErrInsufficientParticipants) || errors.Is(err, ErrInsufficientKeys) {
+		return nil, err
+	} else if err != nil {
 		d.log.Error("init_reshare", "leader setup", "err", err)
 		return nil, fmt.Errorf("drand: invalid setup configuration: %s", err)
 	}
@@ -552,7 +757,7 @@ func (d *Drand) InitReshare(c context.Context, in *drand.InitResharePacket) (*dr
 		return nil, errors.New("fail to push new group")
 	}
 
-	finalGroup, err := d.runResharing(true, oldGroup, newGroup, in.GetInfo().GetTimeout())
+	finalGroup, err := d.runResharing(true, oldGroup, newGroup, in.GetInfo().GetTimeout(), in.GetInfo().GetRetries())
 	if err != nil {
 		return nil, err
 	}
@@ -622,12 +827,75 @@ func (d *Drand) GroupFile(ctx context.Context, in *drand.GroupRequest) (*drand.G
 	return protoGroup, nil
 }
 
-// Shutdown stops the node
+// Shutdown stops the node. In-flight requests are given the timeout of the
+// request to finish, after which their connections are closed.
 func (d *Drand) Shutdown(ctx context.Context, in *drand.ShutdownRequest) (*drand.ShutdownResponse, error) {
-	d.Stop(ctx)
+	timeout := time.Duration(in.GetTimeout()) * time.Second
+	d.log.Info("shutdown", "requested", "timeout", timeout)
+	// the control listener waits for in-flight requests to finish when
+	// stopping, this one included, so the daemon must stop in the background
+	go func() {
+		stopCtx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		d.Stop(stopCtx)
+	}()
 	return nil, nil
 }
 
+// Status returns the state of the beacon of the node, including the progress of
+// the catchup if the node is currently catching up.
+func (d *Drand) Status(ctx context.Context, in *drand.StatusRequest) (*drand.StatusResponse, error) {
+	d.state.Lock()
+	defer d.state.Unlock()
+	resp := &drand.StatusResponse{CatchingUp: d.catchingUp}
+	if d.catchingUp {
+		resp.StoredRound = d.catchup.StoredRound
+		resp.TargetRound = d.catchup.TargetRound
+		resp.BytesDownloaded = d.catchup.BytesDownloaded
+		return resp, nil
+	}
+	if d.beacon != nil {
+		if last, err := d.beacon.Store().Last(); err == nil {
+			resp.StoredRound = last.Round
+		}
+	}
+	return resp, nil
+}
+
+// ListProtocols returns the randomness beacon run by this node, if any, with
+// its current state.
+func (d *Drand) ListProtocols(ctx context.Context, in *drand.ListProtocolsRequest) (*drand.ListProtocolsResponse, error) {
+	d.state.Lock()
+	defer d.state.Unlock()
+	resp := new(drand.ListProtocolsResponse)
+	if d.group == nil {
+		return resp, nil
+	}
+	info := &drand.ProtocolInfo{
+		Id:       d.opts.BeaconID(d.group),
+		Version:  d.opts.Version(),
+		Period:   uint32(d.group.Period.Seconds()),
+		IdPrefix: d.opts.beaconIDPrefix,
+	}
+	switch {
+	case d.beacon == nil && d.dkgInfo != nil:
+		info.Status = "dkg"
+	case d.beacon == nil:
+		info.Status = "stopped"
+	case d.catchingUp:
+		info.Status = "catching_up"
+	default:
+		info.Status = "running"
+	}
+	if d.beacon != nil {
+		if last, err := d.beacon.Store().Last(); err == nil {
+			info.Round = last.Round
+		}
+	}
+	resp.Protocols = append(resp.Protocols, info)
+	return resp, nil
+}
+
 func extractGroup(i *drand.GroupInfo) (*key.Group, error) {
 	var g = new(key.Group)
 	switch x := i.Location.(type) {
@@ -733,7 +1001,9 @@ func (d *Drand) pushDKGInfo(outgoing, incoming []*key.Node, previousThreshold in
 	if nodesContainAddr(incoming, d.priv.Public.Address()) {
 		newThreshold--
 	}
-	to := nodeUnion(outgoing, incoming)
+	// the observers are told about the DKG but are not counted in the
+	// thresholds
+	to := nodeUnion(nodeUnion(outgoing, incoming), group.Observers)
 
 	results := d.pushDKGInfoPacket(ctx, to, packet)
 
@@ -793,20 +1063,12 @@ func (d *Drand) StartFollowChain(req *drand.StartFollowRequest, stream drand.Con
 	// NOTE: this means that if the client quits the requests, the syncing
 	// context will signal and it will stop. If we want the following to
 	// continue nevertheless we can use the next line by using a new context.
-	// ctx, cancel := context.WithCancel(context.Background())
-	ctx, cancel := context.WithCancel(stream.Context())
-	d.syncerCancel = cancel
+	// ctx, release := d.syncerContext(context.Background())
+	ctx, release := d.syncerContext(stream.Context())
 	d.state.Unlock()
-	defer func() {
-		d.state.Lock()
-		if d.syncerCancel != nil {
-			// it can be nil when we recreate a new beacon we cancel it
-			// see drand.go:newBeacon()
-			d.syncerCancel()
-		}
-		d.syncerCancel = nil
-		d.state.Unlock()
-	}()
+	// the syncer may already have been cancelled when we recreate a new beacon
+	// see drand.go:newBeacon()
+	defer release()
 
 	addr := net.RemoteAddress(stream.Context())
 	peers := make([]net.Peer, 0, len(req.GetNodes()))
@@ -929,3 +1191,174 @@ func (d *Drand) BackupDatabase(ctx context.Context, req *drand.BackupDBRequest)
 
 	return &drand.BackupDBResponse{}, inst.Store().SaveTo(w)
 }
+
+// ReloadCert installs the given TLS certificate and key in place of the ones
+// the node was started with, so the node keeps using them after a restart, and
+// serves them on the new connections of the gateways. The files are replaced
+// through a rename, so a crash never leaves them truncated.
+func (d *Drand) ReloadCert(ctx context.Context, req *drand.ReloadCertRequest) (*drand.ReloadCertResponse, error) {
+	if d.opts.insecure {
+		return nil, errors.New("drand: can't reload the certificate of an insecure node")
+	}
+	if _, err := net.LoadCertificate(req.GetCertPath(), req.GetKeyPath(), d.opts.clock.Now()); err != nil {
+		return nil, fmt.Errorf("drand: invalid certificate: %w", err)
+	}
+	d.state.Lock()
+	defer d.state.Unlock()
+	certPath, keyPath := d.opts.certPath, d.opts.keyPath
+	resp := new(drand.ReloadCertResponse)
+	if req.GetBackupExisting() {
+		resp.BackupCertPath = certPath + ".bak"
+		if err := fs.CopyFile(certPath, resp.BackupCertPath); err != nil {
+			return nil, fmt.Errorf("drand: can't backup the certificate: %w", err)
+		}
+		if err := fs.CopyFile(keyPath, keyPath+".bak"); err != nil {
+			return nil, fmt.Errorf("drand: can't backup the key: %w", err)
+		}
+	}
+	if req.GetCertPath() != certPath {
+		if err := fs.CopyFile(req.GetCertPath(), certPath); err != nil {
+			return nil, fmt.Errorf("drand: can't install the certificate: %w", err)
+		}
+	}
+	if req.GetKeyPath() != keyPath {
+		if err := fs.CopyFile(req.GetKeyPath(), keyPath); err != nil {
+			return nil, fmt.Errorf("drand: can't install the key: %w", err)
+		}
+	}
+	if err := d.privGateway.ReloadCert(certPath, keyPath); err != nil {
+		return nil, fmt.Errorf("drand: can't reload the private gateway: %w", err)
+	}
+	if d.pubGateway != nil {
+		if err := d.pubGateway.ReloadCert(certPath, keyPath); err != nil {
+			return nil, fmt.Errorf("drand: can't reload the public gateway: %w", err)
+		}
+	}
+	d.log.Info("reload_cert", "done", "cert", certPath, "backup", resp.BackupCertPath)
+	return resp, nil
+}
+
+// UnfreezeBeacon resumes the beacon generation of the node after a Freeze, for
+// example one made because the node lagged too far behind the chain.
+func (d *Drand) UnfreezeBeacon(ctx context.Context, in *drand.UnfreezeRequest) (*drand.UnfreezeResponse, error) {
+	if err := d.Unfreeze(); err != nil {
+		return nil, err
+	}
+	return &drand.UnfreezeResponse{}, nil
+}
+
+// GracefulDKGAbort abandons the DKG or resharing in progress, from its setup
+// to its last phase, and brings the node back to the state it had before,
+// without restarting it: the phaser is stopped, the DKG state is discarded and
+// the setup manager or receiver is cleaned up. The key pair and the group of a
+// previous DKG are kept, so a new DKG or resharing can be run afterwards.
+func (d *Drand) GracefulDKGAbort() error {
+	d.state.Lock()
+	defer d.state.Unlock()
+	if d.dkgInfo == nil && d.manager == nil && d.receiver == nil {
+		return errors.New("drand: no dkg in progress")
+	}
+	if d.manager != nil {
+		d.manager.StopPreemptively()
+		d.manager = nil
+	}
+	if d.receiver != nil {
+		d.receiver.stop()
+		d.receiver = nil
+	}
+	if d.dkgInfo != nil {
+		d.dkgInfo.phaser.Abort()
+		d.cleanupDKG()
+	}
+	d.log.Info("dkg", "aborted")
+	return nil
+}
+
+// AbortDKG abandons the DKG or resharing in progress on the node.
+func (d *Drand) AbortDKG(ctx context.Context, in *drand.AbortDKGRequest) (*drand.AbortDKGResponse, error) {
+	if err := d.GracefulDKGAbort(); err != nil {
+		return nil, err
+	}
+	return &drand.AbortDKGResponse{}, nil
+}
+
+// KeyFileLocationName is the file, in the config folder, holding the path of
+// the key file set with UseKeyFile, so the node keeps reading its key from it
+// after a restart.
+const KeyFileLocationName = "key_file_location"
+
+// keyFileLocation returns the path of the key file saved in the config folder
+// by UseKeyFile, or "" if there is none.
+func keyFileLocation(folder string) (string, error) {
+	buff, err := ioutil.ReadFile(path.Join(folder, KeyFileLocationName))
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("drand: can't read the key file location: %w", err)
+	}
+	return strings.TrimSpace(string(buff)), nil
+}
+
+// withKeyFile wraps s so the key pair is read from the key file given with
+// WithKeyFile or, failing that, from the one saved by UseKeyFile.
+func withKeyFile(s key.Store, c *Config) (key.Store, error) {
+	keyFile := c.keyFile
+	if keyFile == "" {
+		saved, err := keyFileLocation(c.ConfigFolder())
+		if err != nil {
+			return nil, err
+		}
+		keyFile = saved
+	}
+	if keyFile == "" {
+		return s, nil
+	}
+	return key.NewKeyFileStore(s, keyFile), nil
+}
+
+// UseKeyFile makes the node read its private key from the given file, kept out
+// of the config folder, from the next DKG or resharing on. The path is saved
+// in the config folder, see KeyFileLocationName, and used again at the next
+// start unless WithKeyFile is given. Once the node has a share, the key
+// must be the one of the node in its group.
+func (d *Drand) UseKeyFile(ctx context.Context, in *drand.UseKeyFileRequest) (*drand.UseKeyFileResponse, error) {
+	if in.GetPath() == "" {
+		return nil, errors.New("drand: no key file given")
+	}
+	d.state.Lock()
+	defer d.state.Unlock()
+	if d.dkgInfo != nil || d.manager != nil || d.receiver != nil {
+		return nil, errors.New("drand: can't change the key pair during a dkg")
+	}
+	store := key.NewKeyFileStore(d.store, in.GetPath())
+	pair, err := store.LoadKeyPair()
+	if err != nil {
+		return nil, err
+	}
+	if d.share != nil && d.group.Find(pair.Public) == nil {
+		return nil, errors.New("drand: the key of the file is not the one of the node in its group")
+	}
+	folder := fs.CreateSecureFolder(d.opts.ConfigFolder())
+	if err := fs.WriteFileAtomic(path.Join(folder, KeyFileLocationName), []byte(in.GetPath()+"\n"), 0600); err != nil {
+		return nil, fmt.Errorf("drand: can't save the key file location: %w", err)
+	}
+	d.priv, d.store = pair, store
+	d.log.Info("key_file", in.GetPath(), "public", pair.Public.Key)
+	return &drand.UseKeyFileResponse{Identity: pair.Public.ToProto()}, nil
+}
+
+// SetPeerPriority excludes a node of the group from the aggregation of the
+// partial beacons when the priority is 0, and includes it back otherwise, see
+// beacon.Handler.ExcludePeer.
+func (d *Drand) SetPeerPriority(ctx context.Context, in *drand.SetPeerPriorityRequest) (*drand.SetPeerPriorityResponse, error) {
+	d.state.Lock()
+	b := d.beacon
+	d.state.Unlock()
+	if b == nil {
+		return nil, errors.New("drand: beacon not setup yet")
+	}
+	if err := b.ExcludePeer(in.GetAddress(), in.GetPriority() == 0); err != nil {
+		return nil, err
+	}
+	return &drand.SetPeerPriorityResponse{}, nil
+}
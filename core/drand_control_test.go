@@ -1,11 +1,17 @@
 package core
 
 import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/drand/drand/key"
 	"github.com/drand/drand/log"
+	"github.com/drand/drand/protobuf/drand"
 	"github.com/drand/kyber"
 	"github.com/drand/kyber/util/random"
 	clock "github.com/jonboulle/clockwork"
@@ -86,3 +92,292 @@ func TestValidateGroupTransitionTime(t *testing.T) {
 		t.Fatal("unexpected validation error", err)
 	}
 }
+
+func TestListProtocols(t *testing.T) {
+	d := Drand{log: log.DefaultLogger(), opts: NewConfig(WithVersion("v1.0.0"))}
+
+	resp, err := d.ListProtocols(context.Background(), &drand.ListProtocolsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.GetProtocols()) != 0 {
+		t.Fatal("expected no beacon without a group, got", resp.GetProtocols())
+	}
+
+	d.group = &key.Group{Threshold: 2, Period: 30 * time.Second, GenesisTime: 100}
+	resp, err = d.ListProtocols(context.Background(), &drand.ListProtocolsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.GetProtocols()) != 1 {
+		t.Fatal("expected one beacon, got", resp.GetProtocols())
+	}
+	p := resp.GetProtocols()[0]
+	if p.GetId() != hex.EncodeToString(d.group.Hash()) {
+		t.Fatal("unexpected beacon id", p.GetId())
+	}
+	if p.GetVersion() != "v1.0.0" || p.GetPeriod() != 30 || p.GetStatus() != "stopped" {
+		t.Fatal("unexpected beacon info", p)
+	}
+
+	d.opts = NewConfig(WithBeaconIDPrefix("acme"))
+	resp, err = d.ListProtocols(context.Background(), &drand.ListProtocolsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p = resp.GetProtocols()[0]
+	if p.GetId() != "acme-"+hex.EncodeToString(d.group.Hash()) || p.GetIdPrefix() != "acme" {
+		t.Fatal("unexpected prefixed beacon id", p.GetId(), p.GetIdPrefix())
+	}
+}
+
+func TestDKGSetupPeriod(t *testing.T) {
+	leader := key.NewKeyPair("127.0.0.1:8080").Public
+	info := &drand.SetupInfoPacket{Nodes: 3, Threshold: 2, Leader: true, Timeout: 10}
+	for _, period := range []uint32{0, 3601} {
+		if _, err := newDKGSetup(log.DefaultLogger(), clock.NewFakeClock(), leader, period, 0, 0, info); err == nil {
+			t.Fatal("expected error for a period out of bounds", period)
+		}
+	}
+	for _, period := range []uint32{1, 3600} {
+		if _, err := newDKGSetup(log.DefaultLogger(), clock.NewFakeClock(), leader, period, 0, 0, info); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDKGSetupGenesisTime(t *testing.T) {
+	c := clock.NewFakeClockAt(time.Unix(1000, 0))
+	leader := key.NewKeyPair("127.0.0.1:8080").Public
+	info := &drand.SetupInfoPacket{Nodes: 3, Threshold: 2, Leader: true, Timeout: 10}
+	for _, genesis := range []int64{900, 1000, 1029} {
+		if _, err := newDKGSetup(log.DefaultLogger(), c, leader, 30, 0, genesis, info); err == nil {
+			t.Fatal("expected error for a genesis time less than a period ahead", genesis)
+		}
+	}
+	m, err := newDKGSetup(log.DefaultLogger(), c, leader, 30, 0, 1030, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.genesisTime != 1030 {
+		t.Fatal("unexpected genesis time", m.genesisTime)
+	}
+}
+
+func TestLeaderSetupInProgress(t *testing.T) {
+	d := &Drand{log: log.DefaultLogger(), opts: NewConfig()}
+	leader := key.NewKeyPair("127.0.0.1:8080").Public
+	newSetup := func(d *Drand) (*setupManager, error) {
+		info := &drand.SetupInfoPacket{Nodes: 3, Threshold: 2, Leader: true, Timeout: 10}
+		return newDKGSetup(d.log, d.opts.clock, leader, 30, 0, 0, info)
+	}
+	run := func(force bool) chan error {
+		errCh := make(chan error, 1)
+		go func() {
+			_, err := d.leaderRunSetup(newSetup, force)
+			errCh <- err
+		}()
+		return errCh
+	}
+	waitManager := func(previous *setupManager) *setupManager {
+		for i := 0; i < 100; i++ {
+			d.state.Lock()
+			m := d.manager
+			d.state.Unlock()
+			if m != nil && m != previous {
+				return m
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatal("setup manager not started")
+		return nil
+	}
+
+	// two concurrent setups: only the first one proceeds
+	first := run(false)
+	firstManager := waitManager(nil)
+	select {
+	case err := <-run(false):
+		if err != ErrSetupInProgress {
+			t.Fatal("expected concurrent setup to be rejected, got", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("concurrent setup not rejected")
+	}
+	select {
+	case err := <-first:
+		t.Fatal("first setup should still be running, got", err)
+	default:
+	}
+
+	// a forced setup pre-empts the one in progress
+	forced := run(true)
+	select {
+	case err := <-first:
+		if err != errPreempted {
+			t.Fatal("expected first setup to be pre-empted, got", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("setup not pre-empted")
+	}
+	waitManager(firstManager).StopPreemptively()
+	if err := <-forced; err != errPreempted {
+		t.Fatal("expected forced setup to be pre-empted, got", err)
+	}
+}
+
+func TestSignalDKGParticipantConcurrent(t *testing.T) {
+	n := 10
+	d := &Drand{log: log.DefaultLogger(), opts: NewConfig()}
+	leader := key.NewKeyPair("127.0.0.1:8080").Public
+	secret := []byte("thisistheresharingsecret")
+	info := &drand.SetupInfoPacket{Nodes: uint32(n + 1), Threshold: uint32(n/2 + 1), Leader: true, Timeout: 10, Secret: secret}
+	m, err := newDKGSetup(d.log, d.opts.clock, leader, 30, 0, 0, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go m.run()
+	d.state.Lock()
+	d.manager = m
+	d.state.Unlock()
+
+	// the manager is busy: the state must not stay locked by the signals
+	m.Lock()
+	errCh := make(chan error, n)
+	for i := 0; i < n; i++ {
+		id := key.NewKeyPair(fmt.Sprintf("127.0.0.1:%d", 8081+i)).Public
+		go func() {
+			_, err := d.SignalDKGParticipant(context.Background(), &drand.SignalDKGPacket{
+				Node:        id.ToProto(),
+				SecretProof: secret,
+			})
+			errCh <- err
+		}()
+	}
+	time.Sleep(100 * time.Millisecond)
+	locked := make(chan bool)
+	go func() {
+		d.state.Lock()
+		close(locked)
+		d.state.Unlock()
+	}()
+	select {
+	case <-locked:
+	case <-time.After(time.Second):
+		t.Fatal("state locked while receiving the keys")
+	}
+	m.Unlock()
+
+	for i := 0; i < n; i++ {
+		if err := <-errCh; err != nil {
+			t.Fatal(err)
+		}
+	}
+	select {
+	case group := <-m.WaitGroup():
+		if len(group.Nodes) != n+1 {
+			t.Fatal("expected all the nodes in the group, got", len(group.Nodes))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("group not created")
+	}
+
+	d.state.Lock()
+	d.manager = nil
+	d.state.Unlock()
+	if _, err := d.SignalDKGParticipant(context.Background(), &drand.SignalDKGPacket{}); err == nil {
+		t.Fatal("expected an error without manager")
+	}
+}
+
+func TestLeaderSetupThresholdWait(t *testing.T) {
+	leader := key.NewKeyPair("127.0.0.1:8080").Public
+	run := func(arrive int) error {
+		d := &Drand{log: log.DefaultLogger(), opts: NewConfig(WithThresholdWait(time.Second))}
+		managerCh := make(chan *setupManager, 1)
+		newSetup := func(d *Drand) (*setupManager, error) {
+			info := &drand.SetupInfoPacket{Nodes: 3, Threshold: 2, Leader: true, Timeout: 10, WaitTimeout: 1}
+			m, err := newDKGSetup(d.log, d.opts.clock, leader, 30, 0, 0, info)
+			managerCh <- m
+			return m, err
+		}
+		errCh := make(chan error, 1)
+		go func() {
+			_, err := d.leaderRunSetup(newSetup, false)
+			errCh <- err
+		}()
+		manager := <-managerCh
+		// the other nodes only arrive after the wait timeout
+		time.Sleep(1500 * time.Millisecond)
+		for i := 0; i < arrive; i++ {
+			id := key.NewKeyPair(fmt.Sprintf("127.0.0.1:%d", 8081+i)).Public
+			manager.pushKeyCh <- pushKey{addr: id.Address(), id: id}
+		}
+		select {
+		case err := <-errCh:
+			return err
+		case <-time.After(5 * time.Second):
+			t.Fatal("setup not finished after the threshold wait")
+		}
+		return nil
+	}
+
+	// no other node signals itself
+	err := run(0)
+	if !errors.Is(err, ErrInsufficientKeys) {
+		t.Fatal("expected insufficient keys, got", err)
+	}
+	if !strings.Contains(err.Error(), "1/3") {
+		t.Fatal("expected the number of arrived nodes, got", err)
+	}
+	// all the nodes arrive during the threshold wait
+	if err := run(2); err != nil {
+		t.Fatal("expected the group, got", err)
+	}
+}
+
+func TestLeaderSetupInsufficientParticipants(t *testing.T) {
+	d := &Drand{log: log.DefaultLogger(), opts: NewConfig()}
+	leader := key.NewKeyPair("127.0.0.1:8080").Public
+	var manager *setupManager
+	newSetup := func(d *Drand) (*setupManager, error) {
+		info := &drand.SetupInfoPacket{Nodes: 3, Threshold: 2, Leader: true, Timeout: 10, WaitTimeout: 1}
+		m, err := newDKGSetup(d.log, d.opts.clock, leader, 30, 0, 0, info)
+		manager = m
+		return m, err
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := d.leaderRunSetup(newSetup, false)
+		errCh <- err
+	}()
+	for i := 0; i < 100; i++ {
+		d.state.Lock()
+		m := d.manager
+		d.state.Unlock()
+		if m != nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// only one node out of the two others signals itself
+	id := key.NewKeyPair("127.0.0.1:8081").Public
+	manager.pushKeyCh <- pushKey{addr: id.Address(), id: id}
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, ErrInsufficientParticipants) {
+			t.Fatal("expected insufficient participants, got", err)
+		}
+		if !strings.Contains(err.Error(), "2/3") {
+			t.Fatal("expected the number of arrived nodes, got", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("setup not aborted after the wait timeout")
+	}
+	d.state.Lock()
+	defer d.state.Unlock()
+	if d.manager != nil {
+		t.Fatal("aborted setup not cleared")
+	}
+}
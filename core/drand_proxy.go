@@ -2,11 +2,13 @@ package core
 
 import (
 	"context"
+	"errors"
 	"net"
 	"time"
 
 	"github.com/drand/drand/chain"
 	"github.com/drand/drand/client"
+	"github.com/drand/drand/key"
 	"github.com/drand/drand/protobuf/drand"
 
 	"google.golang.org/grpc/metadata"
@@ -24,6 +26,59 @@ func Proxy(s drand.PublicServer) client.Client {
 	return &drandProxy{s}
 }
 
+// nodeProxy is the proxy to the local drand node served by the HTTP API. It
+// reads the most recent beacons at once from the store of the node.
+type nodeProxy struct {
+	drandProxy
+	d *Drand
+}
+
+// LastN returns the n most recent beacons, in increasing order.
+func (p *nodeProxy) LastN(ctx context.Context, n int) ([]client.Result, error) {
+	p.d.state.Lock()
+	b := p.d.beacon
+	p.d.state.Unlock()
+	if b == nil {
+		return nil, errors.New("drand: beacon generation not started yet")
+	}
+	beacons, err := b.Store().LastN(n)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]client.Result, len(beacons))
+	for i, b := range beacons {
+		results[i] = &client.RandomData{
+			Rnd:               b.Round,
+			Random:            b.Randomness(),
+			Sig:               b.Signature,
+			PreviousSignature: b.PreviousSig,
+		}
+	}
+	return results, nil
+}
+
+// Checkpoint returns the checkpoint of the given round, computed from the
+// store of the node.
+func (p *nodeProxy) Checkpoint(ctx context.Context, round uint64) (*chain.Checkpoint, error) {
+	p.d.state.Lock()
+	b := p.d.beacon
+	p.d.state.Unlock()
+	if b == nil {
+		return nil, errors.New("drand: beacon generation not started yet")
+	}
+	return b.Store().Checkpoint(round)
+}
+
+// Group returns the group of the node.
+func (p *nodeProxy) Group(ctx context.Context) (*key.Group, error) {
+	p.d.state.Lock()
+	defer p.d.state.Unlock()
+	if p.d.group == nil {
+		return nil, errors.New("drand: no group yet")
+	}
+	return p.d.group, nil
+}
+
 // String returns the name of this proxy.
 func (d *drandProxy) String() string {
 	return "Proxy"
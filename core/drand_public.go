@@ -1,6 +1,7 @@
 package core
 
 import (
+	"bytes"
 	"context"
 	"encoding/hex"
 	"errors"
@@ -13,11 +14,18 @@ import (
 	"github.com/drand/drand/net"
 	"github.com/drand/drand/protobuf/drand"
 	"github.com/drand/kyber/encrypt/ecies"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // BroadcastDKG is the public method to call during a DKG protocol.
 func (d *Drand) BroadcastDKG(c context.Context, in *drand.DKGPacket) (*drand.Empty, error) {
 	d.state.Lock()
+	if obs := d.observer; obs != nil {
+		// the observer logs the packet to its transcript without the lock
+		d.state.Unlock()
+		return obs.BroadcastDKG(c, in)
+	}
 	defer d.state.Unlock()
 	if d.dkgInfo == nil {
 		return nil, errors.New("drand: no dkg running")
@@ -38,10 +46,18 @@ func (d *Drand) BroadcastDKG(c context.Context, in *drand.DKGPacket) (*drand.Emp
 // with the partial signature from this drand node.
 func (d *Drand) PartialBeacon(c context.Context, in *drand.PartialBeaconPacket) (*drand.Empty, error) {
 	d.state.Lock()
+	if d.observer != nil {
+		d.state.Unlock()
+		return d.observer.PartialBeacon(c, in)
+	}
 	if d.beacon == nil {
 		d.state.Unlock()
 		return nil, errors.New("drand: beacon not setup yet")
 	}
+	if d.frozen {
+		d.state.Unlock()
+		return nil, status.Error(codes.ResourceExhausted, "drand: beacon generation frozen")
+	}
 	inst := d.beacon
 	d.state.Unlock()
 	return inst.ProcessPartialBeacon(c, in)
@@ -90,18 +106,24 @@ func (d *Drand) PublicRandStream(req *drand.PublicRandRequest, stream drand.Publ
 	done := make(chan error, 1)
 	d.log.Debug("request", "stream", "from", addr, "round", req.GetRound())
 	if req.GetRound() != 0 && req.GetRound() <= lastb.Round {
-		// we need to stream from store first
-		var err error
-		b.Store().Cursor(func(c chain.Cursor) {
-			for bb := c.Seek(req.GetRound()); bb != nil; bb = c.Next() {
-				if err = stream.Send(beaconToProto(bb)); err != nil {
+		// we need to stream from store first, by batches so a request from
+		// an early round does not load the whole chain in memory
+		for from := req.GetRound(); from <= lastb.Round; from += StreamBatchSize {
+			to := from + StreamBatchSize - 1
+			if to > lastb.Round {
+				to = lastb.Round
+			}
+			beacons, err := b.Store().GetRange(from, to)
+			if err != nil {
+				d.log.Debug("stream", err)
+				return err
+			}
+			for _, bb := range beacons {
+				if err := stream.Send(beaconToProto(bb)); err != nil {
 					d.log.Debug("stream", err)
-					return
+					return err
 				}
 			}
-		})
-		if err != nil {
-			return err
 		}
 	}
 	// then we can stream from any new rounds
@@ -148,12 +170,21 @@ func (d *Drand) PrivateRand(c context.Context, priv *drand.PrivateRandRequest) (
 	return &drand.PrivateRandResponse{Response: obj}, err
 }
 
-// Home provides the address the local node is listening
+// Home provides the address the local node is listening, signed with the
+// long-term key of the node so clients can authenticate it.
 func (d *Drand) Home(c context.Context, in *drand.HomeRequest) (*drand.HomeResponse, error) {
 	d.log.With("module", "public").Info("home", net.RemoteAddress(c))
+	timestamp := d.opts.clock.Now().Unix()
+	signature, err := d.priv.SignHome(timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("drand: can't sign home response: %w", err)
+	}
 	return &drand.HomeResponse{
 		Status: fmt.Sprintf("drand up and running on %s",
 			d.priv.Public.Address()),
+		Address:   d.priv.Public.Address(),
+		Timestamp: timestamp,
+		Signature: signature,
 	}, nil
 }
 
@@ -164,25 +195,64 @@ func (d *Drand) ChainInfo(ctx context.Context, in *drand.ChainInfoRequest) (*dra
 	if d.group == nil {
 		return nil, errors.New("drand: no dkg group setup yet")
 	}
-	return chain.NewChainInfo(d.group).ToProto(), nil
+	info := chain.NewChainInfo(d.group)
+	if d.beacon != nil {
+		// the genesis beacon is derived from the group hash, which covers the
+		// genesis time, so it must match the chain we describe
+		first, err := d.beacon.Store().First()
+		if err == nil && first.Round == 0 && !bytes.Equal(first.Signature, info.GroupHash) {
+			return nil, errors.New("drand: the stored genesis beacon does not match the group")
+		}
+	}
+	return info.ToProto(), nil
 }
 
 // SignalDKGParticipant receives a dkg signal packet from another member
 func (d *Drand) SignalDKGParticipant(ctx context.Context, p *drand.SignalDKGPacket) (*drand.Empty, error) {
+	// the manager has its own lock, the state is only locked to read it so the
+	// other operations are not blocked while the keys are received
 	d.state.Lock()
-	defer d.state.Unlock()
-	if d.manager == nil {
+	manager := d.manager
+	d.state.Unlock()
+	if manager == nil {
 		return nil, errors.New("no manager")
 	}
 	addr := net.RemoteAddress(ctx)
 	// manager will verify if information are correct
-	err := d.manager.ReceivedKey(addr, p)
+	err := manager.ReceivedKey(addr, p)
 	if err != nil {
 		return nil, err
 	}
 	return new(drand.Empty), nil
 }
 
+// AnnounceJoin is called by a node that is not part of the current group and
+// wishes to be included in the next resharing. The announcement is only
+// checked against the current group and logged so the operator knows the
+// next resharing can include one more node.
+func (d *Drand) AnnounceJoin(ctx context.Context, p *drand.SignalDKGPacket) (*drand.Empty, error) {
+	d.state.Lock()
+	defer d.state.Unlock()
+	if d.group == nil {
+		return nil, errors.New("drand: no group running")
+	}
+	if !bytes.Equal(d.group.Hash(), p.GetPreviousGroupHash()) {
+		return nil, errors.New("drand: inconsistent previous group hash")
+	}
+	id, err := key.IdentityFromProto(p.GetNode())
+	if err != nil {
+		return nil, fmt.Errorf("drand: invalid id: %w", err)
+	}
+	if err := id.ValidSignature(); err != nil {
+		return nil, fmt.Errorf("drand: invalid sig: %w", err)
+	}
+	if d.group.Find(id) != nil {
+		return nil, errors.New("drand: node is already part of the group")
+	}
+	d.log.Info("announce_join", "new_node", "addr", id.Address(), "from", net.RemoteAddress(ctx))
+	return new(drand.Empty), nil
+}
+
 // PushDKGInfo triggers sending DKG info to other members
 func (d *Drand) PushDKGInfo(ctx context.Context, in *drand.DKGInfoPacket) (*drand.Empty, error) {
 	d.state.Lock()
@@ -200,14 +270,32 @@ func (d *Drand) PushDKGInfo(ctx context.Context, in *drand.DKGInfoPacket) (*dran
 // given round
 func (d *Drand) SyncChain(req *drand.SyncRequest, stream drand.Protocol_SyncChainServer) error {
 	d.state.Lock()
-	b := d.beacon
+	b, obs := d.beacon, d.observer
 	d.state.Unlock()
 	if b != nil {
 		return b.SyncChain(req, stream)
 	}
+	if obs != nil {
+		return obs.SyncChain(req, stream)
+	}
 	return nil
 }
 
+// PushSync is a inter-node protocol that pushes the beacons from a given round
+// by batches, and then the new ones as they are created
+func (d *Drand) PushSync(req *drand.SyncRequest, stream drand.Protocol_PushSyncServer) error {
+	d.state.Lock()
+	b, obs := d.beacon, d.observer
+	d.state.Unlock()
+	if b == nil && obs != nil {
+		return obs.PushSync(req, stream)
+	}
+	if b == nil {
+		return errors.New("drand: beacon not setup yet")
+	}
+	return b.PushSync(req, stream)
+}
+
 // GetIdentity returns the identity of this drand node
 func (d *Drand) GetIdentity(ctx context.Context, req *drand.IdentityRequest) (*drand.Identity, error) {
 	return d.priv.Public.ToProto(), nil
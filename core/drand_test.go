@@ -1,25 +1,42 @@
 package core
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"crypto/tls"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	gnet "net"
+	nethttp "net/http"
 	"os"
 	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/drand/drand/chain"
 	"github.com/drand/drand/key"
 	"github.com/drand/drand/log"
+	"github.com/drand/drand/metrics"
 	"github.com/drand/drand/net"
 	"github.com/drand/drand/protobuf/drand"
 	"github.com/drand/drand/test"
+	"github.com/drand/kyber/share/dkg"
 
+	kitlog "github.com/go-kit/kit/log"
+	clock "github.com/jonboulle/clockwork"
 	"github.com/kabukky/httpscerts"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func setFDLimit() {
@@ -87,6 +104,525 @@ func TestDrandDKGFresh(t *testing.T) {
 	dt.TestPublicBeacon(lastID, false)
 }
 
+// A node that lost its share and group in a crash restores them from a
+// checkpoint and keeps its beacons
+func TestDrandRestoreCheckpoint(t *testing.T) {
+	n := 4
+	beaconPeriod := 1 * time.Second
+	dt := NewDrandTest2(t, n, key.DefaultThreshold(n), beaconPeriod)
+	defer dt.Cleanup()
+	group := dt.RunDKG()
+	time.Sleep(getSleepDuration())
+	dt.MoveToTime(group.GenesisTime)
+	dt.TestBeaconLength(2, false, dt.Ids(n, false)...)
+
+	// checkpoint of the last node, and of another node
+	checkpoint := func(node *Node) string {
+		dir, err := ioutil.TempDir(dt.dir, "checkpoint")
+		require.NoError(t, err)
+		cp, err := key.NewFileStore(dir)
+		require.NoError(t, err)
+		defer cp.Close()
+		s := node.drand.store
+		pair, err := s.LoadKeyPair()
+		require.NoError(t, err)
+		require.NoError(t, cp.SaveKeyPair(pair))
+		g, err := s.LoadGroup()
+		require.NoError(t, err)
+		share, err := s.LoadShare()
+		require.NoError(t, err)
+		require.NoError(t, cp.SaveGroupAndShare(g, share, false))
+		return dir
+	}
+	last := dt.nodes[n-1]
+	lastDir := checkpoint(last)
+	otherDir := checkpoint(dt.nodes[0])
+
+	// crash: the node loses its group and share
+	dt.StopDrand(last.addr, false)
+	store := last.drand.store
+	require.NoError(t, store.Reset())
+
+	require.Error(t, RestoreCheckpoint(store, otherDir))
+	require.Error(t, RestoreCheckpoint(store, path.Join(dt.dir, "none")))
+	require.NoError(t, RestoreCheckpoint(store, lastDir))
+
+	dt.StartDrand(last.addr, true, false)
+	// leave room for the catchup, and for the other nodes to dial the restarted
+	// node again: their connections to it wait for the grpc reconnect backoff
+	time.Sleep(2 * time.Second)
+	dt.MoveTime(beaconPeriod)
+	dt.TestBeaconLength(3, false, dt.Ids(n, false)...)
+	// the beacon stored before the crash is kept
+	b, err := dt.GetBeacon(last.addr, 1, false)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), b.Round)
+	for _, node := range dt.nodes {
+		node.drand.Stop(context.Background())
+	}
+}
+
+// The DKG completes despite nodes failing to receive each packet the first
+// time, as the packets are sent again
+func TestDrandDKGRetries(t *testing.T) {
+	n := 4
+	dt := NewDrandTest2(t, n, key.DefaultThreshold(n), 1*time.Second)
+	defer dt.Cleanup()
+	defer func() {
+		for _, node := range dt.nodes {
+			node.drand.Stop(context.Background())
+		}
+	}()
+	clients := make([]*flakyClient, 0, n)
+	for _, node := range dt.nodes {
+		flaky := &flakyClient{ProtocolClient: node.drand.privGateway.ProtocolClient, failures: 1}
+		node.drand.privGateway.ProtocolClient = flaky
+		clients = append(clients, flaky)
+	}
+	dt.dkgRetries = 2
+	group := dt.RunDKG()
+	require.Equal(t, n, group.Len())
+	require.NotNil(t, group.PublicKey)
+	for i, flaky := range clients {
+		flaky.Lock()
+		require.True(t, flaky.failed > 0, "node %d", i)
+		flaky.Unlock()
+		dt.nodes[i].drand.state.Lock()
+		require.NotNil(t, dt.nodes[i].drand.share)
+		dt.nodes[i].drand.state.Unlock()
+	}
+}
+
+// An observer logs the packets of the DKG and stores the beacons of the chain
+// without getting a share
+func TestDrandDKGObserver(t *testing.T) {
+	n := 3
+	beaconPeriod := 1 * time.Second
+
+	dt := NewDrandTest2(t, n, key.DefaultThreshold(n), beaconPeriod)
+	defer dt.Cleanup()
+	obs := dt.SetupNewNodes(1)[0]
+	// the observer goes first to close its sync streams to the nodes
+	defer func() {
+		for _, node := range append([]*Node{obs}, dt.nodes...) {
+			node.drand.Stop(context.Background())
+		}
+	}()
+	dt.observers = []*Node{obs}
+	group := dt.RunDKG()
+	require.Nil(t, group.Find(obs.drand.priv.Public))
+	require.NotNil(t, group.FindObserver(obs.drand.priv.Public))
+	require.Equal(t, key.ObserverIndex, group.Observers[0].Index)
+
+	obs.drand.state.Lock()
+	require.True(t, group.Equal(obs.drand.group))
+	require.Nil(t, obs.drand.share)
+	require.False(t, obs.drand.dkgDone)
+	observer := obs.drand.observer
+	obs.drand.state.Unlock()
+	// the group is kept out of the store so the observer can join a later DKG
+	stored, err := obs.drand.store.LoadGroup()
+	require.True(t, err != nil || stored == nil)
+	observed := new(key.Group)
+	require.NoError(t, key.Load(path.Join(obs.drand.opts.ConfigFolder(), ObservedGroupFileName), observed))
+	require.True(t, group.Equal(observed))
+	_, err = obs.drand.PartialBeacon(context.Background(), new(drand.PartialBeaconPacket))
+	require.Equal(t, ErrNotParticipant, err)
+
+	// each node sent its deal and its response once
+	f, err := os.Open(path.Join(obs.drand.opts.ConfigFolder(), DKGTranscriptFileName))
+	require.NoError(t, err)
+	defer f.Close()
+	types := make(map[string]int)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		entry := new(TranscriptEntry)
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), entry))
+		require.True(t, entry.Valid)
+		types[entry.Type]++
+	}
+	require.NoError(t, scanner.Err())
+	require.Equal(t, n, types["deal"])
+	require.Equal(t, n, types["response"])
+
+	time.Sleep(getSleepDuration())
+	dt.MoveToTime(group.GenesisTime)
+	dt.TestBeaconLength(2, false, dt.Ids(n, false)...)
+	dt.MoveTime(beaconPeriod)
+	dt.TestBeaconLength(3, false, dt.Ids(n, false)...)
+	var last uint64
+	for i := 0; i < 10 && last < 2; i++ {
+		time.Sleep(getSleepDuration())
+		observer.Lock()
+		store := observer.store
+		observer.Unlock()
+		if store == nil {
+			continue
+		}
+		b, err := store.Last()
+		require.NoError(t, err)
+		last = b.Round
+	}
+	require.Equal(t, uint64(2), last)
+
+	// the observer follows the chain again after a restart
+	obs.drand.Stop(context.Background())
+	<-obs.drand.WaitExit()
+	obs.drand, err = NewDrand(obs.drand.store, obs.drand.opts)
+	require.NoError(t, err)
+	obs.drand.state.Lock()
+	require.True(t, group.Equal(obs.drand.group))
+	observer = obs.drand.observer
+	obs.drand.state.Unlock()
+	require.NotNil(t, observer)
+	dt.MoveTime(beaconPeriod)
+	dt.TestBeaconLength(4, false, dt.Ids(n, false)...)
+	for i := 0; i < 10 && last < 3; i++ {
+		time.Sleep(getSleepDuration())
+		observer.Lock()
+		store := observer.store
+		observer.Unlock()
+		if store == nil {
+			continue
+		}
+		b, err := store.Last()
+		require.NoError(t, err)
+		last = b.Round
+	}
+	require.Equal(t, uint64(3), last)
+}
+
+// The DKG and the beacon generation work without TLS between the nodes
+func TestDrandDKGInsecure(t *testing.T) {
+	n := 3
+	beaconPeriod := 1 * time.Second
+
+	dt := NewInsecureDrandTest(t, n, key.DefaultThreshold(n), beaconPeriod)
+	defer dt.Cleanup()
+	defer func() {
+		for _, node := range dt.nodes {
+			node.drand.Stop(context.Background())
+		}
+	}()
+	group := dt.RunDKG()
+	for _, node := range group.Nodes {
+		require.False(t, node.TLS)
+	}
+	time.Sleep(getSleepDuration())
+	dt.MoveToTime(group.GenesisTime)
+	// genesis + 1st round (happens at genesis)
+	dt.TestBeaconLength(2, false, dt.Ids(n, false)...)
+	dt.MoveTime(beaconPeriod)
+	dt.TestBeaconLength(3, false, dt.Ids(n, false)...)
+	dt.MoveTime(beaconPeriod)
+	// three beacons produced after the genesis one
+	dt.TestBeaconLength(4, false, dt.Ids(n, false)...)
+	dt.TestPublicBeacon(dt.nodes[0].addr, false)
+}
+
+// The leader can set the genesis time of the chain instead of the offset
+func TestDrandDKGGenesisTime(t *testing.T) {
+	n := 3
+	dt := NewDrandTest2(t, n, key.DefaultThreshold(n), 1*time.Second)
+	defer dt.Cleanup()
+	defer func() {
+		for _, node := range dt.nodes {
+			node.drand.Stop(context.Background())
+		}
+	}()
+	dt.genesisTime = dt.Now().Add(time.Hour).Unix()
+	group := dt.RunDKG()
+	require.Equal(t, dt.genesisTime, group.GenesisTime)
+	for _, node := range dt.nodes {
+		stored, err := node.drand.store.LoadGroup()
+		require.NoError(t, err)
+		require.Equal(t, dt.genesisTime, stored.GenesisTime)
+	}
+}
+
+// panicOnRoundService panics the first time any of the nodes using it receives
+// a partial beacon for the given round, and records that node
+type panicOnRoundService struct {
+	net.Service
+	round    uint64
+	armed    *int32
+	panicked *atomic.Value
+}
+
+func (p *panicOnRoundService) PartialBeacon(c context.Context, in *drand.PartialBeaconPacket) (*drand.Empty, error) {
+	if in.GetRound() == p.round && atomic.CompareAndSwapInt32(p.armed, 1, 0) {
+		p.panicked.Store(p.Service)
+		panic("broken beacon")
+	}
+	return p.Service.PartialBeacon(c, in)
+}
+
+// A node whose beacon panics restarts it and keeps following the chain
+func TestDrandRecoverBeacon(t *testing.T) {
+	n := 3
+	beaconPeriod := 1 * time.Second
+	armed := int32(1)
+	var panicked atomic.Value
+	panicking := func(s net.Service) net.Service {
+		return &panicOnRoundService{Service: s, round: 4, armed: &armed, panicked: &panicked}
+	}
+
+	dt := newDrandTest(t, n, key.DefaultThreshold(n), beaconPeriod, false, WithServiceMiddlewares(panicking))
+	defer dt.Cleanup()
+	defer func() {
+		for _, node := range dt.nodes {
+			node.drand.Stop(context.Background())
+		}
+	}()
+	group := dt.RunDKG()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := make(map[*Drand]<-chan Event)
+	for _, node := range dt.nodes {
+		events[node.drand] = node.drand.WatchEvents(ctx)
+	}
+	// skips the events sent before the expected one
+	waitFor := func(ch <-chan Event, expected EventType) {
+		timeout := time.After(10 * time.Second)
+		for {
+			select {
+			case ev := <-ch:
+				if ev.Type == expected {
+					return
+				}
+			case <-timeout:
+				t.Fatalf("no %s event", expected)
+			}
+		}
+	}
+
+	time.Sleep(getSleepDuration())
+	dt.MoveToTime(group.GenesisTime)
+	dt.TestBeaconLength(2, false, dt.Ids(n, false)...)
+	dt.MoveTime(beaconPeriod)
+	dt.TestBeaconLength(3, false, dt.Ids(n, false)...)
+	dt.MoveTime(beaconPeriod)
+	dt.TestBeaconLength(4, false, dt.Ids(n, false)...)
+
+	// three beacons after the genesis one: the next partial beacon panics
+	dt.MoveTime(beaconPeriod)
+	require.Eventually(t, func() bool { return panicked.Load() != nil }, 5*time.Second, 50*time.Millisecond)
+	dr := panicked.Load().(*Drand)
+	waitFor(events[dr], EventBeaconStopped)
+	waitFor(events[dr], EventBeaconStarted)
+
+	dt.MoveTime(beaconPeriod)
+	dt.TestBeaconLength(6, false, dt.Ids(n, false)...)
+	require.Error(t, (&Drand{}).RecoverBeacon())
+}
+
+// The panics of the service restart the beacon at most once per interval
+func TestDrandPanicRecoverInterval(t *testing.T) {
+	c := clock.NewFakeClock()
+	d := &Drand{opts: NewConfig(), log: log.DefaultLogger()}
+	d.opts.clock = c
+	lastRecover := func() time.Time {
+		d.state.Lock()
+		defer d.state.Unlock()
+		return d.lastPanicRecover
+	}
+
+	d.onServicePanic("PublicRand")
+	require.True(t, lastRecover().IsZero())
+	d.onServicePanic("PartialBeacon")
+	first := lastRecover()
+	require.False(t, first.IsZero())
+
+	c.Advance(MinPanicRecoverInterval / 2)
+	d.onServicePanic("SyncChain")
+	require.Equal(t, first, lastRecover())
+
+	c.Advance(MinPanicRecoverInterval)
+	d.onServicePanic("PartialBeacon")
+	require.True(t, lastRecover().After(first))
+}
+
+// A frozen node refuses partial beacons and creates no new beacon, but still
+// serves its stored beacons and catches up once unfrozen
+func TestDrandFreeze(t *testing.T) {
+	n := 4
+	beaconPeriod := 1 * time.Second
+
+	dt := NewDrandTest2(t, n, key.DefaultThreshold(n), beaconPeriod)
+	defer dt.Cleanup()
+	group := dt.RunDKG()
+	time.Sleep(getSleepDuration())
+	dt.MoveToTime(group.GenesisTime)
+	dt.TestBeaconLength(2, false, dt.Ids(n, false)...)
+
+	frozenID := dt.nodes[n-1].addr
+	dr := dt.nodes[n-1].drand
+	require.NoError(t, dr.Freeze())
+	require.Error(t, dr.Freeze())
+
+	_, err := dr.PartialBeacon(context.Background(), new(drand.PartialBeaconPacket))
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	resp := dt.TestPublicBeacon(frozenID, false)
+	require.Equal(t, uint64(1), resp.GetRound())
+
+	// the other nodes are still above the threshold
+	dt.MoveTime(beaconPeriod)
+	dt.TestBeaconLength(3, false, dt.Ids(n-1, false)...)
+	dt.TestBeaconLength(2, false, frozenID)
+
+	require.NoError(t, dr.Unfreeze())
+	require.Error(t, dr.Unfreeze())
+	dt.MoveTime(beaconPeriod)
+	dt.TestBeaconLength(4, false, dt.Ids(n, false)...)
+}
+
+// A frozen node does not wait for the round it won't aggregate when its
+// beacon stops
+func TestDrandStopBeaconFrozen(t *testing.T) {
+	n := 3
+	beaconPeriod := 1 * time.Second
+
+	dt := NewDrandTest2(t, n, key.DefaultThreshold(n), beaconPeriod)
+	defer dt.Cleanup()
+	group := dt.RunDKG()
+	time.Sleep(getSleepDuration())
+	dt.MoveToTime(group.GenesisTime)
+	dt.TestBeaconLength(2, false, dt.Ids(n, false)...)
+
+	dr := dt.nodes[n-1].drand
+	require.NoError(t, dr.Freeze())
+	dt.MoveTime(beaconPeriod)
+	dt.TestBeaconLength(3, false, dt.Ids(n-1, false)...)
+
+	stopped := make(chan struct{})
+	go func() {
+		dr.StopBeacon()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(DrainRoundTimeout / 2):
+		require.FailNow(t, "beacon not stopped")
+	}
+}
+
+// A beacon callback taking the state lock does not block the beacon from
+// stopping
+func TestDrandStopBeaconCallbackLock(t *testing.T) {
+	n := 3
+	beaconPeriod := 1 * time.Second
+
+	dt := NewDrandTest2(t, n, key.DefaultThreshold(n), beaconPeriod)
+	defer dt.Cleanup()
+	group := dt.RunDKG()
+	time.Sleep(getSleepDuration())
+	dt.MoveToTime(group.GenesisTime)
+	dt.TestBeaconLength(2, false, dt.Ids(n, false)...)
+
+	dr := dt.nodes[0].drand
+	called := make(chan struct{}, 1)
+	release := make(chan struct{})
+	dr.state.Lock()
+	dr.beacon.AddCallback("lock", func(*chain.Beacon) {
+		select {
+		case called <- struct{}{}:
+		default:
+		}
+		<-release
+		dr.state.Lock()
+		dr.state.Unlock()
+	})
+	dr.state.Unlock()
+	dt.MoveTime(beaconPeriod)
+	select {
+	case <-called:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "callback not called")
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		dr.StopBeacon()
+		close(stopped)
+	}()
+	// let StopBeacon wait on the callback before releasing it
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	select {
+	case <-stopped:
+	case <-time.After(DrainCallbacksTimeout / 2):
+		require.FailNow(t, "beacon not stopped")
+	}
+}
+
+// lockedBuffer is a bytes.Buffer safe for concurrent use
+type lockedBuffer struct {
+	sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.Lock()
+	defer b.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.Lock()
+	defer b.Unlock()
+	return b.buf.String()
+}
+
+// Each phase of the DKG a node goes through is logged
+func TestDrandDKGPhaseLogs(t *testing.T) {
+	n := 3
+	dt := NewDrandTest2(t, n, key.DefaultThreshold(n), 1*time.Second)
+	defer dt.Cleanup()
+	logs := new(lockedBuffer)
+	dt.nodes[1].drand.log = log.NewLogger(kitlog.NewLogfmtLogger(logs), log.LogInfo)
+	dt.RunDKG()
+
+	var phases []string
+	var finish string
+	for _, line := range strings.Split(logs.String(), "\n") {
+		for _, field := range strings.Fields(line) {
+			if strings.HasPrefix(field, "dkg_phase=") {
+				phases = append(phases, strings.TrimPrefix(field, "dkg_phase="))
+				finish = line
+			}
+		}
+	}
+	// all the responses are approvals so there is no justification phase
+	require.Equal(t, []string{"deal", "response", "finish"}, phases)
+	require.Contains(t, finish, fmt.Sprintf("qualified=%d", n))
+	require.Contains(t, finish, "disqualified=0")
+	require.NotContains(t, logs.String(), "dkg_phase_timeout")
+}
+
+// The DKG callback runs without holding the state lock, so it can call back
+// into the node
+func TestDrandDKGCallback(t *testing.T) {
+	n := 3
+	beaconPeriod := 1 * time.Second
+
+	dt := NewDrandTest2(t, n, key.DefaultThreshold(n), beaconPeriod)
+	defer dt.Cleanup()
+	dr := dt.nodes[1].drand
+	infoCh := make(chan *drand.ChainInfoPacket, 1)
+	dr.opts.dkgCallback = func(*key.Share) {
+		info, err := dr.ChainInfo(context.Background(), new(drand.ChainInfoRequest))
+		require.NoError(t, err)
+		infoCh <- info
+	}
+	group := dt.RunDKG()
+	select {
+	case info := <-infoCh:
+		require.Equal(t, chain.NewChainInfo(group).Hash(), info.GetHash())
+	case <-time.After(time.Second):
+		t.Fatal("dkg callback not called")
+	}
+}
+
 func TestDrandDKGBroadcastDeny(t *testing.T) {
 	n := 4
 	thr := 3
@@ -109,6 +645,147 @@ func TestDrandDKGBroadcastDeny(t *testing.T) {
 	fmt.Println(" --- RESHARING FINISHED ---")
 }
 
+func TestDrandDKGAbort(t *testing.T) {
+	n := 3
+	thr := 2
+	beaconPeriod := 1 * time.Second
+
+	dt := NewDrandTest2(t, n, thr, beaconPeriod)
+	defer dt.Cleanup()
+	defer func() {
+		for _, node := range dt.nodes {
+			node.drand.Stop(context.Background())
+		}
+	}()
+	pairs := make([]*key.Pair, n)
+	for i, node := range dt.nodes {
+		pairs[i] = node.drand.priv
+		require.Error(t, node.drand.GracefulDKGAbort())
+	}
+	// the last node never receives the packets of the others, so the DKG
+	// stalls in its first phase since the time does not move
+	last := dt.nodes[n-1]
+	for _, node := range dt.nodes[:n-1] {
+		node.drand.DenyBroadcastTo(last.addr)
+	}
+
+	secret := "thisisdkg"
+	root := dt.nodes[0]
+	errCh := make(chan error, n)
+	go func() {
+		client, err := net.NewControlClient(root.drand.opts.controlPort)
+		require.NoError(t, err)
+		_, err = client.InitDKGLeader(n, thr, beaconPeriod, dt.catchupPeriod, testDkgTimeout, 0, nil, secret, testBeaconOffset, dt.genesisTime, false, dt.dkgRetries)
+		errCh <- err
+	}()
+	time.Sleep(1 * time.Second)
+	for _, node := range dt.nodes[1:] {
+		go func(node *Node) {
+			client, err := net.NewControlClient(node.drand.opts.controlPort)
+			require.NoError(t, err)
+			_, err = client.InitDKG(root.drand.priv.Public, nil, secret, dt.dkgRetries)
+			errCh <- err
+		}(node)
+	}
+	require.Eventually(t, func() bool {
+		for _, node := range dt.nodes {
+			node.drand.state.Lock()
+			running := node.drand.dkgInfo != nil
+			node.drand.state.Unlock()
+			if !running {
+				return false
+			}
+		}
+		return true
+	}, 10*time.Second, 50*time.Millisecond)
+
+	for _, node := range dt.nodes {
+		client, err := net.NewControlClient(node.drand.opts.controlPort)
+		require.NoError(t, err)
+		require.NoError(t, client.AbortDKG())
+	}
+	for i := 0; i < n; i++ {
+		select {
+		case err := <-errCh:
+			require.Error(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("dkg not aborted")
+		}
+	}
+	for i, node := range dt.nodes {
+		d := node.drand
+		d.state.Lock()
+		require.Nil(t, d.dkgInfo)
+		require.Nil(t, d.receiver)
+		require.Nil(t, d.manager)
+		require.False(t, d.dkgDone)
+		d.state.Unlock()
+		require.Equal(t, pairs[i], d.priv)
+		state, err := d.store.LoadDKGState()
+		require.NoError(t, err)
+		require.Nil(t, state)
+	}
+
+	// a new DKG runs cleanly once the nodes can reach each other again
+	for _, node := range dt.nodes[:n-1] {
+		deny := node.drand.privGateway.ProtocolClient.(*DenyClient)
+		node.drand.privGateway.ProtocolClient = deny.ProtocolClient
+	}
+	group := dt.RunDKG()
+	require.Equal(t, n, group.Len())
+}
+
+func TestDrandUseKeyFile(t *testing.T) {
+	n := 3
+	dt := NewDrandTest2(t, n, key.DefaultThreshold(n), 1*time.Second)
+	defer dt.Cleanup()
+	defer func() {
+		for _, node := range dt.nodes {
+			node.drand.Stop(context.Background())
+		}
+	}()
+	tmp, err := ioutil.TempDir("", "drand-key-file")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+	hsm := key.NewKeyPair("127.0.0.1:1")
+	keyFile := path.Join(tmp, "hsm.private")
+	require.NoError(t, key.Save(keyFile, hsm, true))
+
+	node := dt.nodes[1]
+	client, err := net.NewControlClient(node.drand.opts.controlPort)
+	require.NoError(t, err)
+	_, err = client.UseKeyFile(path.Join(tmp, "missing"))
+	require.Error(t, err)
+	resp, err := client.UseKeyFile(keyFile)
+	require.NoError(t, err)
+	id, err := key.IdentityFromProto(resp.GetIdentity())
+	require.NoError(t, err)
+	require.True(t, id.Key.Equal(hsm.Public.Key))
+	require.Equal(t, node.addr, id.Address())
+
+	group := dt.RunDKG()
+	member := group.Find(node.drand.priv.Public)
+	require.NotNil(t, member)
+	require.True(t, member.Key.Equal(hsm.Public.Key))
+
+	// the key file is still used after a restart
+	saved, err := keyFileLocation(node.drand.opts.ConfigFolder())
+	require.NoError(t, err)
+	require.Equal(t, keyFile, saved)
+	store := test.NewKeyStore()
+	require.NoError(t, store.SaveKeyPair(key.NewKeyPair(node.addr)))
+	restarted, err := withKeyFile(store, node.drand.opts)
+	require.NoError(t, err)
+	priv, err := restarted.LoadKeyPair()
+	require.NoError(t, err)
+	require.True(t, priv.Public.Key.Equal(hsm.Public.Key))
+
+	// the node can not switch to a key outside of its group anymore
+	require.NoError(t, key.Save(keyFile, key.NewKeyPair("127.0.0.1:1"), true))
+	_, err = client.UseKeyFile(keyFile)
+	require.Error(t, err)
+}
+
 func TestDrandReshareForce(t *testing.T) {
 	oldN := 4
 	oldThr := 3
@@ -291,7 +968,7 @@ func TestDrandResharePreempt(t *testing.T) {
 	go func() {
 		client, err := net.NewControlClient(dt.nodes[0].drand.opts.controlPort)
 		require.NoError(t, err)
-		_, err = client.InitReshareLeader(newN, Thr, timeout, 0, "unused secret", "", testBeaconOffset)
+		_, err = client.InitReshareLeader(newN, Thr, timeout, 0, 0, "unused secret", "", testBeaconOffset, false, 0)
 		// Done resharing
 		if err == nil {
 			panic("initial reshare should fail.")
@@ -300,10 +977,10 @@ func TestDrandResharePreempt(t *testing.T) {
 	}()
 	time.Sleep(100 * time.Millisecond)
 
-	// run the resharing
+	// run the resharing, forcing it to pre-empt the pending one
 	var doneReshare = make(chan *key.Group, 1)
 	go func() {
-		g, err := dt.RunReshare(oldN, 0, Thr, timeout, false, false)
+		g, err := dt.RunReshare(oldN, 0, Thr, timeout, true, false)
 		require.NoError(t, err)
 		doneReshare <- g
 	}()
@@ -338,6 +1015,47 @@ func TestDrandResharePreempt(t *testing.T) {
 	dt.TestPublicBeacon(dt.Ids(1, false)[0], false)
 }
 
+// This tests a new node joining the existing chain: it announces itself to the
+// leader and then waits to be included in the resharing
+func TestDrandReshareJoinExisting(t *testing.T) {
+	oldN := 3
+	oldThr := 2
+	newThr := 3
+	timeout := 1 * time.Second
+	beaconPeriod := 2 * time.Second
+
+	dt := NewDrandTest2(t, oldN, oldThr, beaconPeriod)
+	defer dt.Cleanup()
+	group1 := dt.RunDKG()
+	time.Sleep(getSleepDuration())
+	dt.MoveToTime(group1.GenesisTime)
+	dt.MoveTime(1 * time.Second)
+
+	newNode := dt.SetupNewNodes(1)[0]
+	leader := dt.nodes[0]
+	client := newNode.drand.privGateway.ProtocolClient
+	lpeer := leader.drand.priv.Public
+	ctx := context.Background()
+
+	// the leader refuses announcements for another group or from a member
+	packet := &drand.SignalDKGPacket{
+		Node:              newNode.drand.priv.Public.ToProto(),
+		PreviousGroupHash: []byte("not the group hash"),
+	}
+	require.Error(t, client.AnnounceJoin(ctx, lpeer, packet))
+	packet.Node = dt.nodes[1].drand.priv.Public.ToProto()
+	packet.PreviousGroupHash = group1.Hash()
+	require.Error(t, client.AnnounceJoin(ctx, lpeer, packet))
+	packet.Node = newNode.drand.priv.Public.ToProto()
+	require.NoError(t, client.AnnounceJoin(ctx, lpeer, packet))
+
+	dt.joinExisting = true
+	newGroup, err := dt.RunReshare(oldN, 1, newThr, timeout, false, false)
+	require.NoError(t, err)
+	require.Equal(t, oldN+1, newGroup.Len())
+	require.NotNil(t, newGroup.Find(newNode.drand.priv.Public))
+}
+
 // Check they all have same chain info
 func TestDrandPublicChainInfo(t *testing.T) {
 	n := 10
@@ -379,6 +1097,33 @@ func TestDrandPublicChainInfo(t *testing.T) {
 	// require.True(t, group.Equal(received))
 }
 
+func TestDrandFetchChainGroup(t *testing.T) {
+	n := 4
+	thr := key.DefaultThreshold(n)
+	p := 1 * time.Second
+	dt := NewDrandTest2(t, n, thr, p)
+	defer dt.Cleanup()
+	group := dt.RunDKG()
+	chainHash := chain.NewChainInfo(group).Hash()
+
+	leader := dt.nodes[0].drand.priv.Public
+	d := dt.nodes[1].drand
+	fetched, err := d.fetchChainGroup(context.Background(), leader, chainHash)
+	require.NoError(t, err)
+	require.True(t, group.Equal(fetched))
+
+	wrongHash := append([]byte{}, chainHash...)
+	wrongHash[0] ^= 0xff
+	_, err = d.fetchChainGroup(context.Background(), leader, wrongHash)
+	require.Error(t, err)
+
+	// the group is not covered by the hash so it is only fetched over TLS
+	insecure := net.CreatePeer(leader.Address(), false)
+	_, err = d.fetchChainGroup(context.Background(), insecure, chainHash)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "without TLS")
+}
+
 // Test if the we can correctly fetch the rounds after a DKG using the
 // PublicRand RPC call
 func TestDrandPublicRand(t *testing.T) {
@@ -491,6 +1236,20 @@ func TestDrandPublicStream(t *testing.T) {
 	case <-time.After(50 * time.Millisecond):
 		// correct
 	}
+
+	// the past rounds are streamed in order across several batches
+	defer func(size uint64) { StreamBatchSize = size }(StreamBatchSize)
+	StreamBatchSize = 2
+	respCh, err = client.PublicRandStream(ctx, root.drand.priv.Public, &drand.PublicRandRequest{Round: 1})
+	require.NoError(t, err)
+	for round := uint64(1); round <= maxRound; round++ {
+		select {
+		case resp := <-respCh:
+			require.Equal(t, round, resp.GetRound())
+		case <-time.After(1 * time.Second):
+			require.True(t, false, "too late for streaming, round %d didn't reply in time", round)
+		}
+	}
 }
 func TestDrandFollowChain(tt *testing.T) {
 	n := 4
@@ -600,6 +1359,16 @@ func TestDrandPublicStreamProxy(t *testing.T) {
 	resp, err := client.Get(ctx, 0)
 	require.NoError(t, err)
 
+	// the node proxy reads the most recent beacons from the store
+	last, err := (&nodeProxy{*client, root.drand}).LastN(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, last, 2)
+	require.Equal(t, resp.Round()-1, last[0].Round())
+	require.Equal(t, resp.Signature(), last[1].Signature())
+	proxyGroup, err := (&nodeProxy{*client, root.drand}).Group(ctx)
+	require.NoError(t, err)
+	require.True(t, proxyGroup.Equal(group))
+
 	//  run streaming and expect responses
 	rc := client.Watch(ctx)
 	// expect first round now since node already has it
@@ -622,6 +1391,180 @@ func TestDrandPublicStreamProxy(t *testing.T) {
 	}
 }
 
+func TestDrandLoadNotInGroup(t *testing.T) {
+	drands, _, dir, _ := BatchNewDrand(1, true)
+	defer os.RemoveAll(dir)
+	dr := drands[0]
+	dr.Stop(context.Background())
+
+	_, otherGroup := test.BatchIdentities(3)
+	require.NoError(t, dr.store.SaveGroup(otherGroup, false))
+	_, err := LoadDrand(dr.store, dr.opts)
+	require.Equal(t, ErrNotInGroup, err)
+}
+
+func TestDrandAbortDKG(t *testing.T) {
+	drands, group, dir, _ := BatchNewDrand(1, true)
+	defer os.RemoveAll(dir)
+	dr := drands[0]
+	dr.Stop(context.Background())
+
+	// the node restarts while the DKG was in its deal phase
+	group.PublicKey = nil
+	group.GenesisTime = time.Now().Unix() + 60
+	require.NoError(t, dr.store.SaveDKGState(&key.DKGState{
+		Target:  group,
+		Timeout: 1,
+		Started: true,
+		Phase:   int(dkg.DealPhase),
+	}))
+	restarted, err := NewDrand(dr.store, dr.opts)
+	require.NoError(t, err)
+	defer restarted.Stop(context.Background())
+
+	// the interrupted DKG is dropped instead of dealing a new secret
+	restarted.state.Lock()
+	require.Nil(t, restarted.dkgInfo)
+	require.False(t, restarted.dkgDone)
+	restarted.state.Unlock()
+	state, err := dr.store.LoadDKGState()
+	require.NoError(t, err)
+	require.Nil(t, state)
+}
+
+func TestDrandNewErrors(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "drand")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+	privs, _ := test.BatchIdentities(1)
+	store := test.NewKeyStore()
+	require.NoError(t, store.SaveKeyPair(privs[0]))
+
+	// an address already bound by someone else
+	busy, err := gnet.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer busy.Close()
+
+	privAddr := "127.0.0.1:" + test.FreePort()
+	base := []ConfigOption{
+		WithConfigFolder(tmp),
+		WithInsecure(),
+		WithPrivateListenAddress(privAddr),
+		WithPublicListenAddress("127.0.0.1:" + test.FreePort()),
+		WithControlPort(test.FreePort()),
+	}
+	cases := []struct {
+		name string
+		opts []ConfigOption
+	}{
+		{"missing certificate", []ConfigOption{WithConfigFolder(tmp)}},
+		{"unreadable certificate", append(append([]ConfigOption{}, base...), func(c *Config) {
+			c.insecure = false
+			c.certPath = path.Join(tmp, "missing.crt")
+			c.keyPath = path.Join(tmp, "missing.key")
+		})},
+		{"private address in use", append(append([]ConfigOption{}, base...), WithPrivateListenAddress(busy.Addr().String()))},
+		{"public address in use", append(append([]ConfigOption{}, base...), WithPublicListenAddress(busy.Addr().String()))},
+		{"control address in use", append(append([]ConfigOption{}, base...), WithControlPort(busy.Addr().String()))},
+		{"hsts without tls", append(append([]ConfigOption{}, base...), WithHSTS(time.Hour, false))},
+		{"missing well-known dir", append(append([]ConfigOption{}, base...), WithWellKnownDir(path.Join(tmp, "missing")))},
+	}
+	for _, c := range cases {
+		_, err := NewDrand(store, NewConfig(c.opts...))
+		require.Error(t, err, c.name)
+		// the addresses bound before the failure must have been released
+		lis, err := gnet.Listen("tcp", privAddr)
+		require.NoError(t, err, c.name)
+		require.NoError(t, lis.Close())
+	}
+}
+
+func TestDrandWellKnown(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "drand-well-known")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+	wellKnown := path.Join(tmp, "well-known")
+	require.NoError(t, os.Mkdir(wellKnown, 0700))
+	require.NoError(t, os.Mkdir(path.Join(wellKnown, "sub"), 0700))
+	require.NoError(t, ioutil.WriteFile(path.Join(wellKnown, "drand-chain"), []byte(`{"period":30}`), 0600))
+	require.NoError(t, ioutil.WriteFile(path.Join(wellKnown, "notes.txt"), []byte("hello"), 0600))
+
+	privs, _ := test.BatchIdentities(1)
+	store := test.NewKeyStore()
+	require.NoError(t, store.SaveKeyPair(privs[0]))
+	pubAddr := "127.0.0.1:" + test.FreePort()
+	d, err := NewDrand(store, NewConfig(
+		WithConfigFolder(tmp),
+		WithInsecure(),
+		WithPrivateListenAddress("127.0.0.1:"+test.FreePort()),
+		WithPublicListenAddress(pubAddr),
+		WithControlPort(test.FreePort()),
+		WithWellKnownDir(wellKnown),
+		WithCORSOrigins([]string{"https://example.com"}),
+	))
+	require.NoError(t, err)
+	defer d.Stop(context.Background())
+
+	var header nethttp.Header
+	get := func(p string) (int, string, string) {
+		req, err := nethttp.NewRequest(nethttp.MethodGet, "http://"+pubAddr+WellKnownPrefix+p, nil)
+		require.NoError(t, err)
+		req.Header.Set("Origin", "https://example.com")
+		resp, err := nethttp.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		header = resp.Header
+		return resp.StatusCode, resp.Header.Get("Content-Type"), string(body)
+	}
+	code, ctype, body := get("drand-chain")
+	require.Equal(t, nethttp.StatusOK, code)
+	require.Equal(t, "application/json", ctype)
+	require.Equal(t, `{"period":30}`, body)
+	// the files go through the same CORS handler as the API
+	require.Equal(t, "https://example.com", header.Get("Access-Control-Allow-Origin"))
+	code, ctype, body = get("notes.txt")
+	require.Equal(t, nethttp.StatusOK, code)
+	require.Contains(t, ctype, "text/plain")
+	require.Equal(t, "hello", body)
+	code, _, _ = get("sub")
+	require.Equal(t, nethttp.StatusNotFound, code)
+}
+
+func TestConfigValidate(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "drand-validate")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+
+	require.NoError(t, NewConfig(WithConfigFolder(tmp)).Validate())
+	require.NoError(t, NewConfig(WithConfigFolder(tmp), WithBeaconIDPrefix("acme42")).Validate())
+	for _, prefix := range []string{"acme-corp", "acmé", strings.Repeat("a", MaxBeaconIDPrefixLen+1)} {
+		err = NewConfig(WithConfigFolder(tmp), WithBeaconIDPrefix(prefix)).Validate()
+		require.Error(t, err, prefix)
+		require.Contains(t, err.Error(), "beacon id prefix")
+	}
+
+	err = NewConfig(WithConfigFolder(path.Join(tmp, "missing"))).Validate()
+	var cerr *ConfigError
+	require.True(t, errors.As(err, &cerr))
+	require.Len(t, cerr.Problems, 1)
+	require.True(t, errors.Is(err, os.ErrNotExist))
+
+	file := path.Join(tmp, "file")
+	require.NoError(t, ioutil.WriteFile(file, nil, 0600))
+	err = NewConfig(WithConfigFolder(file)).Validate()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "is not a directory")
+
+	store, err := key.NewFileStore(tmp)
+	require.NoError(t, err)
+	err = NewConfig(WithConfigFolder(tmp)).Validate()
+	require.True(t, errors.Is(err, key.ErrStoreLocked))
+	require.NoError(t, store.Close())
+	require.NoError(t, NewConfig(WithConfigFolder(tmp)).Validate())
+}
+
 // BatchNewDrand returns n drands, using TLS or not, with the given
 // options. It returns the list of Drand structures, the group created,
 // the folder where db, certificates, etc are stored. It is the folder
@@ -669,7 +1612,8 @@ func BatchNewDrand(n int, insecure bool, opts ...ConfigOption) (drands []*Drand,
 		s.SaveKeyPair(privs[i])
 		// give each one their own private folder
 		dbFolder := path.Join(dir, fmt.Sprintf("db-%d", i))
-		confOptions := []ConfigOption{WithDBFolder(dbFolder)}
+		confFolder := path.Join(dir, fmt.Sprintf("config-%d", i))
+		confOptions := []ConfigOption{WithConfigFolder(confFolder), WithDBFolder(dbFolder)}
 		if !insecure {
 			confOptions = append(confOptions,
 				WithTLS(certPaths[i], keyPaths[i]),
@@ -677,8 +1621,11 @@ func BatchNewDrand(n int, insecure bool, opts ...ConfigOption) (drands []*Drand,
 		} else {
 			confOptions = append(confOptions, WithInsecure())
 		}
+		// nodes can be stopped in the middle of a setup whose control
+		// requests never return, so don't wait long on them
 		confOptions = append(confOptions,
 			WithControlPort(ports[i]),
+			WithControlStopTimeout(100*time.Millisecond),
 			WithLogLevel(log.LogDebug))
 		// add options in last so it overwrites the default
 		confOptions = append(confOptions, opts...)
@@ -704,3 +1651,399 @@ func getSleepDuration() time.Duration {
 	}
 	return time.Duration(500) * time.Millisecond
 }
+
+func TestDrandSyncerCancel(t *testing.T) {
+	d := &Drand{}
+
+	d.state.Lock()
+	ctx1, release1 := d.syncerContext(context.Background())
+	d.state.Unlock()
+	require.NoError(t, ctx1.Err())
+
+	// starting a second sync operation cancels the first one
+	d.state.Lock()
+	ctx2, release2 := d.syncerContext(context.Background())
+	require.Equal(t, context.Canceled, ctx1.Err())
+	require.NoError(t, ctx2.Err())
+	d.state.Unlock()
+
+	// releasing the first operation leaves the second one running
+	release1()
+	require.NoError(t, ctx2.Err())
+	require.NotNil(t, d.syncerCancel)
+
+	release2()
+	require.Equal(t, context.Canceled, ctx2.Err())
+	require.Nil(t, d.syncerCancel)
+
+	// stopping the syncer cancels the current operation
+	d.state.Lock()
+	ctx3, release3 := d.syncerContext(context.Background())
+	d.stopSyncer()
+	d.state.Unlock()
+	require.Equal(t, context.Canceled, ctx3.Err())
+	release3()
+	require.Nil(t, d.syncerCancel)
+}
+
+// The calls to the protocol and control APIs of the node are appended to the
+// audit log, the public reads are not
+func TestDrandAuditLog(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "drand-audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+	auditPath := path.Join(tmp, "audit.log")
+	pubAddr := "127.0.0.1:" + test.FreePort()
+	drands, group, dir, _ := BatchNewDrand(1, true, WithAuditLog(auditPath), WithPublicListenAddress(pubAddr))
+	defer os.RemoveAll(dir)
+	d := drands[0]
+	// the HTTP API needs the chain info before asking for a round
+	d.state.Lock()
+	d.group = group
+	d.state.Unlock()
+
+	client := net.NewGrpcClient()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	// the node has no beacon running, the failed call is logged as well
+	err = client.PartialBeacon(ctx, d.priv.Public, &drand.PartialBeaconPacket{Round: 1})
+	require.Error(t, err)
+	ctrl, err := net.NewControlClient(d.opts.ControlPort())
+	require.NoError(t, err)
+	_, err = ctrl.Status()
+	require.NoError(t, err)
+	_, err = client.PublicRand(ctx, d.priv.Public, &drand.PublicRandRequest{Round: 1})
+	require.Error(t, err)
+	resp, err := nethttp.Get("http://" + pubAddr + "/public/1")
+	require.NoError(t, err)
+	resp.Body.Close()
+	d.Stop(context.Background())
+	<-d.WaitExit()
+
+	buff, err := ioutil.ReadFile(auditPath)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(buff)), "\n")
+	entries := make(map[string]*net.AuditEntry)
+	for _, line := range lines {
+		entry := new(net.AuditEntry)
+		require.NoError(t, json.Unmarshal([]byte(line), entry))
+		entries[entry.Method] = entry
+	}
+	require.Contains(t, entries, "PartialBeacon", "entries: %v", lines)
+	require.Contains(t, entries, "Status", "entries: %v", lines)
+	require.NotContains(t, entries, "PublicRand", "entries: %v", lines)
+	require.NotEqual(t, codes.OK.String(), entries["PartialBeacon"].Status)
+	require.Equal(t, codes.OK.String(), entries["Status"].Status)
+	for _, entry := range entries {
+		require.Len(t, entry.RequestHash, 64)
+		require.NotEmpty(t, entry.Remote)
+	}
+}
+
+// Stopping the beacon or drand several times concurrently neither panics nor
+// blocks, and the beacon can't start once drand is stopped
+func TestDrandStopTwice(t *testing.T) {
+	drands, _, dir, _ := BatchNewDrand(1, true)
+	defer os.RemoveAll(dir)
+	d := drands[0]
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			d.StopBeacon()
+		}()
+		go func() {
+			defer wg.Done()
+			d.Stop(context.Background())
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("concurrent stops did not return")
+	}
+	select {
+	case <-d.WaitExit():
+	default:
+		t.Fatal("exit not signaled")
+	}
+	d.StopBeacon()
+	d.Stop(context.Background())
+
+	d.StartBeacon(false)
+	d.state.Lock()
+	defer d.state.Unlock()
+	require.Nil(t, d.beacon)
+}
+
+// No beacon is created once drand starts stopping, before Stop returns
+func TestDrandStartBeaconStopping(t *testing.T) {
+	drands, _, dir, _ := BatchNewDrand(1, true)
+	defer os.RemoveAll(dir)
+	d := drands[0]
+	defer d.Stop(context.Background())
+
+	d.state.Lock()
+	d.stopping = true
+	d.state.Unlock()
+	_, err := d.newBeacon()
+	require.Equal(t, errStopping, err)
+	d.StartBeacon(false)
+	d.state.Lock()
+	defer d.state.Unlock()
+	require.Nil(t, d.beacon)
+}
+
+type countingService struct {
+	net.Service
+	calls *int32
+}
+
+func (c *countingService) PingPong(ctx context.Context, in *drand.Ping) (*drand.Pong, error) {
+	atomic.AddInt32(c.calls, 1)
+	return c.Service.PingPong(ctx, in)
+}
+
+func (c *countingService) GetIdentity(ctx context.Context, in *drand.IdentityRequest) (*drand.Identity, error) {
+	atomic.AddInt32(c.calls, 1)
+	return c.Service.GetIdentity(ctx, in)
+}
+
+func TestDrandServiceMiddlewares(t *testing.T) {
+	var calls int32
+	counting := func(s net.Service) net.Service {
+		return &countingService{Service: s, calls: &calls}
+	}
+	drands, _, dir, _ := BatchNewDrand(1, true, WithServiceMiddlewares(counting))
+	defer CloseAllDrands(drands)
+	defer os.RemoveAll(dir)
+	d := drands[0]
+
+	// control API
+	client, err := net.NewControlClient(d.opts.controlPort)
+	require.NoError(t, err)
+	require.NoError(t, client.Ping())
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// private API
+	peer := net.CreatePeer(d.priv.Public.Address(), false)
+	id, err := d.privGateway.ProtocolClient.GetIdentity(context.Background(), peer, &drand.IdentityRequest{})
+	require.NoError(t, err)
+	require.Equal(t, d.priv.Public.Address(), id.GetAddress())
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestDrandReloadConfig(t *testing.T) {
+	drands, _, dir, _ := BatchNewDrand(1, true)
+	defer os.RemoveAll(dir)
+	dr := drands[0]
+	defer dr.Stop(context.Background())
+
+	reloaded := *dr.opts
+	reloaded.logLevel = log.NewLevelVar(log.LogInfo)
+	reloaded.controlStopTimeout = time.Second
+	require.NoError(t, dr.ReloadConfig(&reloaded))
+	require.Equal(t, log.LogInfo, dr.opts.logLevel.Level())
+	require.Equal(t, time.Second, dr.opts.controlStopTimeout)
+
+	changed := *dr.opts
+	changed.logLevel = log.NewLevelVar(log.LogDebug)
+	changed.privateListenAddr = "127.0.0.1:1"
+	err := dr.ReloadConfig(&changed)
+	require.True(t, errors.Is(err, ErrNotReloadable))
+	require.Equal(t, log.LogInfo, dr.opts.logLevel.Level())
+
+	changed = *dr.opts
+	changed.certPath = "other.crt"
+	require.True(t, errors.Is(dr.ReloadConfig(&changed), ErrNotReloadable))
+}
+
+func TestDrandReloadCert(t *testing.T) {
+	drands, _, dir, certPaths := BatchNewDrand(1, false)
+	defer os.RemoveAll(dir)
+	dr := drands[0]
+	defer dr.Stop(context.Background())
+
+	oldCert, err := ioutil.ReadFile(certPaths[0])
+	require.NoError(t, err)
+	certPEM, keyPEM, err := net.SelfSignedCert(dr.priv.Public.Address(), time.Hour)
+	require.NoError(t, err)
+	newCertPath := path.Join(dir, "new.crt")
+	newKeyPath := path.Join(dir, "new.key")
+	require.NoError(t, ioutil.WriteFile(newCertPath, certPEM, 0600))
+	require.NoError(t, ioutil.WriteFile(newKeyPath, keyPEM, 0600))
+
+	resp, err := dr.ReloadCert(context.Background(), &drand.ReloadCertRequest{
+		CertPath:       newCertPath,
+		KeyPath:        newKeyPath,
+		BackupExisting: true,
+	})
+	require.NoError(t, err)
+	backup, err := ioutil.ReadFile(resp.GetBackupCertPath())
+	require.NoError(t, err)
+	require.Equal(t, oldCert, backup)
+	installed, err := ioutil.ReadFile(certPaths[0])
+	require.NoError(t, err)
+	require.Equal(t, certPEM, installed)
+
+	conn, err := tls.Dial("tcp", dr.priv.Public.Address(), &tls.Config{InsecureSkipVerify: true})
+	require.NoError(t, err)
+	defer conn.Close()
+	block, _ := pem.Decode(certPEM)
+	require.Equal(t, block.Bytes, conn.ConnectionState().PeerCertificates[0].Raw)
+
+	// a certificate not matching its key is refused
+	_, err = dr.ReloadCert(context.Background(), &drand.ReloadCertRequest{
+		CertPath: resp.GetBackupCertPath(),
+		KeyPath:  newKeyPath,
+	})
+	require.Error(t, err)
+}
+
+func TestDrandWatchEvents(t *testing.T) {
+	n := 3
+	thr := key.DefaultThreshold(n)
+	dt := NewDrandTest2(t, n, thr, 1*time.Second)
+	defer dt.Cleanup()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := dt.nodes[0].drand.WatchEvents(ctx)
+	next := func(expected EventType) Event {
+		select {
+		case ev := <-events:
+			require.Equal(t, expected, ev.Type, "got %s: %s", ev.Type, ev.Detail)
+			return ev
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no %s event", expected)
+		}
+		return Event{}
+	}
+
+	group := dt.RunDKG()
+	require.Equal(t, "leader: true, nodes: 3", next(EventDKGStarted).Detail)
+	require.Equal(t, "qualified: 3, disqualified: 0", next(EventDKGFinished).Detail)
+	require.Equal(t, "catchup: false", next(EventBeaconStarted).Detail)
+
+	dt.MoveToTime(group.GenesisTime)
+	dt.MoveTime(1 * time.Second)
+	time.Sleep(200 * time.Millisecond)
+	_, err := dt.RunReshare(n, 0, thr, 1*time.Second, false, false)
+	require.NoError(t, err)
+	require.Equal(t, "leader: true, nodes: 3", next(EventReshareStarted).Detail)
+	next(EventDKGFinished)
+
+	dt.nodes[0].drand.StopBeacon()
+	next(EventBeaconStopped)
+
+	cancel()
+	require.Eventually(t, func() bool {
+		_, ok := <-events
+		return !ok
+	}, time.Second, 10*time.Millisecond)
+}
+
+// A node lagging more than the maximum lag freezes once, and only freezes again
+// after it caught up
+func TestDrandBeaconLag(t *testing.T) {
+	d := &Drand{log: log.DefaultLogger(), opts: NewConfig(WithMaxBeaconLag(2))}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := d.WatchEvents(ctx)
+
+	d.checkBeaconLag(10, 8)
+	require.False(t, d.frozen)
+	require.Equal(t, float64(2), testutil.ToFloat64(metrics.BeaconLag))
+
+	freezes := testutil.ToFloat64(metrics.BeaconLagFreezes)
+	d.checkBeaconLag(10, 7)
+	require.True(t, d.frozen)
+	require.Equal(t, float64(3), testutil.ToFloat64(metrics.BeaconLag))
+	require.Equal(t, freezes+1, testutil.ToFloat64(metrics.BeaconLagFreezes))
+	ev := <-events
+	require.Equal(t, EventBeaconLagging, ev.Type)
+	require.Equal(t, "lag: 3, max: 2", ev.Detail)
+
+	// still catching up after the operator unfroze it
+	require.NoError(t, d.Unfreeze())
+	d.checkBeaconLag(11, 7)
+	require.False(t, d.frozen)
+
+	// caught up, then lagging again
+	d.checkBeaconLag(11, 10)
+	d.checkBeaconLag(15, 10)
+	require.True(t, d.frozen)
+
+	// no freeze when the check is disabled
+	d = &Drand{log: log.DefaultLogger(), opts: NewConfig()}
+	d.checkBeaconLag(100, 1)
+	require.False(t, d.frozen)
+	require.Equal(t, float64(99), testutil.ToFloat64(metrics.BeaconLag))
+}
+
+// A watcher that does not read its events is dropped once its buffer is full
+func TestDrandWatchEventsDropped(t *testing.T) {
+	d := &Drand{log: log.DefaultLogger(), opts: NewConfig()}
+	slow := d.WatchEvents(context.Background())
+	fast := d.WatchEvents(context.Background())
+	for i := 0; i <= EventBufferSize; i++ {
+		d.sendEvent(EventBeaconStarted, "")
+		<-fast
+	}
+	for i := 0; i < EventBufferSize; i++ {
+		ev, ok := <-slow
+		require.True(t, ok)
+		require.Equal(t, EventBeaconStarted, ev.Type)
+	}
+	_, ok := <-slow
+	require.False(t, ok)
+
+	d.sendEvent(EventBeaconStopped, "")
+	ev, ok := <-fast
+	require.True(t, ok)
+	require.Equal(t, EventBeaconStopped, ev.Type)
+}
+
+func TestDrandMissedTransition(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "drand-transition")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+	c := clock.NewFakeClockAt(time.Unix(1000, 0))
+	period := 10 * time.Second
+	d := &Drand{opts: NewConfig(WithConfigFolder(tmp)), group: &key.Group{GenesisTime: 1000, Period: period}}
+	d.opts.clock = c
+	store, err := d.createBoltStore()
+	require.NoError(t, err)
+	defer store.Close()
+	missed := func() bool {
+		m, err := d.missedTransition(store)
+		require.NoError(t, err)
+		return m
+	}
+
+	// no resharing happened
+	c.Advance(time.Minute)
+	require.False(t, missed())
+
+	// the transition at round 10 is not over yet
+	d.group.TransitionTime = chain.TimeOfRound(period, d.group.GenesisTime, 10)
+	require.False(t, missed())
+
+	// the node stored no beacon before the transition
+	c.Advance(time.Minute)
+	require.True(t, missed())
+	for round := uint64(1); round < 10; round++ {
+		require.NoError(t, store.Put(&chain.Beacon{Round: round}))
+	}
+	require.True(t, missed())
+
+	// the node was up at the transition
+	require.NoError(t, store.Put(&chain.Beacon{Round: 10}))
+	require.False(t, missed())
+}
@@ -0,0 +1,121 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/drand/drand/log"
+)
+
+// EventType is the kind of a lifecycle event of the node
+type EventType int
+
+const (
+	// EventDKGStarted is sent when the node starts a fresh DKG
+	EventDKGStarted EventType = iota
+	// EventReshareStarted is sent when the node starts a resharing
+	EventReshareStarted
+	// EventDKGFinished is sent when a DKG or a resharing finishes successfully
+	EventDKGFinished
+	// EventBeaconStarted is sent when the node starts generating beacons
+	EventBeaconStarted
+	// EventBeaconStopped is sent when the node stops generating beacons
+	EventBeaconStopped
+	// EventBeaconLagging is sent when the node freezes because it lags too far
+	// behind the chain
+	EventBeaconLagging
+)
+
+func (e EventType) String() string {
+	switch e {
+	case EventDKGStarted:
+		return "dkg_started"
+	case EventReshareStarted:
+		return "reshare_started"
+	case EventDKGFinished:
+		return "dkg_finished"
+	case EventBeaconStarted:
+		return "beacon_started"
+	case EventBeaconStopped:
+		return "beacon_stopped"
+	case EventBeaconLagging:
+		return "beacon_lagging"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is a lifecycle event of the node, as sent to the watchers
+type Event struct {
+	Type      EventType
+	Timestamp time.Time
+	Detail    string
+}
+
+// EventBufferSize is the number of events a watcher can be behind before it is
+// dropped.
+var EventBufferSize = 16
+
+// eventWatchers sends the events of the node to all the registered watchers.
+// The zero value is ready to use.
+type eventWatchers struct {
+	sync.Mutex
+	watchers map[int]chan Event
+	next     int
+}
+
+func (e *eventWatchers) add() (int, chan Event) {
+	e.Lock()
+	defer e.Unlock()
+	if e.watchers == nil {
+		e.watchers = make(map[int]chan Event)
+	}
+	id := e.next
+	e.next++
+	ch := make(chan Event, EventBufferSize)
+	e.watchers[id] = ch
+	return id, ch
+}
+
+// remove closes the channel of the watcher if it is still registered
+func (e *eventWatchers) remove(id int) {
+	e.Lock()
+	defer e.Unlock()
+	if ch, ok := e.watchers[id]; ok {
+		delete(e.watchers, id)
+		close(ch)
+	}
+}
+
+// send sends the event to all the watchers. A watcher whose buffer is full is
+// dropped: its channel is closed.
+func (e *eventWatchers) send(l log.Logger, ev Event) {
+	e.Lock()
+	defer e.Unlock()
+	for id, ch := range e.watchers {
+		select {
+		case ch <- ev:
+		default:
+			l.Warn("events", "watcher_dropped", "id", id, "event", ev.Type)
+			delete(e.watchers, id)
+			close(ch)
+		}
+	}
+}
+
+// WatchEvents returns a channel on which the lifecycle events of the node are
+// sent, until ctx is done. The channel is closed when ctx is done, or when the
+// watcher falls more than EventBufferSize events behind.
+func (d *Drand) WatchEvents(ctx context.Context) <-chan Event {
+	id, ch := d.events.add()
+	go func() {
+		<-ctx.Done()
+		d.events.remove(id)
+	}()
+	return ch
+}
+
+func (d *Drand) sendEvent(t EventType, detail string) {
+	d.events.send(d.log, Event{Type: t, Timestamp: d.opts.clock.Now(), Detail: detail})
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/drand/drand/chain"
@@ -46,7 +47,9 @@ type setupManager struct {
 	beaconOffset  time.Duration
 	catchupPeriod time.Duration
 	beaconPeriod  time.Duration
+	genesisTime   int64
 	dkgTimeout    time.Duration
+	waitTimeout   time.Duration
 	clock         clock.Clock
 	leaderKey     *key.Identity
 	verifyKeys    func([]*key.Identity) bool
@@ -60,6 +63,10 @@ type setupManager struct {
 	pushKeyCh    chan pushKey
 	doneCh       chan bool
 	hashedSecret []byte
+	// number of distinct keys collected so far, the leader's included
+	arrived int32
+	// observers that signaled themselves, they are not counted in expected
+	observers []*key.Identity
 }
 
 func newDKGSetup(
@@ -68,11 +75,19 @@ func newDKGSetup(
 	leaderKey *key.Identity,
 	beaconPeriod,
 	catchupPeriod uint32,
+	genesisTime int64,
 	in *drand.SetupInfoPacket) (*setupManager, error) {
 	n, thr, dkgTimeout, err := validInitPacket(in)
 	if err != nil {
 		return nil, err
 	}
+	period := time.Duration(beaconPeriod) * time.Second
+	if err := key.ValidatePeriod(period); err != nil {
+		return nil, err
+	}
+	if genesisTime != 0 && genesisTime < c.Now().Add(period).Unix() {
+		return nil, fmt.Errorf("genesis time %d must be at least one period (%s) in the future", genesisTime, period)
+	}
 	secret := hashSecret(in.GetSecret())
 	verifyKeys := func(keys []*key.Identity) bool {
 		// XXX Later we can add specific name list of DNS, or prexisting
@@ -83,14 +98,20 @@ func newDKGSetup(
 	if in.GetBeaconOffset() == 0 {
 		offset = DefaultGenesisOffset
 	}
+	waitTimeout := time.Duration(in.GetWaitTimeout()) * time.Second
+	if in.GetWaitTimeout() == 0 {
+		waitTimeout = MaxWaitPrepareDKG
+	}
 
 	sm := &setupManager{
 		expected:      n,
 		thr:           thr,
 		beaconOffset:  offset,
-		beaconPeriod:  time.Duration(beaconPeriod) * time.Second,
+		beaconPeriod:  period,
+		genesisTime:   genesisTime,
 		catchupPeriod: time.Duration(catchupPeriod) * time.Second,
 		dkgTimeout:    dkgTimeout,
+		waitTimeout:   waitTimeout,
 		l:             l,
 		startDKG:      make(chan *key.Group, 1),
 		pushKeyCh:     make(chan pushKey, n),
@@ -115,7 +136,7 @@ func newReshareSetup(
 	if !in.CatchupPeriodChanged {
 		catchupPeriod = uint32(oldGroup.CatchupPeriod.Seconds())
 	}
-	sm, err := newDKGSetup(l, c, leaderKey, beaconPeriod, catchupPeriod, in.GetInfo())
+	sm, err := newDKGSetup(l, c, leaderKey, beaconPeriod, catchupPeriod, 0, in.GetInfo())
 	if err != nil {
 		return nil, err
 	}
@@ -160,6 +181,20 @@ func (s *setupManager) ReceivedKey(addr string, p *drand.SignalDKGPacket) error
 		return fmt.Errorf("invalid sig: %s", err)
 	}
 
+	if p.GetObserver() {
+		if s.isResharing {
+			return errors.New("observers can only join a fresh DKG")
+		}
+		for _, o := range s.observers {
+			if o.Address() == newID.Address() || o.Key.Equal(newID.Key) {
+				return nil
+			}
+		}
+		s.l.Info("setup", "added_observer", "key", newID.String())
+		s.observers = append(s.observers, newID)
+		return nil
+	}
+
 	s.l.Debug("setup", "received_new_key", "id", newID.String())
 
 	s.pushKeyCh <- pushKey{
@@ -173,6 +208,7 @@ func (s *setupManager) run() {
 	defer close(s.startDKG)
 	var inKeys = make([]*key.Identity, 0, s.expected)
 	inKeys = append(inKeys, s.leaderKey)
+	atomic.StoreInt32(&s.arrived, 1)
 	for {
 		select {
 		case pk := <-s.pushKeyCh:
@@ -194,7 +230,8 @@ func (s *setupManager) run() {
 				break
 			}
 			inKeys = append(inKeys, pk.id)
-			s.l.Debug("setup", "added", "key", pk.id.String(), "have", fmt.Sprintf("%d/%d", len(inKeys), s.expected))
+			atomic.StoreInt32(&s.arrived, int32(len(inKeys)))
+			s.l.Info("setup", "added", "key", pk.id.String(), "have", fmt.Sprintf("%d/%d", len(inKeys), s.expected))
 
 			// create group if we have enough keys
 			if len(inKeys) == s.expected {
@@ -220,11 +257,15 @@ func (s *setupManager) createAndSend(keys []*key.Identity) {
 	var group *key.Group
 	totalDKG := s.dkgTimeout*3 + s.beaconOffset
 	if !s.isResharing {
-		genesis := s.clock.Now().Add(totalDKG).Unix()
-		// round the genesis time to a period modulo
-		ps := int64(s.beaconPeriod.Seconds())
-		genesis += (ps - genesis%ps)
+		genesis := s.genesisTime
+		if genesis == 0 {
+			genesis = s.clock.Now().Add(totalDKG).Unix()
+			// round the genesis time to a period modulo
+			ps := int64(s.beaconPeriod.Seconds())
+			genesis += (ps - genesis%ps)
+		}
 		group = key.NewGroup(keys, s.thr, genesis, s.beaconPeriod, s.catchupPeriod)
+		group.Observers = s.observerNodes(group)
 	} else {
 		genesis := s.oldGroup.GenesisTime
 		atLeast := s.clock.Now().Add(totalDKG).Unix()
@@ -241,6 +282,27 @@ func (s *setupManager) createAndSend(keys []*key.Identity) {
 	s.startDKG <- group
 }
 
+// observerNodes returns the observers that are not nodes of the group, with
+// the observer index.
+func (s *setupManager) observerNodes(group *key.Group) []*key.Node {
+	s.Lock()
+	defer s.Unlock()
+	var nodes []*key.Node
+	for _, o := range s.observers {
+		if group.Find(o) != nil {
+			continue
+		}
+		nodes = append(nodes, &key.Node{Identity: o, Index: key.ObserverIndex})
+	}
+	return nodes
+}
+
+// Arrived returns the number of distinct nodes collected so far, the leader
+// included.
+func (s *setupManager) Arrived() int {
+	return int(atomic.LoadInt32(&s.arrived))
+}
+
 func (s *setupManager) WaitGroup() chan *key.Group {
 	return s.startDKG
 }
@@ -0,0 +1,86 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/metrics"
+)
+
+// watchBeaconLag checks the lag of the beacon at every period until ctx is
+// done.
+func (d *Drand) watchBeaconLag(ctx context.Context, period time.Duration) {
+	ticker := d.opts.clock.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.Chan():
+			d.pollBeaconLag()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// pollBeaconLag compares the last stored round to the current round of the
+// chain. It does nothing before the genesis time or while the node catches up,
+// as it is then expected to be behind.
+func (d *Drand) pollBeaconLag() {
+	d.state.Lock()
+	b, group, catchingUp := d.beacon, d.group, d.catchingUp
+	d.state.Unlock()
+	if b == nil || group == nil || catchingUp {
+		return
+	}
+	now := d.opts.clock.Now().Unix()
+	if now < group.GenesisTime {
+		return
+	}
+	last, err := b.Store().Last()
+	if err != nil {
+		d.log.Debug("beacon_lag", "no_last_beacon", "err", err)
+		return
+	}
+	d.checkBeaconLag(chain.CurrentRound(now, group.Period, group.GenesisTime), last.Round)
+}
+
+// checkBeaconLag records the lag of the last stored round and freezes the node
+// when it is above the maximum lag. The check only trips again once the lag
+// went back within the maximum, so the node is not frozen again while it
+// catches up after the operator unfroze it.
+func (d *Drand) checkBeaconLag(current, last uint64) {
+	var lag uint64
+	if current > last {
+		lag = current - last
+	}
+	metrics.BeaconLag.Set(float64(lag))
+	max := d.opts.maxBeaconLag
+	if max == 0 {
+		return
+	}
+	d.state.Lock()
+	if lag <= max {
+		d.lagTripped = false
+		d.state.Unlock()
+		return
+	}
+	if d.lagTripped {
+		d.state.Unlock()
+		return
+	}
+	d.lagTripped = true
+	frozen := d.frozen
+	d.state.Unlock()
+	if frozen {
+		return
+	}
+	d.log.Error("beacon_lag", "freezing", "lag", lag, "max_lag", max, "last_round", last, "current_round", current)
+	metrics.BeaconLagFreezes.Inc()
+	if err := d.Freeze(); err != nil {
+		d.log.Error("beacon_lag", "freeze", "err", err)
+		return
+	}
+	d.sendEvent(EventBeaconLagging, fmt.Sprintf("lag: %d, max: %d", lag, max))
+}
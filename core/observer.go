@@ -0,0 +1,338 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/chain/beacon"
+	"github.com/drand/drand/fs"
+	"github.com/drand/drand/key"
+	"github.com/drand/drand/log"
+	"github.com/drand/drand/net"
+	"github.com/drand/drand/protobuf/drand"
+	"github.com/drand/kyber/share/dkg"
+	"github.com/golang/protobuf/proto"
+	clock "github.com/jonboulle/clockwork"
+)
+
+// ErrNotParticipant is returned by an observer to the requests of the beacon
+// generation: it holds no share.
+var ErrNotParticipant = errors.New("drand: observer does not participate in the beacon generation")
+
+// DKGTranscriptFileName is the file, in the config folder, where an observer
+// appends the DKG packets it receives, one JSON object per line.
+const DKGTranscriptFileName = "dkg_transcript.log"
+
+// ObservedGroupFileName is the file, in the config folder, where an observer
+// saves the group of the chain once the nodes finish the DKG. It is kept out of
+// the group store since the observer holds no share: the node is not part of
+// the group and can still join a later DKG. The observer follows the chain
+// of that group again when it restarts.
+const ObservedGroupFileName = "observed_group.toml"
+
+// observerPollPeriod is the time an observer waits between two requests for
+// the group of the nodes, until they finish the DKG.
+var observerPollPeriod = 1 * time.Second
+
+// TranscriptEntry is a DKG packet received by an observer, as logged in the
+// transcript.
+type TranscriptEntry struct {
+	Time int64  `json:"time"`
+	From string `json:"from"`
+	// Type is deal, response or justification
+	Type string `json:"type"`
+	// Index is the index of the dealer, or of the share holder for a response
+	Index key.Index `json:"index"`
+	Hash  string    `json:"hash"`
+	// Valid is false if the signature of the packet is invalid
+	Valid bool `json:"valid"`
+	// Packet is the protobuf encoding of the drand.DKGPacket
+	Packet []byte `json:"packet"`
+}
+
+// ObserverProtocol is the protocol server of a node observing the DKG and the
+// chain of a group without taking part in them. It logs the DKG packets to a
+// transcript instead of running the DKG, refuses the partial beacons and
+// serves the beacons it stores to the syncing nodes. The other requests are
+// served by the embedded server.
+type ObserverProtocol struct {
+	drand.ProtocolServer
+	sync.Mutex
+	l          log.Logger
+	clock      clock.Clock
+	verif      verifier
+	hashes     set
+	transcript io.WriteCloser
+	// syncer and store are set once the observer follows the chain
+	syncer beacon.Syncer
+	store  chain.Store
+	cancel context.CancelFunc
+}
+
+var _ drand.ProtocolServer = (*ObserverProtocol)(nil)
+
+// NewObserverProtocol returns an observer of the DKG of the group that logs
+// the packets to the transcript, and serves the other requests with s.
+func NewObserverProtocol(s drand.ProtocolServer, l log.Logger, c clock.Clock, group *key.Group, transcript io.WriteCloser) *ObserverProtocol {
+	config := &dkg.Config{
+		Suite:    key.KeyGroup.(dkg.Suite),
+		NewNodes: group.DKGNodes(),
+		Nonce:    getNonce(group),
+		Auth:     key.DKGAuthScheme,
+	}
+	return &ObserverProtocol{
+		ProtocolServer: s,
+		l:              l,
+		clock:          c,
+		verif: func(p dkg.Packet) error {
+			return dkg.VerifyPacketSignature(config, p)
+		},
+		hashes:     new(arraySet),
+		transcript: transcript,
+	}
+}
+
+// BroadcastDKG logs the packet to the transcript the first time it is
+// received. The observer does not rebroadcast it.
+func (o *ObserverProtocol) BroadcastDKG(c context.Context, in *drand.DKGPacket) (*drand.Empty, error) {
+	dkgPacket, err := protoToDKGPacket(in.GetDkg())
+	if err != nil {
+		return nil, errors.New("invalid packet")
+	}
+	o.Lock()
+	defer o.Unlock()
+	if o.transcript == nil {
+		return nil, errors.New("drand: observer stopped")
+	}
+	h := hash(dkgPacket.Hash())
+	if o.hashes.exists(h) {
+		return new(drand.Empty), nil
+	}
+	o.hashes.put(h)
+	entry := &TranscriptEntry{
+		Time:  o.clock.Now().Unix(),
+		From:  net.RemoteAddress(c),
+		Hash:  hex.EncodeToString(h),
+		Valid: o.verif(dkgPacket) == nil,
+	}
+	switch p := dkgPacket.(type) {
+	case *dkg.DealBundle:
+		entry.Type, entry.Index = "deal", p.DealerIndex
+	case *dkg.ResponseBundle:
+		entry.Type, entry.Index = "response", p.ShareIndex
+	case *dkg.JustificationBundle:
+		entry.Type, entry.Index = "justification", p.DealerIndex
+	}
+	if entry.Packet, err = proto.Marshal(in); err != nil {
+		return nil, err
+	}
+	buff, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := o.transcript.Write(append(buff, '\n')); err != nil {
+		o.l.Error("observer", "transcript", "err", err)
+		return nil, errors.New("drand: can't log packet")
+	}
+	o.l.Debug("observer", "dkg_packet", "type", entry.Type, "index", entry.Index, "from", entry.From, "valid", entry.Valid)
+	return new(drand.Empty), nil
+}
+
+// PartialBeacon always fails with ErrNotParticipant.
+func (o *ObserverProtocol) PartialBeacon(context.Context, *drand.PartialBeaconPacket) (*drand.Empty, error) {
+	return nil, ErrNotParticipant
+}
+
+// SyncChain serves the beacons stored by the observer.
+func (o *ObserverProtocol) SyncChain(req *drand.SyncRequest, stream drand.Protocol_SyncChainServer) error {
+	o.Lock()
+	s := o.syncer
+	o.Unlock()
+	if s == nil {
+		return errors.New("drand: observer not following the chain yet")
+	}
+	return s.SyncChain(req, stream)
+}
+
+// PushSync serves the beacons stored by the observer.
+func (o *ObserverProtocol) PushSync(req *drand.SyncRequest, stream drand.Protocol_PushSyncServer) error {
+	o.Lock()
+	s := o.syncer
+	o.Unlock()
+	if s == nil {
+		return errors.New("drand: observer not following the chain yet")
+	}
+	return s.PushSync(req, stream)
+}
+
+// follow stores the beacons of the chain of the group, fetched from its
+// nodes, until the observer is stopped. The store is closed when it returns.
+func (o *ObserverProtocol) follow(ctx context.Context, store chain.Store, group *key.Group, client net.ProtocolClient) {
+	info := chain.NewChainInfo(group)
+	cbStore := beacon.NewCallbackStore(store)
+	defer cbStore.Close()
+	if err := cbStore.Put(chain.GenesisBeacon(info)); err != nil {
+		o.l.Error("observer", "genesis_beacon", "err", err)
+		return
+	}
+	syncer := beacon.NewSyncer(o.l, cbStore, info, client)
+	o.Lock()
+	o.syncer, o.store = syncer, cbStore
+	o.Unlock()
+	peers := make([]net.Peer, 0, group.Len())
+	for _, n := range group.Nodes {
+		peers = append(peers, n.Identity)
+	}
+	for {
+		err := syncer.Follow(ctx, 0, peers)
+		if ctx.Err() != nil {
+			return
+		}
+		o.l.Debug("observer", "follow", "err", err, "retry_in", group.Period)
+		select {
+		case <-o.clock.After(group.Period):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// stop stops following the chain and closes the transcript.
+func (o *ObserverProtocol) stop() {
+	o.Lock()
+	defer o.Unlock()
+	if o.cancel != nil {
+		o.cancel()
+		o.cancel = nil
+	}
+	if o.transcript != nil {
+		o.transcript.Close()
+		o.transcript = nil
+	}
+}
+
+// stopObserver stops the observer of the node, if any. The observer is stopped
+// outside of the lock since it closes its transcript.
+func (d *Drand) stopObserver() {
+	d.state.Lock()
+	obs := d.observer
+	d.observer = nil
+	d.state.Unlock()
+	if obs != nil {
+		obs.stop()
+	}
+}
+
+// observeDKG follows the DKG of the group as an observer: it logs the DKG
+// packets to the transcript until the nodes of the group finish the DKG, then
+// saves their group to ObservedGroupFileName and stores the beacons of their
+// chain. It returns the group of the chain.
+func (d *Drand) observeDKG(group *key.Group, timeout uint32) (*key.Group, error) {
+	fs.CreateSecureFolder(d.opts.ConfigFolder())
+	transcriptPath := path.Join(d.opts.ConfigFolder(), DKGTranscriptFileName)
+	f, err := os.OpenFile(transcriptPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("drand: can't open transcript: %w", err)
+	}
+	obs := NewObserverProtocol(d, d.log, d.opts.clock, group, f)
+	d.stopObserver()
+	d.state.Lock()
+	d.observer = obs
+	d.state.Unlock()
+	d.log.Info("observe_dkg", "begin", "transcript", transcriptPath)
+	d.sendEvent(EventDKGStarted, fmt.Sprintf("observer, nodes: %d", group.Len()))
+
+	final, err := d.waitObservedGroup(group, timeout)
+	if err != nil {
+		return nil, err
+	}
+	if err := key.Save(path.Join(d.opts.ConfigFolder(), ObservedGroupFileName), final, false); err != nil {
+		return nil, fmt.Errorf("drand: can't save observed group: %w", err)
+	}
+	store, err := d.createBoltStore()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	obs.Lock()
+	obs.cancel = cancel
+	obs.Unlock()
+	d.state.Lock()
+	d.group = final
+	d.state.Unlock()
+	d.log.Info("observe_dkg", "finished", "qualified", final.Len())
+	d.sendEvent(EventDKGFinished, fmt.Sprintf("observer, qualified: %d", final.Len()))
+	go obs.follow(ctx, store, final, d.privGateway.ProtocolClient)
+	return final, nil
+}
+
+// resumeObserver follows again the chain of the group saved by observeDKG, if
+// any: a node that observed a DKG holds no share, so it restarts as a fresh
+// install.
+func (d *Drand) resumeObserver() error {
+	groupPath := path.Join(d.opts.ConfigFolder(), ObservedGroupFileName)
+	if _, err := os.Stat(groupPath); os.IsNotExist(err) {
+		return nil
+	}
+	group := new(key.Group)
+	if err := key.Load(groupPath, group); err != nil {
+		return fmt.Errorf("drand: can't load observed group: %w", err)
+	}
+	store, err := d.createBoltStore()
+	if err != nil {
+		return err
+	}
+	// the DKG is over, there is no packet to log
+	obs := NewObserverProtocol(d, d.log, d.opts.clock, group, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	obs.cancel = cancel
+	d.state.Lock()
+	d.observer = obs
+	d.group = group
+	d.state.Unlock()
+	d.log.Info("observer", "resume", "group", groupPath)
+	go obs.follow(ctx, store, group, d.privGateway.ProtocolClient)
+	return nil
+}
+
+// waitObservedGroup polls the nodes of the group until one of them returns
+// the group with the distributed key it got from the DKG, or the three phases
+// of the DKG are over.
+func (d *Drand) waitObservedGroup(group *key.Group, timeout uint32) (*key.Group, error) {
+	dkgTimeout := time.Duration(timeout) * time.Second
+	if timeout == 0 {
+		dkgTimeout = DefaultDKGTimeout
+	}
+	deadline := time.After(3*dkgTimeout + MaxWaitPrepareDKG)
+	seed := group.GetGenesisSeed()
+	for {
+		for _, n := range group.Nodes {
+			ctx, cancel := context.WithTimeout(context.Background(), observerPollPeriod)
+			packet, err := d.privGateway.GroupFile(ctx, n.Identity, new(drand.GroupRequest))
+			cancel()
+			if err != nil {
+				continue
+			}
+			final, err := key.GroupFromProto(packet)
+			if err != nil || final.PublicKey == nil || !bytes.Equal(final.GetGenesisSeed(), seed) {
+				continue
+			}
+			return final, nil
+		}
+		select {
+		case <-time.After(observerPollPeriod):
+		case <-deadline:
+			return nil, errors.New("drand: the nodes did not finish the DKG in time")
+		}
+	}
+}
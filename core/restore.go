@@ -0,0 +1,62 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/drand/drand/key"
+)
+
+// RestoreCheckpoint imports into s the group, the share and the DKG state of
+// the checkpoint in dir, a copy of the config folder of the node taken while
+// it ran, which is only read. They replace the ones in s, if any; the beacons,
+// stored in the database, are left untouched. The key pair of the checkpoint
+// is imported when s has none, and must otherwise be the same. It must be
+// called once, before loading drand from s.
+func RestoreCheckpoint(s key.Store, dir string) error {
+	cp, err := key.NewReadOnlyFileStore(dir)
+	if err != nil {
+		return fmt.Errorf("drand: can't open checkpoint: %w", err)
+	}
+	group, err := cp.LoadGroup()
+	if err != nil {
+		return fmt.Errorf("drand: checkpoint without group: %w", err)
+	}
+	share, err := cp.LoadShare()
+	if err != nil {
+		return fmt.Errorf("drand: checkpoint without share: %w", err)
+	}
+	if group.PublicKey == nil || !group.PublicKey.Key().Equal(share.Public().Key()) {
+		return errors.New("drand: the share of the checkpoint is not from its group")
+	}
+	dkgState, err := cp.LoadDKGState()
+	if err != nil {
+		return fmt.Errorf("drand: invalid dkg state in checkpoint: %w", err)
+	}
+
+	pair, err := s.LoadKeyPair()
+	cpPair, cpErr := cp.LoadKeyPair()
+	switch {
+	case err != nil && cpErr != nil:
+		return fmt.Errorf("drand: no key pair in the store nor in the checkpoint: %w", err)
+	case err != nil:
+		if err := s.SaveKeyPair(cpPair); err != nil {
+			return err
+		}
+		pair = cpPair
+	case cpErr == nil && !pair.Public.Key.Equal(cpPair.Public.Key):
+		return errors.New("drand: the key pair of the checkpoint is not the one of the node")
+	}
+	if group.Find(pair.Public) == nil {
+		return errors.New("drand: the node is not in the group of the checkpoint")
+	}
+
+	if err := s.SaveGroupAndShare(group, share, true); err != nil {
+		return err
+	}
+	if dkgState == nil {
+		// a DKG in progress in s is not the one of the checkpoint
+		return s.DeleteDKGState()
+	}
+	return s.SaveDKGState(dkgState)
+}
@@ -15,7 +15,6 @@ import (
 	"github.com/drand/drand/log"
 	"github.com/drand/drand/net"
 	"github.com/drand/drand/protobuf/drand"
-	"github.com/drand/drand/test"
 	clock "github.com/jonboulle/clockwork"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/sys/unix"
@@ -60,16 +59,36 @@ type DrandTest2 struct {
 	// nodes that actually ran the resharing phase - it's a combination of nodes
 	// and new nodes. These are the one that should appear in the newGroup
 	resharedNodes []*Node
+	// new nodes join the resharing with --join-existing when set
+	joinExisting bool
+	// the nodes communicate without TLS when set
+	insecure bool
+	// genesis time set by the leader of the DKG, if not zero
+	genesisTime int64
+	// nodes observing the DKG, set before running it
+	observers []*Node
+	// number of times the nodes send again a DKG packet that failed
+	dkgRetries int
 }
 
 // NewDrandTest creates a drand test scenario with initial n nodes and ready to
 // run a DKG for the given threshold that will then launch the beacon with the
 // specified period
 func NewDrandTest2(t *testing.T, n, thr int, period time.Duration) *DrandTest2 {
+	return newDrandTest(t, n, thr, period, false)
+}
+
+// NewInsecureDrandTest is like NewDrandTest2 but the nodes communicate without
+// TLS
+func NewInsecureDrandTest(t *testing.T, n, thr int, period time.Duration) *DrandTest2 {
+	return newDrandTest(t, n, thr, period, true)
+}
+
+func newDrandTest(t *testing.T, n, thr int, period time.Duration, insecure bool, opts ...ConfigOption) *DrandTest2 {
 	dt := new(DrandTest2)
-	drands, _, dir, certPaths := BatchNewDrand(n, false,
-		WithCallOption(grpc.WaitForReady(true)),
-	)
+	opts = append([]ConfigOption{WithCallOption(grpc.WaitForReady(true))}, opts...)
+	drands, _, dir, certPaths := BatchNewDrand(n, insecure, opts...)
+	dt.insecure = insecure
 	dt.t = t
 	dt.dir = dir
 	dt.certPaths = certPaths
@@ -112,7 +131,7 @@ func (d *DrandTest2) RunDKG() *key.Group {
 	wg.Add(d.n)
 	// first run the leader and then run the other nodes
 	go func() {
-		gp, err := controlClient.InitDKGLeader(d.n, d.thr, d.period, d.catchupPeriod, testDkgTimeout, nil, secret, testBeaconOffset)
+		gp, err := controlClient.InitDKGLeader(d.n, d.thr, d.period, d.catchupPeriod, testDkgTimeout, 0, nil, secret, testBeaconOffset, d.genesisTime, false, d.dkgRetries)
 		require.NoError(d.t, err)
 		g, err := key.GroupFromProto(gp)
 		require.NoError(d.t, err)
@@ -122,13 +141,28 @@ func (d *DrandTest2) RunDKG() *key.Group {
 
 	// make sure the leader is up and running to start the setup
 	time.Sleep(1 * time.Second)
+	// the observers signal themselves before the other nodes so the leader
+	// lists them in the group
+	for _, obs := range d.observers {
+		wg.Add(1)
+		go func(n *Node) {
+			client, err := net.NewControlClient(n.drand.opts.controlPort)
+			require.NoError(d.t, err)
+			_, err = client.ObserveDKG(root.drand.priv.Public, secret)
+			require.NoError(d.t, err)
+			wg.Done()
+		}(obs)
+	}
+	if len(d.observers) > 0 {
+		time.Sleep(1 * time.Second)
+	}
 	// all other nodes will send their PK to the leader that will create the
 	// group
 	for _, node := range d.nodes[1:] {
 		go func(n *Node) {
 			client, err := net.NewControlClient(n.drand.opts.controlPort)
 			require.NoError(d.t, err)
-			gp, err := client.InitDKG(root.drand.priv.Public, nil, secret)
+			gp, err := client.InitDKG(root.drand.priv.Public, nil, secret, d.dkgRetries)
 			require.NoError(d.t, err)
 			g, err := key.GroupFromProto(gp)
 			require.NoError(d.t, err)
@@ -158,6 +192,12 @@ func (d *DrandTest2) RunDKG() *key.Group {
 }
 
 func (d *DrandTest2) Cleanup() {
+	// stopping twice is a no-op so nodes in several lists are fine
+	for _, nodes := range [][]*Node{d.nodes, d.newNodes, d.resharedNodes, d.observers} {
+		for _, node := range nodes {
+			node.drand.Stop(context.Background())
+		}
+	}
 	os.RemoveAll(d.dir)
 	os.RemoveAll(d.newDir)
 }
@@ -286,7 +326,7 @@ func (d *DrandTest2) TestPublicBeacon(id string, newGroup bool) *drand.PublicRan
 	node := d.GetDrand(id, newGroup)
 	dr := node.drand
 	client := net.NewGrpcClientFromCertManager(dr.opts.certmanager, dr.opts.grpcOpts...)
-	resp, err := client.PublicRand(context.TODO(), test.NewTLSPeer(dr.priv.Public.Addr), &drand.PublicRandRequest{})
+	resp, err := client.PublicRand(context.TODO(), net.CreatePeer(dr.priv.Public.Addr, !d.insecure), &drand.PublicRandRequest{})
 	require.NoError(d.t, err)
 	require.NotNil(d.t, resp)
 	return resp
@@ -325,7 +365,11 @@ func (d *DrandTest2) runNodeReshare(n *Node, errCh chan error, force bool) {
 	// instruct to be ready for a reshare
 	client, err := net.NewControlClient(n.drand.opts.controlPort)
 	require.NoError(d.t, err)
-	_, err = client.InitReshare(leader.drand.priv.Public, secret, d.groupPath, force)
+	if d.joinExisting && d.group.Find(n.drand.priv.Public) == nil {
+		_, err = client.JoinReshare(leader.drand.priv.Public, secret, d.groupPath, force, d.dkgRetries)
+	} else {
+		_, err = client.InitReshare(leader.drand.priv.Public, secret, d.groupPath, force, d.dkgRetries)
+	}
 	if err != nil {
 		fmt.Println("error in NON LEADER: ", err)
 		errCh <- err
@@ -334,7 +378,7 @@ func (d *DrandTest2) runNodeReshare(n *Node, errCh chan error, force bool) {
 	fmt.Printf("\n\nRESHARING TEST: non-leader drand %s DONE RESHARING - %s\n", n.drand.priv.Public.Address(), n.drand.priv.Public.Key)
 }
 
-func (d *DrandTest2) runLeaderReshare(timeout time.Duration, errCh chan error, groupCh chan *key.Group) {
+func (d *DrandTest2) runLeaderReshare(timeout time.Duration, force bool, errCh chan error, groupCh chan *key.Group) {
 	var secret = "thisistheresharing"
 	leader := d.nodes[0]
 	oldNode := d.group.Find(leader.drand.priv.Public)
@@ -344,7 +388,7 @@ func (d *DrandTest2) runLeaderReshare(timeout time.Duration, errCh chan error, g
 	// old root: oldNode.Index leater: leader.addr
 	client, err := net.NewControlClient(leader.drand.opts.controlPort)
 	require.NoError(d.t, err)
-	finalGroup, err := client.InitReshareLeader(d.newN, d.newThr, timeout, 0, secret, "", testBeaconOffset)
+	finalGroup, err := client.InitReshareLeader(d.newN, d.newThr, timeout, 0, 0, secret, "", testBeaconOffset, force, d.dkgRetries)
 	// Done resharing
 	if err != nil {
 		fmt.Println("error in LEADER: ", err)
@@ -389,7 +433,7 @@ func (d *DrandTest2) RunReshare(
 	groupCh := make(chan *key.Group, 1)
 	// first run the leader, then the other nodes will send their PK to the
 	// leader and then the leader will answer back with the new group
-	go d.runLeaderReshare(timeout, errCh, groupCh)
+	go d.runLeaderReshare(timeout, force, errCh, groupCh)
 	d.resharedNodes = append(d.resharedNodes, leader)
 	// leave some time to make sure leader is listening
 	time.Sleep(1 * time.Second)
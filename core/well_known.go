@@ -0,0 +1,49 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"path"
+	"path/filepath"
+
+	"github.com/drand/drand/net"
+)
+
+// WellKnownPrefix is the URL path under which the files of the well-known
+// directory are served, see WithWellKnownDir.
+const WellKnownPrefix = "/.well-known/"
+
+// mountWellKnown serves each regular file of dir under WellKnownPrefix, with
+// the content type of its extension. A file without a known extension, such
+// as drand-chain, is served as JSON when it holds JSON.
+func mountWellKnown(g *net.PublicGateway, dir string) error {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("drand: can't read well-known dir: %w", err)
+	}
+	for _, f := range files {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		filePath := filepath.Join(dir, f.Name())
+		contentType := mime.TypeByExtension(filepath.Ext(f.Name()))
+		if contentType == "" {
+			content, err := ioutil.ReadFile(filePath)
+			if err != nil {
+				return fmt.Errorf("drand: can't read well-known file: %w", err)
+			}
+			if json.Valid(content) {
+				contentType = "application/json"
+			} else {
+				contentType = http.DetectContentType(content)
+			}
+		}
+		if err := g.ServeFile(path.Join(WellKnownPrefix, f.Name()), filePath, contentType); err != nil {
+			return fmt.Errorf("drand: %w", err)
+		}
+	}
+	return nil
+}
@@ -38,6 +38,7 @@ type LocalNode struct {
 	ctrlClient *net.ControlClient
 	tls        bool
 	priv       *key.Pair
+	store      key.Store
 
 	log log.Logger
 
@@ -101,7 +102,11 @@ func (l *LocalNode) Start(certFolder string) error {
 		opts = append(opts, core.WithInsecure())
 	}
 	conf := core.NewConfig(opts...)
-	fs := key.NewFileStore(conf.ConfigFolder())
+	fs, err := key.NewFileStore(conf.ConfigFolder())
+	if err != nil {
+		return err
+	}
+	l.store = fs
 	fs.SaveKeyPair(l.priv)
 	key.Save(path.Join(l.base, "public.toml"), l.priv.Public, false)
 	if l.daemon == nil {
@@ -153,10 +158,10 @@ func (l *LocalNode) RunDKG(nodes, thr int, timeout string, leader bool, leaderAd
 	var grp *drand.GroupPacket
 	var err error
 	if leader {
-		grp, err = cl.InitDKGLeader(nodes, thr, p, 0, t, nil, secretDKG, beaconOffset)
+		grp, err = cl.InitDKGLeader(nodes, thr, p, 0, t, 0, nil, secretDKG, beaconOffset, 0, false, 0)
 	} else {
 		leader := net.CreatePeer(leaderAddr, l.tls)
-		grp, err = cl.InitDKG(leader, nil, secretDKG)
+		grp, err = cl.InitDKG(leader, nil, secretDKG, 0)
 	}
 	if err != nil {
 		l.log.Error("drand", "dkg run failed", "err", err)
@@ -189,10 +194,10 @@ func (l *LocalNode) RunReshare(nodes, thr int, oldGroup string, timeout string,
 	var grp *drand.GroupPacket
 	var err error
 	if leader {
-		grp, err = cl.InitReshareLeader(nodes, thr, t, 0, secretReshare, oldGroup, beaconOffset)
+		grp, err = cl.InitReshareLeader(nodes, thr, t, 0, 0, secretReshare, oldGroup, beaconOffset, false, 0)
 	} else {
 		leader := net.CreatePeer(leaderAddr, l.tls)
-		grp, err = cl.InitReshare(leader, secretReshare, oldGroup, false)
+		grp, err = cl.InitReshare(leader, secretReshare, oldGroup, false, 0)
 	}
 	if err != nil {
 		l.log.Error("drand", "reshare failed", "err", err)
@@ -267,11 +272,12 @@ func (l *LocalNode) WritePublic(p string) {
 
 func (l *LocalNode) Stop() {
 	cl := l.ctrl()
-	_, err := cl.Shutdown()
+	_, err := cl.Shutdown(0)
 	if err != nil {
 		l.log.Error("drand", "failed to shutdown", "err", err)
 	}
 	<-l.daemon.WaitExit()
+	l.store.Close()
 }
 
 func (l *LocalNode) PrintLog() {
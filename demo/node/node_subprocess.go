@@ -110,8 +110,10 @@ func (n *NodeProc) setup() {
 	runCommand(newKey)
 
 	// verify it's done
-	n.store = key.NewFileStore(n.base)
+	n.store, err = key.NewFileStore(n.base)
+	checkErr(err)
 	n.priv, err = n.store.LoadKeyPair()
+	checkErr(n.store.Close())
 	if n.priv.Public.Address() != n.privAddr {
 		panic(fmt.Errorf("[-] Private key stored has address %s vs generated %s || base %s", n.priv.Public.Address(), n.privAddr, n.base))
 	}
@@ -182,6 +184,7 @@ func (n *NodeProc) RunDKG(nodes, thr int, timeout string, leader bool, leaderAdd
 		// make genesis time offset
 		args = append(args, pair("--beacon-delay", strconv.Itoa(beaconOffset))...)
 	} else {
+		args = append(args, "--follower")
 		args = append(args, pair("--connect", leaderAddr)...)
 		if !n.tls {
 			args = append(args, "--tls-disable")
@@ -228,6 +231,7 @@ func (n *NodeProc) RunReshare(nodes, thr int, oldGroup string, timeout string, l
 		// make transition time offset
 		args = append(args, pair("--beacon-delay", strconv.Itoa(beaconOffset))...)
 	} else {
+		args = append(args, "--follower")
 		args = append(args, pair("--connect", leaderAddr)...)
 		if !n.tls {
 			args = append(args, "--tls-disable")
@@ -2,6 +2,7 @@
 package fs
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -12,6 +13,9 @@ import (
 const defaultDirectoryPermission = 0740
 const rwFilePermission = 0600
 
+// ErrLocked is returned by LockFile when the file is already locked
+var ErrLocked = errors.New("fs: file already locked")
+
 // HomeFolder returns the home folder of the current user.
 func HomeFolder() string {
 	u, err := user.Current()
@@ -103,3 +107,44 @@ func FileExists(filePath, name string) bool {
 
 	return false
 }
+
+// CopyFile copies the content of the file src to dst, with the permissions of
+// src. It is written with WriteFileAtomic so dst is never left half written.
+func CopyFile(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	content, err := ioutil.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(dst, content, info.Mode().Perm())
+}
+
+// WriteFileAtomic writes data to a temporary file next to filePath, with the
+// given permissions, and renames it to filePath, so readers see either the
+// previous content or the new one.
+func WriteFileAtomic(filePath string, data []byte, perm os.FileMode) error {
+	tmp, err := ioutil.TempFile(path.Dir(filePath), path.Base(filePath)+".tmp*")
+	if err != nil {
+		return err
+	}
+	// the file is gone once renamed
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filePath)
+}
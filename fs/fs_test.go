@@ -1,6 +1,7 @@
 package fs
 
 import (
+	"io/ioutil"
 	"os"
 	"path"
 	"testing"
@@ -47,3 +48,52 @@ func TestSecureDirAlreadyHere(t *testing.T) {
 		require.True(t, FileExists(tmpPath, f))
 	}
 }
+
+func TestCopyFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "copy")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	src := path.Join(dir, "src")
+	dst := path.Join(dir, "dst")
+	require.NoError(t, ioutil.WriteFile(src, []byte("secret"), rwFilePermission))
+	require.NoError(t, ioutil.WriteFile(dst, []byte("previous content"), rwFilePermission))
+
+	require.NoError(t, CopyFile(src, dst))
+	content, err := ioutil.ReadFile(dst)
+	require.NoError(t, err)
+	require.Equal(t, "secret", string(content))
+
+	dst2 := path.Join(dir, "dst2")
+	require.NoError(t, CopyFile(src, dst2))
+	info, err := os.Stat(dst2)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(rwFilePermission), info.Mode().Perm())
+
+	require.Error(t, CopyFile(path.Join(dir, "missing"), dst))
+	// no temporary file is left behind
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir, err := ioutil.TempDir("", "atomic")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := path.Join(dir, "file")
+
+	require.NoError(t, WriteFileAtomic(file, []byte("first"), rwFilePermission))
+	require.NoError(t, WriteFileAtomic(file, []byte("second"), 0644))
+	content, err := ioutil.ReadFile(file)
+	require.NoError(t, err)
+	require.Equal(t, "second", string(content))
+	info, err := os.Stat(file)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0644), info.Mode().Perm())
+	// no temporary file is left
+	files, err := Files(dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{file}, files)
+
+	require.Error(t, WriteFileAtomic(path.Join(dir, "missing", "file"), []byte("data"), rwFilePermission))
+}
@@ -0,0 +1,36 @@
+//go:build !windows
+// +build !windows
+
+package fs
+
+import (
+	"os"
+	"syscall"
+)
+
+// LockFile opens, or creates, the file at the given path and acquires an
+// exclusive advisory lock on it. It returns ErrLocked if another process, or
+// another open handle, already holds the lock.
+func LockFile(filePath string) (*os.File, error) {
+	fd, err := os.OpenFile(filePath, os.O_RDWR|os.O_CREATE, rwFilePermission)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(fd.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		fd.Close()
+		if err == syscall.EWOULDBLOCK {
+			return nil, ErrLocked
+		}
+		return nil, err
+	}
+	return fd, nil
+}
+
+// UnlockFile releases the lock acquired with LockFile and closes the file.
+func UnlockFile(fd *os.File) error {
+	if err := syscall.Flock(int(fd.Fd()), syscall.LOCK_UN); err != nil {
+		fd.Close()
+		return err
+	}
+	return fd.Close()
+}
@@ -0,0 +1,37 @@
+package fs
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// LockFile opens, or creates, the file at the given path and acquires an
+// exclusive lock on it. It returns ErrLocked if another process, or another
+// open handle, already holds the lock.
+func LockFile(filePath string) (*os.File, error) {
+	fd, err := os.OpenFile(filePath, os.O_RDWR|os.O_CREATE, rwFilePermission)
+	if err != nil {
+		return nil, err
+	}
+	flags := uint32(windows.LOCKFILE_EXCLUSIVE_LOCK | windows.LOCKFILE_FAIL_IMMEDIATELY)
+	ol := new(windows.Overlapped)
+	if err := windows.LockFileEx(windows.Handle(fd.Fd()), flags, 0, 1, 0, ol); err != nil {
+		fd.Close()
+		if err == windows.ERROR_LOCK_VIOLATION {
+			return nil, ErrLocked
+		}
+		return nil, err
+	}
+	return fd, nil
+}
+
+// UnlockFile releases the lock acquired with LockFile and closes the file.
+func UnlockFile(fd *os.File) error {
+	ol := new(windows.Overlapped)
+	if err := windows.UnlockFileEx(windows.Handle(fd.Fd()), 0, 1, 0, ol); err != nil {
+		fd.Close()
+		return err
+	}
+	return fd.Close()
+}
@@ -0,0 +1,120 @@
+package http
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CompressionGzip is the name of the gzip response compression, as used in
+// the Accept-Encoding and Content-Encoding headers.
+const CompressionGzip = "gzip"
+
+// ErrUnsupportedCompression is returned by NewCompressionHandler for an
+// unknown compression algorithm.
+var ErrUnsupportedCompression = errors.New("http: unsupported response compression")
+
+// NewCompressionHandler wraps h so that its responses are compressed with the
+// given algorithm when the request accepts it in its Accept-Encoding header.
+// Only gzip is supported. The compressed stream is flushed each time h
+// flushes, so streamed responses are sent as they are written.
+func NewCompressionHandler(h http.Handler, algo string) (http.Handler, error) {
+	if algo != CompressionGzip {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedCompression, algo)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if r.Method == http.MethodHead || !acceptsEncoding(r.Header.Get("Accept-Encoding"), algo) {
+			h.ServeHTTP(w, r)
+			return
+		}
+		cw := &compressWriter{ResponseWriter: w}
+		defer cw.close()
+		h.ServeHTTP(cw, r)
+	}), nil
+}
+
+// acceptsEncoding returns true if the Accept-Encoding header lists the
+// encoding, or "*", with a non-zero quality.
+func acceptsEncoding(header, encoding string) bool {
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		name := strings.TrimSpace(fields[0])
+		if name != encoding && name != "*" {
+			continue
+		}
+		accepted := true
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+			if q, err := strconv.ParseFloat(param[2:], 64); err == nil && q == 0 {
+				accepted = false
+			}
+		}
+		if accepted {
+			return true
+		}
+	}
+	return false
+}
+
+// compressWriter gzips the body of a response. The gzip stream is only
+// started on the first write of a response that can have a body, so empty
+// responses are left untouched.
+type compressWriter struct {
+	http.ResponseWriter
+	gz          *gzip.Writer
+	wroteHeader bool
+	// passthrough is set for the responses that are not compressed
+	passthrough bool
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	hdr := w.Header()
+	if code == http.StatusNoContent || code == http.StatusNotModified || hdr.Get("Content-Encoding") != "" {
+		w.passthrough = true
+	} else {
+		hdr.Set("Content-Encoding", CompressionGzip)
+		hdr.Del("Content-Length")
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.passthrough {
+		return w.ResponseWriter.Write(b)
+	}
+	if w.gz == nil {
+		w.gz = gzip.NewWriter(w.ResponseWriter)
+	}
+	return w.gz.Write(b)
+}
+
+// Flush implements http.Flusher by flushing the compressed data written so
+// far to the client.
+func (w *compressWriter) Flush() {
+	if w.gz != nil {
+		_ = w.gz.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *compressWriter) close() {
+	if w.gz != nil {
+		_ = w.gz.Close()
+	}
+}
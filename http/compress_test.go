@@ -0,0 +1,201 @@
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/stretchr/testify/require"
+
+	json "github.com/nikkolasg/hexjson"
+)
+
+func TestCompressionHandler(t *testing.T) {
+	body := bytes.Repeat([]byte(`{"round":1,"randomness":"abcdef"}`), 100)
+	handler, err := NewCompressionHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/empty" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		_, _ = w.Write(body)
+	}), CompressionGzip)
+	require.NoError(t, err)
+
+	get := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := get("/", "deflate, gzip;q=0.8")
+	require.Equal(t, CompressionGzip, rr.Header().Get("Content-Encoding"))
+	require.Equal(t, "Accept-Encoding", rr.Header().Get("Vary"))
+	require.True(t, rr.Body.Len() < len(body))
+	gz, err := gzip.NewReader(rr.Body)
+	require.NoError(t, err)
+	decompressed, err := ioutil.ReadAll(gz)
+	require.NoError(t, err)
+	require.Equal(t, body, decompressed)
+
+	// gzip not accepted
+	for _, accept := range []string{"", "deflate", "gzip;q=0"} {
+		rr = get("/", accept)
+		require.Empty(t, rr.Header().Get("Content-Encoding"), accept)
+		require.Equal(t, body, rr.Body.Bytes(), accept)
+	}
+
+	// no body to compress
+	rr = get("/empty", "gzip")
+	require.Equal(t, http.StatusNoContent, rr.Code)
+	require.Empty(t, rr.Header().Get("Content-Encoding"))
+	require.Zero(t, rr.Body.Len())
+
+	_, err = NewCompressionHandler(handler, "zstd")
+	require.True(t, errors.Is(err, ErrUnsupportedCompression))
+}
+
+// A flush of the handler sends the data compressed so far, so streamed
+// responses are not held back by the compression.
+func TestCompressionHandlerFlush(t *testing.T) {
+	flushed := make(chan bool)
+	done := make(chan bool)
+	handler, err := NewCompressionHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("first\n"))
+		w.(http.Flusher).Flush()
+		flushed <- true
+		<-done
+		_, _ = w.Write([]byte("second\n"))
+	}), CompressionGzip)
+	require.NoError(t, err)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	req, err := http.NewRequest("GET", server.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept-Encoding", CompressionGzip)
+	resp, err := (&http.Client{Transport: &http.Transport{DisableCompression: true}}).Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	<-flushed
+	gz, err := gzip.NewReader(resp.Body)
+	require.NoError(t, err)
+	line := make([]byte, len("first\n"))
+	_, err = io.ReadFull(gz, line)
+	require.NoError(t, err)
+	require.Equal(t, "first\n", string(line))
+	close(done)
+	rest, err := ioutil.ReadAll(gz)
+	require.NoError(t, err)
+	require.Equal(t, "second\n", string(rest))
+}
+
+// slowConn limits the bandwidth of the data written to a connection
+type slowConn struct {
+	net.Conn
+	bytesPerSecond int
+}
+
+func (c *slowConn) Write(b []byte) (int, error) {
+	time.Sleep(time.Duration(len(b)) * time.Second / time.Duration(c.bytesPerSecond))
+	return c.Conn.Write(b)
+}
+
+type slowListener struct {
+	net.Listener
+	bytesPerSecond int
+}
+
+func (l *slowListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &slowConn{Conn: c, bytesPerSecond: l.bytesPerSecond}, nil
+}
+
+// BenchmarkCompressionSlowLink compares the time to fetch a batch of beacons
+// in JSON, with and without compression, over a link limited to 1MB/s.
+func BenchmarkCompressionSlowLink(b *testing.B) {
+	beacons := make([]map[string]interface{}, 100)
+	for i := range beacons {
+		sig := make([]byte, 96)
+		prev := make([]byte, 96)
+		_, _ = rand.Read(sig)
+		_, _ = rand.Read(prev)
+		beacon := &chain.Beacon{Round: uint64(i + 1), Signature: sig, PreviousSig: prev}
+		beacons[i] = map[string]interface{}{
+			"round":              beacon.Round,
+			"randomness":         hex.EncodeToString(beacon.Randomness()),
+			"signature":          hex.EncodeToString(beacon.Signature),
+			"previous_signature": hex.EncodeToString(beacon.PreviousSig),
+		}
+	}
+	body, err := json.Marshal(beacons)
+	require.NoError(b, err)
+	raw := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	})
+	compressed, err := NewCompressionHandler(raw, CompressionGzip)
+	require.NoError(b, err)
+
+	for _, bc := range []struct {
+		name           string
+		acceptEncoding string
+	}{
+		{"identity", ""},
+		{"gzip", CompressionGzip},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(b, err)
+			server := &http.Server{Handler: compressed}
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_ = server.Serve(&slowListener{Listener: l, bytesPerSecond: 1 << 20})
+			}()
+			defer func() {
+				server.Close()
+				wg.Wait()
+			}()
+			client := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+			b.SetBytes(int64(len(body)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				req, _ := http.NewRequest("GET", "http://"+l.Addr().String(), nil)
+				if bc.acceptEncoding != "" {
+					req.Header.Set("Accept-Encoding", bc.acceptEncoding)
+				}
+				resp, err := client.Do(req)
+				require.NoError(b, err)
+				var r io.Reader = resp.Body
+				if resp.Header.Get("Content-Encoding") == CompressionGzip {
+					gz, err := gzip.NewReader(resp.Body)
+					require.NoError(b, err)
+					r = gz
+				}
+				n, err := io.Copy(ioutil.Discard, r)
+				require.NoError(b, err)
+				require.Equal(b, int64(len(body)), n)
+				resp.Body.Close()
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package http
 import (
 	"bytes"
 	"context"
+	"encoding/hex"
 	"fmt"
 	"math"
 	"net/http"
@@ -14,8 +15,10 @@ import (
 
 	"github.com/drand/drand/chain"
 	"github.com/drand/drand/client"
+	"github.com/drand/drand/key"
 	"github.com/drand/drand/log"
 	"github.com/drand/drand/metrics"
+	"github.com/gorilla/handlers"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	json "github.com/nikkolasg/hexjson"
@@ -29,8 +32,30 @@ const (
 var (
 	// Timeout for how long to wait for the drand.PublicClient before timing out
 	reqTimeout = 5 * time.Second
+	// Maximum number of beacons returned by /public/last/{n}
+	maxLastBeacons = 100
 )
 
+// lastNClient is implemented by the clients that can return the most recent
+// beacons at once, such as the proxy to a local drand node. The beacons are
+// fetched one by one from the other clients.
+type lastNClient interface {
+	LastN(ctx context.Context, n int) ([]client.Result, error)
+}
+
+// checkpointClient is implemented by the clients that can compute the
+// checkpoint of a round, such as the proxy to a local drand node, since it
+// needs all the beacons of the chain.
+type checkpointClient interface {
+	Checkpoint(ctx context.Context, round uint64) (*chain.Checkpoint, error)
+}
+
+// groupClient is implemented by the clients that know the group of the chain,
+// such as the proxy to a local drand node.
+type groupClient interface {
+	Group(ctx context.Context) (*key.Group, error)
+}
+
 // New creates an HTTP handler for the public Drand API
 func New(ctx context.Context, c client.Client, version string, logger log.Logger) (http.Handler, error) {
 	if logger == nil {
@@ -50,9 +75,12 @@ func New(ctx context.Context, c client.Client, version string, logger log.Logger
 	mux := http.NewServeMux()
 	//TODO: aggregated bulk round responses.
 	mux.HandleFunc("/public/latest", withCommonHeaders(version, handler.LatestRand))
+	mux.HandleFunc("/public/last/", withCommonHeaders(version, handler.LastRand))
 	mux.HandleFunc("/public/", withCommonHeaders(version, handler.PublicRand))
 	mux.HandleFunc("/info", withCommonHeaders(version, handler.ChainInfo))
+	mux.HandleFunc("/group", withCommonHeaders(version, handler.Group))
 	mux.HandleFunc("/health", withCommonHeaders(version, handler.Health))
+	mux.HandleFunc("/chain/", withCommonHeaders(version, handler.Checkpoint))
 
 	instrumented := promhttp.InstrumentHandlerCounter(
 		metrics.HTTPCallCounter,
@@ -64,11 +92,37 @@ func New(ctx context.Context, c client.Client, version string, logger log.Logger
 	return instrumented, nil
 }
 
+// NewCORSHandler wraps h so that it answers cross-origin requests coming from
+// one of the given origins. A single "*" origin allows any origin. Preflight
+// OPTIONS requests are answered directly with a 204 status.
+func NewCORSHandler(h http.Handler, origins []string) http.Handler {
+	return handlers.CORS(
+		handlers.AllowedOrigins(origins),
+		handlers.ExposedHeaders([]string{"Content-Type"}),
+		handlers.OptionStatusCode(http.StatusNoContent),
+	)(h)
+}
+
+// NewHSTSHandler wraps h so that its responses over TLS carry a
+// Strict-Transport-Security header with the given max age, telling browsers
+// to only use HTTPS with this host.
+func NewHSTSHandler(h http.Handler, maxAge time.Duration, includeSubdomains bool) http.Handler {
+	value := fmt.Sprintf("max-age=%d", int64(maxAge.Seconds()))
+	if includeSubdomains {
+		value += "; includeSubDomains"
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil {
+			w.Header().Set("Strict-Transport-Security", value)
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
 func withCommonHeaders(version string, h func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Server", version)
 		w.Header().Set("Content-Type", "application/json")
-		w.Header().Set("Access-Control-Allow-Origin", "*")
 		h(w, r)
 	}
 }
@@ -326,6 +380,110 @@ func (h *handler) LatestRand(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write(data)
 }
 
+// LastRand returns the n most recent beacons, in increasing order, for a
+// request to /public/last/{n}.
+func (h *handler) LastRand(w http.ResponseWriter, r *http.Request) {
+	n, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/public/last/"))
+	if err != nil || n < 1 || n > maxLastBeacons {
+		w.WriteHeader(http.StatusBadRequest)
+		h.log.Warn("http_server", "invalid number of beacons", "client", r.RemoteAddr, "req", url.PathEscape(r.URL.Path))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+	var results []client.Result
+	if c, ok := h.client.(lastNClient); ok {
+		results, err = c.LastN(ctx, n)
+	} else {
+		results, err = h.getLastN(ctx, n)
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		h.log.Warn("http_server", "failed to get randomness", "client", r.RemoteAddr, "req", url.PathEscape(r.URL.Path), "err", err)
+		return
+	}
+
+	data, err := json.Marshal(results)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		h.log.Warn("http_server", "failed to marshal randomness", "client", r.RemoteAddr, "req", url.PathEscape(r.URL.Path), "err", err)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-cache")
+	_, _ = w.Write(data)
+}
+
+// getLastN fetches the n most recent beacons one by one.
+func (h *handler) getLastN(ctx context.Context, n int) ([]client.Result, error) {
+	latest, err := h.client.Get(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	first := uint64(1)
+	if latest.Round() > uint64(n) {
+		first = latest.Round() - uint64(n) + 1
+	}
+	results := make([]client.Result, 0, n)
+	for round := first; round < latest.Round(); round++ {
+		res, err := h.client.Get(ctx, round)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, res)
+	}
+	return append(results, latest), nil
+}
+
+// Checkpoint returns the checkpoint of a round, for a request to
+// /chain/{chain hash}/checkpoint/{round}.
+func (h *handler) Checkpoint(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/chain/"), "/")
+	if len(parts) != 3 || parts[1] != "checkpoint" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	round, err := strconv.ParseUint(parts[2], 10, 64)
+	if err != nil || round == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		h.log.Warn("http_server", "invalid round", "client", r.RemoteAddr, "req", url.PathEscape(r.URL.Path))
+		return
+	}
+	info := h.getChainInfo(r.Context())
+	if info == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		h.log.Warn("http_server", "failed to get chain info", "client", r.RemoteAddr, "req", url.PathEscape(r.URL.Path))
+		return
+	}
+	if parts[0] != hex.EncodeToString(info.Hash()) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	c, ok := h.client.(checkpointClient)
+	if !ok {
+		w.WriteHeader(http.StatusNotImplemented)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+	cp, err := c.Checkpoint(ctx, round)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		h.log.Warn("http_server", "failed to get checkpoint", "client", r.RemoteAddr, "req", url.PathEscape(r.URL.Path), "err", err)
+		return
+	}
+	data, err := json.Marshal(cp)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		h.log.Warn("http_server", "failed to marshal checkpoint", "client", r.RemoteAddr, "req", url.PathEscape(r.URL.Path), "err", err)
+		return
+	}
+	// the checkpoint of a past round never changes
+	w.Header().Set("Cache-Control", "public, max-age=604800, immutable")
+	_, _ = w.Write(data)
+}
+
 func (h *handler) ChainInfo(w http.ResponseWriter, r *http.Request) {
 	info := h.getChainInfo(r.Context())
 	if info == nil {
@@ -348,6 +506,32 @@ func (h *handler) ChainInfo(w http.ResponseWriter, r *http.Request) {
 	http.ServeContent(w, r, "info.json", time.Unix(info.GenesisTime, 0), bytes.NewReader(chainBuff.Bytes()))
 }
 
+// Group returns the group of the chain in JSON, see key.GroupJSON.
+func (h *handler) Group(w http.ResponseWriter, r *http.Request) {
+	c, ok := h.client.(groupClient)
+	if !ok {
+		w.WriteHeader(http.StatusNotImplemented)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+	group, err := c.Group(ctx)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		h.log.Warn("http_server", "failed to get group", "client", r.RemoteAddr, "req", url.PathEscape(r.URL.Path), "err", err)
+		return
+	}
+	data, err := group.ToJSON()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		h.log.Warn("http_server", "failed to marshal group", "client", r.RemoteAddr, "req", url.PathEscape(r.URL.Path), "err", err)
+		return
+	}
+	// the group changes with a resharing
+	w.Header().Set("Cache-Control", "no-cache")
+	_, _ = w.Write(data)
+}
+
 func (h *handler) Health(w http.ResponseWriter, r *http.Request) {
 	h.startOnce.Do(h.start)
 
@@ -5,12 +5,16 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/drand/drand/chain"
 	"github.com/drand/drand/client"
 	"github.com/drand/drand/client/grpc"
+	"github.com/drand/drand/key"
 	"github.com/drand/drand/protobuf/drand"
+	"github.com/drand/drand/test"
 	"github.com/drand/drand/test/mock"
 	"github.com/stretchr/testify/require"
 
@@ -234,3 +238,247 @@ func TestHTTPHealth(t *testing.T) {
 		t.Fatalf("after start server expected to be healthy relatively quickly. %v - %v", string(buf[:]), resp.StatusCode)
 	}
 }
+
+func TestHTTPCORS(t *testing.T) {
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+	})
+
+	serve := func(h http.Handler, method, origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/public/latest", nil)
+		req.Header.Set("Origin", origin)
+		if method == http.MethodOptions {
+			req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// any origin is allowed with "*"
+	all := NewCORSHandler(inner, []string{"*"})
+	rec := serve(all, http.MethodGet, "https://example.com")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+	require.Equal(t, "Content-Type", rec.Header().Get("Access-Control-Expose-Headers"))
+
+	// only the listed origins are allowed otherwise
+	restricted := NewCORSHandler(inner, []string{"https://a.com", "https://b.com"})
+	rec = serve(restricted, http.MethodGet, "https://b.com")
+	require.Equal(t, "https://b.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	rec = serve(restricted, http.MethodGet, "https://c.com")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+
+	// preflight requests are answered without reaching the handler
+	rec = serve(restricted, http.MethodOptions, "https://a.com")
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	require.Equal(t, "https://a.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	require.Empty(t, rec.Header().Get("Content-Type"))
+}
+
+func TestHTTPHSTS(t *testing.T) {
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	serve := func(h http.Handler, overTLS bool) *httptest.ResponseRecorder {
+		target := "http://example.com/public/latest"
+		if overTLS {
+			// the request then has a TLS connection state
+			target = "https://example.com/public/latest"
+		}
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve(NewHSTSHandler(inner, 365*24*time.Hour, false), true)
+	require.Equal(t, "max-age=31536000", rec.Header().Get("Strict-Transport-Security"))
+	rec = serve(NewHSTSHandler(inner, time.Hour, true), true)
+	require.Equal(t, "max-age=3600; includeSubDomains", rec.Header().Get("Strict-Transport-Security"))
+
+	// the header is only sent over TLS
+	rec = serve(NewHSTSHandler(inner, time.Hour, true), false)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Empty(t, rec.Header().Get("Strict-Transport-Security"))
+}
+
+// roundsClient serves the rounds up to latest
+type roundsClient struct {
+	client.Client
+	latest uint64
+}
+
+func (r *roundsClient) Get(ctx context.Context, round uint64) (client.Result, error) {
+	if round == 0 {
+		round = r.latest
+	}
+	if round > r.latest {
+		return nil, fmt.Errorf("round %d not available", round)
+	}
+	return &client.RandomData{Rnd: round, Sig: []byte{byte(round)}}, nil
+}
+
+// lastNRoundsClient returns the most recent rounds at once
+type lastNRoundsClient struct {
+	*roundsClient
+	calls int
+}
+
+func (l *lastNRoundsClient) LastN(ctx context.Context, n int) ([]client.Result, error) {
+	l.calls++
+	var results []client.Result
+	for r := l.latest - uint64(n) + 1; r <= l.latest; r++ {
+		res, _ := l.Get(ctx, r)
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+func TestHTTPLastN(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	getRounds := func(h http.Handler, path string) (int, []uint64) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK {
+			return w.Code, nil
+		}
+		var results []client.RandomData
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &results))
+		var rounds []uint64
+		for _, r := range results {
+			rounds = append(rounds, r.Round())
+		}
+		return w.Code, rounds
+	}
+
+	handler, err := New(ctx, &roundsClient{latest: 10}, "", nil)
+	require.NoError(t, err)
+	code, rounds := getRounds(handler, "/public/last/3")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, []uint64{8, 9, 10}, rounds)
+
+	// more than the length of the chain
+	_, rounds = getRounds(handler, "/public/last/20")
+	require.Len(t, rounds, 10)
+	require.Equal(t, uint64(1), rounds[0])
+
+	for _, path := range []string{"/public/last/0", "/public/last/abc", fmt.Sprintf("/public/last/%d", maxLastBeacons+1)} {
+		code, _ = getRounds(handler, path)
+		require.Equal(t, http.StatusBadRequest, code, path)
+	}
+
+	lastN := &lastNRoundsClient{roundsClient: &roundsClient{latest: 10}}
+	handler, err = New(ctx, lastN, "", nil)
+	require.NoError(t, err)
+	_, rounds = getRounds(handler, "/public/last/2")
+	require.Equal(t, []uint64{9, 10}, rounds)
+	require.Equal(t, 1, lastN.calls)
+}
+
+// infoRoundsClient serves the rounds up to latest, and the chain info
+type infoRoundsClient struct {
+	*roundsClient
+	info *chain.Info
+}
+
+func (c *infoRoundsClient) Info(ctx context.Context) (*chain.Info, error) {
+	return c.info, nil
+}
+
+// checkpointRoundsClient returns a checkpoint for the rounds up to latest
+type checkpointRoundsClient struct {
+	*infoRoundsClient
+}
+
+func (c *checkpointRoundsClient) Checkpoint(ctx context.Context, round uint64) (*chain.Checkpoint, error) {
+	if round > c.latest {
+		return nil, fmt.Errorf("round %d not available", round)
+	}
+	return &chain.Checkpoint{Round: round, Signature: []byte{byte(round)}, Root: []byte("root")}, nil
+}
+
+func TestHTTPCheckpoint(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	info := &chain.Info{
+		PublicKey:   key.NewKeyPair("127.0.0.1:8080").Public.Key,
+		Period:      time.Second,
+		GenesisTime: 1,
+		GroupHash:   []byte("group hash"),
+	}
+	c := &infoRoundsClient{roundsClient: &roundsClient{latest: 10}, info: info}
+	handler, err := New(ctx, &checkpointRoundsClient{c}, "", nil)
+	require.NoError(t, err)
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	prefix := fmt.Sprintf("/chain/%x/checkpoint/", info.Hash())
+	w := get(prefix + "7")
+	require.Equal(t, http.StatusOK, w.Code)
+	cp := new(chain.Checkpoint)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), cp))
+	require.Equal(t, uint64(7), cp.Round)
+	require.Equal(t, []byte("root"), cp.Root)
+
+	require.Equal(t, http.StatusNotFound, get(prefix+"11").Code)
+	require.Equal(t, http.StatusBadRequest, get(prefix+"0").Code)
+	require.Equal(t, http.StatusBadRequest, get(prefix+"abc").Code)
+	require.Equal(t, http.StatusNotFound, get("/chain/0011/checkpoint/7").Code)
+	require.Equal(t, http.StatusNotFound, get(fmt.Sprintf("/chain/%x/other/7", info.Hash())).Code)
+
+	// the client can't compute checkpoints
+	handler, err = New(ctx, c, "", nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotImplemented, get(prefix+"7").Code)
+}
+
+// groupRoundsClient returns the group of the chain
+type groupRoundsClient struct {
+	*roundsClient
+	group *key.Group
+}
+
+func (c *groupRoundsClient) Group(ctx context.Context) (*key.Group, error) {
+	if c.group == nil {
+		return nil, fmt.Errorf("no group")
+	}
+	return c.group, nil
+}
+
+func TestHTTPGroup(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, group := test.BatchIdentities(3)
+	c := &groupRoundsClient{roundsClient: &roundsClient{latest: 10}, group: group}
+	handler, err := New(ctx, c, "", nil)
+	require.NoError(t, err)
+	get := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/group", nil))
+		return w
+	}
+
+	w := get()
+	require.Equal(t, http.StatusOK, w.Code)
+	received, err := key.GroupFromJSON(w.Body.Bytes())
+	require.NoError(t, err)
+	require.True(t, received.Equal(group))
+
+	c.group = nil
+	require.Equal(t, http.StatusServiceUnavailable, get().Code)
+
+	// the client does not know the group
+	handler, err = New(ctx, c.roundsClient, "", nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotImplemented, get().Code)
+}
@@ -0,0 +1,77 @@
+package key
+
+import "fmt"
+
+// DKGState holds the information about a DKG or a resharing in progress, so a
+// node can detect and clean up one that was interrupted by a restart.
+type DKGState struct {
+	// Target is the group the protocol is creating
+	Target *Group
+	// Old is the group being reshared, nil in case of a fresh DKG
+	Old *Group
+	// Timeout is the duration of each phase in seconds
+	Timeout uint32
+	// Retries is the number of times a DKG packet is sent again to a node
+	// that failed to receive it
+	Retries uint32
+	// Started is true once the node has started its phaser
+	Started bool
+	// Phase is the last phase of the protocol the node reached
+	Phase int
+}
+
+// DKGStateTOML is the TOML-able version of a DKG state
+type DKGStateTOML struct {
+	Target  *GroupTOML
+	Old     *GroupTOML `toml:",omitempty"`
+	Timeout uint32
+	Retries uint32 `toml:",omitempty"`
+	Started bool
+	Phase   int
+}
+
+// TOML returns a TOML-compatible version of the DKG state
+func (s *DKGState) TOML() interface{} {
+	st := &DKGStateTOML{
+		Target:  s.Target.TOML().(*GroupTOML),
+		Timeout: s.Timeout,
+		Retries: s.Retries,
+		Started: s.Started,
+		Phase:   s.Phase,
+	}
+	if s.Old != nil {
+		st.Old = s.Old.TOML().(*GroupTOML)
+	}
+	return st
+}
+
+// FromTOML decodes the DKG state from its TOML-compatible version
+func (s *DKGState) FromTOML(i interface{}) error {
+	st, ok := i.(*DKGStateTOML)
+	if !ok {
+		return fmt.Errorf("dkg state: unknown toml")
+	}
+	if st.Target == nil {
+		return fmt.Errorf("dkg state: missing target group")
+	}
+	s.Target = new(Group)
+	if err := s.Target.FromTOML(st.Target); err != nil {
+		return fmt.Errorf("dkg state: target group: %v", err)
+	}
+	if st.Old != nil {
+		s.Old = new(Group)
+		if err := s.Old.FromTOML(st.Old); err != nil {
+			return fmt.Errorf("dkg state: old group: %v", err)
+		}
+	}
+	s.Timeout = st.Timeout
+	s.Retries = st.Retries
+	s.Started = st.Started
+	s.Phase = st.Phase
+	return nil
+}
+
+// TOMLValue returns an empty TOML-compatible value of the DKG state
+func (s *DKGState) TOMLValue() interface{} {
+	return &DKGStateTOML{}
+}
@@ -0,0 +1,77 @@
+package key
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+// ErrDecryptionFailed is returned when an encrypted private key can not be
+// decrypted, either because the passphrase is wrong or because the data is
+// corrupted.
+var ErrDecryptionFailed = errors.New("key: decryption of the private key failed")
+
+// Argon2id parameters used to derive the encryption key from the passphrase,
+// as recommended by the argon2 package for interactive use.
+const (
+	encSaltSize    = 16
+	encNonceSize   = 24
+	encKeySize     = 32
+	argon2Time     = 1
+	argon2Memory   = 64 * 1024
+	argon2Threads  = 4
+	encHeaderSize  = encSaltSize + encNonceSize
+	encMinDataSize = encHeaderSize + secretbox.Overhead
+)
+
+// Encrypt serializes the private key of the pair and encrypts it with
+// nacl/secretbox, using a key derived from the passphrase with Argon2id. The
+// output contains the random salt and nonce followed by the ciphertext.
+func (p *Pair) Encrypt(passphrase string) ([]byte, error) {
+	private, err := p.Key.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, encHeaderSize)
+	if _, err := io.ReadFull(rand.Reader, header); err != nil {
+		return nil, fmt.Errorf("key: can't generate salt and nonce: %w", err)
+	}
+	var nonce [encNonceSize]byte
+	copy(nonce[:], header[encSaltSize:])
+	secret := deriveKey(passphrase, header[:encSaltSize])
+	return secretbox.Seal(header, private, &nonce, secret), nil
+}
+
+// PairFromEncrypted decrypts a private key encrypted with Pair.Encrypt. The
+// public identity of the returned pair only holds the public key. It returns
+// ErrDecryptionFailed if the passphrase is wrong or the data is corrupted.
+func PairFromEncrypted(data []byte, passphrase string) (*Pair, error) {
+	if len(data) < encMinDataSize {
+		return nil, ErrDecryptionFailed
+	}
+	var nonce [encNonceSize]byte
+	copy(nonce[:], data[encSaltSize:encHeaderSize])
+	secret := deriveKey(passphrase, data[:encSaltSize])
+	private, ok := secretbox.Open(nil, data[encHeaderSize:], &nonce, secret)
+	if !ok {
+		return nil, ErrDecryptionFailed
+	}
+	scalar := KeyGroup.Scalar()
+	if err := scalar.UnmarshalBinary(private); err != nil {
+		return nil, ErrDecryptionFailed
+	}
+	return &Pair{
+		Key:    scalar,
+		Public: &Identity{Key: KeyGroup.Point().Mul(scalar, nil)},
+	}, nil
+}
+
+func deriveKey(passphrase string, salt []byte) *[encKeySize]byte {
+	var secret [encKeySize]byte
+	copy(secret[:], argon2.IDKey([]byte(passphrase), salt, argon2Time, argon2Memory, argon2Threads, encKeySize))
+	return &secret
+}
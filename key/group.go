@@ -9,12 +9,15 @@ import (
 	"errors"
 	"fmt"
 	"hash"
+	"io"
+	"math"
 	"sort"
 	"time"
 
 	"github.com/BurntSushi/toml"
 	kyber "github.com/drand/kyber"
 	dkg "github.com/drand/kyber/share/dkg"
+	json "github.com/nikkolasg/hexjson"
 	"golang.org/x/crypto/blake2b"
 
 	proto "github.com/drand/drand/protobuf/drand"
@@ -48,8 +51,15 @@ type Group struct {
 	// The distributed public key of this group. It is nil if the group has not
 	// ran a DKG protocol yet.
 	PublicKey *DistPublic
+	// Observers follow the DKG and the chain of the group without taking part
+	// in them: they hold no share. Their index is ObserverIndex.
+	Observers []*Node
 }
 
+// ObserverIndex is the index of the observers of a group. It is never the
+// index of a node taking part in the DKG.
+const ObserverIndex Index = math.MaxUint32
+
 // Find returns the Node that is equal to the given identity (without the
 // index). If the node is not found, Find returns nil.
 func (g *Group) Find(pub *Identity) *Node {
@@ -61,6 +71,17 @@ func (g *Group) Find(pub *Identity) *Node {
 	return nil
 }
 
+// FindObserver returns the observer that is equal to the given identity, or
+// nil if it is not an observer of the group.
+func (g *Group) FindObserver(pub *Identity) *Node {
+	for _, o := range g.Observers {
+		if o.Identity.Equal(pub) {
+			return o
+		}
+	}
+	return nil
+}
+
 // Node returns the node at the given index if it exists in the group. If it does
 // not, Node() returns nil.
 func (g *Group) Node(i Index) *Node {
@@ -103,9 +124,20 @@ func (g *Group) Hash() []byte {
 	if g.PublicKey != nil {
 		_, _ = h.Write(g.PublicKey.Hash())
 	}
+	// the observers are not hashed: they take no part in the chain, so the
+	// hash does not depend on who audits the DKG
 	return h.Sum(nil)
 }
 
+// PublicKeyBytes returns the compressed binary form of the distributed public
+// key of the group, the same serialization used to hash the chain information.
+func (g *Group) PublicKeyBytes() ([]byte, error) {
+	if g.PublicKey == nil || len(g.PublicKey.Coefficients) == 0 {
+		return nil, errors.New("key: group has no distributed public key")
+	}
+	return g.PublicKey.Key().MarshalBinary()
+}
+
 // Points returns itself under the form of a list of kyber.Point
 func (g *Group) Points() []kyber.Point {
 	pts := make([]kyber.Point, g.Len())
@@ -120,6 +152,22 @@ func (g *Group) Len() int {
 	return len(g.Nodes)
 }
 
+// VerifyPublicKey reconstructs the distributed public key from the given shares
+// and checks it matches the public key of the group.
+func (g *Group) VerifyPublicKey(shares []*Share) error {
+	if g.PublicKey == nil || len(g.PublicKey.Coefficients) == 0 {
+		return errors.New("key: group has no distributed public key")
+	}
+	pub, err := ComputePublicKey(g.Threshold, shares)
+	if err != nil {
+		return err
+	}
+	if !pub.Equal(g.PublicKey.Key()) {
+		return errors.New("key: shares do not reconstruct the group public key")
+	}
+	return nil
+}
+
 func (g *Group) String() string {
 	var b bytes.Buffer
 	_ = toml.NewEncoder(&b).Encode(g.TOML())
@@ -148,6 +196,14 @@ func (g *Group) Equal(g2 *Group) bool {
 			return false
 		}
 	}
+	if len(g.Observers) != len(g2.Observers) {
+		return false
+	}
+	for i := range g.Observers {
+		if !g.Observers[i].Equal(g2.Observers[i]) {
+			return false
+		}
+	}
 	if g.PublicKey != nil {
 		if g2.PublicKey != nil {
 			// both keys aren't nil so we verify
@@ -162,6 +218,90 @@ func (g *Group) Equal(g2 *Group) bool {
 	return true
 }
 
+// ShuffleNodes returns a copy of the group whose nodes are permuted by a
+// Fisher-Yates shuffle seeded with seed, then re-indexed from 0 to n-1 in
+// their new order. The same seed always gives the same group, hence the same
+// hash. The other fields are copied as is: a distributed key does not match
+// the new indexes. The group itself is not modified.
+func (g *Group) ShuffleNodes(seed []byte) *Group {
+	nodes := make([]*Node, len(g.Nodes))
+	for i, n := range g.Nodes {
+		nodes[i] = &Node{Identity: n.Identity, Index: n.Index}
+	}
+	// the shuffle starts from the order of the indexes, not of the slice
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Index < nodes[j].Index
+	})
+	xof, _ := blake2b.NewXOF(blake2b.OutputLengthUnknown, nil)
+	_, _ = xof.Write(seed)
+	for i := len(nodes) - 1; i > 0; i-- {
+		j := uniformIndex(xof, uint64(i+1))
+		nodes[i], nodes[j] = nodes[j], nodes[i]
+	}
+	for i, n := range nodes {
+		n.Index = Index(i)
+	}
+	shuffled := *g
+	shuffled.Nodes = nodes
+	shuffled.Observers = append([]*Node(nil), g.Observers...)
+	return &shuffled
+}
+
+// uniformIndex reads from r an integer uniformly distributed in [0, n). The
+// values that would bias the modulo are rejected.
+func uniformIndex(r io.Reader, n uint64) uint64 {
+	var buff [8]byte
+	limit := math.MaxUint64 - math.MaxUint64%n
+	for {
+		_, _ = io.ReadFull(r, buff[:])
+		if v := binary.LittleEndian.Uint64(buff[:]); v < limit {
+			return v % n
+		}
+	}
+}
+
+// GroupDiff describes the changes from one group to another, typically from the
+// current group to the group of a resharing. The nodes are matched by their
+// public key, since their index and address can change between the groups.
+type GroupDiff struct {
+	// Added holds the nodes of the new group that are not in the old one
+	Added []*Node
+	// Removed holds the nodes of the old group that are not in the new one
+	Removed []*Node
+	// Unchanged holds the nodes of the new group that are also in the old one
+	Unchanged        []*Node
+	ThresholdChanged bool
+	PeriodChanged    bool
+}
+
+// Diff returns the changes from g to the other group.
+func (g *Group) Diff(other *Group) GroupDiff {
+	contains := func(nodes []*Node, n *Node) bool {
+		for _, n2 := range nodes {
+			if n2.Key.Equal(n.Key) {
+				return true
+			}
+		}
+		return false
+	}
+	var diff GroupDiff
+	for _, n := range other.Nodes {
+		if contains(g.Nodes, n) {
+			diff.Unchanged = append(diff.Unchanged, n)
+		} else {
+			diff.Added = append(diff.Added, n)
+		}
+	}
+	for _, n := range g.Nodes {
+		if !contains(other.Nodes, n) {
+			diff.Removed = append(diff.Removed, n)
+		}
+	}
+	diff.ThresholdChanged = g.Threshold != other.Threshold
+	diff.PeriodChanged = g.Period != other.Period
+	return diff
+}
+
 // GroupTOML is the representation of a Group TOML compatible
 type GroupTOML struct {
 	Threshold      int
@@ -172,6 +312,7 @@ type GroupTOML struct {
 	TransitionTime int64           `toml:",omitempty"`
 	GenesisSeed    string          `toml:",omitempty"`
 	PublicKey      *DistPublicTOML `toml:",omitempty"`
+	Observers      []*NodeTOML     `toml:",omitempty"`
 }
 
 // FromTOML decodes the group from the toml struct
@@ -189,6 +330,17 @@ func (g *Group) FromTOML(i interface{}) (err error) {
 		}
 	}
 
+	for i, otoml := range gt.Observers {
+		o := new(Node)
+		if err := o.FromTOML(otoml); err != nil {
+			return fmt.Errorf("group: unwrapping observer[%d]: %v", i, err)
+		}
+		if o.Index != ObserverIndex {
+			return fmt.Errorf("group: observer[%d] has index %d", i, o.Index)
+		}
+		g.Observers = append(g.Observers, o)
+	}
+
 	if g.Threshold < dkg.MinimumT(len(gt.Nodes)) {
 		return errors.New("group file have threshold 0")
 	} else if g.Threshold > g.Len() {
@@ -236,6 +388,9 @@ func (g *Group) TOML() interface{} {
 		gtoml.Nodes[i] = n.TOML().(*NodeTOML)
 	}
 
+	for _, o := range g.Observers {
+		gtoml.Observers = append(gtoml.Observers, o.TOML().(*NodeTOML))
+	}
 	if g.PublicKey != nil {
 		gtoml.PublicKey = g.PublicKey.TOML().(*DistPublicTOML)
 	}
@@ -264,6 +419,107 @@ func (g *Group) TOMLValue() interface{} {
 	return &GroupTOML{}
 }
 
+// GroupJSON is the JSON representation of a Group, to exchange it with
+// programs that do not read TOML, such as web pages. The binary fields are hex
+// encoded and the periods are in seconds, as in the chain info. The hash is the
+// one of the group, checked when the group is decoded.
+type GroupJSON struct {
+	Threshold      int         `json:"threshold"`
+	Period         uint32      `json:"period"`
+	CatchupPeriod  uint32      `json:"catchup_period"`
+	GenesisTime    uint64      `json:"genesis_time"`
+	TransitionTime uint64      `json:"transition_time,omitempty"`
+	GenesisSeed    []byte      `json:"genesis_seed"`
+	PublicKey      [][]byte    `json:"public_key,omitempty"`
+	Nodes          []*NodeJSON `json:"nodes"`
+	Observers      []*NodeJSON `json:"observers,omitempty"`
+	Hash           []byte      `json:"hash"`
+}
+
+// NodeJSON is the JSON representation of a Node of a group.
+type NodeJSON struct {
+	Address   string `json:"address"`
+	Key       []byte `json:"key"`
+	TLS       bool   `json:"tls"`
+	Signature []byte `json:"signature,omitempty"`
+	Index     Index  `json:"index"`
+}
+
+// ToJSON returns the JSON encoding of the group, see GroupJSON.
+func (g *Group) ToJSON() ([]byte, error) {
+	p := g.ToProto()
+	gjson := &GroupJSON{
+		Threshold:      int(p.Threshold),
+		Period:         p.Period,
+		CatchupPeriod:  p.CatchupPeriod,
+		GenesisTime:    p.GenesisTime,
+		TransitionTime: p.TransitionTime,
+		GenesisSeed:    p.GenesisSeed,
+		PublicKey:      p.DistKey,
+		Nodes:          nodesToJSON(p.Nodes),
+		Observers:      nodesToJSON(p.Observers),
+		Hash:           g.Hash(),
+	}
+	return json.Marshal(gjson)
+}
+
+// GroupFromJSON decodes a group encoded with ToJSON. It performs the same
+// checks as GroupFromProto, and checks the hash when there is one.
+func GroupFromJSON(buff []byte) (*Group, error) {
+	gjson := new(GroupJSON)
+	if err := json.Unmarshal(buff, gjson); err != nil {
+		return nil, fmt.Errorf("group: decoding json: %w", err)
+	}
+	g, err := GroupFromProto(&proto.GroupPacket{
+		Threshold:      uint32(gjson.Threshold),
+		Period:         gjson.Period,
+		CatchupPeriod:  gjson.CatchupPeriod,
+		GenesisTime:    gjson.GenesisTime,
+		TransitionTime: gjson.TransitionTime,
+		GenesisSeed:    gjson.GenesisSeed,
+		DistKey:        gjson.PublicKey,
+		Nodes:          nodesFromJSON(gjson.Nodes),
+		Observers:      nodesFromJSON(gjson.Observers),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("group: %w", err)
+	}
+	if len(gjson.Hash) > 0 && !bytes.Equal(gjson.Hash, g.Hash()) {
+		return nil, errors.New("group: hash does not match the group")
+	}
+	return g, nil
+}
+
+func nodesToJSON(nodes []*proto.Node) []*NodeJSON {
+	var out []*NodeJSON
+	for _, n := range nodes {
+		out = append(out, &NodeJSON{
+			Address:   n.GetPublic().GetAddress(),
+			Key:       n.GetPublic().GetKey(),
+			TLS:       n.GetPublic().GetTls(),
+			Signature: n.GetPublic().GetSignature(),
+			Index:     n.GetIndex(),
+		})
+	}
+	return out
+}
+
+func nodesFromJSON(nodes []*NodeJSON) []*proto.Node {
+	var out []*proto.Node
+	for _, n := range nodes {
+		out = append(out, &proto.Node{
+			Public: &proto.Identity{
+				Address:   n.Address,
+				Key:       n.Key,
+				Tls:       n.TLS,
+				Signature: n.Signature,
+			},
+			Index: n.Index,
+		})
+	}
+	return out
+}
+
 // NewGroup returns a group from the given information to be used as a new group
 // in a setup or resharing phase. Every identity is map to a Node struct whose
 // index is the position in the list of identity.
@@ -308,6 +564,26 @@ func copyAndSort(list []*Identity) []*Node {
 	return nodes
 }
 
+// MinPeriod is the shortest period of a chain: each round, the nodes must have
+// the time to exchange their partial beacons over the network.
+const MinPeriod = 1 * time.Second
+
+// MaxPeriod is the longest period of a chain. A longer period is most likely a
+// misconfiguration.
+const MaxPeriod = 1 * time.Hour
+
+// ValidatePeriod returns an error if the period of a new chain is outside of
+// [MinPeriod, MaxPeriod].
+func ValidatePeriod(period time.Duration) error {
+	if period < MinPeriod {
+		return fmt.Errorf("key: period %s shorter than the minimum of %s: the nodes could not exchange their partial beacons in time", period, MinPeriod)
+	}
+	if period > MaxPeriod {
+		return fmt.Errorf("key: period %s longer than the maximum of %s", period, MaxPeriod)
+	}
+	return nil
+}
+
 // MinimumT calculates the threshold needed for the group to produce sufficient shares to decode
 func MinimumT(n int) int {
 	return (n >> 1) + 1
@@ -323,6 +599,17 @@ func GroupFromProto(g *proto.GroupPacket) (*Group, error) {
 		}
 		nodes = append(nodes, kid)
 	}
+	var observers []*Node
+	for _, id := range g.GetObservers() {
+		o, err := NodeFromProto(id)
+		if err != nil {
+			return nil, err
+		}
+		if o.Index != ObserverIndex {
+			return nil, fmt.Errorf("observer with index %d", o.Index)
+		}
+		observers = append(observers, o)
+	}
 	n := len(nodes)
 	thr := int(g.GetThreshold())
 	if thr < MinimumT(n) {
@@ -352,6 +639,7 @@ func GroupFromProto(g *proto.GroupPacket) (*Group, error) {
 		Nodes:          nodes,
 		GenesisTime:    genesisTime,
 		TransitionTime: int64(g.GetTransitionTime()),
+		Observers:      observers,
 	}
 	if g.GetGenesisSeed() != nil {
 		group.GenesisSeed = g.GetGenesisSeed()
@@ -368,20 +656,10 @@ func GroupFromProto(g *proto.GroupPacket) (*Group, error) {
 // ToProto encodes a local group object into its wire format
 func (g *Group) ToProto() *proto.GroupPacket {
 	var out = new(proto.GroupPacket)
-	var ids = make([]*proto.Node, len(g.Nodes))
-	for i, id := range g.Nodes {
-		key, _ := id.Key.MarshalBinary()
-		ids[i] = &proto.Node{
-			Public: &proto.Identity{
-				Address:   id.Address(),
-				Tls:       id.IsTLS(),
-				Key:       key,
-				Signature: id.Signature,
-			},
-			Index: id.Index,
-		}
+	out.Nodes = nodesToProto(g.Nodes)
+	if len(g.Observers) > 0 {
+		out.Observers = nodesToProto(g.Observers)
 	}
-	out.Nodes = ids
 	out.Period = uint32(g.Period.Seconds())
 	out.CatchupPeriod = uint32(g.CatchupPeriod.Seconds())
 	out.Threshold = uint32(g.Threshold)
@@ -399,6 +677,23 @@ func (g *Group) ToProto() *proto.GroupPacket {
 	return out
 }
 
+func nodesToProto(nodes []*Node) []*proto.Node {
+	var ids = make([]*proto.Node, len(nodes))
+	for i, id := range nodes {
+		key, _ := id.Key.MarshalBinary()
+		ids[i] = &proto.Node{
+			Public: &proto.Identity{
+				Address:   id.Address(),
+				Tls:       id.IsTLS(),
+				Key:       key,
+				Signature: id.Signature,
+			},
+			Index: id.Index,
+		}
+	}
+	return ids
+}
+
 // UnsignedIdentities return true if all identities in the group are signed
 // correctly or not. This method is here because of backward compatibility where
 // identities were not self-signed before.
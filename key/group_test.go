@@ -1,9 +1,14 @@
 package key
 
 import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"testing"
+	"testing/quick"
 	"time"
 
 	"github.com/drand/drand/protobuf/drand"
@@ -170,3 +175,179 @@ func TestConvertGroup(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, received.Equal(group))
 }
+
+func TestGroupJSON(t *testing.T) {
+	group := makeGroup(t)
+	group.Nodes = newIds(3)
+	group.Threshold = 2
+	group.PublicKey.Coefficients = append(group.PublicKey.Coefficients, KeyGroup.Point().Pick(random.New()))
+	group.Period = 5 * time.Second
+	group.CatchupPeriod = 2 * time.Second
+	group.GenesisTime = time.Now().Unix()
+	group.TransitionTime = group.GenesisTime + 60
+	group.Observers = []*Node{{Index: ObserverIndex, Identity: NewKeyPair("127.0.0.1:4000").Public}}
+
+	buff, err := group.ToJSON()
+	require.NoError(t, err)
+	received, err := GroupFromJSON(buff)
+	require.NoError(t, err)
+	require.True(t, received.Equal(group))
+	require.Equal(t, group.Hash(), received.Hash())
+	require.Equal(t, group.GenesisTime, received.GenesisTime)
+	require.Equal(t, group.CatchupPeriod, received.CatchupPeriod)
+	again, err := received.ToJSON()
+	require.NoError(t, err)
+	require.Equal(t, buff, again)
+
+	// the byte fields are hex encoded
+	var raw map[string]interface{}
+	require.NoError(t, json.Unmarshal(buff, &raw))
+	require.Equal(t, hex.EncodeToString(group.Hash()), raw["hash"])
+	require.Equal(t, float64(5), raw["period"])
+
+	// a group that does not match its hash is rejected
+	raw["genesis_time"] = float64(group.GenesisTime + 1)
+	tampered, err := json.Marshal(raw)
+	require.NoError(t, err)
+	_, err = GroupFromJSON(tampered)
+	require.Error(t, err)
+
+	_, err = GroupFromJSON([]byte("{"))
+	require.Error(t, err)
+}
+
+func TestGroupObservers(t *testing.T) {
+	group := makeGroup(t)
+	group.Nodes = newIds(3)
+	group.Threshold = 2
+	group.PublicKey = nil
+	group.GenesisTime = time.Now().Unix()
+	hash := group.Hash()
+	observer := &Node{Index: ObserverIndex, Identity: NewKeyPair("127.0.0.1:4000").Public}
+	group.Observers = []*Node{observer}
+	require.Equal(t, hash, group.Hash())
+	require.NotNil(t, group.FindObserver(observer.Identity))
+	require.Nil(t, group.Find(observer.Identity))
+
+	received, err := GroupFromProto(group.ToProto())
+	require.NoError(t, err)
+	require.True(t, received.Equal(group))
+	require.Equal(t, group.Hash(), received.Hash())
+
+	loaded := new(Group)
+	require.NoError(t, loaded.FromTOML(group.TOML()))
+	require.True(t, loaded.Equal(group))
+
+	// an observer must have the observer index
+	packet := group.ToProto()
+	packet.Observers[0].Index = 3
+	_, err = GroupFromProto(packet)
+	require.Error(t, err)
+}
+
+func TestGroupShuffleNodes(t *testing.T) {
+	ids := make([]*Identity, 10)
+	for i := range ids {
+		ids[i] = NewKeyPair(fmt.Sprintf("127.0.0.1:%d", 3000+i)).Public
+	}
+	group := NewGroup(ids, 6, time.Now().Unix(), 30*time.Second, 10*time.Second)
+	hash := group.Hash()
+	indexes := make(map[string]Index)
+	for _, n := range group.Nodes {
+		indexes[n.Addr] = n.Index
+	}
+
+	shuffled := func(seed []byte) bool {
+		s1 := group.ShuffleNodes(seed)
+		// the shuffle is stable and does not modify the original group
+		if !bytes.Equal(s1.Hash(), group.ShuffleNodes(seed).Hash()) || !bytes.Equal(hash, group.Hash()) {
+			return false
+		}
+		for _, n := range group.Nodes {
+			if indexes[n.Addr] != n.Index {
+				return false
+			}
+		}
+		// the nodes are the same with the indexes 0..n-1
+		if s1.Len() != group.Len() || s1.Threshold != group.Threshold {
+			return false
+		}
+		seen := make(map[Index]bool)
+		for _, n := range s1.Nodes {
+			if group.Find(n.Identity) == nil || seen[n.Index] || int(n.Index) >= s1.Len() {
+				return false
+			}
+			seen[n.Index] = true
+		}
+		// the shuffle does not depend on the order of the nodes in the slice
+		reversed := *group
+		reversed.Nodes = make([]*Node, group.Len())
+		for i, n := range group.Nodes {
+			reversed.Nodes[group.Len()-1-i] = n
+		}
+		return bytes.Equal(s1.Hash(), reversed.ShuffleNodes(seed).Hash())
+	}
+	require.NoError(t, quick.Check(shuffled, nil))
+
+	// different seeds give different orders
+	require.NotEqual(t, group.ShuffleNodes([]byte("a")).Hash(), group.ShuffleNodes([]byte("b")).Hash())
+}
+
+func TestGroupPublicKeyBytes(t *testing.T) {
+	group := makeGroup(t)
+	buff, err := group.PublicKeyBytes()
+	require.NoError(t, err)
+	require.Len(t, buff, KeyGroup.PointLen())
+
+	pub := KeyGroup.Point()
+	require.NoError(t, pub.UnmarshalBinary(buff))
+	require.True(t, pub.Equal(group.PublicKey.Key()))
+
+	group.PublicKey = nil
+	_, err = group.PublicKeyBytes()
+	require.Error(t, err)
+}
+
+func TestGroupDiff(t *testing.T) {
+	ids := newIds(5)
+	oldGroup := &Group{Nodes: ids[:4], Threshold: 3, Period: 30 * time.Second}
+
+	// same nodes and parameters
+	diff := oldGroup.Diff(oldGroup)
+	require.Empty(t, diff.Added)
+	require.Empty(t, diff.Removed)
+	require.Len(t, diff.Unchanged, 4)
+	require.False(t, diff.ThresholdChanged)
+	require.False(t, diff.PeriodChanged)
+
+	// node 0 leaves, node 4 joins and the nodes are reindexed
+	var nodes []*Node
+	for i, n := range ids[1:] {
+		nodes = append(nodes, &Node{Identity: n.Identity, Index: uint32(i)})
+	}
+	newGroup := &Group{Nodes: nodes, Threshold: 3, Period: 10 * time.Second}
+	diff = oldGroup.Diff(newGroup)
+	require.Len(t, diff.Added, 1)
+	require.True(t, diff.Added[0].Key.Equal(ids[4].Key))
+	require.Len(t, diff.Removed, 1)
+	require.True(t, diff.Removed[0].Equal(ids[0]))
+	require.Len(t, diff.Unchanged, 3)
+	for _, n := range diff.Unchanged {
+		require.NotNil(t, newGroup.Find(n.Identity))
+		require.NotNil(t, oldGroup.Find(n.Identity))
+	}
+	require.False(t, diff.ThresholdChanged)
+	require.True(t, diff.PeriodChanged)
+
+	newGroup.Threshold = 4
+	require.True(t, oldGroup.Diff(newGroup).ThresholdChanged)
+}
+
+func TestValidatePeriod(t *testing.T) {
+	for _, period := range []time.Duration{0, time.Millisecond, 999 * time.Millisecond, MaxPeriod + time.Second, 24 * time.Hour} {
+		require.Error(t, ValidatePeriod(period), "period %s", period)
+	}
+	for _, period := range []time.Duration{MinPeriod, 30 * time.Second, MaxPeriod} {
+		require.NoError(t, ValidatePeriod(period), "period %s", period)
+	}
+}
@@ -2,6 +2,8 @@ package key
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -43,7 +45,16 @@ func (i *Identity) IsTLS() bool {
 }
 
 func (i *Identity) String() string {
-	return fmt.Sprintf("{%s - %s}", i.Address(), i.Key.String())
+	return fmt.Sprintf("{%s - %s}", i.Address(), i.Fingerprint())
+}
+
+// Fingerprint returns a short identifier of the public key, the hex encoding
+// of the first 8 bytes of the SHA256 of the compressed key. It is meant for
+// logs, where the full key is too long to be read.
+func (i *Identity) Fingerprint() string {
+	buff, _ := i.Key.MarshalBinary()
+	h := sha256.Sum256(buff)
+	return hex.EncodeToString(h[:8])
 }
 
 // Hash returns the hash of the public key without signing the signature. The hash
@@ -76,6 +87,55 @@ func (i *Identity) Equal(i2 *Identity) bool {
 	return true
 }
 
+// homeMessage returns the message a node signs to authenticate its Home
+// response: SHA256("home" || address || timestamp).
+func homeMessage(addr string, timestamp int64) []byte {
+	h := sha256.New()
+	_, _ = h.Write([]byte("home"))
+	_, _ = h.Write([]byte(addr))
+	_ = binary.Write(h, binary.LittleEndian, timestamp)
+	return h.Sum(nil)
+}
+
+// SignHome signs the Home response of the node created at the given unix
+// timestamp.
+func (p *Pair) SignHome(timestamp int64) ([]byte, error) {
+	return AuthScheme.Sign(p.Key, homeMessage(p.Public.Address(), timestamp))
+}
+
+// VerifyHome verifies that the signature of a Home response created at the
+// given unix timestamp was made by this identity.
+func (i *Identity) VerifyHome(timestamp int64, signature []byte) error {
+	return AuthScheme.Verify(i.Key, homeMessage(i.Address(), timestamp), signature)
+}
+
+// controlMessage returns the message an operator signs to authenticate a
+// control request:
+// SHA256("control" || method || SHA256(body) || SHA256(nonce) || timestamp).
+func controlMessage(method string, body, nonce []byte, timestamp int64) []byte {
+	bodyHash := sha256.Sum256(body)
+	nonceHash := sha256.Sum256(nonce)
+	h := sha256.New()
+	_, _ = h.Write([]byte("control"))
+	_, _ = h.Write([]byte(method))
+	_, _ = h.Write(bodyHash[:])
+	_, _ = h.Write(nonceHash[:])
+	_ = binary.Write(h, binary.LittleEndian, timestamp)
+	return h.Sum(nil)
+}
+
+// SignControl signs the control request to the given method with the given
+// serialized body and nonce, made at the given unix timestamp.
+func (p *Pair) SignControl(method string, body, nonce []byte, timestamp int64) ([]byte, error) {
+	return AuthScheme.Sign(p.Key, controlMessage(method, body, nonce, timestamp))
+}
+
+// VerifyControl verifies that the signature of a control request was made by
+// this identity.
+func (i *Identity) VerifyControl(method string, body, nonce []byte, timestamp int64, signature []byte) error {
+	return AuthScheme.Verify(i.Key, controlMessage(method, body, nonce, timestamp), signature)
+}
+
 // SelfSign signs the public key with the key pair
 func (p *Pair) SelfSign() {
 	msg := p.Public.Hash()
@@ -291,6 +351,33 @@ func (s *Share) TOMLValue() interface{} {
 	return &ShareTOML{}
 }
 
+// ComputePublicKey reconstructs the distributed public key from the given
+// shares using Lagrange interpolation on their public counterparts. At least
+// threshold shares with distinct indexes are needed.
+func ComputePublicKey(threshold int, shares []*Share) (kyber.Point, error) {
+	if threshold < 1 {
+		return nil, fmt.Errorf("key: invalid threshold %d", threshold)
+	}
+	pubShares := make([]*share.PubShare, 0, len(shares))
+	for _, s := range shares {
+		if s == nil || s.Share == nil {
+			continue
+		}
+		pubShares = append(pubShares, &share.PubShare{
+			I: s.Share.I,
+			V: KeyGroup.Point().Mul(s.Share.V, nil),
+		})
+	}
+	if len(pubShares) < threshold {
+		return nil, fmt.Errorf("key: not enough shares to compute the public key: got %d, need %d", len(pubShares), threshold)
+	}
+	pub, err := share.RecoverCommit(KeyGroup, pubShares, threshold, len(pubShares))
+	if err != nil {
+		return nil, fmt.Errorf("key: %w", err)
+	}
+	return pub, nil
+}
+
 // ShareTOML is the TOML representation of a dkg.DistKeyShare
 type ShareTOML struct {
 	// index of the share.
@@ -37,6 +37,21 @@ func TestKeyPublic(t *testing.T) {
 	require.Equal(t, kp.Public.Key.String(), p2.Key.String())
 }
 
+func TestKeyFingerprint(t *testing.T) {
+	kp := NewTLSKeyPair(testAddr)
+	fp := kp.Public.Fingerprint()
+	require.Len(t, fp, 16)
+	_, err := hex.DecodeString(fp)
+	require.NoError(t, err)
+	// stable across the address and encoding of the key
+	p2 := &Identity{Key: kp.Public.Key.Clone(), Addr: "127.0.0.1:81"}
+	require.Equal(t, fp, p2.Fingerprint())
+	require.Contains(t, kp.Public.String(), fp)
+	// distinct for distinct keys
+	kp2 := NewTLSKeyPair(testAddr)
+	require.NotEqual(t, fp, kp2.Public.Fingerprint())
+}
+
 func TestKeySignature(t *testing.T) {
 	kp := NewTLSKeyPair(testAddr)
 	validSig := kp.Public.Signature
@@ -64,6 +79,65 @@ func TestKeySignature(t *testing.T) {
 	require.Error(t, decodedID.ValidSignature())
 }
 
+func TestKeyHomeSignature(t *testing.T) {
+	kp := NewKeyPair(testAddr)
+	ts := int64(1600000000)
+	sig, err := kp.SignHome(ts)
+	require.NoError(t, err)
+	require.NoError(t, kp.Public.VerifyHome(ts, sig))
+
+	// another timestamp or another address must be rejected
+	require.Error(t, kp.Public.VerifyHome(ts+1, sig))
+	other := *kp.Public
+	other.Addr = "127.0.0.1:81"
+	require.Error(t, other.VerifyHome(ts, sig))
+
+	// as well as a tampered signature
+	tampered := append([]byte{}, sig...)
+	tampered[0] ^= 0xff
+	require.Error(t, kp.Public.VerifyHome(ts, tampered))
+}
+
+func TestKeyControlSignature(t *testing.T) {
+	kp := NewKeyPair(testAddr)
+	ts := int64(1600000000)
+	body := []byte("request")
+	nonce := []byte("nonce")
+	sig, err := kp.SignControl("Shutdown", body, nonce, ts)
+	require.NoError(t, err)
+	require.NoError(t, kp.Public.VerifyControl("Shutdown", body, nonce, ts, sig))
+
+	// the signature only holds for the same method, body, nonce and timestamp
+	require.Error(t, kp.Public.VerifyControl("Status", body, nonce, ts, sig))
+	require.Error(t, kp.Public.VerifyControl("Shutdown", []byte("other"), nonce, ts, sig))
+	require.Error(t, kp.Public.VerifyControl("Shutdown", body, []byte("other"), ts, sig))
+	require.Error(t, kp.Public.VerifyControl("Shutdown", body, nonce, ts+1, sig))
+	require.Error(t, NewKeyPair(testAddr).Public.VerifyControl("Shutdown", body, nonce, ts, sig))
+}
+
+func TestKeyEncrypt(t *testing.T) {
+	pair := NewKeyPair(testAddr)
+	data, err := pair.Encrypt("passphrase")
+	require.NoError(t, err)
+
+	decrypted, err := PairFromEncrypted(data, "passphrase")
+	require.NoError(t, err)
+	require.True(t, pair.Key.Equal(decrypted.Key))
+	require.True(t, pair.Public.Key.Equal(decrypted.Public.Key))
+
+	// the salt and nonce are random
+	data2, err := pair.Encrypt("passphrase")
+	require.NoError(t, err)
+	require.NotEqual(t, data, data2)
+
+	_, err = PairFromEncrypted(data, "wrong passphrase")
+	require.Equal(t, ErrDecryptionFailed, err)
+	_, err = PairFromEncrypted(data[:len(data)-1], "passphrase")
+	require.Equal(t, ErrDecryptionFailed, err)
+	_, err = PairFromEncrypted(data[:10], "passphrase")
+	require.Equal(t, ErrDecryptionFailed, err)
+}
+
 func TestKeyDistributedPublic(t *testing.T) {
 	n := 4
 	publics := make([]kyber.Point, n)
@@ -130,6 +204,34 @@ func TestShare(t *testing.T) {
 	}
 }
 
+func TestComputePublicKey(t *testing.T) {
+	n, thr := 5, 3
+	priPoly := share.NewPriPoly(KeyGroup, thr, nil, random.New())
+	pubPoly := priPoly.Commit(KeyGroup.Point().Base())
+	_, commits := pubPoly.Info()
+	shares := make([]*Share, n)
+	for i, s := range priPoly.Shares(n) {
+		shares[i] = &Share{Commits: commits, Share: s}
+	}
+
+	pub, err := ComputePublicKey(thr, shares[1:1+thr])
+	require.NoError(t, err)
+	require.True(t, pub.Equal(commits[0]))
+
+	_, err = ComputePublicKey(thr, shares[:thr-1])
+	require.Error(t, err)
+
+	_, group := BatchIdentities(n)
+	group.Threshold = thr
+	group.PublicKey = &DistPublic{commits}
+	require.NoError(t, group.VerifyPublicKey(shares))
+
+	// a share from another polynomial breaks the interpolation
+	other := share.NewPriPoly(KeyGroup, thr, nil, random.New()).Shares(n)
+	shares[0] = &Share{Commits: commits, Share: other[0]}
+	require.Error(t, group.VerifyPublicKey(shares[:thr]))
+}
+
 func BatchIdentities(n int) ([]*Pair, *Group) {
 	startPort := 8000
 	startAddr := "127.0.0.1:"
@@ -1,7 +1,9 @@
 package key
 
 import (
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path"
 	"reflect"
@@ -22,11 +24,35 @@ type Store interface {
 	LoadKeyPair() (*Pair, error)
 	SaveShare(share *Share) error
 	LoadShare() (*Share, error)
-	SaveGroup(*Group) error
+	// SaveGroup saves the group of the node. If a different group is already
+	// saved, it returns ErrGroupExists unless overwrite is set, in which case
+	// the previous group is backed up before being replaced. Saving the same
+	// group again is a no-op.
+	SaveGroup(g *Group, overwrite bool) error
+	// SaveGroupAndShare saves the group and the share resulting from a DKG:
+	// either both replace the saved ones, or none does. overwrite is as for
+	// SaveGroup.
+	SaveGroupAndShare(g *Group, s *Share, overwrite bool) error
 	LoadGroup() (*Group, error)
+	// SaveDKGState saves the state of an in-progress DKG or resharing
+	SaveDKGState(*DKGState) error
+	// LoadDKGState returns the saved DKG state, or nil if there is none
+	LoadDKGState() (*DKGState, error)
+	// DeleteDKGState removes the saved DKG state, if any
+	DeleteDKGState() error
 	Reset(...ResetOption) error
+	// Close releases any resource held by the store
+	Close() error
 }
 
+// ErrStoreLocked is returned by NewFileStore when the folder is already used
+// by another store, most likely another drand daemon.
+var ErrStoreLocked = errors.New("key: store folder is locked by another process")
+
+// ErrGroupExists is returned by SaveGroup when a different group is already
+// saved and must not be overwritten.
+var ErrGroupExists = errors.New("key: a different group is already saved")
+
 // KeyFolderName is the name of the folder where drand keeps its keys
 const KeyFolderName = "key"
 
@@ -35,9 +61,14 @@ const GroupFolderName = "groups"
 const keyFileName = "drand_id"
 const privateExtension = ".private"
 const publicExtension = ".public"
+const encryptedExtension = ".enc"
 const groupFileName = "drand_group.toml"
+const backupExtension = ".bak"
+const tmpExtension = ".tmp"
 const shareFileName = "dist_key.private"
 const distKeyFileName = "dist_key.public"
+const dkgStateFileName = "dkg_state.toml"
+const lockFileName = ".lock"
 
 // Tomler represents any struct that can be (un)marshaled into/from toml format
 // XXX surely golang reflect package can automatically return the TOMLValue()
@@ -56,31 +87,118 @@ type fileStore struct {
 	shareFile      string
 	distKeyFile    string
 	groupFile      string
+	dkgStateFile   string
+	lock           *os.File
+	// passphrase encrypting the private key, if any
+	passphrase string
+}
+
+// FileStoreOption is an option to configure the store created by NewFileStore
+type FileStoreOption func(*fileStore)
+
+// WithEncryptedKey makes the store keep the private key encrypted with the
+// given passphrase, see Pair.Encrypt. The encrypted key is kept in its own
+// file, next to the plaintext one which is then neither read nor written.
+func WithEncryptedKey(passphrase string) FileStoreOption {
+	return func(f *fileStore) {
+		f.passphrase = passphrase
+	}
 }
 
 // NewFileStore is used to create the config folder and all the subfolders.
-// If a folder alredy exists, we simply check the rights
-func NewFileStore(baseFolder string) Store {
+// If a folder alredy exists, we simply check the rights. The folder is locked
+// until the store is closed; it returns ErrStoreLocked if another store
+// already holds the lock.
+func NewFileStore(baseFolder string, opts ...FileStoreOption) (Store, error) {
 	// config folder
 	if fs.CreateSecureFolder(baseFolder) == "" {
 		fmt.Println("Something went wrong with the config folder. Make sure that you have the appropriate rights.")
 		os.Exit(1)
 	}
+	lock, err := fs.LockFile(path.Join(baseFolder, lockFileName))
+	if errors.Is(err, fs.ErrLocked) {
+		return nil, ErrStoreLocked
+	} else if err != nil {
+		return nil, fmt.Errorf("key: can't lock store folder: %w", err)
+	}
+	fs.CreateSecureFolder(path.Join(baseFolder, KeyFolderName))
+	fs.CreateSecureFolder(path.Join(baseFolder, GroupFolderName))
+	store := newFileStore(baseFolder, opts...)
+	store.lock = lock
+	return store, nil
+}
+
+// newFileStore returns a store using the files of baseFolder, without
+// creating nor locking anything.
+func newFileStore(baseFolder string, opts ...FileStoreOption) *fileStore {
 	store := &fileStore{baseFolder: baseFolder}
-	keyFolder := fs.CreateSecureFolder(path.Join(baseFolder, KeyFolderName))
-	groupFolder := fs.CreateSecureFolder(path.Join(baseFolder, GroupFolderName))
+	keyFolder := path.Join(baseFolder, KeyFolderName)
+	groupFolder := path.Join(baseFolder, GroupFolderName)
 	store.privateKeyFile = path.Join(keyFolder, keyFileName) + privateExtension
 	store.publicKeyFile = path.Join(keyFolder, keyFileName) + publicExtension
 	store.groupFile = path.Join(groupFolder, groupFileName)
 	store.shareFile = path.Join(groupFolder, shareFileName)
 	store.distKeyFile = path.Join(groupFolder, distKeyFileName)
+	store.dkgStateFile = path.Join(groupFolder, dkgStateFileName)
+	for _, opt := range opts {
+		opt(store)
+	}
+	if store.passphrase != "" {
+		store.privateKeyFile += encryptedExtension
+	}
 	return store
 }
 
+// ErrReadOnlyStore is returned by the methods writing to a store opened with
+// NewReadOnlyFileStore.
+var ErrReadOnlyStore = errors.New("key: store is read-only")
+
+// NewReadOnlyFileStore returns a store reading the files of the config folder
+// baseFolder, e.g. a copy of it, without locking it nor creating anything in
+// it. Its methods writing to the folder return ErrReadOnlyStore.
+func NewReadOnlyFileStore(baseFolder string, opts ...FileStoreOption) (Store, error) {
+	info, err := os.Stat(baseFolder)
+	if err != nil {
+		return nil, fmt.Errorf("key: can't open store folder: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("key: store folder %s is not a directory", baseFolder)
+	}
+	return &readOnlyStore{newFileStore(baseFolder, opts...)}, nil
+}
+
+type readOnlyStore struct {
+	*fileStore
+}
+
+func (r *readOnlyStore) SaveKeyPair(*Pair) error                      { return ErrReadOnlyStore }
+func (r *readOnlyStore) SaveShare(*Share) error                       { return ErrReadOnlyStore }
+func (r *readOnlyStore) SaveGroup(*Group, bool) error                 { return ErrReadOnlyStore }
+func (r *readOnlyStore) SaveGroupAndShare(*Group, *Share, bool) error { return ErrReadOnlyStore }
+func (r *readOnlyStore) SaveDKGState(*DKGState) error                 { return ErrReadOnlyStore }
+func (r *readOnlyStore) DeleteDKGState() error                        { return ErrReadOnlyStore }
+func (r *readOnlyStore) Reset(...ResetOption) error                   { return ErrReadOnlyStore }
+
+// CheckUnlocked returns ErrStoreLocked if a store is open on the given folder,
+// by this process or another one.
+func CheckUnlocked(baseFolder string) error {
+	lock, err := fs.LockFile(path.Join(baseFolder, lockFileName))
+	if errors.Is(err, fs.ErrLocked) {
+		return ErrStoreLocked
+	} else if err != nil {
+		return fmt.Errorf("key: can't lock store folder: %w", err)
+	}
+	return fs.UnlockFile(lock)
+}
+
 // SaveKeyPair first saves the private key in a file with tight permissions and then
 // saves the public part in another file.
 func (f *fileStore) SaveKeyPair(p *Pair) error {
-	if err := Save(f.privateKeyFile, p, true); err != nil {
+	if f.passphrase != "" {
+		if err := f.saveEncryptedKey(p); err != nil {
+			return err
+		}
+	} else if err := Save(f.privateKeyFile, p, true); err != nil {
 		return err
 	}
 	fmt.Printf("Saved the key : %s at %s\n", p.Public.Addr, f.publicKeyFile)
@@ -89,6 +207,17 @@ func (f *fileStore) SaveKeyPair(p *Pair) error {
 
 // LoadKeyPair decode private key first then public
 func (f *fileStore) LoadKeyPair() (*Pair, error) {
+	if f.passphrase != "" {
+		data, err := ioutil.ReadFile(f.privateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		p, err := PairFromEncrypted(data, f.passphrase)
+		if err != nil {
+			return nil, err
+		}
+		return p, Load(f.publicKeyFile, p.Public)
+	}
 	p := new(Pair)
 	if err := Load(f.privateKeyFile, p); err != nil {
 		return nil, err
@@ -96,15 +225,102 @@ func (f *fileStore) LoadKeyPair() (*Pair, error) {
 	return p, Load(f.publicKeyFile, p.Public)
 }
 
+func (f *fileStore) saveEncryptedKey(p *Pair) error {
+	data, err := p.Encrypt(f.passphrase)
+	if err != nil {
+		return err
+	}
+	fd, err := fs.CreateSecureFile(f.privateKeyFile)
+	if err != nil {
+		return fmt.Errorf("key: can't save encrypted key to %s: %s", f.privateKeyFile, err)
+	}
+	defer fd.Close()
+	_, err = fd.Write(data)
+	return err
+}
+
 func (f *fileStore) LoadGroup() (*Group, error) {
 	g := new(Group)
 	return g, Load(f.groupFile, g)
 }
 
-func (f *fileStore) SaveGroup(g *Group) error {
+func (f *fileStore) SaveGroup(g *Group, overwrite bool) error {
+	same, _, err := f.backupGroup(g, overwrite)
+	if err != nil || same {
+		return err
+	}
 	return Save(f.groupFile, g, false)
 }
 
+// backupGroup checks g can replace the saved group and backs the latter up.
+// It returns whether g is the saved group, in which case there is nothing to
+// replace, and whether a backup was made.
+func (f *fileStore) backupGroup(g *Group, overwrite bool) (same, backup bool, err error) {
+	exists, err := fs.Exists(f.groupFile)
+	if err != nil || !exists {
+		return false, false, err
+	}
+	current, err := f.LoadGroup()
+	if err == nil && current.Equal(g) {
+		return true, false, nil
+	}
+	if !overwrite {
+		return false, false, ErrGroupExists
+	}
+	if err := fs.CopyFile(f.groupFile, f.groupFile+backupExtension); err != nil {
+		return false, false, fmt.Errorf("key: can't backup group file: %w", err)
+	}
+	return false, true, nil
+}
+
+// SaveGroupAndShare writes the group and the share to temporary files and
+// reads them back, so that a failure does not touch the saved ones. The files
+// then replace the saved ones by a rename each. If the share can't be
+// renamed, the previous group is restored.
+func (f *fileStore) SaveGroupAndShare(g *Group, s *Share, overwrite bool) error {
+	same, backup, err := f.backupGroup(g, overwrite)
+	if err != nil {
+		return err
+	}
+	groupTmp, shareTmp := f.groupFile+tmpExtension, f.shareFile+tmpExtension
+	defer os.Remove(groupTmp)
+	defer os.Remove(shareTmp)
+	if err := Save(groupTmp, g, false); err != nil {
+		return err
+	}
+	if err := Save(shareTmp, s, true); err != nil {
+		return err
+	}
+	if err := Load(groupTmp, new(Group)); err != nil {
+		return fmt.Errorf("key: can't read back the group: %w", err)
+	}
+	if err := Load(shareTmp, new(Share)); err != nil {
+		return fmt.Errorf("key: can't read back the share: %w", err)
+	}
+	if !same {
+		if err := os.Rename(groupTmp, f.groupFile); err != nil {
+			return fmt.Errorf("key: can't save group: %w", err)
+		}
+	}
+	fmt.Printf("crypto store: saving private share in %s\n", f.shareFile)
+	if err := os.Rename(shareTmp, f.shareFile); err != nil {
+		if same {
+			return fmt.Errorf("key: can't save share: %w", err)
+		}
+		var rerr error
+		if backup {
+			rerr = fs.CopyFile(f.groupFile+backupExtension, f.groupFile)
+		} else {
+			rerr = os.Remove(f.groupFile)
+		}
+		if rerr != nil {
+			return fmt.Errorf("key: can't save share: %w, and can't restore the previous group: %v", err, rerr)
+		}
+		return fmt.Errorf("key: can't save share, previous group restored: %w", err)
+	}
+	return nil
+}
+
 func (f *fileStore) SaveShare(share *Share) error {
 	fmt.Printf("crypto store: saving private share in %s\n", f.shareFile)
 	return Save(f.shareFile, share, true)
@@ -115,6 +331,22 @@ func (f *fileStore) LoadShare() (*Share, error) {
 	return s, Load(f.shareFile, s)
 }
 
+func (f *fileStore) SaveDKGState(s *DKGState) error {
+	return Save(f.dkgStateFile, s, true)
+}
+
+func (f *fileStore) LoadDKGState() (*DKGState, error) {
+	if exists, err := fs.Exists(f.dkgStateFile); err != nil || !exists {
+		return nil, err
+	}
+	s := new(DKGState)
+	return s, Load(f.dkgStateFile, s)
+}
+
+func (f *fileStore) DeleteDKGState() error {
+	return Delete(f.dkgStateFile)
+}
+
 func (f *fileStore) Reset(...ResetOption) error {
 	if err := Delete(f.distKeyFile); err != nil {
 		return fmt.Errorf("drand: err deleting dist. key file: %v", err)
@@ -126,9 +358,60 @@ func (f *fileStore) Reset(...ResetOption) error {
 	if err := Delete(f.groupFile); err != nil {
 		return fmt.Errorf("drand: err deleting group file: %v", err)
 	}
+
+	if err := Delete(f.dkgStateFile); err != nil {
+		return fmt.Errorf("drand: err deleting dkg state file: %v", err)
+	}
 	return nil
 }
 
+// Close releases the lock on the store folder
+func (f *fileStore) Close() error {
+	if f.lock == nil {
+		return nil
+	}
+	err := fs.UnlockFile(f.lock)
+	f.lock = nil
+	return err
+}
+
+// NewKeyFileStore returns a store reading the private key of the node from the
+// file at path, in the format of the private key file of a file store, for
+// keys kept out of the config folder. The public identity of the pair keeps
+// the address and TLS setting of the one saved in s, with the key and the
+// self signature of the read private key. Everything else is kept in s.
+func NewKeyFileStore(s Store, path string) Store {
+	return &keyFileStore{Store: s, path: path}
+}
+
+type keyFileStore struct {
+	Store
+	path string
+}
+
+func (k *keyFileStore) LoadKeyPair() (*Pair, error) {
+	p := new(Pair)
+	if err := Load(k.path, p); err != nil {
+		return nil, fmt.Errorf("key: can't load key file: %w", err)
+	}
+	stored, err := k.Store.LoadKeyPair()
+	if err != nil {
+		return nil, fmt.Errorf("key: can't load the identity of the node: %w", err)
+	}
+	p.Public = &Identity{
+		Key:  KeyGroup.Point().Mul(p.Key, nil),
+		Addr: stored.Public.Addr,
+		TLS:  stored.Public.TLS,
+	}
+	p.SelfSign()
+	return p, nil
+}
+
+// SaveKeyPair fails: the key file is never written by drand.
+func (k *keyFileStore) SaveKeyPair(*Pair) error {
+	return fmt.Errorf("key: the key pair is read from %s", k.path)
+}
+
 // Save the given Tomler interface to the given path. If secure is true, the
 // file will have a 0700 security.
 // TODO: move that to fs/
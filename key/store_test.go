@@ -1,6 +1,7 @@
 package key
 
 import (
+	"io/ioutil"
 	"os"
 	"path"
 	"testing"
@@ -17,7 +18,10 @@ func TestKeysSaveLoad(t *testing.T) {
 	tmp = path.Join(tmp, "drand-key")
 	os.RemoveAll(tmp)
 	defer os.RemoveAll(tmp)
-	store := NewFileStore(tmp).(*fileStore)
+	s, err := NewFileStore(tmp)
+	require.NoError(t, err)
+	defer s.Close()
+	store := s.(*fileStore)
 	require.Equal(t, tmp, store.baseFolder)
 
 	// test loading saving private public key
@@ -36,7 +40,7 @@ func TestKeysSaveLoad(t *testing.T) {
 	require.Nil(t, err)
 
 	// test group
-	require.Nil(t, store.SaveGroup(group))
+	require.Nil(t, store.SaveGroup(group, false))
 	loadedGroup, err := store.LoadGroup()
 	require.NoError(t, err)
 	require.Equal(t, group.Threshold, loadedGroup.Threshold)
@@ -65,3 +69,239 @@ func TestKeysSaveLoad(t *testing.T) {
 	require.Equal(t, testShare.Share.V, loadedShare.Share.V)
 	require.Equal(t, testShare.Share.I, loadedShare.Share.I)
 }
+
+func TestKeysStoreLocked(t *testing.T) {
+	tmp := path.Join(os.TempDir(), "drand-key-lock")
+	os.RemoveAll(tmp)
+	defer os.RemoveAll(tmp)
+
+	store, err := NewFileStore(tmp)
+	require.NoError(t, err)
+	_, err = NewFileStore(tmp)
+	require.Equal(t, ErrStoreLocked, err)
+
+	// once released, the folder can be used again
+	require.NoError(t, store.Close())
+	store, err = NewFileStore(tmp)
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+}
+
+func TestKeysReadOnlyStore(t *testing.T) {
+	tmp := path.Join(os.TempDir(), "drand-key-readonly")
+	os.RemoveAll(tmp)
+	defer os.RemoveAll(tmp)
+	_, err := NewReadOnlyFileStore(tmp)
+	require.Error(t, err)
+
+	// nothing is created in the folder
+	require.NoError(t, os.Mkdir(tmp, 0700))
+	ro, err := NewReadOnlyFileStore(tmp)
+	require.NoError(t, err)
+	_, err = ro.LoadKeyPair()
+	require.Error(t, err)
+	require.Equal(t, ErrReadOnlyStore, ro.SaveKeyPair(NewKeyPair("127.0.0.1:8080")))
+	require.NoError(t, ro.Close())
+	files, err := ioutil.ReadDir(tmp)
+	require.NoError(t, err)
+	require.Empty(t, files)
+
+	// the folder can be read while a store holds it
+	pair := NewKeyPair("127.0.0.1:8080")
+	store, err := NewFileStore(tmp)
+	require.NoError(t, err)
+	defer store.Close()
+	require.NoError(t, store.SaveKeyPair(pair))
+	ro, err = NewReadOnlyFileStore(tmp)
+	require.NoError(t, err)
+	loaded, err := ro.LoadKeyPair()
+	require.NoError(t, err)
+	require.True(t, pair.Public.Key.Equal(loaded.Public.Key))
+	require.NoError(t, ro.Close())
+}
+
+func TestKeysEncryptedStore(t *testing.T) {
+	tmp := path.Join(os.TempDir(), "drand-key-enc")
+	os.RemoveAll(tmp)
+	defer os.RemoveAll(tmp)
+
+	pair := NewKeyPair("127.0.0.1:8080")
+	store, err := NewFileStore(tmp, WithEncryptedKey("correct horse"))
+	require.NoError(t, err)
+	require.NoError(t, store.SaveKeyPair(pair))
+	loaded, err := store.LoadKeyPair()
+	require.NoError(t, err)
+	require.Equal(t, pair.Key.String(), loaded.Key.String())
+	require.True(t, pair.Public.Equal(loaded.Public))
+	require.NoError(t, store.Close())
+
+	// the private key is not written in plaintext
+	_, err = os.Stat(path.Join(tmp, KeyFolderName, keyFileName+privateExtension))
+	require.True(t, os.IsNotExist(err))
+
+	store, err = NewFileStore(tmp, WithEncryptedKey("wrong horse"))
+	require.NoError(t, err)
+	defer store.Close()
+	_, err = store.LoadKeyPair()
+	require.Equal(t, ErrDecryptionFailed, err)
+}
+
+func TestKeysDKGState(t *testing.T) {
+	tmp := path.Join(os.TempDir(), "drand-key-dkg")
+	os.RemoveAll(tmp)
+	defer os.RemoveAll(tmp)
+	store, err := NewFileStore(tmp)
+	require.NoError(t, err)
+	defer store.Close()
+
+	state, err := store.LoadDKGState()
+	require.NoError(t, err)
+	require.Nil(t, state)
+
+	_, oldGroup := BatchIdentities(4)
+	_, newGroup := BatchIdentities(5)
+	newGroup.PublicKey = nil
+	require.NoError(t, store.SaveDKGState(&DKGState{
+		Target:  newGroup,
+		Old:     oldGroup,
+		Timeout: 10,
+		Started: true,
+		Phase:   2,
+	}))
+	state, err = store.LoadDKGState()
+	require.NoError(t, err)
+	require.Equal(t, newGroup.Hash(), state.Target.Hash())
+	require.Equal(t, oldGroup.Hash(), state.Old.Hash())
+	require.Equal(t, uint32(10), state.Timeout)
+	require.True(t, state.Started)
+	require.Equal(t, 2, state.Phase)
+
+	require.NoError(t, store.DeleteDKGState())
+	state, err = store.LoadDKGState()
+	require.NoError(t, err)
+	require.Nil(t, state)
+}
+
+func TestKeysSaveGroupOverwrite(t *testing.T) {
+	tmp := path.Join(os.TempDir(), "drand-key-group")
+	os.RemoveAll(tmp)
+	defer os.RemoveAll(tmp)
+	s, err := NewFileStore(tmp)
+	require.NoError(t, err)
+	defer s.Close()
+	store := s.(*fileStore)
+
+	_, group := BatchIdentities(4)
+	_, other := BatchIdentities(4)
+
+	// no group saved yet
+	require.NoError(t, store.SaveGroup(group, false))
+	// saving the same group again is a no-op
+	require.NoError(t, store.SaveGroup(group, false))
+	_, err = os.Stat(store.groupFile + backupExtension)
+	require.True(t, os.IsNotExist(err))
+
+	// a different group is refused without overwrite
+	require.Equal(t, ErrGroupExists, store.SaveGroup(other, false))
+	loaded, err := store.LoadGroup()
+	require.NoError(t, err)
+	require.True(t, group.Equal(loaded))
+
+	// and replaces the saved one with overwrite, after a backup
+	require.NoError(t, store.SaveGroup(other, true))
+	loaded, err = store.LoadGroup()
+	require.NoError(t, err)
+	require.True(t, other.Equal(loaded))
+	backup := new(Group)
+	require.NoError(t, Load(store.groupFile+backupExtension, backup))
+	require.True(t, group.Equal(backup))
+}
+
+func TestKeysSaveGroupAndShare(t *testing.T) {
+	tmp := path.Join(os.TempDir(), "drand-key-group-share")
+	os.RemoveAll(tmp)
+	defer os.RemoveAll(tmp)
+	s, err := NewFileStore(tmp)
+	require.NoError(t, err)
+	defer s.Close()
+	store := s.(*fileStore)
+
+	_, group := BatchIdentities(4)
+	_, other := BatchIdentities(4)
+	newShare := func(v int64) *Share {
+		return &Share{Share: &share.PriShare{I: 0, V: KeyGroup.Scalar().SetInt64(v)}}
+	}
+
+	require.NoError(t, store.SaveGroupAndShare(group, newShare(1), false))
+	loaded, err := store.LoadGroup()
+	require.NoError(t, err)
+	require.True(t, group.Equal(loaded))
+	sh, err := store.LoadShare()
+	require.NoError(t, err)
+	require.True(t, sh.Share.V.Equal(KeyGroup.Scalar().SetInt64(1)))
+
+	// a different group is refused without overwrite, and the share is kept
+	require.Equal(t, ErrGroupExists, store.SaveGroupAndShare(other, newShare(2), false))
+	sh, err = store.LoadShare()
+	require.NoError(t, err)
+	require.True(t, sh.Share.V.Equal(KeyGroup.Scalar().SetInt64(1)))
+
+	// the share can't replace the saved one: the previous group is restored
+	require.NoError(t, os.Remove(store.shareFile))
+	require.NoError(t, os.MkdirAll(path.Join(store.shareFile, "busy"), 0700))
+	err = store.SaveGroupAndShare(other, newShare(2), true)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "previous group restored")
+	loaded, err = store.LoadGroup()
+	require.NoError(t, err)
+	require.True(t, group.Equal(loaded))
+	require.NoError(t, os.RemoveAll(store.shareFile))
+
+	require.NoError(t, store.SaveGroupAndShare(other, newShare(2), true))
+	loaded, err = store.LoadGroup()
+	require.NoError(t, err)
+	require.True(t, other.Equal(loaded))
+	sh, err = store.LoadShare()
+	require.NoError(t, err)
+	require.True(t, sh.Share.V.Equal(KeyGroup.Scalar().SetInt64(2)))
+	for _, f := range []string{store.groupFile, store.shareFile} {
+		_, err = os.Stat(f + tmpExtension)
+		require.True(t, os.IsNotExist(err))
+	}
+}
+
+func TestKeysKeyFileStore(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "drand-key-file")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+	fs, err := NewFileStore(path.Join(tmp, "node"))
+	require.NoError(t, err)
+	defer fs.Close()
+	stored := NewTLSKeyPair("127.0.0.1:8080")
+	require.NoError(t, fs.SaveKeyPair(stored))
+
+	// the key of the file only holds the private key
+	other := NewKeyPair("127.0.0.1:9090")
+	keyFile := path.Join(tmp, "hsm.private")
+	require.NoError(t, Save(keyFile, other, true))
+
+	s := NewKeyFileStore(fs, keyFile)
+	pair, err := s.LoadKeyPair()
+	require.NoError(t, err)
+	require.True(t, pair.Key.Equal(other.Key))
+	require.True(t, pair.Public.Key.Equal(other.Public.Key))
+	require.Equal(t, stored.Public.Address(), pair.Public.Address())
+	require.True(t, pair.Public.IsTLS())
+	require.NoError(t, pair.Public.ValidSignature())
+	require.Error(t, s.SaveKeyPair(other))
+
+	// the rest is kept in the file store
+	_, group := BatchIdentities(3)
+	require.NoError(t, s.SaveGroup(group, false))
+	loaded, err := fs.LoadGroup()
+	require.NoError(t, err)
+	require.True(t, loaded.Equal(group))
+
+	_, err = NewKeyFileStore(fs, path.Join(tmp, "missing")).LoadKeyPair()
+	require.Error(t, err)
+}
@@ -4,6 +4,7 @@ import (
 	"io"
 	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-kit/kit/log"
@@ -82,6 +83,55 @@ func NewLogger(l log.Logger, level int) Logger {
 	return NewKitLogger(l, opt)
 }
 
+// LevelVar holds a logging level that can be changed while the loggers using
+// it are running.
+type LevelVar struct {
+	level int32
+}
+
+// NewLevelVar returns a LevelVar set to the given level.
+func NewLevelVar(level int) *LevelVar {
+	v := new(LevelVar)
+	v.Set(level)
+	return v
+}
+
+// Set changes the level. It panics if the level is unknown, as NewLogger does.
+func (v *LevelVar) Set(level int) {
+	if level < LogNone || level > LogDebug {
+		panic("unknown log level")
+	}
+	atomic.StoreInt32(&v.level, int32(level))
+}
+
+// Level returns the current level.
+func (v *LevelVar) Level() int {
+	return int(atomic.LoadInt32(&v.level))
+}
+
+// levelFilter forwards statements to the filter of the current level.
+type levelFilter struct {
+	level   *LevelVar
+	filters [LogDebug + 1]log.Logger
+}
+
+func (f *levelFilter) Log(kv ...interface{}) error {
+	return f.filters[f.level.Level()].Log(kv...)
+}
+
+// NewLoggerWithLevel returns a logger like NewLogger but that prints statements
+// at the level currently held by the given LevelVar.
+func NewLoggerWithLevel(l log.Logger, level *LevelVar) Logger {
+	if l == nil {
+		l = LoggerTo(os.Stdout)
+	}
+	f := &levelFilter{level: level}
+	f.filters[LogNone] = lvl.NewFilter(l, lvl.AllowNone())
+	f.filters[LogInfo] = lvl.NewFilter(l, lvl.AllowInfo())
+	f.filters[LogDebug] = lvl.NewFilter(l, lvl.AllowDebug())
+	return NewKitLogger(f)
+}
+
 // NewKitLoggerFrom returns a Logger out of a go-kit/kit/log logger interface. The
 // caller can set the options that it needs to the logger first.
 // The underlying logger should already be synchronized.
@@ -85,3 +85,24 @@ func requireContains(t *testing.T, r io.Reader, outs []string, present bool) {
 		require.Contains(t, string(out), o)
 	}
 }
+
+func TestLoggerWithLevel(t *testing.T) {
+	var b bytes.Buffer
+	level := NewLevelVar(LogInfo)
+	logger := NewLoggerWithLevel(log.NewLogfmtLogger(&b), level)
+
+	logger.Debug("msg", "hidden")
+	requireContains(t, &b, nil, false)
+	logger.Info("msg", "shown")
+	requireContains(t, &b, []string{"shown"}, true)
+
+	level.Set(LogDebug)
+	logger.With("yard", "bird").Debug("msg", "debug")
+	requireContains(t, &b, []string{"yard", "bird", "debug"}, true)
+
+	level.Set(LogNone)
+	logger.Info("msg", "hidden")
+	requireContains(t, &b, nil, false)
+
+	require.Panics(t, func() { level.Set(LogDebug + 1) })
+}
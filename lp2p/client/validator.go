@@ -33,7 +33,7 @@ func randomnessValidator(info *chain.Info, cache client.Cache, c *Client) pubsub
 		}
 
 		// Unwilling to relay beacons in the future.
-		if time.Unix(chain.TimeOfRound(info.Period, info.GenesisTime, b.Round), 0).After(time.Now()) {
+		if b.Time(info.Period, info.GenesisTime).After(time.Now()) {
 			return pubsub.ValidationReject
 		}
 
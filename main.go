@@ -1,16 +1,22 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/drand/drand/cmd/drand-cli"
+	"github.com/urfave/cli/v2"
 )
 
 func main() {
 	app := drand.CLI()
 	if err := app.Run(os.Args); err != nil {
 		fmt.Printf("%+v\n", err)
+		var exit cli.ExitCoder
+		if errors.As(err, &exit) {
+			os.Exit(exit.ExitCode())
+		}
 		os.Exit(1)
 	}
 }
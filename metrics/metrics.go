@@ -57,6 +57,18 @@ var (
 		Name: "last_beacon_round",
 		Help: "Last locally stored beacon",
 	})
+	// BeaconLag (Group) is the number of rounds the last stored beacon is
+	// behind the current round of the chain.
+	BeaconLag = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "beacon_lag",
+		Help: "Number of rounds the last stored beacon is behind the current round",
+	})
+	// BeaconLagFreezes (Group) counts the times the node froze because it
+	// lagged too far behind the chain.
+	BeaconLagFreezes = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "beacon_lag_freezes",
+		Help: "Number of times the beacon generation froze because of the lag",
+	})
 
 	// HTTPCallCounter (HTTP) how many http requests
 	HTTPCallCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
@@ -175,6 +187,8 @@ func bindMetrics() error {
 		GroupThreshold,
 		BeaconDiscrepancyLatency,
 		LastBeaconRound,
+		BeaconLag,
+		BeaconLagFreezes,
 	}
 	for _, c := range group {
 		if err := GroupMetrics.Register(c); err != nil {
@@ -0,0 +1,296 @@
+package net
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/drand/drand/log"
+	"github.com/drand/drand/protobuf/drand"
+)
+
+// AuditEntry is a call to the node as logged in the audit log, one JSON
+// object per line.
+type AuditEntry struct {
+	// Time is the unix time of the end of the call, in nanoseconds
+	Time   int64  `json:"time"`
+	Method string `json:"method"`
+	Remote string `json:"remote"`
+	// RequestHash is the hex encoded SHA256 hash of the protobuf encoding of
+	// the request
+	RequestHash string `json:"request_hash"`
+	// Status is the gRPC status code of the response, OK on success
+	Status string `json:"status"`
+}
+
+// WithAuditLog returns a middleware appending an AuditEntry to w for each
+// call to the methods of the Protocol and Control services, once it returned.
+// The calls to the Public service, ChainInfo included, are not logged. A call
+// that panics is logged with the Internal status before the panic goes on. A
+// failure to write the entry is logged to l, the call is not failed.
+func WithAuditLog(w io.Writer, l log.Logger) func(Service) Service {
+	return func(s Service) Service {
+		return &auditService{Service: s, w: w, l: l}
+	}
+}
+
+type auditService struct {
+	Service
+	sync.Mutex
+	w io.Writer
+	l log.Logger
+}
+
+// audit logs the call once it returned, it is deferred by the audited methods
+// with the error they return.
+func (a *auditService) audit(c context.Context, method string, in proto.Message, callErr *error) {
+	code := status.Code(*callErr)
+	r := recover()
+	if r != nil {
+		code = codes.Internal
+		defer panic(r)
+	}
+	entry := &AuditEntry{
+		Method: method,
+		Remote: RemoteAddress(c),
+		Status: code.String(),
+	}
+	if body, err := proto.Marshal(in); err == nil {
+		h := sha256.Sum256(body)
+		entry.RequestHash = hex.EncodeToString(h[:])
+	}
+	entry.Time = time.Now().UnixNano()
+	buff, err := json.Marshal(entry)
+	if err != nil {
+		a.l.Error("audit", "marshal", "method", method, "err", err)
+		return
+	}
+	a.Lock()
+	defer a.Unlock()
+	if _, err := a.w.Write(append(buff, '\n')); err != nil {
+		a.l.Error("audit", "write", "method", method, "err", err)
+	}
+}
+
+func (a *auditService) GetIdentity(c context.Context, in *drand.IdentityRequest) (_ *drand.Identity, err error) {
+	defer a.audit(c, "GetIdentity", in, &err)
+	return a.Service.GetIdentity(c, in)
+}
+
+func (a *auditService) SignalDKGParticipant(c context.Context, in *drand.SignalDKGPacket) (_ *drand.Empty, err error) {
+	defer a.audit(c, "SignalDKGParticipant", in, &err)
+	return a.Service.SignalDKGParticipant(c, in)
+}
+
+func (a *auditService) AnnounceJoin(c context.Context, in *drand.SignalDKGPacket) (_ *drand.Empty, err error) {
+	defer a.audit(c, "AnnounceJoin", in, &err)
+	return a.Service.AnnounceJoin(c, in)
+}
+
+func (a *auditService) PushDKGInfo(c context.Context, in *drand.DKGInfoPacket) (_ *drand.Empty, err error) {
+	defer a.audit(c, "PushDKGInfo", in, &err)
+	return a.Service.PushDKGInfo(c, in)
+}
+
+func (a *auditService) BroadcastDKG(c context.Context, in *drand.DKGPacket) (_ *drand.Empty, err error) {
+	defer a.audit(c, "BroadcastDKG", in, &err)
+	return a.Service.BroadcastDKG(c, in)
+}
+
+func (a *auditService) PartialBeacon(c context.Context, in *drand.PartialBeaconPacket) (_ *drand.Empty, err error) {
+	defer a.audit(c, "PartialBeacon", in, &err)
+	return a.Service.PartialBeacon(c, in)
+}
+
+func (a *auditService) GroupFile(c context.Context, in *drand.GroupRequest) (_ *drand.GroupPacket, err error) {
+	defer a.audit(c, "GroupFile", in, &err)
+	return a.Service.GroupFile(c, in)
+}
+
+func (a *auditService) SyncChain(in *drand.SyncRequest, stream drand.Protocol_SyncChainServer) (err error) {
+	defer a.audit(stream.Context(), "SyncChain", in, &err)
+	return a.Service.SyncChain(in, stream)
+}
+
+func (a *auditService) PushSync(in *drand.SyncRequest, stream drand.Protocol_PushSyncServer) (err error) {
+	defer a.audit(stream.Context(), "PushSync", in, &err)
+	return a.Service.PushSync(in, stream)
+}
+
+func (a *auditService) PingPong(c context.Context, in *drand.Ping) (_ *drand.Pong, err error) {
+	defer a.audit(c, "PingPong", in, &err)
+	return a.Service.PingPong(c, in)
+}
+
+func (a *auditService) InitDKG(c context.Context, in *drand.InitDKGPacket) (_ *drand.GroupPacket, err error) {
+	defer a.audit(c, "InitDKG", in, &err)
+	return a.Service.InitDKG(c, in)
+}
+
+func (a *auditService) InitReshare(c context.Context, in *drand.InitResharePacket) (_ *drand.GroupPacket, err error) {
+	defer a.audit(c, "InitReshare", in, &err)
+	return a.Service.InitReshare(c, in)
+}
+
+func (a *auditService) Share(c context.Context, in *drand.ShareRequest) (_ *drand.ShareResponse, err error) {
+	defer a.audit(c, "Share", in, &err)
+	return a.Service.Share(c, in)
+}
+
+func (a *auditService) PublicKey(c context.Context, in *drand.PublicKeyRequest) (_ *drand.PublicKeyResponse, err error) {
+	defer a.audit(c, "PublicKey", in, &err)
+	return a.Service.PublicKey(c, in)
+}
+
+func (a *auditService) PrivateKey(c context.Context, in *drand.PrivateKeyRequest) (_ *drand.PrivateKeyResponse, err error) {
+	defer a.audit(c, "PrivateKey", in, &err)
+	return a.Service.PrivateKey(c, in)
+}
+
+func (a *auditService) Shutdown(c context.Context, in *drand.ShutdownRequest) (_ *drand.ShutdownResponse, err error) {
+	defer a.audit(c, "Shutdown", in, &err)
+	return a.Service.Shutdown(c, in)
+}
+
+func (a *auditService) BackupDatabase(c context.Context, in *drand.BackupDBRequest) (_ *drand.BackupDBResponse, err error) {
+	defer a.audit(c, "BackupDatabase", in, &err)
+	return a.Service.BackupDatabase(c, in)
+}
+
+func (a *auditService) Status(c context.Context, in *drand.StatusRequest) (_ *drand.StatusResponse, err error) {
+	defer a.audit(c, "Status", in, &err)
+	return a.Service.Status(c, in)
+}
+
+func (a *auditService) ListProtocols(c context.Context, in *drand.ListProtocolsRequest) (_ *drand.ListProtocolsResponse, err error) {
+	defer a.audit(c, "ListProtocols", in, &err)
+	return a.Service.ListProtocols(c, in)
+}
+
+func (a *auditService) ReloadCert(c context.Context, in *drand.ReloadCertRequest) (_ *drand.ReloadCertResponse, err error) {
+	defer a.audit(c, "ReloadCert", in, &err)
+	return a.Service.ReloadCert(c, in)
+}
+
+func (a *auditService) UnfreezeBeacon(c context.Context, in *drand.UnfreezeRequest) (_ *drand.UnfreezeResponse, err error) {
+	defer a.audit(c, "UnfreezeBeacon", in, &err)
+	return a.Service.UnfreezeBeacon(c, in)
+}
+
+func (a *auditService) AbortDKG(c context.Context, in *drand.AbortDKGRequest) (_ *drand.AbortDKGResponse, err error) {
+	defer a.audit(c, "AbortDKG", in, &err)
+	return a.Service.AbortDKG(c, in)
+}
+
+func (a *auditService) UseKeyFile(c context.Context, in *drand.UseKeyFileRequest) (_ *drand.UseKeyFileResponse, err error) {
+	defer a.audit(c, "UseKeyFile", in, &err)
+	return a.Service.UseKeyFile(c, in)
+}
+
+func (a *auditService) SetPeerPriority(c context.Context, in *drand.SetPeerPriorityRequest) (_ *drand.SetPeerPriorityResponse, err error) {
+	defer a.audit(c, "SetPeerPriority", in, &err)
+	return a.Service.SetPeerPriority(c, in)
+}
+
+func (a *auditService) StartFollowChain(in *drand.StartFollowRequest, stream drand.Control_StartFollowChainServer) (err error) {
+	defer a.audit(stream.Context(), "StartFollowChain", in, &err)
+	return a.Service.StartFollowChain(in, stream)
+}
+
+// auditQueueSize is the number of entries an AuditLog holds before the
+// audited calls wait for them to be written.
+const auditQueueSize = 1024
+
+// errAuditLogClosed is returned when writing to a closed AuditLog
+var errAuditLogClosed = errors.New("audit log closed")
+
+// AuditLog is an io.WriteCloser writing to the underlying writer in the
+// background, so the audited calls do not wait for the disk. The writes are
+// buffered and flushed once no more is pending, the underlying writer being
+// synced then if it is a file.
+type AuditLog struct {
+	sync.RWMutex
+	closed  bool
+	entries chan []byte
+	done    chan struct{}
+	w       io.Writer
+	buff    *bufio.Writer
+	l       log.Logger
+}
+
+// NewAuditLog returns an AuditLog writing to w, failures to write being logged
+// to l. Closing it closes w if it is an io.Closer.
+func NewAuditLog(w io.Writer, l log.Logger) *AuditLog {
+	a := &AuditLog{
+		entries: make(chan []byte, auditQueueSize),
+		done:    make(chan struct{}),
+		w:       w,
+		buff:    bufio.NewWriter(w),
+		l:       l,
+	}
+	go a.run()
+	return a
+}
+
+// Write queues p to be written.
+func (a *AuditLog) Write(p []byte) (int, error) {
+	a.RLock()
+	defer a.RUnlock()
+	if a.closed {
+		return 0, errAuditLogClosed
+	}
+	a.entries <- append([]byte(nil), p...)
+	return len(p), nil
+}
+
+// Close writes the queued entries and closes the underlying writer.
+func (a *AuditLog) Close() error {
+	a.Lock()
+	if a.closed {
+		a.Unlock()
+		return nil
+	}
+	a.closed = true
+	close(a.entries)
+	a.Unlock()
+	<-a.done
+	if c, ok := a.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+func (a *AuditLog) run() {
+	defer close(a.done)
+	for entry := range a.entries {
+		if _, err := a.buff.Write(entry); err != nil {
+			a.l.Error("audit", "write", "err", err)
+		}
+		if len(a.entries) == 0 {
+			a.flush()
+		}
+	}
+	a.flush()
+}
+
+func (a *AuditLog) flush() {
+	if err := a.buff.Flush(); err != nil {
+		a.l.Error("audit", "flush", "err", err)
+		return
+	}
+	if s, ok := a.w.(interface{ Sync() error }); ok {
+		if err := s.Sync(); err != nil {
+			a.l.Error("audit", "sync", "err", err)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package net
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/drand/drand/log"
+	"github.com/drand/drand/protobuf/drand"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// auditedService answers the audited methods, failing PartialBeacon
+type auditedService struct {
+	Service
+}
+
+func (s *auditedService) BroadcastDKG(context.Context, *drand.DKGPacket) (*drand.Empty, error) {
+	return new(drand.Empty), nil
+}
+
+func (s *auditedService) PartialBeacon(context.Context, *drand.PartialBeaconPacket) (*drand.Empty, error) {
+	return nil, status.Error(codes.FailedPrecondition, "not running")
+}
+
+func (s *auditedService) PublicRand(context.Context, *drand.PublicRandRequest) (*drand.PublicRandResponse, error) {
+	return new(drand.PublicRandResponse), nil
+}
+
+func (s *auditedService) SyncChain(*drand.SyncRequest, drand.Protocol_SyncChainServer) error {
+	return errors.New("no chain")
+}
+
+func (s *auditedService) Status(context.Context, *drand.StatusRequest) (*drand.StatusResponse, error) {
+	return new(drand.StatusResponse), nil
+}
+
+func TestAuditLog(t *testing.T) {
+	var out bytes.Buffer
+	s := WithAuditLog(&out, log.DefaultLogger())(&auditedService{})
+	ctx := context.Background()
+
+	_, err := s.BroadcastDKG(ctx, new(drand.DKGPacket))
+	require.NoError(t, err)
+	in := &drand.PartialBeaconPacket{Round: 2}
+	_, err = s.PartialBeacon(ctx, in)
+	require.Error(t, err)
+	_, err = s.Status(ctx, new(drand.StatusRequest))
+	require.NoError(t, err)
+	require.Error(t, s.SyncChain(&drand.SyncRequest{FromRound: 4}, &testSyncStream{ctx: ctx}))
+	// the public reads are not audited
+	_, err = s.PublicRand(ctx, &drand.PublicRandRequest{Round: 3})
+	require.NoError(t, err)
+
+	var entries []*AuditEntry
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		entry := new(AuditEntry)
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), entry))
+		require.NotZero(t, entry.Time)
+		entries = append(entries, entry)
+	}
+	require.Len(t, entries, 4)
+	var methods, statuses []string
+	for _, e := range entries {
+		methods = append(methods, e.Method)
+		statuses = append(statuses, e.Status)
+	}
+	require.Equal(t, []string{"BroadcastDKG", "PartialBeacon", "Status", "SyncChain"}, methods)
+	require.Equal(t, []string{"OK", "FailedPrecondition", "OK", "Unknown"}, statuses)
+	body, err := proto.Marshal(in)
+	require.NoError(t, err)
+	h := sha256.Sum256(body)
+	require.Equal(t, hex.EncodeToString(h[:]), entries[1].RequestHash)
+	require.NotEqual(t, entries[0].RequestHash, entries[1].RequestHash)
+}
+
+// panickingService panics on PartialBeacon
+type panickingService struct {
+	Service
+}
+
+func (s *panickingService) PartialBeacon(context.Context, *drand.PartialBeaconPacket) (*drand.Empty, error) {
+	panic("broken")
+}
+
+func TestAuditLogPanic(t *testing.T) {
+	var out bytes.Buffer
+	s := WithAuditLog(&out, log.DefaultLogger())(&panickingService{})
+	// the panic goes on once the call is logged
+	require.PanicsWithValue(t, "broken", func() {
+		_, _ = s.PartialBeacon(context.Background(), &drand.PartialBeaconPacket{Round: 3})
+	})
+	entry := new(AuditEntry)
+	require.NoError(t, json.Unmarshal(out.Bytes(), entry))
+	require.Equal(t, "PartialBeacon", entry.Method)
+	require.Equal(t, codes.Internal.String(), entry.Status)
+}
+
+// closingBuffer records whether it was closed
+type closingBuffer struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *closingBuffer) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestAuditLogWriter(t *testing.T) {
+	var out closingBuffer
+	a := NewAuditLog(&out, log.DefaultLogger())
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := a.Write([]byte("entry\n"))
+			require.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	// the pending entries are written before closing
+	require.NoError(t, a.Close())
+	require.True(t, out.closed)
+	require.Equal(t, strings.Repeat("entry\n", 100), out.String())
+	_, err := a.Write([]byte("entry\n"))
+	require.Error(t, err)
+	require.NoError(t, a.Close())
+}
@@ -0,0 +1,302 @@
+package net
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/drand/drand/key"
+	"github.com/drand/drand/protobuf/drand"
+)
+
+// metadata keys of the token authenticating a control request
+const (
+	authKeyHeader       = "drand-auth-key"
+	authTimestampHeader = "drand-auth-timestamp"
+	authSignatureHeader = "drand-auth-signature"
+	authNonceHeader     = "drand-auth-nonce"
+)
+
+// authNonceLength is the number of random bytes of the nonce of a token
+const authNonceLength = 16
+
+// MaxControlAuthDrift is the maximum difference allowed between the timestamp
+// of an authenticated control request and the clock of the node.
+var MaxControlAuthDrift = 30 * time.Second
+
+var errUnauthenticated = status.Error(codes.Unauthenticated, "control request not authenticated")
+
+// WithControlAuth returns a middleware that only lets through the control
+// requests signed by one of the allowed keys, as done by the client options of
+// ControlAuthOptions. The signature covers the method, the body of the request,
+// a random nonce and a timestamp that must be within MaxControlAuthDrift of the
+// clock of the node. A token is only accepted once: a replayed nonce is
+// rejected for as long as its timestamp is valid. Other requests fail with an
+// Unauthenticated status. ChainInfo and GroupFile are not covered since the
+// other nodes call them as well.
+func WithControlAuth(allowedKeys []*key.Identity) func(Service) Service {
+	return func(s Service) Service {
+		return &controlAuthService{Service: s, allowed: allowedKeys, nonces: make(map[string]time.Time)}
+	}
+}
+
+// controlBodyKey is the context key of the body of a streaming control request,
+// since the stream interceptors don't see it.
+type controlBodyKey struct{}
+
+// withControlBody returns a context carrying the body of a streaming control
+// request so ControlAuthOptions can sign it.
+func withControlBody(c context.Context, in proto.Message) context.Context {
+	return context.WithValue(c, controlBodyKey{}, in)
+}
+
+// ControlAuthOptions returns the dial options signing the control requests
+// with the given key pair, for a node using WithControlAuth. The body of a
+// streaming request is only signed when it is set in the context of the call,
+// as done by the ControlClient.
+func ControlAuthOptions(pair *key.Pair) []grpc.DialOption {
+	unary := func(c context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		msg, _ := req.(proto.Message)
+		c, err := controlAuthContext(c, pair, method, msg)
+		if err != nil {
+			return err
+		}
+		return invoker(c, method, req, reply, cc, opts...)
+	}
+	stream := func(c context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+		streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		msg, _ := c.Value(controlBodyKey{}).(proto.Message)
+		c, err := controlAuthContext(c, pair, method, msg)
+		if err != nil {
+			return nil, err
+		}
+		return streamer(c, desc, cc, method, opts...)
+	}
+	return []grpc.DialOption{grpc.WithUnaryInterceptor(unary), grpc.WithStreamInterceptor(stream)}
+}
+
+// controlAuthContext adds the token of the request to the outgoing metadata
+func controlAuthContext(c context.Context, pair *key.Pair, fullMethod string, in proto.Message) (context.Context, error) {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = proto.Marshal(in); err != nil {
+			return nil, err
+		}
+	}
+	pub, err := pair.Public.Key.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, authNonceLength)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	timestamp := time.Now().Unix()
+	sig, err := pair.SignControl(path.Base(fullMethod), body, nonce, timestamp)
+	if err != nil {
+		return nil, err
+	}
+	return metadata.AppendToOutgoingContext(c,
+		authKeyHeader, hex.EncodeToString(pub),
+		authTimestampHeader, strconv.FormatInt(timestamp, 10),
+		authNonceHeader, hex.EncodeToString(nonce),
+		authSignatureHeader, hex.EncodeToString(sig)), nil
+}
+
+type controlAuthService struct {
+	Service
+	allowed []*key.Identity
+
+	sync.Mutex
+	// nonces of the accepted tokens, with the time they can be forgotten at
+	nonces map[string]time.Time
+}
+
+// authenticate checks the token of a request
+func (a *controlAuthService) authenticate(c context.Context, method string, in proto.Message) error {
+	md, _ := metadata.FromIncomingContext(c)
+	header := func(k string) string {
+		if v := md.Get(k); len(v) == 1 {
+			return v[0]
+		}
+		return ""
+	}
+	pub, err := hex.DecodeString(header(authKeyHeader))
+	if err != nil || len(pub) == 0 {
+		return errUnauthenticated
+	}
+	timestamp, err := strconv.ParseInt(header(authTimestampHeader), 10, 64)
+	if err != nil {
+		return errUnauthenticated
+	}
+	if drift := time.Since(time.Unix(timestamp, 0)); drift > MaxControlAuthDrift || drift < -MaxControlAuthDrift {
+		return errUnauthenticated
+	}
+	nonce, err := hex.DecodeString(header(authNonceHeader))
+	if err != nil || len(nonce) != authNonceLength {
+		return errUnauthenticated
+	}
+	sig, err := hex.DecodeString(header(authSignatureHeader))
+	if err != nil {
+		return errUnauthenticated
+	}
+	var body []byte
+	if in != nil {
+		if body, err = proto.Marshal(in); err != nil {
+			return errUnauthenticated
+		}
+	}
+	for _, id := range a.allowed {
+		allowed, err := id.Key.MarshalBinary()
+		if err != nil || !bytes.Equal(allowed, pub) {
+			continue
+		}
+		if id.VerifyControl(method, body, nonce, timestamp, sig) == nil {
+			return a.useNonce(nonce, timestamp)
+		}
+	}
+	return errUnauthenticated
+}
+
+// useNonce records the nonce of a valid token and fails if it was already
+// used. Nonces are forgotten once their token is stale.
+func (a *controlAuthService) useNonce(nonce []byte, timestamp int64) error {
+	a.Lock()
+	defer a.Unlock()
+	now := time.Now()
+	for n, expiry := range a.nonces {
+		if now.After(expiry) {
+			delete(a.nonces, n)
+		}
+	}
+	if _, seen := a.nonces[string(nonce)]; seen {
+		return errUnauthenticated
+	}
+	a.nonces[string(nonce)] = time.Unix(timestamp, 0).Add(MaxControlAuthDrift)
+	return nil
+}
+
+func (a *controlAuthService) PingPong(c context.Context, in *drand.Ping) (*drand.Pong, error) {
+	if err := a.authenticate(c, "PingPong", in); err != nil {
+		return nil, err
+	}
+	return a.Service.PingPong(c, in)
+}
+
+func (a *controlAuthService) InitDKG(c context.Context, in *drand.InitDKGPacket) (*drand.GroupPacket, error) {
+	if err := a.authenticate(c, "InitDKG", in); err != nil {
+		return nil, err
+	}
+	return a.Service.InitDKG(c, in)
+}
+
+func (a *controlAuthService) InitReshare(c context.Context, in *drand.InitResharePacket) (*drand.GroupPacket, error) {
+	if err := a.authenticate(c, "InitReshare", in); err != nil {
+		return nil, err
+	}
+	return a.Service.InitReshare(c, in)
+}
+
+func (a *controlAuthService) Share(c context.Context, in *drand.ShareRequest) (*drand.ShareResponse, error) {
+	if err := a.authenticate(c, "Share", in); err != nil {
+		return nil, err
+	}
+	return a.Service.Share(c, in)
+}
+
+func (a *controlAuthService) PublicKey(c context.Context, in *drand.PublicKeyRequest) (*drand.PublicKeyResponse, error) {
+	if err := a.authenticate(c, "PublicKey", in); err != nil {
+		return nil, err
+	}
+	return a.Service.PublicKey(c, in)
+}
+
+func (a *controlAuthService) PrivateKey(c context.Context, in *drand.PrivateKeyRequest) (*drand.PrivateKeyResponse, error) {
+	if err := a.authenticate(c, "PrivateKey", in); err != nil {
+		return nil, err
+	}
+	return a.Service.PrivateKey(c, in)
+}
+
+func (a *controlAuthService) Shutdown(c context.Context, in *drand.ShutdownRequest) (*drand.ShutdownResponse, error) {
+	if err := a.authenticate(c, "Shutdown", in); err != nil {
+		return nil, err
+	}
+	return a.Service.Shutdown(c, in)
+}
+
+func (a *controlAuthService) StartFollowChain(in *drand.StartFollowRequest, stream drand.Control_StartFollowChainServer) error {
+	if err := a.authenticate(stream.Context(), "StartFollowChain", in); err != nil {
+		return err
+	}
+	return a.Service.StartFollowChain(in, stream)
+}
+
+func (a *controlAuthService) BackupDatabase(c context.Context, in *drand.BackupDBRequest) (*drand.BackupDBResponse, error) {
+	if err := a.authenticate(c, "BackupDatabase", in); err != nil {
+		return nil, err
+	}
+	return a.Service.BackupDatabase(c, in)
+}
+
+func (a *controlAuthService) Status(c context.Context, in *drand.StatusRequest) (*drand.StatusResponse, error) {
+	if err := a.authenticate(c, "Status", in); err != nil {
+		return nil, err
+	}
+	return a.Service.Status(c, in)
+}
+
+func (a *controlAuthService) ListProtocols(c context.Context, in *drand.ListProtocolsRequest) (*drand.ListProtocolsResponse, error) {
+	if err := a.authenticate(c, "ListProtocols", in); err != nil {
+		return nil, err
+	}
+	return a.Service.ListProtocols(c, in)
+}
+
+func (a *controlAuthService) ReloadCert(c context.Context, in *drand.ReloadCertRequest) (*drand.ReloadCertResponse, error) {
+	if err := a.authenticate(c, "ReloadCert", in); err != nil {
+		return nil, err
+	}
+	return a.Service.ReloadCert(c, in)
+}
+
+func (a *controlAuthService) UnfreezeBeacon(c context.Context, in *drand.UnfreezeRequest) (*drand.UnfreezeResponse, error) {
+	if err := a.authenticate(c, "UnfreezeBeacon", in); err != nil {
+		return nil, err
+	}
+	return a.Service.UnfreezeBeacon(c, in)
+}
+
+func (a *controlAuthService) AbortDKG(c context.Context, in *drand.AbortDKGRequest) (*drand.AbortDKGResponse, error) {
+	if err := a.authenticate(c, "AbortDKG", in); err != nil {
+		return nil, err
+	}
+	return a.Service.AbortDKG(c, in)
+}
+
+func (a *controlAuthService) UseKeyFile(c context.Context, in *drand.UseKeyFileRequest) (*drand.UseKeyFileResponse, error) {
+	if err := a.authenticate(c, "UseKeyFile", in); err != nil {
+		return nil, err
+	}
+	return a.Service.UseKeyFile(c, in)
+}
+
+func (a *controlAuthService) SetPeerPriority(c context.Context, in *drand.SetPeerPriorityRequest) (*drand.SetPeerPriorityResponse, error) {
+	if err := a.authenticate(c, "SetPeerPriority", in); err != nil {
+		return nil, err
+	}
+	return a.Service.SetPeerPriority(c, in)
+}
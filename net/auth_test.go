@@ -0,0 +1,134 @@
+package net
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/key"
+	"github.com/drand/drand/protobuf/drand"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// statusService answers the Status requests
+type statusService struct {
+	Service
+}
+
+func (s *statusService) Status(context.Context, *drand.StatusRequest) (*drand.StatusResponse, error) {
+	return new(drand.StatusResponse), nil
+}
+
+func TestControlAuth(t *testing.T) {
+	allowed := key.NewKeyPair("127.0.0.1:80")
+	other := key.NewKeyPair("127.0.0.1:81")
+	s := WithControlAuth([]*key.Identity{allowed.Public})(&statusService{})
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	drand.RegisterControlServer(server, s)
+	go func() { _ = server.Serve(lis) }()
+	defer server.Stop()
+
+	call := func(opts ...grpc.DialOption) codes.Code {
+		conn, err := grpc.Dial(lis.Addr().String(), append(opts, grpc.WithInsecure())...)
+		require.NoError(t, err)
+		defer conn.Close()
+		_, err = drand.NewControlClient(conn).Status(context.Background(), new(drand.StatusRequest))
+		return status.Code(err)
+	}
+	require.Equal(t, codes.OK, call(ControlAuthOptions(allowed)...))
+	require.Equal(t, codes.Unauthenticated, call(ControlAuthOptions(other)...))
+	require.Equal(t, codes.Unauthenticated, call())
+}
+
+func TestControlAuthToken(t *testing.T) {
+	pair := key.NewKeyPair("127.0.0.1:80")
+	s := WithControlAuth([]*key.Identity{pair.Public})(&statusService{}).(*controlAuthService)
+	pub, err := pair.Public.Key.MarshalBinary()
+	require.NoError(t, err)
+	in := &drand.BackupDBRequest{OutputFile: "backup.db"}
+	body, err := proto.Marshal(in)
+	require.NoError(t, err)
+
+	var nonce [authNonceLength]byte
+	authenticate := func(method string, timestamp int64, in *drand.BackupDBRequest) error {
+		sig, err := pair.SignControl("BackupDatabase", body, nonce[:], timestamp)
+		require.NoError(t, err)
+		c := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+			authKeyHeader, hex.EncodeToString(pub),
+			authTimestampHeader, strconv.FormatInt(timestamp, 10),
+			authNonceHeader, hex.EncodeToString(nonce[:]),
+			authSignatureHeader, hex.EncodeToString(sig)))
+		return s.authenticate(c, method, in)
+	}
+	now := time.Now().Unix()
+	require.NoError(t, authenticate("BackupDatabase", now, in))
+	// replayed token
+	require.Equal(t, codes.Unauthenticated, status.Code(authenticate("BackupDatabase", now, in)))
+	nonce[0]++
+	require.NoError(t, authenticate("BackupDatabase", now-20, in))
+	nonce[0]++
+	// stale or future token
+	require.Equal(t, codes.Unauthenticated, status.Code(authenticate("BackupDatabase", now-60, in)))
+	require.Equal(t, codes.Unauthenticated, status.Code(authenticate("BackupDatabase", now+60, in)))
+	// token of another method or body
+	require.Equal(t, codes.Unauthenticated, status.Code(authenticate("Shutdown", now, in)))
+	require.Equal(t, codes.Unauthenticated, status.Code(authenticate("BackupDatabase", now, &drand.BackupDBRequest{OutputFile: "other.db"})))
+	// the nonce is forgotten once the token is stale
+	s.nonces["stale"] = time.Now().Add(-time.Second)
+	require.NoError(t, authenticate("BackupDatabase", now, in))
+	require.NotContains(t, s.nonces, "stale")
+}
+
+// followService answers the StartFollowChain requests
+type followService struct {
+	Service
+	hash string
+}
+
+func (s *followService) StartFollowChain(in *drand.StartFollowRequest, _ drand.Control_StartFollowChainServer) error {
+	s.hash = in.GetInfoHash()
+	return nil
+}
+
+func TestControlAuthStream(t *testing.T) {
+	pair := key.NewKeyPair("127.0.0.1:80")
+	follow := new(followService)
+	s := WithControlAuth([]*key.Identity{pair.Public})(follow)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	drand.RegisterControlServer(server, s)
+	go func() { _ = server.Serve(lis) }()
+	defer server.Stop()
+
+	conn, err := grpc.Dial(lis.Addr().String(), append(ControlAuthOptions(pair), grpc.WithInsecure())...)
+	require.NoError(t, err)
+	defer conn.Close()
+	client := drand.NewControlClient(conn)
+	call := func(c context.Context, req *drand.StartFollowRequest) error {
+		stream, err := client.StartFollowChain(c, req)
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		return err
+	}
+	req := &drand.StartFollowRequest{InfoHash: "deadbeef"}
+	// the body is signed when it is given in the context
+	require.Equal(t, io.EOF, call(withControlBody(context.Background(), req), req))
+	require.Equal(t, "deadbeef", follow.hash)
+	// and the token doesn't hold for another body
+	other := &drand.StartFollowRequest{InfoHash: "cafe"}
+	require.Equal(t, codes.Unauthenticated, status.Code(call(withControlBody(context.Background(), req), other)))
+}
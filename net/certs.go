@@ -1,9 +1,18 @@
 package net
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
 	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
 	"io/ioutil"
+	"math/big"
+	"net"
+	"time"
 
 	"github.com/drand/drand/log"
 )
@@ -43,3 +52,72 @@ func (p *CertManager) Add(certPath string) error {
 	log.DefaultLogger().Debug("cert_manager", "add", "server cert path", certPath)
 	return nil
 }
+
+// LoadCertificate loads the PEM encoded certificate and private key at the
+// given paths, with the parsed certificate in the Leaf field. It fails if the
+// certificate is not valid at the given time.
+func LoadCertificate(certPath, keyPath string, now time.Time) (*tls.Certificate, error) {
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("load cert: %w", err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("load cert: parsing certificate: %w", err)
+	}
+	if now.Before(leaf.NotBefore) {
+		return nil, fmt.Errorf("load cert: certificate not valid before %s", leaf.NotBefore)
+	}
+	if now.After(leaf.NotAfter) {
+		return nil, fmt.Errorf("load cert: certificate expired on %s", leaf.NotAfter)
+	}
+	cert.Leaf = leaf
+	return &cert, nil
+}
+
+// SelfSignedCert generates a self-signed ECDSA certificate for the given host
+// name or IP address, valid for the given duration. It returns the certificate
+// and its private key, both PEM encoded.
+func SelfSignedCert(host string, validFor time.Duration) (certPEM, keyPEM []byte, err error) {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if host == "" {
+		return nil, nil, fmt.Errorf("self signed cert: empty host")
+	}
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("self signed cert: generating key: %w", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, fmt.Errorf("self signed cert: generating serial number: %w", err)
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"drand"}, CommonName: host},
+		NotBefore:             now,
+		NotAfter:              now.Add(validFor),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		template.IPAddresses = []net.IP{ip}
+	} else {
+		template.DNSNames = []string{host}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &priv.PublicKey, priv)
+	if err != nil {
+		return nil, nil, fmt.Errorf("self signed cert: creating certificate: %w", err)
+	}
+	privBytes, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		return nil, nil, fmt.Errorf("self signed cert: encoding key: %w", err)
+	}
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: privBytes})
+	return certPEM, keyPEM, nil
+}
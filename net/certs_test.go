@@ -0,0 +1,43 @@
+package net
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelfSignedCert(t *testing.T) {
+	certPEM, keyPEM, err := SelfSignedCert("127.0.0.1:0", time.Hour)
+	require.NoError(t, err)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+
+	lis, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	require.NoError(t, err)
+	defer lis.Close()
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+
+	pool := x509.NewCertPool()
+	require.True(t, pool.AppendCertsFromPEM(certPEM))
+	conn, err := tls.Dial("tcp", lis.Addr().String(), &tls.Config{RootCAs: pool})
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	// the certificate is not valid for another host
+	_, port, _ := net.SplitHostPort(lis.Addr().String())
+	_, err = tls.Dial("tcp", net.JoinHostPort("localhost", port), &tls.Config{RootCAs: pool})
+	require.Error(t, err)
+}
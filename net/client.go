@@ -30,10 +30,13 @@ type CallOption = grpc.CallOption
 type ProtocolClient interface {
 	GetIdentity(ctx context.Context, p Peer, in *drand.IdentityRequest, opts ...CallOption) (*drand.Identity, error)
 	SyncChain(ctx context.Context, p Peer, in *drand.SyncRequest, opts ...CallOption) (chan *drand.BeaconPacket, error)
+	PushSync(ctx context.Context, p Peer, in *drand.SyncRequest, opts ...CallOption) (chan *drand.BeaconBatch, error)
 	PartialBeacon(ctx context.Context, p Peer, in *drand.PartialBeaconPacket, opts ...CallOption) error
 	BroadcastDKG(c context.Context, p Peer, in *drand.DKGPacket, opts ...CallOption) error
 	SignalDKGParticipant(ctx context.Context, p Peer, in *drand.SignalDKGPacket, opts ...CallOption) error
+	AnnounceJoin(ctx context.Context, p Peer, in *drand.SignalDKGPacket, opts ...CallOption) error
 	PushDKGInfo(ctx context.Context, p Peer, in *drand.DKGInfoPacket, opts ...grpc.CallOption) error
+	GroupFile(ctx context.Context, p Peer, in *drand.GroupRequest, opts ...CallOption) (*drand.GroupPacket, error)
 }
 
 // PublicClient holds all the methods of the public API . See
@@ -190,6 +190,16 @@ func (g *grpcClient) SignalDKGParticipant(ctx context.Context, p Peer, in *drand
 	return err
 }
 
+func (g *grpcClient) AnnounceJoin(ctx context.Context, p Peer, in *drand.SignalDKGPacket, opts ...CallOption) error {
+	c, err := g.conn(p)
+	if err != nil {
+		return err
+	}
+	client := drand.NewProtocolClient(c)
+	_, err = client.AnnounceJoin(ctx, in, opts...)
+	return err
+}
+
 func (g *grpcClient) BroadcastDKG(ctx context.Context, p Peer, in *drand.DKGPacket, opts ...CallOption) error {
 	c, err := g.conn(p)
 	if err != nil {
@@ -202,6 +212,18 @@ func (g *grpcClient) BroadcastDKG(ctx context.Context, p Peer, in *drand.DKGPack
 	return err
 }
 
+// GroupFile returns the group of the chain the node participates to
+func (g *grpcClient) GroupFile(ctx context.Context, p Peer, in *drand.GroupRequest, opts ...CallOption) (*drand.GroupPacket, error) {
+	c, err := g.conn(p)
+	if err != nil {
+		return nil, err
+	}
+	client := drand.NewProtocolClient(c)
+	ctx, cancel := g.getTimeoutContext(ctx)
+	defer cancel()
+	return client.GroupFile(ctx, in, opts...)
+}
+
 func (g *grpcClient) PartialBeacon(ctx context.Context, p Peer, in *drand.PartialBeaconPacket, opts ...CallOption) error {
 	c, err := g.conn(p)
 	if err != nil {
@@ -254,6 +276,42 @@ func (g *grpcClient) SyncChain(ctx context.Context, p Peer, in *drand.SyncReques
 	return resp, nil
 }
 
+// PushSync waits for the first batch of beacons pushed by the node, so an
+// error, e.g. a node not supporting push syncing, is returned right away. The
+// batches are then sent on the returned channel.
+func (g *grpcClient) PushSync(ctx context.Context, p Peer, in *drand.SyncRequest, opts ...CallOption) (chan *drand.BeaconBatch, error) {
+	c, err := g.conn(p)
+	if err != nil {
+		return nil, err
+	}
+	client := drand.NewProtocolClient(c)
+	stream, err := client.PushSync(ctx, in, opts...)
+	if err != nil {
+		return nil, err
+	}
+	batch, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	resp := make(chan *drand.BeaconBatch, 1)
+	go func() {
+		defer close(resp)
+		for {
+			select {
+			case resp <- batch:
+			case <-ctx.Done():
+				return
+			}
+			batch, err = stream.Recv()
+			if err != nil {
+				log.DefaultLogger().Info("grpc client", "push sync", "error", err, "to", p.Address())
+				return
+			}
+		}
+	}()
+	return resp, nil
+}
+
 func (g *grpcClient) Home(ctx context.Context, p Peer, in *drand.HomeRequest) (*drand.HomeResponse, error) {
 	var resp *drand.HomeResponse
 	c, err := g.conn(p)
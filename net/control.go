@@ -4,6 +4,7 @@ import (
 	ctx "context"
 	"fmt"
 	"net"
+	"os"
 	"strings"
 	"time"
 
@@ -20,12 +21,49 @@ type ControlListener struct {
 }
 
 // NewTCPGrpcControlListener registers the pairing between a ControlServer and a grpc server
-func NewTCPGrpcControlListener(s control.ControlServer, controlAddr string) ControlListener {
-	lis, err := net.Listen(controlListenAddr(controlAddr))
+func NewTCPGrpcControlListener(s control.ControlServer, controlAddr string) (ControlListener, error) {
+	network, addr := controlListenAddr(controlAddr)
+	if network == "unix" {
+		return NewUnixGrpcControlListener(s, addr)
+	}
+	lis, err := net.Listen(network, addr)
+	if err != nil {
+		return ControlListener{}, fmt.Errorf("control listener: %w", err)
+	}
+	return newControlListener(s, lis), nil
+}
+
+// NewUnixGrpcControlListener registers the pairing between a ControlServer and
+// a grpc server listening on a unix domain socket. The socket is only
+// accessible to the user running the daemon. A socket file left over by a
+// daemon that is not running anymore is replaced, but any other kind of file
+// at the path is left untouched.
+func NewUnixGrpcControlListener(s control.ControlServer, socketPath string) (ControlListener, error) {
+	if info, err := os.Lstat(socketPath); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return ControlListener{}, fmt.Errorf("control listener: %s exists and is not a socket", socketPath)
+		}
+		conn, err := net.Dial("unix", socketPath)
+		if err == nil {
+			conn.Close()
+			return ControlListener{}, fmt.Errorf("control listener: socket %s already in use", socketPath)
+		}
+		if err := os.Remove(socketPath); err != nil {
+			return ControlListener{}, fmt.Errorf("control listener: %w", err)
+		}
+	}
+	lis, err := net.Listen("unix", socketPath)
 	if err != nil {
-		log.DefaultLogger().Error("grpc listener", "failure", "err", err)
-		return ControlListener{}
+		return ControlListener{}, fmt.Errorf("control listener: %w", err)
+	}
+	if err := os.Chmod(socketPath, 0600); err != nil {
+		lis.Close()
+		return ControlListener{}, fmt.Errorf("control listener: %w", err)
 	}
+	return newControlListener(s, lis), nil
+}
+
+func newControlListener(s control.ControlServer, lis net.Listener) ControlListener {
 	grpcServer := grpc.NewServer()
 	control.RegisterControlServer(grpcServer, s)
 	return ControlListener{conns: grpcServer, lis: lis}
@@ -40,9 +78,31 @@ func (g *ControlListener) Start() {
 
 // Stop the listener and connections
 func (g *ControlListener) Stop() {
+	// the listener is only closed by grpc if it started serving already
+	_ = g.lis.Close()
 	g.conns.Stop()
 }
 
+// GracefulStop stops the listener from accepting new connections and waits for
+// the in-flight requests to finish, up to the given timeout. Once the timeout
+// is reached, the remaining connections are forcibly closed and an error is
+// returned.
+func (g *ControlListener) GracefulStop(timeout time.Duration) error {
+	_ = g.lis.Close()
+	done := make(chan struct{})
+	go func() {
+		g.conns.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-time.After(timeout):
+		g.conns.Stop()
+		return fmt.Errorf("control listener: forced stop after %s", timeout)
+	}
+}
+
 // ControlClient is a struct that implement control.ControlClient and is used to
 // request a Share to a ControlListener on a specific port
 type ControlClient struct {
@@ -53,14 +113,15 @@ type ControlClient struct {
 const grpcDefaultIPNetwork = "tcp"
 
 // NewControlClient creates a client capable of issuing control commands to a
-// localhost running drand node.
-func NewControlClient(addr string) (*ControlClient, error) {
+// localhost running drand node. The options are added to the ones used to
+// dial the node, e.g. ControlAuthOptions.
+func NewControlClient(addr string, opts ...grpc.DialOption) (*ControlClient, error) {
 	var conn *grpc.ClientConn
 	network, host := controlListenAddr(addr)
 	if network != grpcDefaultIPNetwork {
 		host = fmt.Sprintf("%s://%s", network, host)
 	}
-	conn, err := grpc.Dial(host, grpc.WithInsecure())
+	conn, err := grpc.Dial(host, append([]grpc.DialOption{grpc.WithInsecure()}, opts...)...)
 	if err != nil {
 		log.DefaultLogger().Error("control client", "connect failure", "err", err)
 		return nil, err
@@ -76,13 +137,17 @@ func (c *ControlClient) Ping() error {
 }
 
 // InitReshareLeader sets up the node to be ready for a resharing protocol.
+// For this method and the other setup methods, retries is the number of times
+// the node sends again a DKG packet that another node failed to receive.
 // NOTE: only group referral via filesystem path is supported at the moment.
 // XXX Might be best to move to core/
 func (c *ControlClient) InitReshareLeader(
 	nodes, threshold int,
-	timeout, catchupPeriod time.Duration,
+	timeout, catchupPeriod, waitTimeout time.Duration,
 	secret, oldPath string,
-	offset int) (*control.GroupPacket, error) {
+	offset int,
+	force bool,
+	retries int) (*control.GroupPacket, error) {
 	request := &control.InitResharePacket{
 		Old: &control.GroupInfo{
 			Location: &control.GroupInfo_Path{Path: oldPath},
@@ -92,8 +157,11 @@ func (c *ControlClient) InitReshareLeader(
 			Threshold:    uint32(threshold),
 			Leader:       true,
 			Timeout:      uint32(timeout.Seconds()),
+			WaitTimeout:  uint32(waitTimeout.Seconds()),
 			Secret:       []byte(secret),
 			BeaconOffset: uint32(offset),
+			Force:        force,
+			Retries:      uint32(retries),
 		},
 		CatchupPeriodChanged: catchupPeriod >= 0,
 		CatchupPeriod:        uint32(catchupPeriod.Seconds()),
@@ -102,7 +170,26 @@ func (c *ControlClient) InitReshareLeader(
 }
 
 // InitReshare sets up the node to be ready for a resharing protocol.
-func (c *ControlClient) InitReshare(leader Peer, secret, oldPath string, force bool) (*control.GroupPacket, error) {
+func (c *ControlClient) InitReshare(leader Peer, secret, oldPath string, force bool, retries int) (*control.GroupPacket, error) {
+	request := &control.InitResharePacket{
+		Old: &control.GroupInfo{
+			Location: &control.GroupInfo_Path{Path: oldPath},
+		},
+		Info: &control.SetupInfoPacket{
+			Leader:        false,
+			LeaderAddress: leader.Address(),
+			LeaderTls:     leader.IsTLS(),
+			Secret:        []byte(secret),
+			Force:         force,
+			Retries:       uint32(retries),
+		},
+	}
+	return c.client.InitReshare(ctx.Background(), request)
+}
+
+// JoinReshare sets up a node that is not part of the old group to announce
+// itself to the leader and wait to be included in the next resharing.
+func (c *ControlClient) JoinReshare(leader Peer, secret, oldPath string, force bool, retries int) (*control.GroupPacket, error) {
 	request := &control.InitResharePacket{
 		Old: &control.GroupInfo{
 			Location: &control.GroupInfo_Path{Path: oldPath},
@@ -113,50 +200,101 @@ func (c *ControlClient) InitReshare(leader Peer, secret, oldPath string, force b
 			LeaderTls:     leader.IsTLS(),
 			Secret:        []byte(secret),
 			Force:         force,
+			Retries:       uint32(retries),
 		},
+		JoinExisting: true,
+	}
+	return c.client.InitReshare(ctx.Background(), request)
+}
+
+// ReshareFromChain sets up a node that does not have the group of the chain to
+// fetch it from the leader, checking it matches the given chain hash, and then
+// to be ready for a resharing protocol. If joinExisting is set, the node
+// announces itself to the leader as with JoinReshare.
+func (c *ControlClient) ReshareFromChain(leader Peer, secret string, chainHash []byte, force, joinExisting bool,
+	retries int) (*control.GroupPacket, error) {
+	request := &control.InitResharePacket{
+		Old: &control.GroupInfo{
+			Location: &control.GroupInfo_ChainHash{ChainHash: chainHash},
+		},
+		Info: &control.SetupInfoPacket{
+			Leader:        false,
+			LeaderAddress: leader.Address(),
+			LeaderTls:     leader.IsTLS(),
+			Secret:        []byte(secret),
+			Force:         force,
+			Retries:       uint32(retries),
+		},
+		JoinExisting: joinExisting,
 	}
 	return c.client.InitReshare(ctx.Background(), request)
 }
 
 // InitDKGLeader sets up the node to be ready for a first DKG protocol.
+// A non zero genesisTime, in seconds since the epoch, is used as the genesis
+// time of the chain instead of the one derived from the offset.
 // groupPart
 // NOTE: only group referral via filesystem path is supported at the moment.
 // XXX Might be best to move to core/
 func (c *ControlClient) InitDKGLeader(nodes, threshold int,
-	beaconPeriod, catchupPeriod, timeout time.Duration,
+	beaconPeriod, catchupPeriod, timeout, waitTimeout time.Duration,
 	entropy *control.EntropyInfo,
 	secret string,
-	offset int) (*control.GroupPacket, error) {
+	offset int,
+	genesisTime int64,
+	force bool,
+	retries int) (*control.GroupPacket, error) {
 	request := &control.InitDKGPacket{
 		Info: &control.SetupInfoPacket{
 			Nodes:        uint32(nodes),
 			Threshold:    uint32(threshold),
 			Leader:       true,
 			Timeout:      uint32(timeout.Seconds()),
+			WaitTimeout:  uint32(waitTimeout.Seconds()),
 			Secret:       []byte(secret),
 			BeaconOffset: uint32(offset),
+			Force:        force,
+			Retries:      uint32(retries),
 		},
 		Entropy:       entropy,
 		BeaconPeriod:  uint32(beaconPeriod.Seconds()),
 		CatchupPeriod: uint32(catchupPeriod.Seconds()),
+		GenesisTime:   genesisTime,
 	}
 	return c.client.InitDKG(ctx.Background(), request)
 }
 
 // InitDKG sets up the node to be ready for a first DKG protocol.
-func (c *ControlClient) InitDKG(leader Peer, entropy *control.EntropyInfo, secret string) (*control.GroupPacket, error) {
+func (c *ControlClient) InitDKG(leader Peer, entropy *control.EntropyInfo, secret string, retries int) (*control.GroupPacket, error) {
 	request := &control.InitDKGPacket{
 		Info: &control.SetupInfoPacket{
 			Leader:        false,
 			LeaderAddress: leader.Address(),
 			LeaderTls:     leader.IsTLS(),
 			Secret:        []byte(secret),
+			Retries:       uint32(retries),
 		},
 		Entropy: entropy,
 	}
 	return c.client.InitDKG(ctx.Background(), request)
 }
 
+// ObserveDKG sets up the node to observe the DKG run by the leader: the node
+// is listed in the group as an observer, logs the DKG packets and stores the
+// beacons of the chain but does not receive a share.
+func (c *ControlClient) ObserveDKG(leader Peer, secret string) (*control.GroupPacket, error) {
+	request := &control.InitDKGPacket{
+		Info: &control.SetupInfoPacket{
+			Leader:        false,
+			LeaderAddress: leader.Address(),
+			LeaderTls:     leader.IsTLS(),
+			Secret:        []byte(secret),
+			Observer:      true,
+		},
+	}
+	return c.client.InitDKG(ctx.Background(), request)
+}
+
 // Share returns the share of the remote node
 func (c *ControlClient) Share() (*control.ShareResponse, error) {
 	return c.client.Share(ctx.Background(), &control.ShareRequest{})
@@ -183,9 +321,9 @@ func (c *ControlClient) GroupFile() (*control.GroupPacket, error) {
 	return c.client.GroupFile(ctx.Background(), &control.GroupRequest{})
 }
 
-// Shutdown stops the daemon
-func (c *ControlClient) Shutdown() (*control.ShutdownResponse, error) {
-	return c.client.Shutdown(ctx.Background(), &control.ShutdownRequest{})
+// Shutdown stops the daemon, giving in-flight requests up to timeout to finish
+func (c *ControlClient) Shutdown(timeout time.Duration) (*control.ShutdownResponse, error) {
+	return c.client.Shutdown(ctx.Background(), &control.ShutdownRequest{Timeout: uint32(timeout.Seconds())})
 }
 
 const progressFollowQueue = 100
@@ -197,12 +335,13 @@ func (c *ControlClient) StartFollowChain(cc ctx.Context,
 	tls bool,
 	upTo uint64) (outCh chan *control.FollowProgress,
 	errCh chan error, e error) {
-	stream, err := c.client.StartFollowChain(cc, &control.StartFollowRequest{
+	req := &control.StartFollowRequest{
 		InfoHash: hash,
 		Nodes:    nodes,
 		IsTls:    tls,
 		UpTo:     upTo,
-	})
+	}
+	stream, err := c.client.StartFollowChain(withControlBody(cc, req), req)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -235,6 +374,58 @@ func (c *ControlClient) BackupDB(outFile string) error {
 	return err
 }
 
+// Status returns the state of the beacon of the daemon
+func (c *ControlClient) Status() (*control.StatusResponse, error) {
+	return c.client.Status(ctx.Background(), &control.StatusRequest{})
+}
+
+// ReloadCert installs the certificate and key at the given paths in place of
+// the ones of the daemon, which serves them without restarting. The paths must
+// be readable by the daemon.
+func (c *ControlClient) ReloadCert(certPath, keyPath string, backupExisting bool) (*control.ReloadCertResponse, error) {
+	return c.client.ReloadCert(ctx.Background(), &control.ReloadCertRequest{
+		CertPath:       certPath,
+		KeyPath:        keyPath,
+		BackupExisting: backupExisting,
+	})
+}
+
+// UnfreezeBeacon resumes the beacon generation of a frozen daemon
+func (c *ControlClient) UnfreezeBeacon() error {
+	_, err := c.client.UnfreezeBeacon(ctx.Background(), &control.UnfreezeRequest{})
+	return err
+}
+
+// AbortDKG abandons the DKG or resharing in progress on the daemon
+func (c *ControlClient) AbortDKG() error {
+	_, err := c.client.AbortDKG(ctx.Background(), &control.AbortDKGRequest{})
+	return err
+}
+
+// UseKeyFile makes the daemon read its private key from the file at path
+func (c *ControlClient) UseKeyFile(path string) (*control.UseKeyFileResponse, error) {
+	return c.client.UseKeyFile(ctx.Background(), &control.UseKeyFileRequest{Path: path})
+}
+
+// ExcludePeer excludes the node at addr from the aggregation of the partial
+// beacons of the daemon, or includes it back
+func (c *ControlClient) ExcludePeer(addr string, excluded bool) error {
+	var priority uint32 = 1
+	if excluded {
+		priority = 0
+	}
+	_, err := c.client.SetPeerPriority(ctx.Background(), &control.SetPeerPriorityRequest{
+		Address:  addr,
+		Priority: priority,
+	})
+	return err
+}
+
+// ListProtocols returns the randomness beacons run by the daemon
+func (c *ControlClient) ListProtocols() (*control.ListProtocolsResponse, error) {
+	return c.client.ListProtocols(ctx.Background(), &control.ListProtocolsRequest{})
+}
+
 // controlListenAddr parses the control address as specified, into a dialable / listenable address
 func controlListenAddr(listenAddr string) (network, addr string) {
 	if strings.HasPrefix(listenAddr, "unix://") {
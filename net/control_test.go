@@ -1,12 +1,19 @@
 package net
 
 import (
+	"context"
 	"io/ioutil"
+	gnet "net"
 	"os"
+	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 
+	"github.com/drand/drand/protobuf/drand"
 	testnet "github.com/drand/drand/test/net"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
 )
 
 const runtimeGOOSWindows = "windows"
@@ -35,7 +42,8 @@ func TestControlUnix(t *testing.T) {
 	}
 	defer os.RemoveAll(name)
 	s := testnet.EmptyServer{}
-	service := NewTCPGrpcControlListener(&s, "unix://"+name+"/sock")
+	service, err := NewTCPGrpcControlListener(&s, "unix://"+name+"/sock")
+	require.NoError(t, err)
 	client, err := NewControlClient("unix://" + name + "/sock")
 
 	if err != nil {
@@ -45,3 +53,148 @@ func TestControlUnix(t *testing.T) {
 	client.conn.Close()
 	service.lis.Close()
 }
+
+// keyServer answers the control calls needed by the unix socket test
+type keyServer struct {
+	testnet.EmptyServer
+	key []byte
+}
+
+func (s *keyServer) PingPong(context.Context, *drand.Ping) (*drand.Pong, error) {
+	return &drand.Pong{}, nil
+}
+
+func (s *keyServer) PublicKey(context.Context, *drand.PublicKeyRequest) (*drand.PublicKeyResponse, error) {
+	return &drand.PublicKeyResponse{PubKey: s.key}, nil
+}
+
+func TestControlUnixListener(t *testing.T) {
+	if !testable() {
+		t.Skip("Platform does not support unix.")
+	}
+	dir, err := ioutil.TempDir("", "unixctrl")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "control.sock")
+
+	s := &keyServer{key: []byte("public key")}
+	service, err := NewUnixGrpcControlListener(s, path)
+	require.NoError(t, err)
+	go service.Start()
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// the socket can not be taken over while the daemon listens on it
+	_, err = NewUnixGrpcControlListener(s, path)
+	require.Error(t, err)
+
+	conn, err := grpc.Dial(path, grpc.WithInsecure(), grpc.WithContextDialer(func(_ context.Context, addr string) (gnet.Conn, error) {
+		return gnet.Dial("unix", addr)
+	}))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := drand.NewControlClient(conn)
+	_, err = client.PingPong(context.Background(), &drand.Ping{})
+	require.NoError(t, err)
+	resp, err := client.PublicKey(context.Background(), &drand.PublicKeyRequest{})
+	require.NoError(t, err)
+	require.Equal(t, s.key, resp.GetPubKey())
+
+	// the drand control client reaches the socket through its unix:// address
+	ctrl, err := NewControlClient("unix://" + path)
+	require.NoError(t, err)
+	defer ctrl.conn.Close()
+	require.NoError(t, ctrl.Ping())
+	service.Stop()
+
+	// a socket file left over by a stopped daemon is replaced
+	lis, err := gnet.Listen("unix", path)
+	require.NoError(t, err)
+	lis.(*gnet.UnixListener).SetUnlinkOnClose(false)
+	lis.Close()
+	_, err = os.Stat(path)
+	require.NoError(t, err)
+	service, err = NewUnixGrpcControlListener(s, path)
+	require.NoError(t, err)
+	service.Stop()
+
+	// any other file is not removed
+	other := filepath.Join(dir, "control.toml")
+	require.NoError(t, ioutil.WriteFile(other, []byte("keep"), 0600))
+	_, err = NewUnixGrpcControlListener(s, other)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not a socket")
+	content, err := ioutil.ReadFile(other)
+	require.NoError(t, err)
+	require.Equal(t, "keep", string(content))
+
+	// nor a symlink, whatever it points to
+	link := filepath.Join(dir, "link.sock")
+	require.NoError(t, os.Symlink(other, link))
+	_, err = NewUnixGrpcControlListener(s, link)
+	require.Error(t, err)
+	_, err = os.Lstat(link)
+	require.NoError(t, err)
+}
+
+func TestControlListenerError(t *testing.T) {
+	s := testnet.EmptyServer{}
+	service, err := NewTCPGrpcControlListener(&s, "127.0.0.1:0")
+	require.NoError(t, err)
+	defer service.Stop()
+
+	// the address is already in use
+	_, err = NewTCPGrpcControlListener(&s, service.lis.Addr().String())
+	require.Error(t, err)
+}
+
+// slowPingServer answers a ping only after the given delay
+type slowPingServer struct {
+	testnet.EmptyServer
+	delay   time.Duration
+	started chan bool
+}
+
+func (s *slowPingServer) PingPong(context.Context, *drand.Ping) (*drand.Pong, error) {
+	s.started <- true
+	time.Sleep(s.delay)
+	return &drand.Pong{}, nil
+}
+
+func TestControlGracefulStop(t *testing.T) {
+	s := &slowPingServer{delay: 500 * time.Millisecond, started: make(chan bool, 1)}
+	service, err := NewTCPGrpcControlListener(s, "127.0.0.1:0")
+	require.NoError(t, err)
+	go service.Start()
+
+	client, err := NewControlClient(service.lis.Addr().String())
+	require.NoError(t, err)
+	defer client.conn.Close()
+
+	pingErr := make(chan error, 1)
+	go func() { pingErr <- client.Ping() }()
+	<-s.started
+
+	// the in-flight ping must complete before the listener stops
+	require.NoError(t, service.GracefulStop(2*time.Second))
+	select {
+	case err := <-pingErr:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("ping did not complete")
+	}
+
+	// with a timeout shorter than the request, the listener is forced to stop
+	service, err = NewTCPGrpcControlListener(s, "127.0.0.1:0")
+	require.NoError(t, err)
+	go service.Start()
+	client2, err := NewControlClient(service.lis.Addr().String())
+	require.NoError(t, err)
+	defer client2.conn.Close()
+	go func() { pingErr <- client2.Ping() }()
+	<-s.started
+	require.Error(t, service.GracefulStop(50*time.Millisecond))
+	require.Error(t, <-pingErr)
+}
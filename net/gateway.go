@@ -2,10 +2,16 @@ package net
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"os"
+	"strings"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
 
 	"github.com/drand/drand/protobuf/drand"
 )
@@ -33,6 +39,21 @@ func (g *PrivateGateway) StopAll(ctx context.Context) {
 	}
 }
 
+// ReloadCert replaces the TLS certificate served by the gateway with the one at
+// the given paths, without restarting it. It fails if the gateway does not use
+// TLS.
+func (g *PrivateGateway) ReloadCert(certPath, keyPath string) error {
+	return reloadCert(g.Listener, certPath, keyPath)
+}
+
+func reloadCert(l Listener, certPath, keyPath string) error {
+	r, ok := l.(certReloader)
+	if !ok {
+		return errors.New("gateway: can't reload the certificate of an insecure listener")
+	}
+	return r.ReloadCert(certPath, keyPath)
+}
+
 // Listener is the active listener for incoming requests.
 type Listener interface {
 	Start()
@@ -47,16 +68,51 @@ type Service interface {
 	drand.ProtocolServer
 }
 
+// Chain composes the given middlewares into a single one, analogous to HTTP
+// middleware chains. The first middleware is the outermost one, i.e. it sees
+// the requests first. Without any middleware, the service is left unchanged.
+func Chain(middlewares ...func(Service) Service) func(Service) Service {
+	return func(s Service) Service {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			s = middlewares[i](s)
+		}
+		return s
+	}
+}
+
+// privateKeepalive makes the private gateway ping idle connections so the ones
+// to dead peers are closed and don't count against the connection limit.
+var privateKeepalive = keepalive.ServerParameters{
+	Time:    1 * time.Minute,
+	Timeout: 20 * time.Second,
+}
+
 // NewGRPCPrivateGateway returns a grpc gateway listening on "listen" for the
 // public methods, listening on "port" for the control methods, using the given
-// Service s with the given options.
+// Service s with the given options. The gateway accepts at most maxConns
+// simultaneous connections, or any number if maxConns <= 0. It rejects the
+// requests larger than maxRecvMsgSize bytes with a ResourceExhausted status,
+// and fails the responses larger than maxSendMsgSize bytes; a size <= 0 keeps
+// the gRPC default.
 func NewGRPCPrivateGateway(ctx context.Context,
 	listen, certPath, keyPath string,
 	certs *CertManager,
 	s Service,
 	insecure bool,
+	maxConns, maxRecvMsgSize, maxSendMsgSize int,
 	opts ...grpc.DialOption) (*PrivateGateway, error) {
-	l, err := NewGRPCListenerForPrivate(ctx, listen, certPath, keyPath, s, insecure, grpc.ConnectionTimeout(time.Second))
+	lis, err := net.Listen("tcp", listen)
+	if err != nil {
+		return nil, err
+	}
+	serverOpts := []grpc.ServerOption{grpc.ConnectionTimeout(time.Second), grpc.KeepaliveParams(privateKeepalive)}
+	if maxRecvMsgSize > 0 {
+		serverOpts = append(serverOpts, grpc.MaxRecvMsgSize(maxRecvMsgSize))
+	}
+	if maxSendMsgSize > 0 {
+		serverOpts = append(serverOpts, grpc.MaxSendMsgSize(maxSendMsgSize))
+	}
+	l, err := newGRPCListenerForPrivate(LimitListener(lis, maxConns), certPath, keyPath, s, insecure, serverOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -78,6 +134,7 @@ func NewGRPCPrivateGateway(ctx context.Context,
 // The gateway fixes all drand functionalities offered by drand.
 type PublicGateway struct {
 	Listener
+	files *staticFiles
 }
 
 // StartAll starts the control and public functionalities of the node
@@ -90,18 +147,54 @@ func (g *PublicGateway) StopAll(ctx context.Context) {
 	g.Listener.Stop(ctx)
 }
 
+// ReloadCert replaces the TLS certificate served by the gateway with the one at
+// the given paths, without restarting it. It fails if the gateway does not use
+// TLS.
+func (g *PublicGateway) ReloadCert(certPath, keyPath string) error {
+	return reloadCert(g.Listener, certPath, keyPath)
+}
+
+// ServeFile serves the file at filePath, with the given content type, at
+// urlPath, in place of the handler of the gateway. The file is read again from
+// disk at most every 10 seconds, so it can be updated without restarting. It
+// replaces the file mounted at urlPath, if any.
+func (g *PublicGateway) ServeFile(urlPath, filePath, contentType string) error {
+	if !strings.HasPrefix(urlPath, "/") {
+		return fmt.Errorf("gateway: url path %q must start with /", urlPath)
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return fmt.Errorf("gateway: can't serve file: %w", err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("gateway: can't serve directory %s", filePath)
+	}
+	g.files.mount(urlPath, &staticFile{path: filePath, contentType: contentType})
+	return nil
+}
+
 // NewRESTPublicGateway returns a grpc gateway listening on "listen" for the
 // public methods, listening on "port" for the control methods, using the given
-// Service s with the given options.
+// Service s with the given options. If wrap is not nil, the handler and the
+// files served with ServeFile both go through the handler it returns.
 func NewRESTPublicGateway(
 	ctx context.Context,
 	listen, certPath, keyPath string,
 	certs *CertManager,
 	handler http.Handler,
+	wrap func(http.Handler) (http.Handler, error),
 	insecure bool) (*PublicGateway, error) {
-	l, err := NewRESTListenerForPublic(ctx, listen, certPath, keyPath, handler, insecure)
+	files := newStaticFiles(handler)
+	var h http.Handler = files
+	if wrap != nil {
+		var err error
+		if h, err = wrap(files); err != nil {
+			return nil, err
+		}
+	}
+	l, err := NewRESTListenerForPublic(ctx, listen, certPath, keyPath, h, insecure)
 	if err != nil {
 		return nil, err
 	}
-	return &PublicGateway{Listener: l}, nil
+	return &PublicGateway{Listener: l, files: files}, nil
 }
@@ -2,7 +2,11 @@ package net
 
 import (
 	"context"
+	"crypto/tls"
+	"encoding/pem"
 	"fmt"
+	"io/ioutil"
+	"net"
 	"net/http"
 	"os"
 	"path"
@@ -14,6 +18,9 @@ import (
 	testnet "github.com/drand/drand/test/net"
 	"github.com/kabukky/httpscerts"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type testPeer struct {
@@ -125,3 +132,182 @@ func testListenerTLS(t *testing.T) {
 	expected := &drand.PublicRandResponse{Round: randServer.round}
 	require.Equal(t, expected.GetRound(), resp.GetRound())
 }
+
+type blockingStreamServer struct {
+	*testnet.EmptyServer
+	started chan struct{}
+}
+
+func (b *blockingStreamServer) PublicRandStream(in *drand.PublicRandRequest, stream drand.Public_PublicRandStreamServer) error {
+	close(b.started)
+	<-stream.Context().Done()
+	return stream.Context().Err()
+}
+
+func TestListenerStopTimeout(t *testing.T) {
+	ctx := context.Background()
+	server := &blockingStreamServer{started: make(chan struct{})}
+	lis, err := NewGRPCListenerForPrivate(ctx, "localhost:", "", "", server, true)
+	require.NoError(t, err)
+	go lis.Start()
+
+	client := NewGrpcClient()
+	peer := &testPeer{lis.Addr(), false}
+	ch, err := client.PublicRandStream(ctx, peer, &drand.PublicRandRequest{})
+	require.NoError(t, err)
+	select {
+	case <-server.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream not started")
+	}
+
+	// the stream never finishes by itself so it is closed once the timeout is
+	// exceeded
+	timeout := 500 * time.Millisecond
+	stopCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	start := time.Now()
+	lis.Stop(stopCtx)
+	elapsed := time.Since(start)
+	require.True(t, elapsed >= timeout-50*time.Millisecond, "stopped too early: %s", elapsed)
+	require.True(t, elapsed < timeout+time.Second, "stopped too late: %s", elapsed)
+
+	select {
+	case _, ok := <-ch:
+		require.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("stream not terminated")
+	}
+}
+
+type recordingService struct {
+	Service
+	name  string
+	calls *[]string
+}
+
+func (r *recordingService) PingPong(c context.Context, in *drand.Ping) (*drand.Pong, error) {
+	*r.calls = append(*r.calls, r.name)
+	return r.Service.PingPong(c, in)
+}
+
+func TestChain(t *testing.T) {
+	var calls []string
+	record := func(name string) func(Service) Service {
+		return func(s Service) Service {
+			return &recordingService{Service: s, name: name, calls: &calls}
+		}
+	}
+	base := &testnet.EmptyServer{}
+
+	// no middleware leaves the service untouched
+	require.Equal(t, Service(base), Chain()(base))
+
+	s := Chain(record("first"), record("second"), record("third"))(base)
+	_, err := s.PingPong(context.Background(), &drand.Ping{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"first", "second", "third"}, calls)
+}
+
+// readUntilError reads from the connection until it fails or the deadline is
+// reached and returns the error.
+func readUntilError(t *testing.T, conn net.Conn) error {
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(200*time.Millisecond)))
+	buff := make([]byte, 1024)
+	for {
+		if _, err := conn.Read(buff); err != nil {
+			return err
+		}
+	}
+}
+
+func TestPrivateGatewayMaxConnections(t *testing.T) {
+	max := 2
+	ctx := context.Background()
+	gw, err := NewGRPCPrivateGateway(ctx, "localhost:0", "", "", nil, &testnet.EmptyServer{}, true, max, 0, 0)
+	require.NoError(t, err)
+	gw.StartAll()
+	defer gw.StopAll(ctx)
+
+	// connections under the limit are kept open
+	for i := 0; i < max; i++ {
+		conn, err := net.Dial("tcp", gw.Addr())
+		require.NoError(t, err)
+		defer conn.Close()
+		nerr, ok := readUntilError(t, conn).(net.Error)
+		require.True(t, ok && nerr.Timeout(), "connection %d should be open: %v", i, nerr)
+	}
+
+	// the next one is refused
+	conn, err := net.Dial("tcp", gw.Addr())
+	require.NoError(t, err)
+	defer conn.Close()
+	err = readUntilError(t, conn)
+	nerr, ok := err.(net.Error)
+	require.False(t, ok && nerr.Timeout(), "connection above the limit should be refused: %v", err)
+}
+
+func TestPrivateGatewayMaxRecvMsgSize(t *testing.T) {
+	max := 1024
+	ctx := context.Background()
+	gw, err := NewGRPCPrivateGateway(ctx, "localhost:0", "", "", nil, &testnet.EmptyServer{}, true, 0, max, 0)
+	require.NoError(t, err)
+	gw.StartAll()
+	defer gw.StopAll(ctx)
+
+	conn, err := grpc.Dial(gw.Addr(), grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+	client := drand.NewProtocolClient(conn)
+
+	_, err = client.PartialBeacon(ctx, &drand.PartialBeaconPacket{PartialSig: make([]byte, max/2)})
+	require.NotEqual(t, codes.ResourceExhausted, status.Code(err), "request under the limit: %v", err)
+
+	_, err = client.PartialBeacon(ctx, &drand.PartialBeaconPacket{PartialSig: make([]byte, 2*max)})
+	require.Equal(t, codes.ResourceExhausted, status.Code(err), "request above the limit: %v", err)
+}
+
+func TestGatewayReloadCert(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reload-cert")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	writeCert := func(name string) (certPath, keyPath string, certPEM []byte) {
+		certPEM, keyPEM, err := SelfSignedCert("127.0.0.1", time.Hour)
+		require.NoError(t, err)
+		certPath = path.Join(dir, name+".crt")
+		keyPath = path.Join(dir, name+".key")
+		require.NoError(t, ioutil.WriteFile(certPath, certPEM, 0600))
+		require.NoError(t, ioutil.WriteFile(keyPath, keyPEM, 0600))
+		return certPath, keyPath, certPEM
+	}
+	servedCert := func(addr string) []byte {
+		conn, err := tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true})
+		require.NoError(t, err)
+		defer conn.Close()
+		return conn.ConnectionState().PeerCertificates[0].Raw
+	}
+	certPath, keyPath, certPEM := writeCert("old")
+	newCertPath, newKeyPath, newCertPEM := writeCert("new")
+
+	ctx := context.Background()
+	gw, err := NewGRPCPrivateGateway(ctx, "127.0.0.1:0", certPath, keyPath, NewCertManager(), &testnet.EmptyServer{}, false, 0, 0, 0)
+	require.NoError(t, err)
+	gw.StartAll()
+	defer gw.StopAll(ctx)
+
+	block, _ := pem.Decode(certPEM)
+	require.Equal(t, block.Bytes, servedCert(gw.Addr()))
+
+	require.NoError(t, gw.ReloadCert(newCertPath, newKeyPath))
+	block, _ = pem.Decode(newCertPEM)
+	require.Equal(t, block.Bytes, servedCert(gw.Addr()))
+
+	// an invalid pair leaves the served certificate untouched
+	require.Error(t, gw.ReloadCert(newCertPath, keyPath))
+	require.Equal(t, block.Bytes, servedCert(gw.Addr()))
+
+	insecure, err := NewGRPCPrivateGateway(ctx, "127.0.0.1:0", "", "", nil, &testnet.EmptyServer{}, true, 0, 0, 0)
+	require.NoError(t, err)
+	defer insecure.StopAll(ctx)
+	require.Error(t, insecure.ReloadCert(newCertPath, newKeyPath))
+}
@@ -0,0 +1,71 @@
+package net
+
+import (
+	"net"
+	"sync"
+)
+
+// limitListener is a net.Listener that accepts at most max simultaneous
+// connections. Connections above the limit are refused right away with a TCP
+// reset instead of being queued, so a peer can not exhaust the file
+// descriptors of the node.
+type limitListener struct {
+	net.Listener
+	sync.Mutex
+	max    int
+	active int
+}
+
+// LimitListener returns a listener that accepts at most max simultaneous
+// connections from l. A max <= 0 means no limit and l is returned as is.
+func LimitListener(l net.Listener, max int) net.Listener {
+	if max <= 0 {
+		return l
+	}
+	return &limitListener{Listener: l, max: max}
+}
+
+// Accept returns the next connection under the limit, refusing the ones above.
+func (l *limitListener) Accept() (net.Conn, error) {
+	for {
+		c, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		l.Lock()
+		if l.active >= l.max {
+			l.Unlock()
+			refuse(c)
+			continue
+		}
+		l.active++
+		l.Unlock()
+		return &limitConn{Conn: c, release: l.release}, nil
+	}
+}
+
+func (l *limitListener) release() {
+	l.Lock()
+	defer l.Unlock()
+	l.active--
+}
+
+// refuse closes the connection with a reset so the peer is told right away.
+func refuse(c net.Conn) {
+	if tcp, ok := c.(*net.TCPConn); ok {
+		_ = tcp.SetLinger(0)
+	}
+	_ = c.Close()
+}
+
+type limitConn struct {
+	net.Conn
+	once    sync.Once
+	release func()
+}
+
+func (c *limitConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(c.release)
+	return err
+}
@@ -3,8 +3,11 @@ package net
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"net"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/drand/drand/log"
 	"github.com/drand/drand/metrics"
@@ -34,10 +37,21 @@ func NewGRPCListenerForPrivate(
 	if err != nil {
 		return nil, err
 	}
+	return newGRPCListenerForPrivate(lis, certPath, keyPath, s, insecure, opts...)
+}
 
+// newGRPCListenerForPrivate serves the Public and Protocol APIs over GRPC on
+// the given raw TCP listener.
+func newGRPCListenerForPrivate(
+	lis net.Listener,
+	certPath, keyPath string,
+	s Service,
+	insecure bool,
+	opts ...grpc.ServerOption) (Listener, error) {
 	if !insecure {
 		grpcCreds, err := credentials.NewServerTLSFromFile(certPath, keyPath)
 		if err != nil {
+			_ = lis.Close()
 			return nil, err
 		}
 		opts = append(opts, grpc.Creds(grpcCreds))
@@ -59,12 +73,14 @@ func NewGRPCListenerForPrivate(
 	} else {
 		x509KeyPair, err := tls.LoadX509KeyPair(certPath, keyPath)
 		if err != nil {
+			_ = lis.Close()
 			return nil, err
 		}
 
 		gr := &restListener{
-			restServer: buildTLSServer(grpcServer, &x509KeyPair),
+			certs: &certStore{cert: &x509KeyPair},
 		}
+		gr.restServer = buildTLSServer(grpcServer, gr.certs)
 		gr.lis = tls.NewListener(lis, gr.restServer.TLSConfig)
 		g = gr
 	}
@@ -96,16 +112,18 @@ func NewRESTListenerForPublic(
 	} else {
 		x509KeyPair, err := tls.LoadX509KeyPair(certPath, keyPath)
 		if err != nil {
+			_ = lis.Close()
 			return nil, err
 		}
 
-		g.restServer = buildTLSServer(handler, &x509KeyPair)
+		g.certs = &certStore{cert: &x509KeyPair}
+		g.restServer = buildTLSServer(handler, g.certs)
 		g.lis = tls.NewListener(lis, g.restServer.TLSConfig)
 	}
 	return g, nil
 }
 
-func buildTLSServer(httpHandler http.Handler, x509KeyPair *tls.Certificate) *http.Server {
+func buildTLSServer(httpHandler http.Handler, certs *certStore) *http.Server {
 	return &http.Server{
 		Handler: httpHandler,
 		TLSConfig: &tls.Config{
@@ -134,15 +152,51 @@ func buildTLSServer(httpHandler http.Handler, x509KeyPair *tls.Certificate) *htt
 			},
 			// End Cloudflare recommendations.
 
-			Certificates: []tls.Certificate{*x509KeyPair},
-			NextProtos:   []string{"h2"},
+			GetCertificate: certs.getCertificate,
+			NextProtos:     []string{"h2"},
 		},
 	}
 }
 
+// certStore holds the certificate served by a TLS listener so it can be
+// replaced while the listener runs.
+type certStore struct {
+	sync.RWMutex
+	cert *tls.Certificate
+}
+
+func (c *certStore) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.RLock()
+	defer c.RUnlock()
+	return c.cert, nil
+}
+
+// certReloader is implemented by the listeners serving TLS
+type certReloader interface {
+	ReloadCert(certPath, keyPath string) error
+}
+
 type restListener struct {
 	restServer *http.Server
 	lis        net.Listener
+	// certs is nil if the listener does not use TLS
+	certs *certStore
+}
+
+// ReloadCert loads the certificate and key at the given paths and serves them
+// on the new connections. The established connections are left untouched.
+func (g *restListener) ReloadCert(certPath, keyPath string) error {
+	if g.certs == nil {
+		return errors.New("listener: can't reload the certificate of an insecure listener")
+	}
+	cert, err := LoadCertificate(certPath, keyPath, time.Now())
+	if err != nil {
+		return err
+	}
+	g.certs.Lock()
+	defer g.certs.Unlock()
+	g.certs.cert = cert
+	return nil
 }
 
 func (g *restListener) Addr() string {
@@ -153,12 +207,20 @@ func (g *restListener) Start() {
 	_ = g.restServer.Serve(g.lis)
 }
 
+// Stop waits for in-flight requests to finish until the deadline of ctx, after
+// which the remaining connections are closed. Without a deadline on ctx, the
+// connections are closed right away since streams may never end.
 func (g *restListener) Stop(ctx context.Context) {
 	if err := g.lis.Close(); err != nil {
 		log.DefaultLogger().Debug("grpc listener", "grpc shutdown", "err", err)
 	}
+	if _, ok := ctx.Deadline(); !ok {
+		_ = g.restServer.Close()
+		return
+	}
 	if err := g.restServer.Shutdown(ctx); err != nil {
 		log.DefaultLogger().Debug("grpc listener", "http shutdown", "err", err)
+		_ = g.restServer.Close()
 	}
 }
 
@@ -178,7 +240,23 @@ func (g *grpcListener) Start() {
 	}()
 }
 
+// Stop waits for in-flight requests to finish until the deadline of ctx, after
+// which the remaining connections are closed. Without a deadline on ctx, the
+// connections are closed right away.
 func (g *grpcListener) Stop(ctx context.Context) {
 	_ = g.lis.Close()
-	g.grpcServer.Stop()
+	if _, ok := ctx.Deadline(); !ok {
+		g.grpcServer.Stop()
+		return
+	}
+	done := make(chan struct{})
+	go func() {
+		g.grpcServer.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		g.grpcServer.Stop()
+	}
 }
@@ -0,0 +1,191 @@
+package net
+
+import (
+	"context"
+	"runtime/debug"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/drand/drand/log"
+	"github.com/drand/drand/protobuf/drand"
+)
+
+// errInternal is returned to the caller of a method that panicked. The panic
+// itself is only logged, it is not sent to the caller.
+var errInternal = status.Error(codes.Internal, "internal server error")
+
+// WithPanicRecovery returns a service that recovers from a panic of any method
+// of s: the panic is logged with its stack trace and the call fails with an
+// Internal status, instead of tearing down the connection. If onPanic is not
+// nil, it is then called with the name of the method that panicked.
+func WithPanicRecovery(s Service, l log.Logger, onPanic func(method string)) Service {
+	return &recoveryService{Service: s, l: l, onPanic: onPanic}
+}
+
+type recoveryService struct {
+	Service
+	l       log.Logger
+	onPanic func(method string)
+}
+
+// recoverPanic must be deferred by the methods, so it can stop the panic.
+func (r *recoveryService) recoverPanic(method string, err *error) {
+	if p := recover(); p != nil {
+		r.l.Error("service", "panic", "method", method, "panic", p, "stack", string(debug.Stack()))
+		*err = errInternal
+		if r.onPanic != nil {
+			r.onPanic(method)
+		}
+	}
+}
+
+func (r *recoveryService) PublicRand(c context.Context, in *drand.PublicRandRequest) (out *drand.PublicRandResponse, err error) {
+	defer r.recoverPanic("PublicRand", &err)
+	return r.Service.PublicRand(c, in)
+}
+
+func (r *recoveryService) PublicRandStream(in *drand.PublicRandRequest, stream drand.Public_PublicRandStreamServer) (err error) {
+	defer r.recoverPanic("PublicRandStream", &err)
+	return r.Service.PublicRandStream(in, stream)
+}
+
+func (r *recoveryService) PrivateRand(c context.Context, in *drand.PrivateRandRequest) (out *drand.PrivateRandResponse, err error) {
+	defer r.recoverPanic("PrivateRand", &err)
+	return r.Service.PrivateRand(c, in)
+}
+
+func (r *recoveryService) ChainInfo(c context.Context, in *drand.ChainInfoRequest) (out *drand.ChainInfoPacket, err error) {
+	defer r.recoverPanic("ChainInfo", &err)
+	return r.Service.ChainInfo(c, in)
+}
+
+func (r *recoveryService) Home(c context.Context, in *drand.HomeRequest) (out *drand.HomeResponse, err error) {
+	defer r.recoverPanic("Home", &err)
+	return r.Service.Home(c, in)
+}
+
+func (r *recoveryService) GetIdentity(c context.Context, in *drand.IdentityRequest) (out *drand.Identity, err error) {
+	defer r.recoverPanic("GetIdentity", &err)
+	return r.Service.GetIdentity(c, in)
+}
+
+func (r *recoveryService) SignalDKGParticipant(c context.Context, in *drand.SignalDKGPacket) (out *drand.Empty, err error) {
+	defer r.recoverPanic("SignalDKGParticipant", &err)
+	return r.Service.SignalDKGParticipant(c, in)
+}
+
+func (r *recoveryService) AnnounceJoin(c context.Context, in *drand.SignalDKGPacket) (out *drand.Empty, err error) {
+	defer r.recoverPanic("AnnounceJoin", &err)
+	return r.Service.AnnounceJoin(c, in)
+}
+
+func (r *recoveryService) PushDKGInfo(c context.Context, in *drand.DKGInfoPacket) (out *drand.Empty, err error) {
+	defer r.recoverPanic("PushDKGInfo", &err)
+	return r.Service.PushDKGInfo(c, in)
+}
+
+func (r *recoveryService) BroadcastDKG(c context.Context, in *drand.DKGPacket) (out *drand.Empty, err error) {
+	defer r.recoverPanic("BroadcastDKG", &err)
+	return r.Service.BroadcastDKG(c, in)
+}
+
+func (r *recoveryService) PartialBeacon(c context.Context, in *drand.PartialBeaconPacket) (out *drand.Empty, err error) {
+	defer r.recoverPanic("PartialBeacon", &err)
+	return r.Service.PartialBeacon(c, in)
+}
+
+func (r *recoveryService) SyncChain(in *drand.SyncRequest, stream drand.Protocol_SyncChainServer) (err error) {
+	defer r.recoverPanic("SyncChain", &err)
+	return r.Service.SyncChain(in, stream)
+}
+
+func (r *recoveryService) PushSync(in *drand.SyncRequest, stream drand.Protocol_PushSyncServer) (err error) {
+	defer r.recoverPanic("PushSync", &err)
+	return r.Service.PushSync(in, stream)
+}
+
+func (r *recoveryService) GroupFile(c context.Context, in *drand.GroupRequest) (out *drand.GroupPacket, err error) {
+	defer r.recoverPanic("GroupFile", &err)
+	return r.Service.GroupFile(c, in)
+}
+
+func (r *recoveryService) PingPong(c context.Context, in *drand.Ping) (out *drand.Pong, err error) {
+	defer r.recoverPanic("PingPong", &err)
+	return r.Service.PingPong(c, in)
+}
+
+func (r *recoveryService) InitDKG(c context.Context, in *drand.InitDKGPacket) (out *drand.GroupPacket, err error) {
+	defer r.recoverPanic("InitDKG", &err)
+	return r.Service.InitDKG(c, in)
+}
+
+func (r *recoveryService) InitReshare(c context.Context, in *drand.InitResharePacket) (out *drand.GroupPacket, err error) {
+	defer r.recoverPanic("InitReshare", &err)
+	return r.Service.InitReshare(c, in)
+}
+
+func (r *recoveryService) Share(c context.Context, in *drand.ShareRequest) (out *drand.ShareResponse, err error) {
+	defer r.recoverPanic("Share", &err)
+	return r.Service.Share(c, in)
+}
+
+func (r *recoveryService) PublicKey(c context.Context, in *drand.PublicKeyRequest) (out *drand.PublicKeyResponse, err error) {
+	defer r.recoverPanic("PublicKey", &err)
+	return r.Service.PublicKey(c, in)
+}
+
+func (r *recoveryService) PrivateKey(c context.Context, in *drand.PrivateKeyRequest) (out *drand.PrivateKeyResponse, err error) {
+	defer r.recoverPanic("PrivateKey", &err)
+	return r.Service.PrivateKey(c, in)
+}
+
+func (r *recoveryService) Shutdown(c context.Context, in *drand.ShutdownRequest) (out *drand.ShutdownResponse, err error) {
+	defer r.recoverPanic("Shutdown", &err)
+	return r.Service.Shutdown(c, in)
+}
+
+func (r *recoveryService) StartFollowChain(in *drand.StartFollowRequest, stream drand.Control_StartFollowChainServer) (err error) {
+	defer r.recoverPanic("StartFollowChain", &err)
+	return r.Service.StartFollowChain(in, stream)
+}
+
+func (r *recoveryService) BackupDatabase(c context.Context, in *drand.BackupDBRequest) (out *drand.BackupDBResponse, err error) {
+	defer r.recoverPanic("BackupDatabase", &err)
+	return r.Service.BackupDatabase(c, in)
+}
+
+func (r *recoveryService) Status(c context.Context, in *drand.StatusRequest) (out *drand.StatusResponse, err error) {
+	defer r.recoverPanic("Status", &err)
+	return r.Service.Status(c, in)
+}
+
+func (r *recoveryService) ListProtocols(c context.Context, in *drand.ListProtocolsRequest) (out *drand.ListProtocolsResponse, err error) {
+	defer r.recoverPanic("ListProtocols", &err)
+	return r.Service.ListProtocols(c, in)
+}
+
+func (r *recoveryService) ReloadCert(c context.Context, in *drand.ReloadCertRequest) (out *drand.ReloadCertResponse, err error) {
+	defer r.recoverPanic("ReloadCert", &err)
+	return r.Service.ReloadCert(c, in)
+}
+
+func (r *recoveryService) UnfreezeBeacon(c context.Context, in *drand.UnfreezeRequest) (out *drand.UnfreezeResponse, err error) {
+	defer r.recoverPanic("UnfreezeBeacon", &err)
+	return r.Service.UnfreezeBeacon(c, in)
+}
+
+func (r *recoveryService) AbortDKG(c context.Context, in *drand.AbortDKGRequest) (out *drand.AbortDKGResponse, err error) {
+	defer r.recoverPanic("AbortDKG", &err)
+	return r.Service.AbortDKG(c, in)
+}
+
+func (r *recoveryService) UseKeyFile(c context.Context, in *drand.UseKeyFileRequest) (out *drand.UseKeyFileResponse, err error) {
+	defer r.recoverPanic("UseKeyFile", &err)
+	return r.Service.UseKeyFile(c, in)
+}
+
+func (r *recoveryService) SetPeerPriority(c context.Context, in *drand.SetPeerPriorityRequest) (out *drand.SetPeerPriorityResponse, err error) {
+	defer r.recoverPanic("SetPeerPriority", &err)
+	return r.Service.SetPeerPriority(c, in)
+}
@@ -0,0 +1,64 @@
+package net
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/drand/drand/log"
+	"github.com/drand/drand/protobuf/drand"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// panicService panics on every call, as the embedded service is nil
+type panicService struct {
+	Service
+}
+
+func TestPanicRecovery(t *testing.T) {
+	var out bytes.Buffer
+	var mu sync.Mutex
+	var panicked []string
+	onPanic := func(method string) {
+		mu.Lock()
+		defer mu.Unlock()
+		panicked = append(panicked, method)
+	}
+	s := WithPanicRecovery(&panicService{}, log.NewLogger(log.LoggerTo(&out), log.LogDebug), onPanic)
+
+	_, err := s.PartialBeacon(context.Background(), new(drand.PartialBeaconPacket))
+	require.Equal(t, codes.Internal, status.Code(err))
+	require.Contains(t, out.String(), "method=PartialBeacon")
+	require.Contains(t, out.String(), "stack=")
+
+	err = s.SyncChain(new(drand.SyncRequest), &testSyncStream{ctx: context.Background()})
+	require.Equal(t, codes.Internal, status.Code(err))
+
+	_, err = s.Status(context.Background(), new(drand.StatusRequest))
+	require.Equal(t, codes.Internal, status.Code(err))
+	mu.Lock()
+	require.Equal(t, []string{"PartialBeacon", "SyncChain", "Status"}, panicked)
+	mu.Unlock()
+
+	// the connection survives the panics
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	drand.RegisterPublicServer(server, s)
+	go func() { _ = server.Serve(lis) }()
+	defer server.Stop()
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+	client := drand.NewPublicClient(conn)
+	for i := 0; i < 2; i++ {
+		_, err = client.PublicRand(context.Background(), new(drand.PublicRandRequest))
+		require.Equal(t, codes.Internal, status.Code(err))
+		require.Equal(t, "internal server error", status.Convert(err).Message())
+	}
+}
@@ -0,0 +1,85 @@
+package net
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// staticFileCacheTime is how long the content of a file served with ServeFile
+// is kept in memory before it is read again from disk.
+var staticFileCacheTime = 10 * time.Second
+
+// staticFiles serves the files mounted with ServeFile, and passes the other
+// requests to the next handler.
+type staticFiles struct {
+	sync.RWMutex
+	files map[string]*staticFile
+	next  http.Handler
+}
+
+func newStaticFiles(next http.Handler) *staticFiles {
+	return &staticFiles{files: make(map[string]*staticFile), next: next}
+}
+
+func (s *staticFiles) mount(urlPath string, f *staticFile) {
+	s.Lock()
+	defer s.Unlock()
+	s.files[urlPath] = f
+}
+
+func (s *staticFiles) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.RLock()
+	f, ok := s.files[r.URL.Path]
+	s.RUnlock()
+	if !ok {
+		s.next.ServeHTTP(w, r)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	content, err := f.content()
+	if os.IsNotExist(err) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", f.contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(content)
+	}
+}
+
+// staticFile is a file served with ServeFile, read from disk at most once per
+// staticFileCacheTime.
+type staticFile struct {
+	sync.Mutex
+	path        string
+	contentType string
+	cached      []byte
+	readAt      time.Time
+}
+
+func (f *staticFile) content() ([]byte, error) {
+	f.Lock()
+	defer f.Unlock()
+	if f.cached != nil && time.Since(f.readAt) < staticFileCacheTime {
+		return f.cached, nil
+	}
+	content, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return nil, err
+	}
+	f.cached, f.readAt = content, time.Now()
+	return content, nil
+}
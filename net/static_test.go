@@ -0,0 +1,62 @@
+package net
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublicGatewayServeFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "static")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := path.Join(dir, "drand-chain")
+	require.NoError(t, ioutil.WriteFile(file, []byte(`{"period":30}`), 0600))
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("next"))
+	})
+	g, err := NewRESTPublicGateway(context.Background(), "127.0.0.1:0", "", "", nil, next, nil, true)
+	require.NoError(t, err)
+	g.StartAll()
+	defer g.StopAll(context.Background())
+
+	require.Error(t, g.ServeFile("well-known", file, "application/json"))
+	require.Error(t, g.ServeFile("/.well-known/", dir, "application/json"))
+	require.Error(t, g.ServeFile("/.well-known/none", path.Join(dir, "none"), "application/json"))
+	require.NoError(t, g.ServeFile("/.well-known/drand-chain", file, "application/json"))
+
+	get := func(p string) (int, string, string) {
+		resp, err := http.Get("http://" + g.Addr() + p)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, resp.Header.Get("Content-Type"), string(body)
+	}
+	code, ctype, body := get("/.well-known/drand-chain")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "application/json", ctype)
+	require.Equal(t, `{"period":30}`, body)
+	_, _, body = get("/info")
+	require.Equal(t, "next", body)
+
+	// the file is cached, then read again once the cache expired
+	require.NoError(t, ioutil.WriteFile(file, []byte(`{"period":3}`), 0600))
+	_, _, body = get("/.well-known/drand-chain")
+	require.Equal(t, `{"period":30}`, body)
+	defer func(d time.Duration) { staticFileCacheTime = d }(staticFileCacheTime)
+	staticFileCacheTime = 0
+	_, _, body = get("/.well-known/drand-chain")
+	require.Equal(t, `{"period":3}`, body)
+
+	require.NoError(t, os.Remove(file))
+	code, _, _ = get("/.well-known/drand-chain")
+	require.Equal(t, http.StatusNotFound, code)
+}
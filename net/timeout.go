@@ -0,0 +1,228 @@
+package net
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/drand/drand/protobuf/drand"
+)
+
+// ServiceTimeouts holds the maximum duration of each method of the public and
+// protocol APIs. A zero duration means no timeout. The control API is local
+// and some of its calls, such as InitDKG, wait for other nodes on purpose, so
+// it is never timed out.
+type ServiceTimeouts struct {
+	PublicRand           time.Duration
+	PrivateRand          time.Duration
+	ChainInfo            time.Duration
+	Home                 time.Duration
+	GetIdentity          time.Duration
+	SignalDKGParticipant time.Duration
+	AnnounceJoin         time.Duration
+	PushDKGInfo          time.Duration
+	BroadcastDKG         time.Duration
+	PartialBeacon        time.Duration
+	GroupFile            time.Duration
+	// For the streaming methods, the timeout only applies until the first
+	// message is sent. The stream then lives as long as the request.
+	PublicRandStream time.Duration
+	SyncChain        time.Duration
+	PushSync         time.Duration
+}
+
+// WithTimeouts returns a service that calls s with the context of each request
+// bounded by the timeout of the method, so a slow handler can not hold the
+// resources of the node indefinitely.
+func WithTimeouts(s Service, timeouts ServiceTimeouts) Service {
+	return &timeoutService{Service: s, t: timeouts}
+}
+
+type timeoutService struct {
+	Service
+	t ServiceTimeouts
+}
+
+// withTimeout returns the context of a unary call bounded by the timeout d.
+func withTimeout(c context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return c, func() {}
+	}
+	return context.WithTimeout(c, d)
+}
+
+func (t *timeoutService) PublicRand(c context.Context, in *drand.PublicRandRequest) (*drand.PublicRandResponse, error) {
+	c, cancel := withTimeout(c, t.t.PublicRand)
+	defer cancel()
+	return t.Service.PublicRand(c, in)
+}
+
+func (t *timeoutService) PrivateRand(c context.Context, in *drand.PrivateRandRequest) (*drand.PrivateRandResponse, error) {
+	c, cancel := withTimeout(c, t.t.PrivateRand)
+	defer cancel()
+	return t.Service.PrivateRand(c, in)
+}
+
+func (t *timeoutService) ChainInfo(c context.Context, in *drand.ChainInfoRequest) (*drand.ChainInfoPacket, error) {
+	c, cancel := withTimeout(c, t.t.ChainInfo)
+	defer cancel()
+	return t.Service.ChainInfo(c, in)
+}
+
+func (t *timeoutService) Home(c context.Context, in *drand.HomeRequest) (*drand.HomeResponse, error) {
+	c, cancel := withTimeout(c, t.t.Home)
+	defer cancel()
+	return t.Service.Home(c, in)
+}
+
+func (t *timeoutService) GetIdentity(c context.Context, in *drand.IdentityRequest) (*drand.Identity, error) {
+	c, cancel := withTimeout(c, t.t.GetIdentity)
+	defer cancel()
+	return t.Service.GetIdentity(c, in)
+}
+
+func (t *timeoutService) SignalDKGParticipant(c context.Context, in *drand.SignalDKGPacket) (*drand.Empty, error) {
+	c, cancel := withTimeout(c, t.t.SignalDKGParticipant)
+	defer cancel()
+	return t.Service.SignalDKGParticipant(c, in)
+}
+
+func (t *timeoutService) AnnounceJoin(c context.Context, in *drand.SignalDKGPacket) (*drand.Empty, error) {
+	c, cancel := withTimeout(c, t.t.AnnounceJoin)
+	defer cancel()
+	return t.Service.AnnounceJoin(c, in)
+}
+
+func (t *timeoutService) PushDKGInfo(c context.Context, in *drand.DKGInfoPacket) (*drand.Empty, error) {
+	c, cancel := withTimeout(c, t.t.PushDKGInfo)
+	defer cancel()
+	return t.Service.PushDKGInfo(c, in)
+}
+
+func (t *timeoutService) BroadcastDKG(c context.Context, in *drand.DKGPacket) (*drand.Empty, error) {
+	c, cancel := withTimeout(c, t.t.BroadcastDKG)
+	defer cancel()
+	return t.Service.BroadcastDKG(c, in)
+}
+
+func (t *timeoutService) PartialBeacon(c context.Context, in *drand.PartialBeaconPacket) (*drand.Empty, error) {
+	c, cancel := withTimeout(c, t.t.PartialBeacon)
+	defer cancel()
+	return t.Service.PartialBeacon(c, in)
+}
+
+func (t *timeoutService) GroupFile(c context.Context, in *drand.GroupRequest) (*drand.GroupPacket, error) {
+	c, cancel := withTimeout(c, t.t.GroupFile)
+	defer cancel()
+	return t.Service.GroupFile(c, in)
+}
+
+func (t *timeoutService) PublicRandStream(in *drand.PublicRandRequest, stream drand.Public_PublicRandStreamServer) error {
+	if t.t.PublicRandStream <= 0 {
+		return t.Service.PublicRandStream(in, stream)
+	}
+	fs := newFirstSend(stream.Context(), t.t.PublicRandStream)
+	defer fs.stop()
+	return t.Service.PublicRandStream(in, &publicRandStream{stream, fs})
+}
+
+func (t *timeoutService) SyncChain(in *drand.SyncRequest, stream drand.Protocol_SyncChainServer) error {
+	if t.t.SyncChain <= 0 {
+		return t.Service.SyncChain(in, stream)
+	}
+	fs := newFirstSend(stream.Context(), t.t.SyncChain)
+	defer fs.stop()
+	return t.Service.SyncChain(in, &syncChainStream{stream, fs})
+}
+
+func (t *timeoutService) PushSync(in *drand.SyncRequest, stream drand.Protocol_PushSyncServer) error {
+	if t.t.PushSync <= 0 {
+		return t.Service.PushSync(in, stream)
+	}
+	fs := newFirstSend(stream.Context(), t.t.PushSync)
+	defer fs.stop()
+	return t.Service.PushSync(in, &pushSyncStream{stream, fs})
+}
+
+// firstSendContext is canceled with its parent, or with a deadline exceeded
+// error when the first message of a stream is not sent in time.
+type firstSendContext struct {
+	context.Context
+	expired int32
+}
+
+func (c *firstSendContext) Err() error {
+	if atomic.LoadInt32(&c.expired) == 1 {
+		return context.DeadlineExceeded
+	}
+	return c.Context.Err()
+}
+
+// firstSend holds the context of a stream until its first message is sent.
+type firstSend struct {
+	ctx    *firstSendContext
+	cancel context.CancelFunc
+	timer  *time.Timer
+}
+
+func newFirstSend(parent context.Context, d time.Duration) *firstSend {
+	ctx, cancel := context.WithCancel(parent)
+	fs := &firstSend{ctx: &firstSendContext{Context: ctx}, cancel: cancel}
+	fs.timer = time.AfterFunc(d, func() {
+		atomic.StoreInt32(&fs.ctx.expired, 1)
+		cancel()
+	})
+	return fs
+}
+
+// sent stops the timeout once a message has been sent in time.
+func (fs *firstSend) sent(err error) error {
+	if err == nil {
+		fs.timer.Stop()
+	}
+	return err
+}
+
+func (fs *firstSend) stop() {
+	fs.timer.Stop()
+	fs.cancel()
+}
+
+type publicRandStream struct {
+	drand.Public_PublicRandStreamServer
+	fs *firstSend
+}
+
+func (s *publicRandStream) Context() context.Context {
+	return s.fs.ctx
+}
+
+func (s *publicRandStream) Send(r *drand.PublicRandResponse) error {
+	return s.fs.sent(s.Public_PublicRandStreamServer.Send(r))
+}
+
+type syncChainStream struct {
+	drand.Protocol_SyncChainServer
+	fs *firstSend
+}
+
+func (s *syncChainStream) Context() context.Context {
+	return s.fs.ctx
+}
+
+func (s *syncChainStream) Send(b *drand.BeaconPacket) error {
+	return s.fs.sent(s.Protocol_SyncChainServer.Send(b))
+}
+
+type pushSyncStream struct {
+	drand.Protocol_PushSyncServer
+	fs *firstSend
+}
+
+func (s *pushSyncStream) Context() context.Context {
+	return s.fs.ctx
+}
+
+func (s *pushSyncStream) Send(b *drand.BeaconBatch) error {
+	return s.fs.sent(s.Protocol_PushSyncServer.Send(b))
+}
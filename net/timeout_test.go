@@ -0,0 +1,87 @@
+package net
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/protobuf/drand"
+	testnet "github.com/drand/drand/test/net"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// waitingService blocks every call until its context is done
+type waitingService struct {
+	*testnet.EmptyServer
+}
+
+func (w *waitingService) PartialBeacon(c context.Context, in *drand.PartialBeaconPacket) (*drand.Empty, error) {
+	<-c.Done()
+	return nil, c.Err()
+}
+
+// SyncChain sends one beacon if a round is requested and then waits
+func (w *waitingService) SyncChain(req *drand.SyncRequest, stream drand.Protocol_SyncChainServer) error {
+	if req.GetFromRound() > 0 {
+		if err := stream.Send(&drand.BeaconPacket{Round: req.GetFromRound()}); err != nil {
+			return err
+		}
+	}
+	<-stream.Context().Done()
+	return stream.Context().Err()
+}
+
+type testSyncStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent []*drand.BeaconPacket
+}
+
+func (t *testSyncStream) Context() context.Context {
+	return t.ctx
+}
+
+func (t *testSyncStream) Send(b *drand.BeaconPacket) error {
+	t.sent = append(t.sent, b)
+	return nil
+}
+
+func TestServiceTimeouts(t *testing.T) {
+	timeout := 50 * time.Millisecond
+	s := WithTimeouts(&waitingService{}, ServiceTimeouts{
+		PartialBeacon: timeout,
+		SyncChain:     timeout,
+	})
+
+	start := time.Now()
+	_, err := s.PartialBeacon(context.Background(), new(drand.PartialBeaconPacket))
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+	require.True(t, time.Since(start) >= timeout)
+
+	// no beacon is sent before the timeout
+	stream := &testSyncStream{ctx: context.Background()}
+	err = s.SyncChain(new(drand.SyncRequest), stream)
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+	require.Empty(t, stream.sent)
+
+	// once the first beacon is sent, the stream lives as long as the request
+	ctx, cancel := context.WithCancel(context.Background())
+	stream = &testSyncStream{ctx: ctx}
+	done := make(chan error, 1)
+	go func() { done <- s.SyncChain(&drand.SyncRequest{FromRound: 1}, stream) }()
+	select {
+	case err := <-done:
+		require.FailNow(t, "stream ended after the timeout", "err: %v", err)
+	case <-time.After(4 * timeout):
+	}
+	cancel()
+	select {
+	case err := <-done:
+		require.True(t, errors.Is(err, context.Canceled))
+	case <-time.After(time.Second):
+		require.FailNow(t, "stream not ended with the request")
+	}
+	require.Len(t, stream.sent, 1)
+}
@@ -40,6 +40,27 @@ type ControlClient interface {
 	Shutdown(ctx context.Context, in *ShutdownRequest, opts ...grpc.CallOption) (*ShutdownResponse, error)
 	StartFollowChain(ctx context.Context, in *StartFollowRequest, opts ...grpc.CallOption) (Control_StartFollowChainClient, error)
 	BackupDatabase(ctx context.Context, in *BackupDBRequest, opts ...grpc.CallOption) (*BackupDBResponse, error)
+	// Status returns the current state of the beacon of the node
+	Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error)
+	// ListProtocols returns the randomness beacons the node is running
+	ListProtocols(ctx context.Context, in *ListProtocolsRequest, opts ...grpc.CallOption) (*ListProtocolsResponse, error)
+	// ReloadCert installs a new TLS certificate and key in place of the ones
+	// the node uses, and serves them on the new connections without
+	// restarting the node.
+	ReloadCert(ctx context.Context, in *ReloadCertRequest, opts ...grpc.CallOption) (*ReloadCertResponse, error)
+	// UnfreezeBeacon resumes the beacon generation of a frozen node, e.g. one
+	// that froze because it lagged too far behind the chain.
+	UnfreezeBeacon(ctx context.Context, in *UnfreezeRequest, opts ...grpc.CallOption) (*UnfreezeResponse, error)
+	// AbortDKG abandons the DKG or resharing in progress on the node and
+	// brings it back to the state it had before, keeping its key pair and
+	// the group of a previous DKG.
+	AbortDKG(ctx context.Context, in *AbortDKGRequest, opts ...grpc.CallOption) (*AbortDKGResponse, error)
+	// UseKeyFile makes the node read its private key from the given file
+	// instead of from its config folder, for the next DKG or resharing.
+	UseKeyFile(ctx context.Context, in *UseKeyFileRequest, opts ...grpc.CallOption) (*UseKeyFileResponse, error)
+	// SetPeerPriority excludes a node of the group from the aggregation of
+	// the partial beacons with priority 0, or includes it back otherwise.
+	SetPeerPriority(ctx context.Context, in *SetPeerPriorityRequest, opts ...grpc.CallOption) (*SetPeerPriorityResponse, error)
 }
 
 type controlClient struct {
@@ -172,6 +193,69 @@ func (c *controlClient) BackupDatabase(ctx context.Context, in *BackupDBRequest,
 	return out, nil
 }
 
+func (c *controlClient) Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error) {
+	out := new(StatusResponse)
+	err := c.cc.Invoke(ctx, "/drand.Control/Status", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlClient) ListProtocols(ctx context.Context, in *ListProtocolsRequest, opts ...grpc.CallOption) (*ListProtocolsResponse, error) {
+	out := new(ListProtocolsResponse)
+	err := c.cc.Invoke(ctx, "/drand.Control/ListProtocols", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlClient) ReloadCert(ctx context.Context, in *ReloadCertRequest, opts ...grpc.CallOption) (*ReloadCertResponse, error) {
+	out := new(ReloadCertResponse)
+	err := c.cc.Invoke(ctx, "/drand.Control/ReloadCert", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlClient) UnfreezeBeacon(ctx context.Context, in *UnfreezeRequest, opts ...grpc.CallOption) (*UnfreezeResponse, error) {
+	out := new(UnfreezeResponse)
+	err := c.cc.Invoke(ctx, "/drand.Control/UnfreezeBeacon", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlClient) AbortDKG(ctx context.Context, in *AbortDKGRequest, opts ...grpc.CallOption) (*AbortDKGResponse, error) {
+	out := new(AbortDKGResponse)
+	err := c.cc.Invoke(ctx, "/drand.Control/AbortDKG", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlClient) UseKeyFile(ctx context.Context, in *UseKeyFileRequest, opts ...grpc.CallOption) (*UseKeyFileResponse, error) {
+	out := new(UseKeyFileResponse)
+	err := c.cc.Invoke(ctx, "/drand.Control/UseKeyFile", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlClient) SetPeerPriority(ctx context.Context, in *SetPeerPriorityRequest, opts ...grpc.CallOption) (*SetPeerPriorityResponse, error) {
+	out := new(SetPeerPriorityResponse)
+	err := c.cc.Invoke(ctx, "/drand.Control/SetPeerPriority", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ControlServer is the server API for Control service.
 // All implementations should embed UnimplementedControlServer
 // for forward compatibility
@@ -198,6 +282,27 @@ type ControlServer interface {
 	Shutdown(context.Context, *ShutdownRequest) (*ShutdownResponse, error)
 	StartFollowChain(*StartFollowRequest, Control_StartFollowChainServer) error
 	BackupDatabase(context.Context, *BackupDBRequest) (*BackupDBResponse, error)
+	// Status returns the current state of the beacon of the node
+	Status(context.Context, *StatusRequest) (*StatusResponse, error)
+	// ListProtocols returns the randomness beacons the node is running
+	ListProtocols(context.Context, *ListProtocolsRequest) (*ListProtocolsResponse, error)
+	// ReloadCert installs a new TLS certificate and key in place of the ones
+	// the node uses, and serves them on the new connections without
+	// restarting the node.
+	ReloadCert(context.Context, *ReloadCertRequest) (*ReloadCertResponse, error)
+	// UnfreezeBeacon resumes the beacon generation of a frozen node, e.g. one
+	// that froze because it lagged too far behind the chain.
+	UnfreezeBeacon(context.Context, *UnfreezeRequest) (*UnfreezeResponse, error)
+	// AbortDKG abandons the DKG or resharing in progress on the node and
+	// brings it back to the state it had before, keeping its key pair and
+	// the group of a previous DKG.
+	AbortDKG(context.Context, *AbortDKGRequest) (*AbortDKGResponse, error)
+	// UseKeyFile makes the node read its private key from the given file
+	// instead of from its config folder, for the next DKG or resharing.
+	UseKeyFile(context.Context, *UseKeyFileRequest) (*UseKeyFileResponse, error)
+	// SetPeerPriority excludes a node of the group from the aggregation of
+	// the partial beacons with priority 0, or includes it back otherwise.
+	SetPeerPriority(context.Context, *SetPeerPriorityRequest) (*SetPeerPriorityResponse, error)
 }
 
 // UnimplementedControlServer should be embedded to have forward compatible implementations.
@@ -237,6 +342,27 @@ func (UnimplementedControlServer) StartFollowChain(*StartFollowRequest, Control_
 func (UnimplementedControlServer) BackupDatabase(context.Context, *BackupDBRequest) (*BackupDBResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method BackupDatabase not implemented")
 }
+func (UnimplementedControlServer) Status(context.Context, *StatusRequest) (*StatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
+}
+func (UnimplementedControlServer) ListProtocols(context.Context, *ListProtocolsRequest) (*ListProtocolsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListProtocols not implemented")
+}
+func (UnimplementedControlServer) ReloadCert(context.Context, *ReloadCertRequest) (*ReloadCertResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReloadCert not implemented")
+}
+func (UnimplementedControlServer) UnfreezeBeacon(context.Context, *UnfreezeRequest) (*UnfreezeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnfreezeBeacon not implemented")
+}
+func (UnimplementedControlServer) AbortDKG(context.Context, *AbortDKGRequest) (*AbortDKGResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AbortDKG not implemented")
+}
+func (UnimplementedControlServer) UseKeyFile(context.Context, *UseKeyFileRequest) (*UseKeyFileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UseKeyFile not implemented")
+}
+func (UnimplementedControlServer) SetPeerPriority(context.Context, *SetPeerPriorityRequest) (*SetPeerPriorityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetPeerPriority not implemented")
+}
 
 // UnsafeControlServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to ControlServer will
@@ -450,6 +576,132 @@ func _Control_BackupDatabase_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Control_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServer).Status(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/drand.Control/Status",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServer).Status(ctx, req.(*StatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Control_ListProtocols_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListProtocolsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServer).ListProtocols(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/drand.Control/ListProtocols",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServer).ListProtocols(ctx, req.(*ListProtocolsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Control_ReloadCert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReloadCertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServer).ReloadCert(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/drand.Control/ReloadCert",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServer).ReloadCert(ctx, req.(*ReloadCertRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Control_UnfreezeBeacon_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnfreezeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServer).UnfreezeBeacon(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/drand.Control/UnfreezeBeacon",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServer).UnfreezeBeacon(ctx, req.(*UnfreezeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Control_AbortDKG_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AbortDKGRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServer).AbortDKG(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/drand.Control/AbortDKG",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServer).AbortDKG(ctx, req.(*AbortDKGRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Control_UseKeyFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UseKeyFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServer).UseKeyFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/drand.Control/UseKeyFile",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServer).UseKeyFile(ctx, req.(*UseKeyFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Control_SetPeerPriority_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetPeerPriorityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServer).SetPeerPriority(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/drand.Control/SetPeerPriority",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServer).SetPeerPriority(ctx, req.(*SetPeerPriorityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Control_ServiceDesc is the grpc.ServiceDesc for Control service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -497,6 +749,34 @@ var Control_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "BackupDatabase",
 			Handler:    _Control_BackupDatabase_Handler,
 		},
+		{
+			MethodName: "Status",
+			Handler:    _Control_Status_Handler,
+		},
+		{
+			MethodName: "ListProtocols",
+			Handler:    _Control_ListProtocols_Handler,
+		},
+		{
+			MethodName: "ReloadCert",
+			Handler:    _Control_ReloadCert_Handler,
+		},
+		{
+			MethodName: "UnfreezeBeacon",
+			Handler:    _Control_UnfreezeBeacon_Handler,
+		},
+		{
+			MethodName: "AbortDKG",
+			Handler:    _Control_AbortDKG_Handler,
+		},
+		{
+			MethodName: "UseKeyFile",
+			Handler:    _Control_UseKeyFile_Handler,
+		},
+		{
+			MethodName: "SetPeerPriority",
+			Handler:    _Control_SetPeerPriority_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
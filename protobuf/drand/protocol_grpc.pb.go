@@ -24,6 +24,10 @@ type ProtocolClient interface {
 	// public keys and secret proof they have to the coordinator so that he can
 	// create the group.
 	SignalDKGParticipant(ctx context.Context, in *SignalDKGPacket, opts ...grpc.CallOption) (*Empty, error)
+	// AnnounceJoin is called by a node that wishes to join the running chain of
+	// the coordinator. The coordinator checks the node builds on its current
+	// group so the node can then wait to be included in the next resharing.
+	AnnounceJoin(ctx context.Context, in *SignalDKGPacket, opts ...grpc.CallOption) (*Empty, error)
 	// PushDKGInfo is called by the coordinator to push the group he created
 	// from all received keys and as well other information such as the time of
 	// starting the DKG.
@@ -34,6 +38,14 @@ type ProtocolClient interface {
 	PartialBeacon(ctx context.Context, in *PartialBeaconPacket, opts ...grpc.CallOption) (*Empty, error)
 	// SyncRequest forces a daemon to sync up its chain with other nodes
 	SyncChain(ctx context.Context, in *SyncRequest, opts ...grpc.CallOption) (Protocol_SyncChainClient, error)
+	// PushSync is similar to SyncChain but the node pushes the stored beacons
+	// by batches as fast as the network allows, then keeps pushing the new
+	// ones. It fails right away if the node has no beacon from the requested
+	// round.
+	PushSync(ctx context.Context, in *SyncRequest, opts ...grpc.CallOption) (Protocol_PushSyncClient, error)
+	// GroupFile returns the group of the chain the node participates to, so a
+	// new node can fetch it before joining the chain.
+	GroupFile(ctx context.Context, in *GroupRequest, opts ...grpc.CallOption) (*GroupPacket, error)
 }
 
 type protocolClient struct {
@@ -62,6 +74,15 @@ func (c *protocolClient) SignalDKGParticipant(ctx context.Context, in *SignalDKG
 	return out, nil
 }
 
+func (c *protocolClient) AnnounceJoin(ctx context.Context, in *SignalDKGPacket, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/drand.Protocol/AnnounceJoin", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *protocolClient) PushDKGInfo(ctx context.Context, in *DKGInfoPacket, opts ...grpc.CallOption) (*Empty, error) {
 	out := new(Empty)
 	err := c.cc.Invoke(ctx, "/drand.Protocol/PushDKGInfo", in, out, opts...)
@@ -121,6 +142,47 @@ func (x *protocolSyncChainClient) Recv() (*BeaconPacket, error) {
 	return m, nil
 }
 
+func (c *protocolClient) PushSync(ctx context.Context, in *SyncRequest, opts ...grpc.CallOption) (Protocol_PushSyncClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Protocol_ServiceDesc.Streams[1], "/drand.Protocol/PushSync", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &protocolPushSyncClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Protocol_PushSyncClient interface {
+	Recv() (*BeaconBatch, error)
+	grpc.ClientStream
+}
+
+type protocolPushSyncClient struct {
+	grpc.ClientStream
+}
+
+func (x *protocolPushSyncClient) Recv() (*BeaconBatch, error) {
+	m := new(BeaconBatch)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *protocolClient) GroupFile(ctx context.Context, in *GroupRequest, opts ...grpc.CallOption) (*GroupPacket, error) {
+	out := new(GroupPacket)
+	err := c.cc.Invoke(ctx, "/drand.Protocol/GroupFile", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ProtocolServer is the server API for Protocol service.
 // All implementations should embed UnimplementedProtocolServer
 // for forward compatibility
@@ -131,6 +193,10 @@ type ProtocolServer interface {
 	// public keys and secret proof they have to the coordinator so that he can
 	// create the group.
 	SignalDKGParticipant(context.Context, *SignalDKGPacket) (*Empty, error)
+	// AnnounceJoin is called by a node that wishes to join the running chain of
+	// the coordinator. The coordinator checks the node builds on its current
+	// group so the node can then wait to be included in the next resharing.
+	AnnounceJoin(context.Context, *SignalDKGPacket) (*Empty, error)
 	// PushDKGInfo is called by the coordinator to push the group he created
 	// from all received keys and as well other information such as the time of
 	// starting the DKG.
@@ -141,6 +207,14 @@ type ProtocolServer interface {
 	PartialBeacon(context.Context, *PartialBeaconPacket) (*Empty, error)
 	// SyncRequest forces a daemon to sync up its chain with other nodes
 	SyncChain(*SyncRequest, Protocol_SyncChainServer) error
+	// PushSync is similar to SyncChain but the node pushes the stored beacons
+	// by batches as fast as the network allows, then keeps pushing the new
+	// ones. It fails right away if the node has no beacon from the requested
+	// round.
+	PushSync(*SyncRequest, Protocol_PushSyncServer) error
+	// GroupFile returns the group of the chain the node participates to, so a
+	// new node can fetch it before joining the chain.
+	GroupFile(context.Context, *GroupRequest) (*GroupPacket, error)
 }
 
 // UnimplementedProtocolServer should be embedded to have forward compatible implementations.
@@ -153,6 +227,9 @@ func (UnimplementedProtocolServer) GetIdentity(context.Context, *IdentityRequest
 func (UnimplementedProtocolServer) SignalDKGParticipant(context.Context, *SignalDKGPacket) (*Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SignalDKGParticipant not implemented")
 }
+func (UnimplementedProtocolServer) AnnounceJoin(context.Context, *SignalDKGPacket) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AnnounceJoin not implemented")
+}
 func (UnimplementedProtocolServer) PushDKGInfo(context.Context, *DKGInfoPacket) (*Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method PushDKGInfo not implemented")
 }
@@ -165,6 +242,12 @@ func (UnimplementedProtocolServer) PartialBeacon(context.Context, *PartialBeacon
 func (UnimplementedProtocolServer) SyncChain(*SyncRequest, Protocol_SyncChainServer) error {
 	return status.Errorf(codes.Unimplemented, "method SyncChain not implemented")
 }
+func (UnimplementedProtocolServer) PushSync(*SyncRequest, Protocol_PushSyncServer) error {
+	return status.Errorf(codes.Unimplemented, "method PushSync not implemented")
+}
+func (UnimplementedProtocolServer) GroupFile(context.Context, *GroupRequest) (*GroupPacket, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GroupFile not implemented")
+}
 
 // UnsafeProtocolServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to ProtocolServer will
@@ -213,6 +296,24 @@ func _Protocol_SignalDKGParticipant_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Protocol_AnnounceJoin_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignalDKGPacket)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProtocolServer).AnnounceJoin(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/drand.Protocol/AnnounceJoin",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProtocolServer).AnnounceJoin(ctx, req.(*SignalDKGPacket))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Protocol_PushDKGInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(DKGInfoPacket)
 	if err := dec(in); err != nil {
@@ -288,6 +389,45 @@ func (x *protocolSyncChainServer) Send(m *BeaconPacket) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _Protocol_PushSync_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SyncRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ProtocolServer).PushSync(m, &protocolPushSyncServer{stream})
+}
+
+type Protocol_PushSyncServer interface {
+	Send(*BeaconBatch) error
+	grpc.ServerStream
+}
+
+type protocolPushSyncServer struct {
+	grpc.ServerStream
+}
+
+func (x *protocolPushSyncServer) Send(m *BeaconBatch) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Protocol_GroupFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProtocolServer).GroupFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/drand.Protocol/GroupFile",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProtocolServer).GroupFile(ctx, req.(*GroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Protocol_ServiceDesc is the grpc.ServiceDesc for Protocol service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -303,6 +443,10 @@ var Protocol_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SignalDKGParticipant",
 			Handler:    _Protocol_SignalDKGParticipant_Handler,
 		},
+		{
+			MethodName: "AnnounceJoin",
+			Handler:    _Protocol_AnnounceJoin_Handler,
+		},
 		{
 			MethodName: "PushDKGInfo",
 			Handler:    _Protocol_PushDKGInfo_Handler,
@@ -315,6 +459,10 @@ var Protocol_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "PartialBeacon",
 			Handler:    _Protocol_PartialBeacon_Handler,
 		},
+		{
+			MethodName: "GroupFile",
+			Handler:    _Protocol_GroupFile_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -322,6 +470,11 @@ var Protocol_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _Protocol_SyncChain_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "PushSync",
+			Handler:       _Protocol_PushSync_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "drand/protocol.proto",
 }
@@ -7,6 +7,7 @@ type KeyStore struct {
 	share *key.Share
 	group *key.Group
 	dist  *key.DistPublic
+	dkg   *key.DKGState
 }
 
 func NewKeyStore() key.Store {
@@ -31,11 +32,22 @@ func (k *KeyStore) LoadShare() (*key.Share, error) {
 	return k.share, nil
 }
 
-func (k *KeyStore) SaveGroup(g *key.Group) error {
+func (k *KeyStore) SaveGroup(g *key.Group, overwrite bool) error {
+	if k.group != nil && !overwrite && !k.group.Equal(g) {
+		return key.ErrGroupExists
+	}
 	k.group = g
 	return nil
 }
 
+func (k *KeyStore) SaveGroupAndShare(g *key.Group, s *key.Share, overwrite bool) error {
+	if err := k.SaveGroup(g, overwrite); err != nil {
+		return err
+	}
+	k.share = s
+	return nil
+}
+
 func (k *KeyStore) LoadGroup() (*key.Group, error) {
 	return k.group, nil
 }
@@ -48,9 +60,28 @@ func (k *KeyStore) LoadDistPublic() (*key.DistPublic, error) {
 	return k.dist, nil
 }
 
+func (k *KeyStore) SaveDKGState(s *key.DKGState) error {
+	k.dkg = s
+	return nil
+}
+
+func (k *KeyStore) LoadDKGState() (*key.DKGState, error) {
+	return k.dkg, nil
+}
+
+func (k *KeyStore) DeleteDKGState() error {
+	k.dkg = nil
+	return nil
+}
+
 func (k *KeyStore) Reset(...key.ResetOption) error {
+	k.dkg = nil
 	k.group = nil
 	k.dist = nil
 	k.share = nil
 	return nil
 }
+
+func (k *KeyStore) Close() error {
+	return nil
+}
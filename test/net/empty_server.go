@@ -44,6 +44,11 @@ func (s *EmptyServer) SignalDKGParticipant(context.Context, *drand.SignalDKGPack
 	return nil, nil
 }
 
+// AnnounceJoin is an empty implementation
+func (s *EmptyServer) AnnounceJoin(context.Context, *drand.SignalDKGPacket) (*drand.Empty, error) {
+	return nil, nil
+}
+
 // PushDKGInfo is an empty implementation
 func (s *EmptyServer) PushDKGInfo(context.Context, *drand.DKGInfoPacket) (*drand.Empty, error) {
 	return nil, nil
@@ -59,6 +64,11 @@ func (s *EmptyServer) SyncChain(*drand.SyncRequest, drand.Protocol_SyncChainServ
 	return nil
 }
 
+// PushSync is an empty implementation
+func (s *EmptyServer) PushSync(*drand.SyncRequest, drand.Protocol_PushSyncServer) error {
+	return nil
+}
+
 // StartFollowChain is the control method to instruct a drand daemon to follow
 // its chain
 func (s *EmptyServer) StartFollowChain(*drand.StartFollowRequest, drand.Control_StartFollowChainServer) error {
@@ -119,3 +129,38 @@ func (s *EmptyServer) Shutdown(context.Context, *drand.ShutdownRequest) (*drand.
 func (s *EmptyServer) BackupDatabase(context.Context, *drand.BackupDBRequest) (*drand.BackupDBResponse, error) {
 	return nil, nil
 }
+
+// Status is an empty implementation
+func (s *EmptyServer) Status(context.Context, *drand.StatusRequest) (*drand.StatusResponse, error) {
+	return nil, nil
+}
+
+// ListProtocols is an empty implementation
+func (s *EmptyServer) ListProtocols(context.Context, *drand.ListProtocolsRequest) (*drand.ListProtocolsResponse, error) {
+	return nil, nil
+}
+
+// ReloadCert is an empty implementation
+func (s *EmptyServer) ReloadCert(context.Context, *drand.ReloadCertRequest) (*drand.ReloadCertResponse, error) {
+	return nil, nil
+}
+
+// UnfreezeBeacon is an empty implementation
+func (s *EmptyServer) UnfreezeBeacon(context.Context, *drand.UnfreezeRequest) (*drand.UnfreezeResponse, error) {
+	return nil, nil
+}
+
+// AbortDKG is an empty implementation
+func (s *EmptyServer) AbortDKG(context.Context, *drand.AbortDKGRequest) (*drand.AbortDKGResponse, error) {
+	return nil, nil
+}
+
+// UseKeyFile is an empty implementation
+func (s *EmptyServer) UseKeyFile(context.Context, *drand.UseKeyFileRequest) (*drand.UseKeyFileResponse, error) {
+	return nil, nil
+}
+
+// SetPeerPriority is an empty implementation
+func (s *EmptyServer) SetPeerPriority(context.Context, *drand.SetPeerPriorityRequest) (*drand.SetPeerPriorityResponse, error) {
+	return nil, nil
+}